@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-    http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -54,9 +54,9 @@ func runGrpcService() {
 
 	log := logger.GetLogger()
 
-	storage := NewDbStorage()
+	storage := NewCachedStorage(NewInstrumentedStorage(NewDbStorage()))
 
-	err := grpcService.RunService(port, storage, log)
+	err := grpcService.RunService(port, storage, log, NewDefaultLocation(), NewAuthenticator())
 	if err != nil {
 		log.Fatalf("can't run grpc service %s\n", err)
 	}
@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-    http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -94,7 +94,7 @@ func runHttpService() {
 	}
 
 	// run http service
-	err := httpService.RunService(port, storage, log, metrics)
+	err := httpService.RunService(port, NewCachedStorage(NewInstrumentedStorage(storage)), log, metrics, NewDefaultLocation(), NewAuthenticator())
 	if err != nil {
 		log.Fatalf("can't run http service %s\n", err)
 	}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/logger"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/sql"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate schema of db",
+	Long: `Migrate schema of db from 'db' key of config by embedded versioned migrations.
+Applied versions are kept in schema_version table, schema migrated by flyway before is baselined.`,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all not applied migrations",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runMigrator(func(ctx context.Context, migrator *sql.Migrator) (int, error) {
+			return migrator.Up(ctx)
+		})
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Undo the last applied migration",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runMigrator(func(ctx context.Context, migrator *sql.Migrator) (int, error) {
+			return migrator.Down(ctx)
+		})
+	},
+}
+
+var migrateToCmd = &cobra.Command{
+	Use:   "to <version>",
+	Short: "Apply or undo migrations until schema has version",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			logger.GetLogger().Fatalf("version must be integer instead of `%s`", args[0])
+		}
+		runMigrator(func(ctx context.Context, migrator *sql.Migrator) (int, error) {
+			return migrator.To(ctx, version)
+		})
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print state of every migration",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		printMigrationStatus()
+	},
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateToCmd, migrateStatusCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
+
+// Migrator of db from `db` key of config
+func NewMigrator() *sql.Migrator {
+	log := logger.GetLogger()
+
+	dbConf := viper.GetStringMapString("db")
+	if len(dbConf) == 0 {
+		log.Fatal("can't migrate, db settings not found in `db` key of config")
+	}
+
+	dbConfig, err := sql.NewConfig(dbConf)
+	if err != nil {
+		log.Fatalf("can't init migrator %s\n", err)
+	}
+
+	migrator, err := sql.NewMigrator(*dbConfig)
+	if err != nil {
+		log.Fatalf("can't init migrator %s\n", err)
+	}
+
+	return migrator
+}
+
+// Run migration, interrupt signal cancels current migration (it is rolled back)
+func runMigrator(migrate func(ctx context.Context, migrator *sql.Migrator) (int, error)) {
+	log := logger.GetLogger()
+
+	migrator := NewMigrator()
+	defer func() {
+		_ = migrator.Close()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	count, err := migrate(ctx, migrator)
+	if err != nil {
+		log.Fatalf("migration failed after %d migrations %s\n", count, err)
+	}
+
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		log.Fatalf("can't get version of schema %s\n", err)
+	}
+
+	fmt.Printf("%d migrations done, schema version %d\n", count, version)
+}
+
+// Print version, name and time of applying of every migration
+func printMigrationStatus() {
+	log := logger.GetLogger()
+
+	migrator := NewMigrator()
+	defer func() {
+		_ = migrator.Close()
+	}()
+
+	statuses, err := migrator.Status(context.Background())
+	if err != nil {
+		log.Fatalf("can't get status of migrations %s\n", err)
+	}
+
+	for _, status := range statuses {
+		applied := "pending"
+		if status.Applied {
+			applied = "applied"
+			if !status.AppliedTime.IsZero() {
+				applied += " " + status.AppliedTime.Format(time.RFC3339)
+			}
+		}
+		fmt.Printf("%3d  %-16s %s\n", status.Version, status.Name, applied)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/logger"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/trash"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var purgerCmd = &cobra.Command{
+	Use:   "purger",
+	Short: "A trash purger",
+	Long:  `A trash purger, delete permanently events that were moved to trash more than retention ago.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTrashPurger()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(purgerCmd)
+}
+
+// Run trash purger
+func runTrashPurger() {
+
+	log := logger.GetLogger()
+
+	tConf := cast.ToStringMapString(viper.Get("trash"))
+
+	retentionVal, ok := tConf["retention"]
+	if !ok {
+		retentionVal = "720h"
+	}
+
+	retention, err := time.ParseDuration(retentionVal)
+	if err != nil {
+		log.Fatalf("can't init purger, fail on parsing `retention` value == `%s`", retentionVal)
+	}
+
+	intervalVal, ok := tConf["purge_interval"]
+	if !ok {
+		intervalVal = "1h"
+	}
+
+	interval, err := time.ParseDuration(intervalVal)
+	if err != nil {
+		log.Fatalf("can't init purger, fail on parsing `purge_interval` value == `%s`", intervalVal)
+	}
+
+	storage := NewInstrumentedStorage(NewDbStorage())
+
+	purger := trash.NewPurger(
+		interval,
+		retention,
+		storage,
+		log,
+	)
+
+	err = purger.Run()
+	if err != nil {
+		log.Fatalf("can't run purger, error happened: %s", err)
+	}
+
+}
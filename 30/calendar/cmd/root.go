@@ -1,14 +1,21 @@
 package cmd
 
 import (
+	"errors"
 	"log"
+	"time"
 
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/auth"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/logger"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/monitoring"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/notificaiton"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/cache"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/file"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/instrumented"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/memory"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/sql"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -69,17 +76,20 @@ func initLogger() {
 	logger.InitLogger(viper.GetViper())
 }
 
+// Storage by `storage.type` key of config: memory, sql (settings in `db` key) or file (settings in `storage` key)
+// Type of storage is resolved by StorageType
 func NewDbStorage() entities.Storage {
 	log := logger.GetLogger()
 
 	var storage entities.Storage
 
-	dbConf := viper.GetStringMapString("db")
+	storageType := StorageType()
 
-	if dbConf == nil {
+	switch storageType {
+	case "memory":
 		storage = memory.NewStorage()
-	} else {
-		dbConfig, err := sql.NewConfig(dbConf)
+	case "sql":
+		dbConfig, err := sql.NewConfig(viper.GetStringMapString("db"))
 		if err != nil {
 			log.Fatalf("can't init sql storage %s\n", err)
 		}
@@ -87,11 +97,99 @@ func NewDbStorage() entities.Storage {
 		if err != nil {
 			log.Fatalf("can't init sql storage %s\n", err)
 		}
+	case "file":
+		fileConfig, err := file.NewConfig(viper.GetStringMapString("storage"))
+		if err != nil {
+			log.Fatalf("can't init file storage %s\n", err)
+		}
+		storage, err = file.NewStorage(*fileConfig, log)
+		if errors.Is(err, file.ErrLocked) {
+			log.Fatalf("can't init file storage, file storage is for one process only (use sql storage to run http, grpc, scheduler and purger together): %s\n", err)
+		}
+		if err != nil {
+			log.Fatalf("can't init file storage %s\n", err)
+		}
+	default:
+		log.Fatalf("can't init storage, unknown type `%s` in `storage.type` key of config\n", storageType)
 	}
 
 	return storage
 }
 
+// Type of storage by `storage.type` key of config, if key is missing it is sql when `db` key is set and memory otherwise
+func StorageType() string {
+	storageType := viper.GetString("storage.type")
+	if storageType == "" {
+		storageType = "memory"
+		if len(viper.GetStringMapString("db")) > 0 {
+			storageType = "sql"
+		}
+	}
+	return storageType
+}
+
+// Storage with latency and error metrics of calls by `storage_metrics` key of config (`slow_threshold`), storage itself if key is missing
+// Metrics are registered in default registry with type of storage, slow calls are logged
+func NewInstrumentedStorage(storage entities.Storage) entities.Storage {
+	if !viper.IsSet("storage_metrics") {
+		return storage
+	}
+
+	log := logger.GetLogger()
+
+	instrumentedConfig, err := instrumented.NewConfig(viper.GetStringMapString("storage_metrics"))
+	if err != nil {
+		log.Fatalf("can't init storage metrics %s\n", err)
+	}
+
+	metrics := monitoring.NewStorageMetrics(prometheus.DefaultRegisterer, StorageType(), log)
+	return instrumented.NewStorage(storage, *instrumentedConfig, metrics, log)
+}
+
+// Storage with read-through cache by `cache` key of config (`size` and `ttl`), storage itself if key is missing
+// Hit/miss metrics of cache are registered in default registry, so they are exported with http metrics
+func NewCachedStorage(storage entities.Storage) entities.Storage {
+	if !viper.IsSet("cache") {
+		return storage
+	}
+
+	log := logger.GetLogger()
+
+	cacheConfig, err := cache.NewConfig(viper.GetStringMapString("cache"))
+	if err != nil {
+		log.Fatalf("can't init storage cache %s\n", err)
+	}
+
+	return cache.NewStorage(storage, *cacheConfig, monitoring.NewCacheMetrics(prometheus.DefaultRegisterer, log))
+}
+
+// Default time zone of requests from `app.timezone` key of config, UTC if key is missing
+func NewDefaultLocation() *time.Location {
+	log := logger.GetLogger()
+
+	tz := viper.GetString("app.timezone")
+
+	loc, err := entities.LoadLocation(tz)
+	if err != nil {
+		log.Fatalf("can't load time zone `%s` from `app.timezone` config %s\n", tz, err)
+	}
+
+	return loc
+}
+
+// Authenticator of http and grpc callers from `auth` key of config, nil (authentication is disabled) if key is missing
+// `auth.secret` is secret of bearer tokens, `auth.api_keys` is map of api keys to ids of users
+func NewAuthenticator() *auth.Authenticator {
+	if !viper.IsSet("auth") {
+		return nil
+	}
+
+	secret := viper.GetString("auth.secret")
+	apiKeys := viper.GetStringMapString("auth.api_keys")
+
+	return auth.NewAuthenticator(apiKeys, secret)
+}
+
 func NewSqlMetrics(storage *sql.Storage) (*monitoring.SqlMetrics, error) {
 
 	log := logger.GetLogger()
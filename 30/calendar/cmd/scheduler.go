@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-    http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -65,11 +65,28 @@ func runNotificationScheduler() {
 		log.Fatal("can't init scheduler, fail on parsing `scan_timeout` value == `%s`", scanTimeoutVal)
 	}
 
+	claimLeaseVal, ok := sConf["claim_lease"]
+	if !ok {
+		claimLeaseVal = "1m"
+	}
+
+	claimLease, err := time.ParseDuration(claimLeaseVal)
+	if err != nil {
+		log.Fatalf("can't init scheduler, fail on parsing `claim_lease` value == `%s`", claimLeaseVal)
+	}
+
+	// claims of memory and file storages are kept in map of process, only sql storage shares them between processes
+	if storageType := StorageType(); storageType != "sql" {
+		log.Warnf("claims of reminders and invitations of %s storage live in this process only and are lost on restart, "+
+			"schedulers of other processes don't see them and could push duplicates, use sql storage to run several schedulers", storageType)
+	}
+
 	queue := NewNotificationQueue()
-	storage := NewDbStorage()
+	storage := NewInstrumentedStorage(NewDbStorage())
 
 	scheduler := notificaiton.NewScheduler(
 		scanTimeout,
+		claimLease,
 		storage,
 		queue,
 		log,
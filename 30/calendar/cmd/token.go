@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var tokenTtl time.Duration
+
+// tokenCmd represents the token command
+var tokenCmd = &cobra.Command{
+	Use:   "token <user>",
+	Short: "Issue bearer token for user",
+	Long:  `Issue bearer token for user signed by secret from 'auth.secret' key of config`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueToken(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.Flags().DurationVar(&tokenTtl, "ttl", 24*time.Hour, "time to live of token, 0 means token never expires")
+}
+
+// Issue bearer token and print it to stdout
+func issueToken(user string) {
+	log := logger.GetLogger()
+
+	authenticator := NewAuthenticator()
+	if authenticator == nil {
+		log.Fatal("can't issue token, auth settings not found in `auth` key of config")
+	}
+
+	token, err := authenticator.IssueToken(user, tokenTtl)
+	if err != nil {
+		log.Fatalf("can't issue token %s\n", err)
+	}
+
+	fmt.Println(token)
+}
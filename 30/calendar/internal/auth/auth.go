@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrorUnauthenticated = errors.New("unauthenticated: api key or bearer token is required")
+	ErrorInvalidApiKey   = errors.New("unauthenticated: invalid api key")
+	ErrorInvalidToken    = errors.New("unauthenticated: invalid bearer token")
+	ErrorTokenExpired    = errors.New("unauthenticated: bearer token is expired")
+)
+
+// Header of bearer tokens, only HS256 (HMAC SHA256) signed tokens are supported
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Claims of bearer token (JWT), sub is id of user, exp is unix time of expiration
+type claims struct {
+	Sub string `json:"sub"`
+	Exp int64  `json:"exp"`
+}
+
+// Authenticator of users by api keys and signed bearer tokens
+// Tokens are JWT signed by HS256 with secret, so they are validated locally without any auth service
+type Authenticator struct {
+	apiKeys map[string]string // api key -> id of user
+	secret  []byte            // secret of HMAC signature of tokens, empty secret means tokens are not supported
+	now     func() time.Time  // inject now time, need to tests
+}
+
+// Constructor
+func NewAuthenticator(apiKeys map[string]string, secret string) *Authenticator {
+	keys := make(map[string]string, len(apiKeys))
+	for key, user := range apiKeys {
+		keys[key] = user
+	}
+	return &Authenticator{
+		apiKeys: keys,
+		secret:  []byte(secret),
+		now:     time.Now,
+	}
+}
+
+// Authenticate user by api key (if not empty) or by value of Authorization header ("Bearer <token>")
+// Return id of user
+func (a *Authenticator) Authenticate(apiKey string, authorization string) (string, error) {
+	if apiKey != "" {
+		return a.AuthenticateApiKey(apiKey)
+	}
+
+	const prefix = "Bearer "
+	if len(authorization) > len(prefix) && strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return a.AuthenticateToken(authorization[len(prefix):])
+	}
+
+	return "", ErrorUnauthenticated
+}
+
+// Authenticate user by api key, return id of user
+func (a *Authenticator) AuthenticateApiKey(apiKey string) (string, error) {
+	user, ok := a.apiKeys[apiKey]
+	if !ok || user == "" {
+		return "", ErrorInvalidApiKey
+	}
+	return user, nil
+}
+
+// Authenticate user by signed bearer token, return id of user
+func (a *Authenticator) AuthenticateToken(token string) (string, error) {
+	if len(a.secret) == 0 {
+		return "", ErrorInvalidToken
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenHeader {
+		return "", ErrorInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, a.sign(parts[0]+"."+parts[1])) {
+		return "", ErrorInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrorInvalidToken
+	}
+
+	c := claims{}
+	err = json.Unmarshal(payload, &c)
+	if err != nil || c.Sub == "" {
+		return "", ErrorInvalidToken
+	}
+
+	if c.Exp != 0 && a.now().Unix() >= c.Exp {
+		return "", ErrorTokenExpired
+	}
+
+	return c.Sub, nil
+}
+
+// Issue bearer token for user, ttl 0 means token never expires
+func (a *Authenticator) IssueToken(user string, ttl time.Duration) (string, error) {
+	if len(a.secret) == 0 {
+		return "", errors.New("secret of tokens is not defined")
+	}
+	if user == "" {
+		return "", errors.New("user must not be empty")
+	}
+
+	c := claims{Sub: user}
+	if ttl > 0 {
+		c.Exp = a.now().Add(ttl).Unix()
+	}
+
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal claims: %w", err)
+	}
+
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(a.sign(unsigned)), nil
+}
+
+// HMAC SHA256 signature
+func (a *Authenticator) sign(data string) []byte {
+	mac := hmac.New(sha256.New, a.secret)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+type contextKey struct{}
+
+// Context with authenticated user
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// Authenticated user from context, empty string if there is no user
+func UserFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(contextKey{}).(string)
+	return user
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuthenticateApiKey(t *testing.T) {
+	a := NewAuthenticator(map[string]string{"secret-key": "team-a"}, "")
+
+	user, err := a.Authenticate("secret-key", "")
+	if err != nil || user != "team-a" {
+		t.Errorf("must be user `team-a` instead of `%s` (%v)", user, err)
+	}
+
+	_, err = a.Authenticate("unknown-key", "")
+	if err != ErrorInvalidApiKey {
+		t.Errorf("must be ErrorInvalidApiKey instead of %v", err)
+	}
+
+	_, err = a.Authenticate("", "")
+	if err != ErrorUnauthenticated {
+		t.Errorf("must be ErrorUnauthenticated instead of %v", err)
+	}
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	a := NewAuthenticator(nil, "top-secret")
+	now := time.Date(2019, 11, 25, 10, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	token, err := a.IssueToken("team-a", time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	user, err := a.Authenticate("", "Bearer "+token)
+	if err != nil || user != "team-a" {
+		t.Errorf("must be user `team-a` instead of `%s` (%v)", user, err)
+	}
+
+	// token signed by other secret
+	other := NewAuthenticator(nil, "other-secret")
+	otherToken, _ := other.IssueToken("team-a", 0)
+	_, err = a.Authenticate("", "Bearer "+otherToken)
+	if err != ErrorInvalidToken {
+		t.Errorf("must be ErrorInvalidToken instead of %v", err)
+	}
+
+	// tampered payload
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "." + parts[1] + "x." + parts[2]
+	_, err = a.Authenticate("", "Bearer "+tampered)
+	if err != ErrorInvalidToken {
+		t.Errorf("must be ErrorInvalidToken instead of %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	_, err = a.Authenticate("", "Bearer "+token)
+	if err != ErrorTokenExpired {
+		t.Errorf("must be ErrorTokenExpired instead of %v", err)
+	}
+
+	_, err = a.Authenticate("", "Basic dXNlcjpwYXNz")
+	if err != ErrorUnauthenticated {
+		t.Errorf("must be ErrorUnauthenticated instead of %v", err)
+	}
+}
+
+func TestUserFromContext(t *testing.T) {
+	if UserFromContext(context.Background()) != "" {
+		t.Error("must be no user in empty context")
+	}
+	ctx := WithUser(context.Background(), "team-a")
+	if UserFromContext(ctx) != "team-a" {
+		t.Errorf("must be user `team-a` instead of `%s`", UserFromContext(ctx))
+	}
+}
@@ -0,0 +1,122 @@
+package entities
+
+import (
+	"errors"
+)
+
+// Statuses of response of attendee to invitation
+const (
+	AttendeeStatusNeedsAction = "needs-action" // attendee has not responded yet
+	AttendeeStatusAccepted    = "accepted"
+	AttendeeStatusDeclined    = "declined"
+	AttendeeStatusTentative   = "tentative"
+)
+
+// Error about unknown status of response to invitation
+var ErrInvalidAttendeeStatus = errors.New("status of attendee must be one of needs-action, accepted, declined or tentative")
+
+// Attendee of event with status of response to invitation
+// Every attendee has its own state of delivery of invitation: start and end of event attendee was invited to
+type Attendee struct {
+	email        string   // email of attendee
+	status       string   // one of AttendeeStatus* constants
+	isInvited    bool     // was invitation enqueued
+	invitedStart DateTime // start of event in the last enqueued invitation (or update)
+	invitedEnd   DateTime // end of event in the last enqueued invitation (or update), zero if unknown
+}
+
+// Constructor, attendee has not responded yet
+func NewAttendee(email string) Attendee {
+	return Attendee{
+		email:  email,
+		status: AttendeeStatusNeedsAction,
+	}
+}
+
+// Email getter
+func (attendee Attendee) Email() string {
+	return attendee.email
+}
+
+// Status of response, one of AttendeeStatus* constants
+func (attendee Attendee) Status() string {
+	return attendee.status
+}
+
+// Is attendee going to attend event: invitation is accepted or tentatively accepted
+func (attendee Attendee) IsAttending() bool {
+	return attendee.status == AttendeeStatusAccepted || attendee.status == AttendeeStatusTentative
+}
+
+// Was invitation enqueued
+func (attendee Attendee) IsInvited() bool {
+	return attendee.isInvited
+}
+
+// Start of event in the last enqueued invitation (or update)
+func (attendee Attendee) InvitedStart() DateTime {
+	return attendee.invitedStart
+}
+
+// End of event in the last enqueued invitation (or update), zero if unknown
+func (attendee Attendee) InvitedEnd() DateTime {
+	return attendee.invitedEnd
+}
+
+// Copy of attendee with status of response
+func (attendee Attendee) Responded(status string) Attendee {
+	attendee.status = status
+	return attendee
+}
+
+// Copy of attendee that invited to event with start and end, zero end means that end is unknown
+func (attendee Attendee) Invited(start DateTime, end DateTime) Attendee {
+	attendee.isInvited = true
+	attendee.invitedStart = start
+	attendee.invitedEnd = end
+	return attendee
+}
+
+// Was attendee invited to event with start and end, unknown end of invitation is not compared
+func (attendee Attendee) isInvitedTo(start DateTime, end DateTime) bool {
+	if !attendee.isInvited || !attendee.invitedStart.Equal(start) {
+		return false
+	}
+	return attendee.invitedEnd.Time().IsZero() || attendee.invitedEnd.Equal(end)
+}
+
+// Validate status of response to invitation, return ErrInvalidAttendeeStatus for unknown status
+func ValidateAttendeeStatus(status string) error {
+	switch status {
+	case AttendeeStatusNeedsAction, AttendeeStatusAccepted, AttendeeStatusDeclined, AttendeeStatusTentative:
+		return nil
+	default:
+		return ErrInvalidAttendeeStatus
+	}
+}
+
+// Invitation of attendee to event
+// Invitation is event itself, so it has all info about event
+// Invitation is update if attendee was already invited but time (start or end) of event is changed since then
+type Invitation struct {
+	Event
+	attendee Attendee
+}
+
+// Constructor
+func NewInvitation(event Event, attendee Attendee) Invitation {
+	return Invitation{
+		Event:    event,
+		attendee: attendee,
+	}
+}
+
+// Invited attendee
+func (invitation Invitation) Attendee() Attendee {
+	return invitation.attendee
+}
+
+// Is invitation update about changed time of event
+func (invitation Invitation) IsUpdate() bool {
+	return invitation.attendee.isInvited
+}
@@ -0,0 +1,100 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAttendeeResponded(t *testing.T) {
+	event := NewEvent("Meeting", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 11, 0))
+	event = WithAttendees(event, []string{"alice@example.com", "bob@example.com", "alice@example.com"})
+
+	if attendees := event.Attendees(); !reflect.DeepEqual(attendees, []string{"alice@example.com", "bob@example.com"}) {
+		t.Errorf("attendees must be [alice@example.com bob@example.com] instead of %v", attendees)
+	}
+
+	responded, ok := event.AttendeeResponded("alice@example.com", AttendeeStatusAccepted)
+	if !ok {
+		t.Fatalf("alice must be attendee of event")
+	}
+
+	if status := responded.AttendeeList()[0].Status(); status != AttendeeStatusAccepted {
+		t.Errorf("status of alice must be %s instead of %s", AttendeeStatusAccepted, status)
+	}
+
+	if status := event.AttendeeList()[0].Status(); status != AttendeeStatusNeedsAction {
+		t.Errorf("status of alice in original event must be %s instead of %s", AttendeeStatusNeedsAction, status)
+	}
+
+	_, ok = event.AttendeeResponded("carol@example.com", AttendeeStatusAccepted)
+	if ok {
+		t.Errorf("carol must not be attendee of event")
+	}
+
+	// organizer changes attendees, response of alice is kept, carol is new attendee
+	updated := WithAttendees(event, []string{"carol@example.com", "alice@example.com"})
+	updated = WithAttendeeStatesOf(updated, responded)
+
+	var statuses []string
+	for _, attendee := range updated.AttendeeList() {
+		statuses = append(statuses, attendee.Status())
+	}
+	if !reflect.DeepEqual(statuses, []string{AttendeeStatusNeedsAction, AttendeeStatusAccepted}) {
+		t.Errorf("statuses must be [needs-action accepted] instead of %v", statuses)
+	}
+}
+
+func TestInvitations(t *testing.T) {
+	event := NewEvent("Meeting", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 11, 0))
+	event = WithAttendees(event, []string{"alice@example.com", "bob@example.com"})
+
+	invitations := event.Invitations()
+	if len(invitations) != 2 || invitations[0].IsUpdate() || invitations[1].IsUpdate() {
+		t.Fatalf("must be 2 invitations instead of %+v", invitations)
+	}
+
+	event, _ = event.AttendeeInvited("alice@example.com", event.Start(), event.End())
+
+	invitations = event.Invitations()
+	if len(invitations) != 1 || invitations[0].Attendee().Email() != "bob@example.com" {
+		t.Fatalf("must be invitation of bob only instead of %+v", invitations)
+	}
+
+	// time of event is changed since invitation of alice
+	moved := NewEvent("Meeting", NewDateTime(2019, 11, 25, 12, 0), NewDateTime(2019, 11, 25, 13, 0))
+	moved = WithAttendees(moved, []string{"alice@example.com", "bob@example.com"})
+	moved = WithAttendeeStatesOf(moved, event)
+
+	invitations = moved.Invitations()
+	if len(invitations) != 2 || !invitations[0].IsUpdate() || invitations[1].IsUpdate() {
+		t.Fatalf("must be update for alice and invitation of bob instead of %+v", invitations)
+	}
+
+	// only end of event is changed since invitation of alice
+	extended := NewEvent("Meeting", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 12, 0))
+	extended = WithAttendees(extended, []string{"alice@example.com"})
+	extended = WithAttendeeStatesOf(extended, event)
+
+	invitations = extended.Invitations()
+	if len(invitations) != 1 || !invitations[0].IsUpdate() {
+		t.Fatalf("must be update for alice about changed end instead of %+v", invitations)
+	}
+
+	// end of invitation is unknown, only start is compared
+	legacy := WithAttendeeList(event, []Attendee{NewAttendee("alice@example.com").Invited(event.Start(), DateTime{})})
+	if invitations := legacy.Invitations(); len(invitations) != 0 {
+		t.Errorf("must be no update for invitation with unknown end instead of %+v", invitations)
+	}
+}
+
+func TestValidateAttendeeStatus(t *testing.T) {
+	for _, status := range []string{AttendeeStatusNeedsAction, AttendeeStatusAccepted, AttendeeStatusDeclined, AttendeeStatusTentative} {
+		if err := ValidateAttendeeStatus(status); err != nil {
+			t.Errorf("status %s must be valid, got %s", status, err)
+		}
+	}
+
+	if err := ValidateAttendeeStatus("maybe"); err != ErrInvalidAttendeeStatus {
+		t.Errorf("must be ErrInvalidAttendeeStatus instead of %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package entities
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Actions of audit entries
+const (
+	AuditActionCreate   = "create"
+	AuditActionUpdate   = "update"
+	AuditActionDelete   = "delete"   // event is moved to trash
+	AuditActionRestore  = "restore"  // event is restored from trash
+	AuditActionNotified = "notified" // reminders of event are marked as notified
+	AuditActionRespond  = "respond"  // attendee responded to invitation
+)
+
+// Layout of times of event in field changes, times are local wall clock times in time zone of event
+const auditTimeLayout = "2006-01-02 15:04"
+
+// Change of one field of event, values are human readable strings, empty value means absent value
+type FieldChange struct {
+	field  string
+	before string
+	after  string
+}
+
+// Constructor
+func NewFieldChange(field string, before string, after string) FieldChange {
+	return FieldChange{
+		field:  field,
+		before: before,
+		after:  after,
+	}
+}
+
+// Name of changed field
+func (change FieldChange) Field() string {
+	return change.field
+}
+
+// Value of field before change
+func (change FieldChange) Before() string {
+	return change.before
+}
+
+// Value of field after change
+func (change FieldChange) After() string {
+	return change.after
+}
+
+// Audit entry about change of event: who, what and when changed
+type AuditEntry struct {
+	eventId int
+	owner   string        // owner of changed event
+	actor   string        // id of user who changed event, empty for changes by system (e.g. scheduler) or without authentication
+	action  string        // one of AuditAction* constants
+	time    time.Time     // when event was changed
+	changes []FieldChange // diff of event before and after change
+}
+
+// Constructor
+func NewAuditEntry(eventId int, owner string, actor string, action string, t time.Time, changes []FieldChange) AuditEntry {
+	return AuditEntry{
+		eventId: eventId,
+		owner:   owner,
+		actor:   actor,
+		action:  action,
+		time:    t,
+		changes: changes,
+	}
+}
+
+// Id of changed event
+func (entry AuditEntry) EventId() int {
+	return entry.eventId
+}
+
+// Owner of changed event
+func (entry AuditEntry) Owner() string {
+	return entry.owner
+}
+
+// Id of user who changed event, empty for changes by system or without authentication
+func (entry AuditEntry) Actor() string {
+	return entry.actor
+}
+
+// Action, one of AuditAction* constants
+func (entry AuditEntry) Action() string {
+	return entry.action
+}
+
+// When event was changed
+func (entry AuditEntry) Time() time.Time {
+	return entry.time
+}
+
+// Changed fields of event
+func (entry AuditEntry) Changes() []FieldChange {
+	return entry.changes
+}
+
+// Field of event that is tracked by audit
+type auditField struct {
+	name  string
+	value func(event Event) string
+}
+
+// Tracked fields of event in order of changes in audit entry
+var auditFields = []auditField{
+	{FieldName, func(event Event) string { return event.name }},
+	{FieldStart, func(event Event) string {
+		if event.allDay {
+			return event.StartDate().String()
+		}
+		return event.start.Format(auditTimeLayout)
+	}},
+	{FieldEnd, func(event Event) string {
+		if event.allDay {
+			return event.EndDate().String()
+		}
+		return event.end.Format(auditTimeLayout)
+	}},
+	{"allDay", func(event Event) string { return strconv.FormatBool(event.allDay) }},
+	{"timezone", func(event Event) string { return event.Location().String() }},
+	{"rrule", func(event Event) string {
+		if event.recurrence == nil {
+			return ""
+		}
+		return event.recurrence.String()
+	}},
+	{"exdates", func(event Event) string {
+		if event.recurrence == nil {
+			return ""
+		}
+		exDates := make([]string, 0, len(event.recurrence.ExDates()))
+		for _, exDate := range event.recurrence.ExDates() {
+			exDates = append(exDates, exDate.Format(auditTimeLayout))
+		}
+		return strings.Join(exDates, ", ")
+	}},
+	{"description", func(event Event) string { return event.description }},
+	{"location", func(event Event) string { return event.place }},
+	{"organizer", func(event Event) string { return event.organizer }},
+	{"attendees", func(event Event) string {
+		attendees := make([]string, 0, len(event.attendees))
+		for _, attendee := range event.attendees {
+			str := attendee.email
+			if attendee.status != AttendeeStatusNeedsAction {
+				str += fmt.Sprintf(" (%s)", attendee.status)
+			}
+			attendees = append(attendees, str)
+		}
+		return strings.Join(attendees, ", ")
+	}},
+	{"color", func(event Event) string { return event.color }},
+	{"calendar", func(event Event) string {
+		if event.calendarId == 0 {
+			return ""
+		}
+		return strconv.Itoa(event.calendarId)
+	}},
+	{"reminders", func(event Event) string {
+		reminders := make([]string, 0, len(event.reminders))
+		for _, reminder := range event.reminders {
+			str := strconv.Itoa(reminder.beforeMinutes)
+			if reminder.isNotified {
+				str += fmt.Sprintf(" (notified %s)", reminder.notifiedTime.In(time.UTC).Format(time.RFC3339))
+			}
+			reminders = append(reminders, str)
+		}
+		return strings.Join(reminders, ", ")
+	}},
+	{"deletedTime", func(event Event) string {
+		if !event.IsDeleted() {
+			return ""
+		}
+		return event.deletedTime.In(time.UTC).Format(time.RFC3339)
+	}},
+}
+
+// Diff of tracked fields of event before and after change
+// Nil means absent event, e.g. before is nil for created event, all fields of absent event are empty
+func DiffEvents(before *Event, after *Event) []FieldChange {
+	var changes []FieldChange
+	for _, field := range auditFields {
+		var beforeValue, afterValue string
+		if before != nil {
+			beforeValue = field.value(*before)
+		}
+		if after != nil {
+			afterValue = field.value(*after)
+		}
+		if beforeValue != afterValue {
+			changes = append(changes, NewFieldChange(field.name, beforeValue, afterValue))
+		}
+	}
+	return changes
+}
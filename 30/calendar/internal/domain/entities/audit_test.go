@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffEvents(t *testing.T) {
+	before := NewEvent("Meeting", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 11, 0))
+	before = WithPlace(before, "Room 1")
+
+	after := NewEvent("Meeting", NewDateTime(2019, 11, 25, 12, 0), NewDateTime(2019, 11, 25, 13, 0))
+	after = WithPlace(after, "Room 2")
+	after = WithReminders(after, []Reminder{NewReminder(10)})
+
+	expected := []FieldChange{
+		NewFieldChange(FieldStart, "2019-11-25 10:00", "2019-11-25 12:00"),
+		NewFieldChange(FieldEnd, "2019-11-25 11:00", "2019-11-25 13:00"),
+		NewFieldChange("location", "Room 1", "Room 2"),
+		NewFieldChange("reminders", "", "10"),
+	}
+	if changes := DiffEvents(&before, &after); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected\n%+v\ngot\n%+v", expected, changes)
+	}
+
+	if changes := DiffEvents(&before, &before); changes != nil {
+		t.Errorf("must be no changes of the same event instead of %+v", changes)
+	}
+
+	notified := after.Notified(time.Date(2019, 11, 25, 11, 50, 0, 0, time.UTC))
+	expected = []FieldChange{
+		NewFieldChange("reminders", "10", "10 (notified 2019-11-25T11:50:00Z)"),
+	}
+	if changes := DiffEvents(&after, &notified); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected\n%+v\ngot\n%+v", expected, changes)
+	}
+
+	// all fields of created event are changed from empty values
+	changes := DiffEvents(nil, &before)
+	if len(changes) == 0 || changes[0] != NewFieldChange(FieldName, "", "Meeting") {
+		t.Errorf("first change of created event must be its name instead of %+v", changes)
+	}
+}
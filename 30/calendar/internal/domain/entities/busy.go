@@ -0,0 +1,93 @@
+package entities
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Typed error about period of event that is busy by other events
+type ErrDateBusy struct {
+	conflicts []Event
+}
+
+// Error interface
+func (e *ErrDateBusy) Error() string {
+	names := make([]string, 0, len(e.conflicts))
+	for _, conflict := range e.conflicts {
+		names = append(names, conflict.String())
+	}
+	return fmt.Sprintf("date is busy by other events: %s", strings.Join(names, ", "))
+}
+
+// Occurrences of other events that overlap event
+func (e *ErrDateBusy) Conflicts() []Event {
+	return e.conflicts
+}
+
+// Check that event (every occurrence of recurring event) doesn't overlap events of storage, return *ErrDateBusy if it does
+// Event with the same place (e.g. meeting room) overlaps events of all owners, otherwise only events of storage view
+// id is id of updated event that is not checked against itself, 0 for new event
+// Check is not atomic with following adding of event
+func CheckDateBusy(ctx context.Context, storage Storage, event Event, id int) error {
+	occurrences := event.OccurrencesInPeriod(nil, nil)
+	if len(occurrences) == 0 {
+		return nil
+	}
+
+	start := occurrences[0].start
+	end := occurrences[0].exclusiveEnd()
+	for _, occurrence := range occurrences[1:] {
+		if end.Less(occurrence.exclusiveEnd()) {
+			end = occurrence.exclusiveEnd()
+		}
+	}
+
+	others, err := storage.GetOverlappingEvents(ctx, start, end)
+	if err != nil {
+		return fmt.Errorf("couldn't get overlapping events: %w", err)
+	}
+
+	if event.place != "" {
+		placeOthers, err := storage.ForOwner("").GetOverlappingEvents(ctx, start, end)
+		if err != nil {
+			return fmt.Errorf("couldn't get overlapping events: %w", err)
+		}
+		for _, other := range placeOthers {
+			if other.place == event.place {
+				others = append(others, other)
+			}
+		}
+	}
+
+	var conflicts []Event
+	seen := make(map[string]bool)
+	for _, other := range others {
+		if other.id == id && id != 0 {
+			continue
+		}
+		// the same occurrence could be found twice: as event of view and as event in the same place
+		key := fmt.Sprintf("%d %d", other.id, other.start.Time().Unix())
+		if seen[key] {
+			continue
+		}
+		for _, occurrence := range occurrences {
+			if occurrence.Overlaps(other) {
+				seen[key] = true
+				conflicts = append(conflicts, other)
+				break
+			}
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Less(conflicts[j])
+	})
+
+	return &ErrDateBusy{conflicts}
+}
@@ -0,0 +1,69 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Max length of name of calendar in characters
+const MaxCalendarNameLength = 256
+
+// Errors of invalid calendar
+var (
+	ErrEmptyCalendarName   = errors.New("name of calendar must not be empty")
+	ErrCalendarNameTooLong = fmt.Errorf("name of calendar must not be longer than %d characters", MaxCalendarNameLength)
+)
+
+// Named calendar (e.g. work, personal, team-X) of owner that events belong to
+// Deleting of calendar deletes its events
+type Calendar struct {
+	id    int    // id of calendar, need for identify calendar in storage
+	name  string // name of calendar
+	owner string // id of user that owns calendar, empty for calendars without owner
+}
+
+// Constructor
+func NewCalendar(name string) Calendar {
+	return Calendar{
+		name: name,
+	}
+}
+
+// Clone constructor with setting ID
+func CalendarWithId(calendar Calendar, id int) Calendar {
+	calendar.id = id
+	return calendar
+}
+
+// Clone constructor with setting owner (id of user)
+func CalendarWithOwner(calendar Calendar, owner string) Calendar {
+	calendar.owner = owner
+	return calendar
+}
+
+// Id getter
+func (calendar Calendar) Id() int {
+	return calendar.id
+}
+
+// Name getter
+func (calendar Calendar) Name() string {
+	return calendar.name
+}
+
+// Owner (id of user) getter
+func (calendar Calendar) Owner() string {
+	return calendar.owner
+}
+
+// Validate calendar, return one of Err* errors of invalid calendar
+func ValidateCalendar(calendar Calendar) error {
+	if calendar.name == "" {
+		return ErrEmptyCalendarName
+	}
+	if utf8.RuneCountInString(calendar.name) > MaxCalendarNameLength {
+		return ErrCalendarNameTooLong
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateCalendar(t *testing.T) {
+	if err := ValidateCalendar(NewCalendar("work")); err != nil {
+		t.Errorf("calendar must be valid, got error %s", err)
+	}
+
+	// length is counted in characters, not in bytes
+	if err := ValidateCalendar(NewCalendar(strings.Repeat("ж", MaxCalendarNameLength))); err != nil {
+		t.Errorf("name of %d characters must be valid, got error %s", MaxCalendarNameLength, err)
+	}
+
+	if err := ValidateCalendar(NewCalendar("")); err != ErrEmptyCalendarName {
+		t.Errorf("expected error `%s` instead of `%v`", ErrEmptyCalendarName, err)
+	}
+
+	if err := ValidateCalendar(NewCalendar(strings.Repeat("x", MaxCalendarNameLength+1))); err != ErrCalendarNameTooLong {
+		t.Errorf("expected error `%s` instead of `%v`", ErrCalendarNameTooLong, err)
+	}
+}
@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Error about storage that doesn't implement Claimer
+var ErrClaimNotSupported = errors.New("claiming of notifications is not supported by storage")
+
+// Optional feature of storage: atomic claim of due reminders and of invitations to send,
+// so any number of schedulers could notify and invite without duplicates
+// Storage views (see Storage.ForOwner) of claimer are claimers too
+type Claimer interface {
+
+	// Claim not notified reminders which time (start of occurrence minus before minutes) is in period and that are not claimed yet,
+	// and reminders (of any time) which claims are expired at now, so reminders of crashed schedulers are retried
+	// Claimed reminders are leased until now plus lease: they are not claimed again until lease expires,
+	// claim ends when reminder is marked as notified (see Storage.MarkReminderAsNotified)
+	// One notification per claimed reminder of every due occurrence in order of time, start and end is inclusive
+	ClaimEventsToNotify(ctx context.Context, startTime *DateTime, endTime *DateTime, now time.Time, lease time.Duration) ([]Notification, error)
+
+	// Claim invitations to send (see Storage.GetInvitationsToSend) which attendees are not claimed yet or which claims are expired at now
+	// Claimed attendees are leased until now plus lease, claim ends when attendee is marked as invited (see Storage.MarkAttendeeAsInvited)
+	ClaimInvitationsToSend(ctx context.Context, now time.Time, lease time.Duration) ([]Invitation, error)
+}
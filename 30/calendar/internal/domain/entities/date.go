@@ -0,0 +1,74 @@
+package entities
+
+import (
+	"time"
+)
+
+const dateLayout = "02 Jan 2006"
+
+// Date of all day event - wrapper on time.Time without time part
+type Date struct {
+	t time.Time
+}
+
+// Constructor
+func NewDate(year, month, day int) Date {
+	return Date{
+		time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// Construct from time.Time, time part is dropped
+func ConvertDateFromTime(t time.Time) Date {
+	return NewDate(t.Year(), int(t.Month()), t.Day())
+}
+
+// Less method for compare 2 dates
+func (date Date) Less(thatDate Date) bool {
+	return date.t.Before(thatDate.t)
+}
+
+// String representation of date
+func (date Date) String() string {
+	return date.t.Format(dateLayout)
+}
+
+// Formatting
+func (date Date) Format(layout string) string {
+	return date.t.Format(layout)
+}
+
+// get time.Time (midnight of date)
+func (date Date) Time() time.Time {
+	return date.t
+}
+
+// Beginning of the day (00:00) in UTC
+func (date Date) StartDateTime() DateTime {
+	return date.StartDateTimeIn(time.UTC)
+}
+
+// Ending of the day (23:59) in UTC, the same boundary as used for periods of days
+func (date Date) EndDateTime() DateTime {
+	return date.EndDateTimeIn(time.UTC)
+}
+
+// Beginning of the day (00:00) in location
+func (date Date) StartDateTimeIn(loc *time.Location) DateTime {
+	return NewDateTimeInLocation(date.t.Year(), int(date.t.Month()), date.t.Day(), 0, 0, loc)
+}
+
+// Ending of the day (23:59) in location, day could be shorter or longer than 24 hours because of DST
+func (date Date) EndDateTimeIn(loc *time.Location) DateTime {
+	return NewDateTimeInLocation(date.t.Year(), int(date.t.Month()), date.t.Day(), 23, 59, loc)
+}
+
+// Date that is days after this date
+func (date Date) AddDays(days int) Date {
+	return Date{date.t.AddDate(0, 0, days)}
+}
+
+// Number of days from this date to that date
+func (date Date) DaysUntil(thatDate Date) int {
+	return int(thatDate.t.Sub(date.t) / (24 * time.Hour))
+}
@@ -1,12 +1,31 @@
 package entities
 
 import (
+	"sync"
 	"time"
 )
 
 const layout = "02 Jan 2006 15:04"
 
+// cache of loaded locations, time.LoadLocation reads tz database every time
+var locations sync.Map
+
+// Load location by IANA name (like Europe/Moscow), empty name means UTC
+// Locations are cached, so the same pointer is returned for the same name
+func LoadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
 // Time of event - wrapper on time.Time but more simple constructor
+// DateTime keeps location (time zone) of time, so local wall clock is always known
 type DateTime struct {
 	t time.Time
 }
@@ -18,10 +37,17 @@ func NewDateTime(year, month, day, hour, minute int) DateTime {
 	}
 }
 
-// Construct from time.Time
+// Constructor of local wall clock time in location
+func NewDateTimeInLocation(year, month, day, hour, minute int, loc *time.Location) DateTime {
+	return DateTime{
+		time.Date(year, time.Month(month), day, hour, minute, 0, 0, loc),
+	}
+}
+
+// Construct from time.Time, location of time is kept, seconds are dropped
 func ConvertFromTime(t time.Time) DateTime {
 	return DateTime{
-		time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC),
+		time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location()),
 	}
 }
 
@@ -35,6 +61,11 @@ func (eventTime DateTime) LessOrEqual(thatEventTime DateTime) bool {
 	return eventTime.t.Unix() <= thatEventTime.t.Unix()
 }
 
+// Is the same moment of time, locations could be different
+func (eventTime DateTime) Equal(thatEventTime DateTime) bool {
+	return eventTime.t.Equal(thatEventTime.t)
+}
+
 // String representation of event time
 func (eventTime DateTime) String() string {
 	return eventTime.t.Format(layout)
@@ -50,6 +81,22 @@ func (eventTime DateTime) Time() time.Time {
 	return eventTime.t
 }
 
+// Location of time
+func (eventTime DateTime) Location() *time.Location {
+	return eventTime.t.Location()
+}
+
+// The same moment of time in other location
+func (eventTime DateTime) In(loc *time.Location) DateTime {
+	return DateTime{eventTime.t.In(loc)}
+}
+
+// The same wall clock time in other location (it is other moment of time)
+func (eventTime DateTime) WallClockIn(loc *time.Location) DateTime {
+	t := eventTime.t
+	return NewDateTimeInLocation(t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), loc)
+}
+
 // Minus minutes
 func (eventTime DateTime) MinusMinutes(m int) DateTime {
 	return ConvertFromTime(eventTime.t.Add(-time.Duration(m) * time.Minute))
@@ -2,19 +2,31 @@ package entities
 
 import (
 	"fmt"
+	"net/mail"
+	"sort"
 	"time"
 )
 
-// Simplest event struct, not support all day and repeat properties
+// Simplest event struct
+// All day event starts at beginning of first day and ends at ending (23:59) of last day
+// Time zone of event is location of its start and end times
 type Event struct {
-	id                 int       // id of event, need for identify event in entities
-	name               string    // name of event
-	start              DateTime  // start event time
-	end                DateTime  // end event time
-	isNotifyingEnabled bool      // need or not isNotifyingEnabled
-	beforeMinutes      int       // isNotifyingEnabled before minutes
-	isNotified         bool      // was notification enqueued
-	notifiedTime       time.Time // when notification enqueued
+	id          int         // id of event, need for identify event in entities
+	name        string      // name of event
+	start       DateTime    // start event time
+	end         DateTime    // end event time
+	reminders   []Reminder  // reminders sorted by before minutes descending (the earliest first)
+	recurrence  *Recurrence // repeat rule, nil for not recurring event
+	allDay      bool        // is all day (multi-day) event
+	description string      // description of event
+	place       string      // location (place) of event, e.g. meeting room or address
+	organizer   string      // email of organizer
+	attendees   []Attendee  // attendees with their responses to invitation
+	color       string      // color or category of event
+	owner       string      // id of user that owns event, empty for events without owner
+	deletedTime time.Time   // when event was moved to trash, zero for not deleted event
+	version     int         // version of event, it is incremented by every update, 0 for not stored event
+	calendarId  int         // id of calendar event belongs to, 0 for event without calendar
 }
 
 // Constructor
@@ -33,6 +45,151 @@ func WithId(event Event, id int) Event {
 	return event
 }
 
+// Clone constructor with setting recurrence rule (nil means not recurring event)
+func WithRecurrence(event Event, recurrence *Recurrence) Event {
+	event.recurrence = recurrence
+	return event
+}
+
+// Clone constructor with setting time zone of event
+// Start and end of event are converted to the same moments in location,
+// but all day event keeps its days, so it starts and ends at local midnight of location
+func WithLocation(event Event, loc *time.Location) Event {
+	if event.allDay {
+		startDate, endDate := event.StartDate(), event.EndDate()
+		event.start = startDate.StartDateTimeIn(loc)
+		event.end = endDate.EndDateTimeIn(loc)
+		return event
+	}
+	event.start = event.start.In(loc)
+	event.end = event.end.In(loc)
+	return event
+}
+
+// Clone constructor with setting owner (id of user)
+func WithOwner(event Event, owner string) Event {
+	event.owner = owner
+	return event
+}
+
+// Clone constructor with setting time when event was moved to trash, zero time means event is not deleted
+func WithDeletedTime(event Event, deletedTime time.Time) Event {
+	event.deletedTime = deletedTime
+	return event
+}
+
+// Clone constructor with setting version
+// Version of event passed to UpdateEvent of storage is expected version of stored event, 0 means update without check
+func WithVersion(event Event, version int) Event {
+	event.version = version
+	return event
+}
+
+// Clone constructor with setting id of calendar event belongs to, 0 means event without calendar
+func WithCalendarId(event Event, calendarId int) Event {
+	event.calendarId = calendarId
+	return event
+}
+
+// Clone constructor with setting reminders
+// Reminders with the same before minutes are merged, reminders are sorted by before minutes descending
+func WithReminders(event Event, reminders []Reminder) Event {
+	event.reminders = nil
+	seen := make(map[int]bool, len(reminders))
+	for _, reminder := range reminders {
+		if seen[reminder.beforeMinutes] {
+			continue
+		}
+		seen[reminder.beforeMinutes] = true
+		event.reminders = append(event.reminders, reminder)
+	}
+	sort.SliceStable(event.reminders, func(i, j int) bool {
+		return event.reminders[i].beforeMinutes > event.reminders[j].beforeMinutes
+	})
+	return event
+}
+
+// Clone constructor with setting description
+func WithDescription(event Event, description string) Event {
+	event.description = description
+	return event
+}
+
+// Clone constructor with setting location (place) of event
+func WithPlace(event Event, place string) Event {
+	event.place = place
+	return event
+}
+
+// Clone constructor with setting email of organizer
+func WithOrganizer(event Event, organizer string) Event {
+	event.organizer = organizer
+	return event
+}
+
+// Clone constructor with setting emails of attendees, attendees have not responded and not invited yet
+func WithAttendees(event Event, emails []string) Event {
+	attendees := make([]Attendee, 0, len(emails))
+	for _, email := range emails {
+		attendees = append(attendees, NewAttendee(email))
+	}
+	return WithAttendeeList(event, attendees)
+}
+
+// Clone constructor with setting attendees with their responses and states of invitation
+// Attendees with the same email are merged, order of attendees is kept
+func WithAttendeeList(event Event, attendees []Attendee) Event {
+	event.attendees = nil
+	seen := make(map[string]bool, len(attendees))
+	for _, attendee := range attendees {
+		if seen[attendee.email] {
+			continue
+		}
+		seen[attendee.email] = true
+		event.attendees = append(event.attendees, attendee)
+	}
+	return event
+}
+
+// Clone constructor that keeps responses and states of invitation of attendees of old event
+// that are still attendees of event, e.g. on update of event by organizer
+func WithAttendeeStatesOf(event Event, old Event) Event {
+	if len(event.attendees) == 0 {
+		return event
+	}
+	attendees := append([]Attendee(nil), event.attendees...)
+	for i, attendee := range attendees {
+		for _, oldAttendee := range old.attendees {
+			if oldAttendee.email == attendee.email {
+				attendees[i] = oldAttendee
+				break
+			}
+		}
+	}
+	event.attendees = attendees
+	return event
+}
+
+// Clone constructor with setting color (or category) of event
+func WithColor(event Event, color string) Event {
+	event.color = color
+	return event
+}
+
+// Constructor of all day event, endDate is inclusive
+func NewAllDayEvent(name string, startDate Date, endDate Date) Event {
+	return WithAllDay(NewEvent(name, startDate.StartDateTime(), endDate.EndDateTime()), startDate, endDate)
+}
+
+// Clone constructor that make all day event from startDate to endDate (inclusive) in time zone of event
+func WithAllDay(event Event, startDate Date, endDate Date) Event {
+	loc := event.Location()
+	event.start = startDate.StartDateTimeIn(loc)
+	event.end = endDate.EndDateTimeIn(loc)
+	event.allDay = true
+	return event
+}
+
 // Constructor for existing in entities events
 func NewEventWithId(id int, name string, start DateTime, end DateTime) Event {
 	event := Event{
@@ -45,6 +202,7 @@ func NewEventWithId(id int, name string, start DateTime, end DateTime) Event {
 }
 
 // Constructor for event all fields
+// Event with enabled notifying has one reminder with beforeMinutes, use WithReminders for more reminders
 func NewDetailedEvent(
 	name string,
 	start DateTime,
@@ -54,16 +212,7 @@ func NewDetailedEvent(
 	isNotified bool,
 	notifiedTime time.Time,
 ) Event {
-
-	return Event{
-		name:               name,
-		start:              start,
-		end:                end,
-		isNotifyingEnabled: isNotifyingEnable,
-		beforeMinutes:      beforeMinutes,
-		isNotified:         isNotified,
-		notifiedTime:       notifiedTime,
-	}
+	return NewDetailedEventWithId(0, name, start, end, isNotifyingEnable, beforeMinutes, isNotified, notifiedTime)
 }
 
 // Constructor for event all fields AND id
@@ -78,16 +227,22 @@ func NewDetailedEventWithId(
 	notifiedTime time.Time,
 ) Event {
 
-	return Event{
-		id:                 id,
-		name:               name,
-		start:              start,
-		end:                end,
-		isNotifyingEnabled: isNotifyingEnable,
-		beforeMinutes:      beforeMinutes,
-		isNotified:         isNotified,
-		notifiedTime:       notifiedTime,
+	event := Event{
+		id:    id,
+		name:  name,
+		start: start,
+		end:   end,
+	}
+
+	if isNotifyingEnable {
+		reminder := NewReminder(beforeMinutes)
+		if isNotified {
+			reminder = reminder.Notified(notifiedTime)
+		}
+		event.reminders = []Reminder{reminder}
 	}
+
+	return event
 }
 
 // Id of event getter
@@ -110,34 +265,398 @@ func (event Event) End() DateTime {
 	return event.end
 }
 
-//
+// Is event has reminders
 func (event Event) IsNotifyingEnabled() bool {
-	return event.isNotifyingEnabled
+	return len(event.reminders) > 0
 }
 
-//
+// Before minutes of the earliest reminder, 0 if event has no reminders
 func (event Event) BeforeMinutes() int {
-	return event.beforeMinutes
+	if len(event.reminders) == 0 {
+		return 0
+	}
+	return event.reminders[0].beforeMinutes
 }
 
-//
+// Are notifications of all reminders enqueued
 func (event Event) IsNotified() bool {
-	return event.isNotified
+	for _, reminder := range event.reminders {
+		if !reminder.isNotified {
+			return false
+		}
+	}
+	return len(event.reminders) > 0
 }
 
+// When the last notification enqueued
 func (event Event) NotifiedTime() time.Time {
-	return event.notifiedTime
+	var notifiedTime time.Time
+	for _, reminder := range event.reminders {
+		if reminder.isNotified && reminder.notifiedTime.After(notifiedTime) {
+			notifiedTime = reminder.notifiedTime
+		}
+	}
+	return notifiedTime
 }
 
+// Copy of event with all reminders marked as notified at time t
+// Reminders of recurring event are marked as notified for its first occurrence only
 func (event Event) Notified(t time.Time) Event {
-	event.isNotified = true
-	event.notifiedTime = t
+	if len(event.reminders) == 0 {
+		return event
+	}
+	reminders := make([]Reminder, 0, len(event.reminders))
+	for _, reminder := range event.reminders {
+		reminders = append(reminders, reminder.OccurrenceNotified(event.start, t))
+	}
+	event.reminders = reminders
 	return event
 }
 
+// Copy of event with reminder with beforeMinutes marked as notified at time t for occurrence started at start
+// Return false if event has no such reminder
+func (event Event) ReminderNotified(beforeMinutes int, start DateTime, t time.Time) (Event, bool) {
+	for i, reminder := range event.reminders {
+		if reminder.beforeMinutes == beforeMinutes {
+			event.reminders = append([]Reminder(nil), event.reminders...)
+			event.reminders[i] = reminder.OccurrenceNotified(start, t)
+			return event, true
+		}
+	}
+	return event, false
+}
+
+// Is notification of reminder enqueued for occurrence started at start
+// Reminder of not recurring event has the only occurrence, reminder of recurring event is notified for occurrences
+// not later than the latest notified one
+func (event Event) isReminderNotified(reminder Reminder, start DateTime) bool {
+	if !reminder.isNotified {
+		return false
+	}
+	if event.recurrence == nil {
+		return true
+	}
+	return !reminder.notifiedStart.Less(start)
+}
+
+// Reminders getter, reminders are sorted by before minutes descending
+func (event Event) Reminders() []Reminder {
+	if event.reminders == nil {
+		return nil
+	}
+	return append([]Reminder(nil), event.reminders...)
+}
+
+// Notifications by not notified reminders of occurrences of event which time (start minus before minutes) is in period
+// Notification of recurring event is about its occurrence, i.e. event shifted to start of occurrence
+// Period without start but with end is used to catch up missed notifications, so missed occurrences of recurring event
+// are notified once by the latest one
+// Boundaries of period are included, nil has special means - no boundary for range period
+// Notifications are sorted by time
+func (event Event) NotificationsInPeriod(startTime *DateTime, endTime *DateTime) []Notification {
+	var notifications []Notification
+	for _, reminder := range event.reminders {
+		var due []Notification
+		// occurrences which notifications are in period started before minutes later
+		var from, to *DateTime
+		if startTime != nil {
+			t := startTime.PlusMinutes(reminder.beforeMinutes)
+			from = &t
+		}
+		if endTime != nil {
+			t := endTime.PlusMinutes(reminder.beforeMinutes)
+			to = &t
+		}
+
+		for _, occurrence := range event.OccurrencesInPeriod(from, to) {
+			if event.isReminderNotified(reminder, occurrence.start) {
+				continue
+			}
+			notification := NewNotification(occurrence, reminder)
+			notifyTime := notification.Time()
+			// all day occurrence that started before period is in period too, so time is checked exactly
+			if startTime != nil && !startTime.LessOrEqual(notifyTime) {
+				continue
+			}
+			if endTime != nil && !notifyTime.LessOrEqual(*endTime) {
+				continue
+			}
+			due = append(due, notification)
+		}
+
+		if event.recurrence != nil && startTime == nil && endTime != nil && len(due) > 1 {
+			due = due[len(due)-1:]
+		}
+		notifications = append(notifications, due...)
+	}
+
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return notifications[i].Time().Less(notifications[j].Time())
+	})
+
+	return notifications
+}
+
+// Is event moved to trash
+func (event Event) IsDeleted() bool {
+	return !event.deletedTime.IsZero()
+}
+
+// When event was moved to trash, zero for not deleted event
+func (event Event) DeletedTime() time.Time {
+	return event.deletedTime
+}
+
+// Version getter
+func (event Event) Version() int {
+	return event.version
+}
+
+// Id of calendar event belongs to, 0 for event without calendar
+func (event Event) CalendarId() int {
+	return event.calendarId
+}
+
+// Owner (id of user) getter
+func (event Event) Owner() string {
+	return event.owner
+}
+
+// Description getter
+func (event Event) Description() string {
+	return event.description
+}
+
+// Location (place) of event getter, don't confuse with Location (time zone)
+func (event Event) Place() string {
+	return event.place
+}
+
+// Email of organizer getter
+func (event Event) Organizer() string {
+	return event.organizer
+}
+
+// Emails of attendees getter
+func (event Event) Attendees() []string {
+	if event.attendees == nil {
+		return nil
+	}
+	emails := make([]string, 0, len(event.attendees))
+	for _, attendee := range event.attendees {
+		emails = append(emails, attendee.email)
+	}
+	return emails
+}
+
+// Attendees with their responses and states of invitation getter
+func (event Event) AttendeeList() []Attendee {
+	if event.attendees == nil {
+		return nil
+	}
+	return append([]Attendee(nil), event.attendees...)
+}
+
+// Copy of event with response of attendee with email
+// Return false if event has no such attendee
+func (event Event) AttendeeResponded(email string, status string) (Event, bool) {
+	for i, attendee := range event.attendees {
+		if attendee.email == email {
+			event.attendees = append([]Attendee(nil), event.attendees...)
+			event.attendees[i] = attendee.Responded(status)
+			return event, true
+		}
+	}
+	return event, false
+}
+
+// Copy of event with attendee with email invited to event with start and end
+// Return false if event has no such attendee
+func (event Event) AttendeeInvited(email string, start DateTime, end DateTime) (Event, bool) {
+	for i, attendee := range event.attendees {
+		if attendee.email == email {
+			event.attendees = append([]Attendee(nil), event.attendees...)
+			event.attendees[i] = attendee.Invited(start, end)
+			return event, true
+		}
+	}
+	return event, false
+}
+
+// Invitations that must be enqueued: for attendees that are not invited yet
+// and updates for invited attendees if start or end of event is changed since invitation
+func (event Event) Invitations() []Invitation {
+	var invitations []Invitation
+	for _, attendee := range event.attendees {
+		if attendee.isInvitedTo(event.start, event.end) {
+			continue
+		}
+		invitations = append(invitations, NewInvitation(event, attendee))
+	}
+	return invitations
+}
+
+// Color (or category) getter
+func (event Event) Color() string {
+	return event.color
+}
+
+// Time zone of event
+func (event Event) Location() *time.Location {
+	return event.start.Location()
+}
+
+// Recurrence rule getter, nil for not recurring event
+func (event Event) Recurrence() *Recurrence {
+	return event.recurrence
+}
+
+// Is event recurring
+func (event Event) IsRecurring() bool {
+	return event.recurrence != nil
+}
+
+// Is event ended before now, recurring event is never over
+func (event Event) IsOver(now time.Time) bool {
+	return event.recurrence == nil && event.end.Time().Before(now)
+}
+
+// Is all day event
+func (event Event) IsAllDay() bool {
+	return event.allDay
+}
+
+// First day of event
+func (event Event) StartDate() Date {
+	return ConvertDateFromTime(event.start.Time())
+}
+
+// Last day of event (inclusive)
+func (event Event) EndDate() Date {
+	return ConvertDateFromTime(event.end.Time())
+}
+
+// Occurrences of event that started in period (boundary of period are included) sorted by start
+// All day event occurrence is in period if it overlaps period, i.e. it is in period every day it covers
+// All day event is floating: its days are compared with local days of period, whatever time zone of period is
+// Occurrence is copy of event (with the same id) shifted to start of occurrence
+// Recurring event keeps local wall clock of its time zone for every occurrence, even across DST changes
+// Not recurring event has only one occurrence - itself
+// You also can pass nil for start or end times, nil has special means - no boundary for range period
+func (event Event) OccurrencesInPeriod(startTime *DateTime, endTime *DateTime) []Event {
+	var occurrences []Event
+	event.eachOccurrenceInPeriod(startTime, endTime, func(occurrence Event) bool {
+		occurrences = append(occurrences, occurrence)
+		return endTime != nil || event.recurrence == nil || event.recurrence.IsFinite() || len(occurrences) < MaxOccurrences
+	})
+	return occurrences
+}
+
+// Occurrences of event in period (see OccurrencesInPeriod) that are after cursor, so they are on next page
+// Expansion starts from cursor and stops after limit plus one occurrences, one more is to know that there is next page
+// limit <= 0 means no limit
+func (event Event) OccurrencesInPage(startTime *DateTime, endTime *DateTime, cursor Cursor, limit int) []Event {
+	// occurrences after cursor start not earlier than cursor
+	if !cursor.IsZero() {
+		cursorTime := ConvertFromTime(time.Unix(cursor.Start(), 0).UTC())
+		if startTime == nil || startTime.Less(cursorTime) {
+			startTime = &cursorTime
+		}
+	}
+
+	var occurrences []Event
+	event.eachOccurrenceInPeriod(startTime, endTime, func(occurrence Event) bool {
+		if cursor.Before(occurrence) {
+			occurrences = append(occurrences, occurrence)
+		}
+		if limit > 0 {
+			return len(occurrences) <= limit
+		}
+		return endTime != nil || event.recurrence == nil || event.recurrence.IsFinite() || len(occurrences) < MaxOccurrences
+	})
+	return occurrences
+}
+
+// Call fn for occurrences of event in period (see OccurrencesInPeriod) sorted by start until fn returns false
+func (event Event) eachOccurrenceInPeriod(startTime *DateTime, endTime *DateTime, fn func(occurrence Event) bool) {
+	duration := event.end.Time().Sub(event.start.Time())
+	days := event.StartDate().DaysUntil(event.EndDate())
+
+	if event.allDay {
+		loc := event.Location()
+		// all day event that started before period but still lasts in period is in period too
+		if startTime != nil {
+			overlapStart := ConvertDateFromTime(startTime.Time()).AddDays(-days).StartDateTimeIn(loc)
+			startTime = &overlapStart
+		}
+		if endTime != nil {
+			localEnd := endTime.WallClockIn(loc)
+			endTime = &localEnd
+		}
+	}
+
+	if event.recurrence == nil {
+		if startTime != nil && !startTime.LessOrEqual(event.start) {
+			return
+		}
+		if endTime != nil && !event.start.LessOrEqual(*endTime) {
+			return
+		}
+		fn(event)
+		return
+	}
+
+	event.recurrence.eachOccurrence(event.start, startTime, endTime, func(start DateTime) bool {
+		occurrence := event
+		occurrence.start = start
+		if event.allDay {
+			occurrence.end = ConvertDateFromTime(start.Time()).AddDays(days).EndDateTimeIn(event.Location())
+		} else {
+			occurrence.end = ConvertFromTime(start.Time().Add(duration))
+		}
+		return fn(occurrence)
+	})
+}
+
+// Occurrences of event that overlap interval [start, end), i.e. started before end and ended after start
+// So occurrence that ends exactly at start of interval (or starts exactly at its end) doesn't overlap it
+// All day occurrence lasts till beginning of day after its last day in its time zone
+func (event Event) OccurrencesOverlapping(start DateTime, end DateTime) []Event {
+	if !start.Less(end) {
+		return nil
+	}
+
+	// period of OccurrencesInPeriod is widened by duration of event and by two days for floating all day events,
+	// exact overlapping is checked below
+	duration := event.end.Time().Sub(event.start.Time())
+	from := ConvertFromTime(start.Time().Add(-duration - 48*time.Hour))
+	to := ConvertFromTime(end.Time().Add(48 * time.Hour))
+
+	var occurrences []Event
+	for _, occurrence := range event.OccurrencesInPeriod(&from, &to) {
+		if occurrence.start.Less(end) && start.Less(occurrence.exclusiveEnd()) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+	return occurrences
+}
+
+// Is occurrence of event overlaps occurrence of that event (see OccurrencesOverlapping)
+func (event Event) Overlaps(thatEvent Event) bool {
+	return event.start.Less(thatEvent.exclusiveEnd()) && thatEvent.start.Less(event.exclusiveEnd())
+}
+
+// End of event as exclusive boundary, for all day event it is beginning of day after its last day
+func (event Event) exclusiveEnd() DateTime {
+	if event.allDay {
+		return event.EndDate().AddDays(1).StartDateTimeIn(event.Location())
+	}
+	return event.end
+}
+
 // Less method for compare 2 event, will need for sorting in entities
 func (event Event) Less(thatEvent Event) bool {
-	if event.start != thatEvent.start {
+	if !event.start.Equal(thatEvent.start) {
 		return event.start.Less(thatEvent.start)
 	} else {
 		return event.end.Less(thatEvent.end)
@@ -146,5 +665,17 @@ func (event Event) Less(thatEvent Event) bool {
 
 // String representation of event
 func (event Event) String() string {
+	if event.allDay {
+		return fmt.Sprintf("%s: %s -> %s (all day)", event.name, event.StartDate(), event.EndDate())
+	}
 	return fmt.Sprintf("%s: %s -> %s", event.name, event.start, event.end)
 }
+
+// Check that value is bare email address (like user@example.com), used for organizer and attendees
+func ValidateEmail(email string) error {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return fmt.Errorf("invalid email `%s`", email)
+	}
+	return nil
+}
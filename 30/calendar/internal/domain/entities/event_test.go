@@ -0,0 +1,257 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllDayEvent(t *testing.T) {
+	event := NewAllDayEvent("Holidays", NewDate(2019, 12, 31), NewDate(2020, 1, 2))
+
+	if !event.IsAllDay() {
+		t.Fatal("event must be all day")
+	}
+
+	if event.Start() != NewDateTime(2019, 12, 31, 0, 0) || event.End() != NewDateTime(2020, 1, 2, 23, 59) {
+		t.Errorf("all day event must cover whole days, got %s -> %s", event.Start(), event.End())
+	}
+
+	if event.StartDate() != NewDate(2019, 12, 31) || event.EndDate() != NewDate(2020, 1, 2) {
+		t.Errorf("unexpected dates of all day event %s -> %s", event.StartDate(), event.EndDate())
+	}
+}
+
+func TestAllDayEventOccurrencesInPeriod(t *testing.T) {
+	event := NewAllDayEvent("Holidays", NewDate(2019, 12, 31), NewDate(2020, 1, 2))
+
+	type periodCase struct {
+		start    DateTime
+		end      DateTime
+		expected int
+	}
+
+	cases := []periodCase{
+		// day before
+		{NewDateTime(2019, 12, 30, 0, 0), NewDateTime(2019, 12, 30, 23, 59), 0},
+		// first day
+		{NewDateTime(2019, 12, 31, 0, 0), NewDateTime(2019, 12, 31, 23, 59), 1},
+		// day in the middle, event started before period
+		{NewDateTime(2020, 1, 1, 0, 0), NewDateTime(2020, 1, 1, 23, 59), 1},
+		// last day
+		{NewDateTime(2020, 1, 2, 0, 0), NewDateTime(2020, 1, 2, 23, 59), 1},
+		// day after
+		{NewDateTime(2020, 1, 3, 0, 0), NewDateTime(2020, 1, 3, 23, 59), 0},
+	}
+
+	for _, c := range cases {
+		occurrences := event.OccurrencesInPeriod(&c.start, &c.end)
+		if len(occurrences) != c.expected {
+			t.Errorf("period %s - %s expected %d occurrences instead of %d", c.start, c.end, c.expected, len(occurrences))
+		}
+	}
+
+	// not all day event that started before period is not in period
+	event = NewEvent("Party", NewDateTime(2019, 12, 31, 22, 0), NewDateTime(2020, 1, 1, 2, 0))
+	start := NewDateTime(2020, 1, 1, 0, 0)
+	end := NewDateTime(2020, 1, 1, 23, 59)
+	if len(event.OccurrencesInPeriod(&start, &end)) != 0 {
+		t.Errorf("not all day event must be in period only by its start")
+	}
+}
+
+func TestRecurringAllDayEventOccurrencesInPeriod(t *testing.T) {
+	recurrence, _ := ParseRecurrence("FREQ=WEEKLY")
+	// weekend
+	event := WithRecurrence(NewAllDayEvent("Weekend", NewDate(2019, 11, 23), NewDate(2019, 11, 24)), recurrence)
+
+	// sunday
+	start := NewDateTime(2019, 12, 1, 0, 0)
+	end := NewDateTime(2019, 12, 1, 23, 59)
+
+	occurrences := event.OccurrencesInPeriod(&start, &end)
+	if len(occurrences) != 1 {
+		t.Fatalf("expected 1 occurrence instead of %d", len(occurrences))
+	}
+
+	if occurrences[0].StartDate() != NewDate(2019, 11, 30) || occurrences[0].EndDate() != NewDate(2019, 12, 1) {
+		t.Errorf("unexpected occurrence %s", occurrences[0])
+	}
+}
+
+func TestEventWithLocation(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	event := WithLocation(NewEvent("Meeting", NewDateTime(2019, 11, 25, 7, 0), NewDateTime(2019, 11, 25, 8, 0)), moscow)
+	if event.Location() != moscow {
+		t.Errorf("location must be %s instead of %s", moscow, event.Location())
+	}
+	if event.Start().Format("15:04") != "10:00" {
+		t.Errorf("local start must be 10:00 instead of %s", event.Start().Format("15:04"))
+	}
+	if !event.Start().Equal(NewDateTime(2019, 11, 25, 7, 0)) {
+		t.Errorf("moment of start must not be changed, got %s", event.Start().In(time.UTC))
+	}
+
+	// all day event keeps its days
+	event = WithLocation(NewAllDayEvent("Holiday", NewDate(2020, 1, 1), NewDate(2020, 1, 2)), moscow)
+	if event.StartDate() != NewDate(2020, 1, 1) || event.EndDate() != NewDate(2020, 1, 2) {
+		t.Errorf("all day event must keep its days, got %s", event)
+	}
+	if event.Start().Format("15:04") != "00:00" || event.End().Format("15:04") != "23:59" {
+		t.Errorf("all day event must start and end at local midnight, got %s -> %s", event.Start(), event.End())
+	}
+}
+
+func TestRecurringEventAcrossDst(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	recurrence, _ := ParseRecurrence("FREQ=DAILY")
+	event := WithRecurrence(NewEvent("Stand-up",
+		NewDateTimeInLocation(2020, 3, 27, 10, 0, berlin),
+		NewDateTimeInLocation(2020, 3, 27, 10, 15, berlin),
+	), recurrence)
+
+	// DST starts at 29 Mar 2020 in Berlin
+	start := NewDateTimeInLocation(2020, 3, 27, 0, 0, berlin)
+	end := NewDateTimeInLocation(2020, 3, 31, 23, 59, berlin)
+
+	occurrences := event.OccurrencesInPeriod(&start, &end)
+	if len(occurrences) != 5 {
+		t.Fatalf("expected 5 occurrences instead of %d", len(occurrences))
+	}
+
+	for _, occurrence := range occurrences {
+		if occurrence.Start().Format("15:04") != "10:00" || occurrence.End().Format("15:04") != "10:15" {
+			t.Errorf("occurrence must keep local wall clock, got %s", occurrence)
+		}
+	}
+
+	before := occurrences[0].Start().In(time.UTC).Format("15:04")
+	after := occurrences[4].Start().In(time.UTC).Format("15:04")
+	if before != "09:00" || after != "08:00" {
+		t.Errorf("UTC time of occurrence must be changed by DST, got %s and %s", before, after)
+	}
+}
+
+func TestAllDayEventIsFloating(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	event := WithLocation(NewAllDayEvent("Holiday", NewDate(2020, 1, 1), NewDate(2020, 1, 1)), tokyo)
+
+	// the same day in other time zone
+	start := NewDateTimeInLocation(2020, 1, 1, 0, 0, newYork)
+	end := NewDateTimeInLocation(2020, 1, 1, 23, 59, newYork)
+	if len(event.OccurrencesInPeriod(&start, &end)) != 1 {
+		t.Errorf("all day event must be in period of its day in any time zone")
+	}
+
+	start = NewDateTimeInLocation(2019, 12, 31, 0, 0, newYork)
+	end = NewDateTimeInLocation(2019, 12, 31, 23, 59, newYork)
+	if len(event.OccurrencesInPeriod(&start, &end)) != 0 {
+		t.Errorf("all day event must not be in period of previous day in any time zone")
+	}
+}
+
+func TestEventDetails(t *testing.T) {
+	attendees := []string{"alice@example.com", "bob@example.com"}
+
+	event := NewEvent("Meeting", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 11, 0))
+	event = WithDescription(event, "Quarterly planning")
+	event = WithPlace(event, "Room 42")
+	event = WithOrganizer(event, "boss@example.com")
+	event = WithAttendees(event, attendees)
+	event = WithColor(event, "#ff0000")
+
+	if event.Description() != "Quarterly planning" || event.Place() != "Room 42" ||
+		event.Organizer() != "boss@example.com" || event.Color() != "#ff0000" {
+		t.Errorf("unexpected details of event %q %q %q %q", event.Description(), event.Place(), event.Organizer(), event.Color())
+	}
+
+	// event is immutable
+	attendees[0] = "eve@example.com"
+	event.Attendees()[1] = "eve@example.com"
+	if event.Attendees()[0] != "alice@example.com" || event.Attendees()[1] != "bob@example.com" {
+		t.Errorf("attendees of event must not be changed outside, got %v", event.Attendees())
+	}
+}
+
+func TestValidateEmail(t *testing.T) {
+	if err := ValidateEmail("alice@example.com"); err != nil {
+		t.Errorf("must not be error %s", err)
+	}
+	for _, email := range []string{"", "alice", "Alice <alice@example.com>", "alice@example.com,bob@example.com"} {
+		if err := ValidateEmail(email); err == nil {
+			t.Errorf("email `%s` must be invalid", email)
+		}
+	}
+}
+
+func TestOccurrencesOverlapping(t *testing.T) {
+	event := NewEvent("Meeting", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 11, 0))
+
+	cases := []struct {
+		start    DateTime
+		end      DateTime
+		expected int
+	}{
+		{NewDateTime(2019, 11, 25, 10, 30), NewDateTime(2019, 11, 25, 12, 0), 1},
+		{NewDateTime(2019, 11, 25, 9, 0), NewDateTime(2019, 11, 25, 10, 1), 1},
+		{NewDateTime(2019, 11, 25, 10, 15), NewDateTime(2019, 11, 25, 10, 45), 1},
+		{NewDateTime(2019, 11, 25, 11, 0), NewDateTime(2019, 11, 25, 12, 0), 0}, // back to back
+		{NewDateTime(2019, 11, 25, 9, 0), NewDateTime(2019, 11, 25, 10, 0), 0},  // back to back
+		{NewDateTime(2019, 11, 25, 12, 0), NewDateTime(2019, 11, 25, 11, 0), 0}, // empty interval
+	}
+
+	for _, c := range cases {
+		occurrences := event.OccurrencesOverlapping(c.start, c.end)
+		if len(occurrences) != c.expected {
+			t.Errorf("[%s, %s) must overlaps %d occurrences instead of %d", c.start, c.end, c.expected, len(occurrences))
+		}
+	}
+
+	r, _ := ParseRecurrence("FREQ=DAILY")
+	recurring := WithRecurrence(event, r)
+	occurrences := recurring.OccurrencesOverlapping(NewDateTime(2019, 11, 27, 10, 59), NewDateTime(2019, 11, 28, 10, 1))
+	if len(occurrences) != 2 || !occurrences[0].Start().Equal(NewDateTime(2019, 11, 27, 10, 0)) {
+		t.Errorf("recurring event must overlaps by 2 occurrences, got %v", occurrences)
+	}
+
+	moscow, _ := LoadLocation("Europe/Moscow")
+	allDay := WithLocation(NewAllDayEvent("Holiday", NewDate(2020, 1, 1), NewDate(2020, 1, 2)), moscow)
+
+	// 31 Dec 21:00 UTC is 1 Jan 00:00 in Moscow
+	if len(allDay.OccurrencesOverlapping(NewDateTime(2019, 12, 31, 20, 0), NewDateTime(2019, 12, 31, 21, 0))) != 0 {
+		t.Errorf("all day event must not overlaps interval before its first day")
+	}
+	if len(allDay.OccurrencesOverlapping(NewDateTime(2019, 12, 31, 20, 0), NewDateTime(2019, 12, 31, 21, 1))) != 1 {
+		t.Errorf("all day event must overlaps interval at beginning of its first day")
+	}
+	if len(allDay.OccurrencesOverlapping(NewDateTime(2020, 1, 2, 20, 59), NewDateTime(2020, 1, 2, 22, 0))) != 1 {
+		t.Errorf("all day event must overlaps interval at ending of its last day")
+	}
+	if len(allDay.OccurrencesOverlapping(NewDateTime(2020, 1, 2, 21, 0), NewDateTime(2020, 1, 2, 22, 0))) != 0 {
+		t.Errorf("all day event must not overlaps interval after its last day")
+	}
+}
+
+func TestIsOver(t *testing.T) {
+	event := NewEvent("Meeting", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 11, 0))
+	if event.IsOver(time.Date(2019, 11, 25, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("event must not be over at its end")
+	}
+	if !event.IsOver(time.Date(2019, 11, 25, 11, 1, 0, 0, time.UTC)) {
+		t.Errorf("event must be over after its end")
+	}
+
+	recurrence, _ := ParseRecurrence("FREQ=DAILY;COUNT=2")
+	if WithRecurrence(event, recurrence).IsOver(time.Date(2020, 11, 25, 11, 1, 0, 0, time.UTC)) {
+		t.Errorf("recurring event must never be over")
+	}
+}
@@ -0,0 +1,199 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Events that started before range of free/busy query are taken into account if they started not earlier than that
+const freeBusyLookBack = 24 * time.Hour
+
+// Error about invalid working hours
+var ErrInvalidWorkingHours = errors.New("working hours must be HH:MM and start must be before end")
+
+// Default working hours of day
+const (
+	DefaultWorkStart = "09:00"
+	DefaultWorkEnd   = "18:00"
+)
+
+// Working hours of day: DefaultWorkStart - DefaultWorkEnd
+var DefaultWorkingHours = WorkingHours{9 * 60, 18 * 60}
+
+// Interval of time, start is included, end is excluded
+type Interval struct {
+	start DateTime
+	end   DateTime
+}
+
+// Constructor
+func NewInterval(start DateTime, end DateTime) Interval {
+	return Interval{
+		start: start,
+		end:   end,
+	}
+}
+
+// Start getter
+func (interval Interval) Start() DateTime {
+	return interval.start
+}
+
+// End getter, end is excluded
+func (interval Interval) End() DateTime {
+	return interval.end
+}
+
+// Duration of interval
+func (interval Interval) Duration() time.Duration {
+	return interval.end.Time().Sub(interval.start.Time())
+}
+
+// String representation of interval
+func (interval Interval) String() string {
+	return fmt.Sprintf("[%s, %s)", interval.start, interval.end)
+}
+
+// Working hours of every day, local wall clock time in location of free/busy query
+type WorkingHours struct {
+	start int // minutes from midnight
+	end   int // minutes from midnight, 24:00 is end of day
+}
+
+// Constructor, start and end are HH:MM (end could be 24:00), start must be before end
+func NewWorkingHours(start string, end string) (WorkingHours, error) {
+	startMinutes, err := parseClock(start)
+	if err != nil {
+		return WorkingHours{}, err
+	}
+	endMinutes, err := parseClock(end)
+	if err != nil {
+		return WorkingHours{}, err
+	}
+	if startMinutes >= endMinutes {
+		return WorkingHours{}, ErrInvalidWorkingHours
+	}
+	return WorkingHours{startMinutes, endMinutes}, nil
+}
+
+// Merged busy intervals of events of storage view in range [start, end)
+// Occurrences are got by GetEventsByPeriod of storage, overlapping and adjacent occurrences are merged, intervals are clipped by range
+func GetBusyIntervals(ctx context.Context, storage Storage, start DateTime, end DateTime) ([]Interval, error) {
+	from := ConvertFromTime(start.Time().Add(-freeBusyLookBack))
+	events, err := storage.GetEventsByPeriod(ctx, &from, &end)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get events by period: %w", err)
+	}
+	return MergeBusyIntervals(events, start, end), nil
+}
+
+// Merge intervals of events (all day events are busy whole days) clipped by range [start, end)
+// Overlapping and adjacent intervals are merged, result is sorted by start
+func MergeBusyIntervals(events []Event, start DateTime, end DateTime) []Interval {
+	var intervals []Interval
+	for _, event := range events {
+		interval := NewInterval(event.start, event.exclusiveEnd())
+		if interval.start.Less(start) {
+			interval.start = start
+		}
+		if end.Less(interval.end) {
+			interval.end = end
+		}
+		if interval.start.Less(interval.end) {
+			intervals = append(intervals, NewInterval(interval.start.In(start.Location()), interval.end.In(start.Location())))
+		}
+	}
+
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start.Less(intervals[j].start)
+	})
+
+	var merged []Interval
+	for _, interval := range intervals {
+		last := len(merged) - 1
+		if last >= 0 && interval.start.LessOrEqual(merged[last].end) {
+			if merged[last].end.Less(interval.end) {
+				merged[last].end = interval.end
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+
+	return merged
+}
+
+// Free gaps between merged busy intervals (see MergeBusyIntervals) in range [start, end) within working hours of every day
+// Days are local days in location of start, gaps shorter than minDuration are skipped
+func FreeIntervals(busy []Interval, start DateTime, end DateTime, hours WorkingHours, minDuration time.Duration) []Interval {
+	loc := start.Location()
+	startTime := start.Time()
+
+	var free []Interval
+	day := time.Date(startTime.Year(), startTime.Month(), startTime.Day(), 0, 0, 0, 0, loc)
+	for ; day.Before(end.Time()); day = day.AddDate(0, 0, 1) {
+		dayStart := ConvertFromTime(time.Date(day.Year(), day.Month(), day.Day(), 0, hours.start, 0, 0, loc))
+		dayEnd := ConvertFromTime(time.Date(day.Year(), day.Month(), day.Day(), 0, hours.end, 0, 0, loc))
+		if dayStart.Less(start) {
+			dayStart = start
+		}
+		if end.Less(dayEnd) {
+			dayEnd = end
+		}
+		if !dayStart.Less(dayEnd) {
+			continue
+		}
+
+		cursor := dayStart
+		for _, interval := range busy {
+			if interval.end.LessOrEqual(cursor) {
+				continue
+			}
+			if dayEnd.LessOrEqual(interval.start) {
+				break
+			}
+			if cursor.Less(interval.start) {
+				free = appendFreeInterval(free, NewInterval(cursor, interval.start), minDuration)
+			}
+			cursor = interval.end
+		}
+		if cursor.Less(dayEnd) {
+			free = appendFreeInterval(free, NewInterval(cursor, dayEnd), minDuration)
+		}
+	}
+
+	return free
+}
+
+// Inner helper that append free interval if it is not shorter than minDuration
+func appendFreeInterval(free []Interval, interval Interval, minDuration time.Duration) []Interval {
+	if interval.Duration() < minDuration {
+		return free
+	}
+	return append(free, interval)
+}
+
+// Inner helper that parse HH:MM into minutes from midnight, 24:00 is allowed
+func parseClock(clock string) (int, error) {
+	parts := strings.Split(clock, ":")
+	if len(parts) != 2 || len(parts[1]) != 2 {
+		return 0, ErrInvalidWorkingHours
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, ErrInvalidWorkingHours
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, ErrInvalidWorkingHours
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, ErrInvalidWorkingHours
+	}
+	return hour*60 + minute, nil
+}
@@ -0,0 +1,81 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeBusyIntervals(t *testing.T) {
+	events := []Event{
+		NewEvent("Lunch", NewDateTime(2019, 11, 25, 12, 0), NewDateTime(2019, 11, 25, 13, 0)),
+		NewEvent("Morning", NewDateTime(2019, 11, 25, 9, 0), NewDateTime(2019, 11, 25, 10, 0)),
+		// adjacent to Morning
+		NewEvent("Stand-up", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 10, 15)),
+		// inside Lunch
+		NewEvent("Call", NewDateTime(2019, 11, 25, 12, 15), NewDateTime(2019, 11, 25, 12, 30)),
+		// started before range
+		NewEvent("Night", NewDateTime(2019, 11, 24, 23, 0), NewDateTime(2019, 11, 25, 1, 0)),
+		NewAllDayEvent("Holiday", NewDate(2019, 11, 26), NewDate(2019, 11, 26)),
+	}
+
+	start := NewDateTime(2019, 11, 25, 0, 0)
+	end := NewDateTime(2019, 11, 26, 12, 0)
+
+	expected := []Interval{
+		NewInterval(NewDateTime(2019, 11, 25, 0, 0), NewDateTime(2019, 11, 25, 1, 0)),
+		NewInterval(NewDateTime(2019, 11, 25, 9, 0), NewDateTime(2019, 11, 25, 10, 15)),
+		NewInterval(NewDateTime(2019, 11, 25, 12, 0), NewDateTime(2019, 11, 25, 13, 0)),
+		NewInterval(NewDateTime(2019, 11, 26, 0, 0), NewDateTime(2019, 11, 26, 12, 0)),
+	}
+
+	busy := MergeBusyIntervals(events, start, end)
+	if !reflect.DeepEqual(busy, expected) {
+		t.Errorf("busy intervals must be %v instead of %v", expected, busy)
+	}
+}
+
+func TestFreeIntervals(t *testing.T) {
+	busy := []Interval{
+		NewInterval(NewDateTime(2019, 11, 25, 8, 0), NewDateTime(2019, 11, 25, 10, 15)),
+		NewInterval(NewDateTime(2019, 11, 25, 10, 30), NewDateTime(2019, 11, 25, 12, 0)),
+		NewInterval(NewDateTime(2019, 11, 25, 17, 0), NewDateTime(2019, 11, 26, 10, 0)),
+	}
+
+	start := NewDateTime(2019, 11, 25, 0, 0)
+	end := NewDateTime(2019, 11, 27, 0, 0)
+
+	expected := []Interval{
+		NewInterval(NewDateTime(2019, 11, 25, 12, 0), NewDateTime(2019, 11, 25, 17, 0)),
+		NewInterval(NewDateTime(2019, 11, 26, 10, 0), NewDateTime(2019, 11, 26, 18, 0)),
+	}
+
+	// gap 10:15 - 10:30 is shorter than 30 minutes
+	free := FreeIntervals(busy, start, end, DefaultWorkingHours, 30*time.Minute)
+	if !reflect.DeepEqual(free, expected) {
+		t.Errorf("free intervals must be %v instead of %v", expected, free)
+	}
+
+	hours, _ := NewWorkingHours("10:00", "11:00")
+	free = FreeIntervals(busy, start, NewDateTime(2019, 11, 26, 0, 0), hours, 0)
+	expected = []Interval{
+		NewInterval(NewDateTime(2019, 11, 25, 10, 15), NewDateTime(2019, 11, 25, 10, 30)),
+	}
+	if !reflect.DeepEqual(free, expected) {
+		t.Errorf("free intervals must be %v instead of %v", expected, free)
+	}
+}
+
+func TestNewWorkingHours(t *testing.T) {
+	hours, err := NewWorkingHours("08:30", "24:00")
+	if err != nil || hours != (WorkingHours{8*60 + 30, 24 * 60}) {
+		t.Errorf("unexpected working hours %v, error %v", hours, err)
+	}
+
+	cases := [][2]string{{"18:00", "09:00"}, {"9", "18:00"}, {"09:00", "25:00"}, {"09:0", "18:00"}, {"09:60", "18:00"}}
+	for _, c := range cases {
+		if _, err := NewWorkingHours(c[0], c[1]); err != ErrInvalidWorkingHours {
+			t.Errorf("expected error `%s` for %v instead of `%v`", ErrInvalidWorkingHours, c, err)
+		}
+	}
+}
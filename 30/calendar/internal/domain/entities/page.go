@@ -0,0 +1,113 @@
+package entities
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Limits of pages of list queries of APIs: page size when limit is not passed and the maximal page size
+const (
+	DefaultPageLimit = 100
+	MaxPageLimit     = 1000
+)
+
+// Error about cursor that is not got from previous page
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page of events of list query, events are ordered by start and id
+// Next cursor points after the last event of page, it is empty if there are no more events
+type EventPage struct {
+	Events     []Event
+	NextCursor string
+}
+
+// Position in list of events ordered by start and id (keyset), zero cursor is position before the first event
+type Cursor struct {
+	start int64 // unix time of start of event (occurrence)
+	id    int
+}
+
+// Cursor that points after event
+func NewCursor(event Event) Cursor {
+	return Cursor{start: event.Start().Time().Unix(), id: event.Id()}
+}
+
+// Parse opaque cursor string, empty string is zero cursor
+func ParseCursor(s string) (Cursor, error) {
+	if s == "" {
+		return Cursor{}, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	cursor := Cursor{}
+	_, err = fmt.Sscanf(string(data), "%d:%d", &cursor.start, &cursor.id)
+	if err != nil || cursor.id <= 0 || cursor.String() != s {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// Is it zero cursor, i.e. position before the first event
+func (cursor Cursor) IsZero() bool {
+	return cursor == Cursor{}
+}
+
+// Unix time of start of the last event of previous page
+func (cursor Cursor) Start() int64 {
+	return cursor.start
+}
+
+// Id of the last event of previous page
+func (cursor Cursor) Id() int {
+	return cursor.id
+}
+
+// Is event after cursor, so it is on next page
+func (cursor Cursor) Before(event Event) bool {
+	return cursor.IsZero() || cursor.less(NewCursor(event))
+}
+
+// Opaque string representation of cursor, empty for zero cursor
+func (cursor Cursor) String() string {
+	if cursor.IsZero() {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", cursor.start, cursor.id)))
+}
+
+// Build page from events after cursor, events could be unordered and contain events before cursor
+// limit <= 0 means no limit, so page contains all events after cursor
+func NewEventPage(events []Event, cursor Cursor, limit int) EventPage {
+	page := EventPage{}
+	for _, event := range events {
+		if cursor.Before(event) {
+			page.Events = append(page.Events, event)
+		}
+	}
+
+	SortEventsByStartAndId(page.Events)
+
+	if limit > 0 && len(page.Events) > limit {
+		page.Events = page.Events[:limit]
+		page.NextCursor = NewCursor(page.Events[limit-1]).String()
+	}
+	return page
+}
+
+// Sort events in order of pages
+func SortEventsByStartAndId(events []Event) {
+	sort.Slice(events, func(i, j int) bool {
+		return NewCursor(events[i]).less(NewCursor(events[j]))
+	})
+}
+
+func (cursor Cursor) less(that Cursor) bool {
+	if cursor.start != that.start {
+		return cursor.start < that.start
+	}
+	return cursor.id < that.id
+}
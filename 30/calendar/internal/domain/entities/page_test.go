@@ -0,0 +1,104 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	event := WithId(NewEvent("Meeting", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 11, 0)), 7)
+
+	cursor, err := ParseCursor(NewCursor(event).String())
+	if err != nil {
+		t.Fatalf("must not be error instead of %s", err)
+	}
+	if cursor != NewCursor(event) {
+		t.Errorf("parsed cursor must be %v instead of %v", NewCursor(event), cursor)
+	}
+	if cursor.Before(event) {
+		t.Errorf("event of cursor must not be after cursor")
+	}
+
+	cursor, err = ParseCursor("")
+	if err != nil || !cursor.IsZero() || !cursor.Before(event) {
+		t.Errorf("empty cursor must be zero cursor before all events, got %v, %v", cursor, err)
+	}
+
+	for _, s := range []string{"x", "LTE", "MTow", "MTo1Ojc", "MTo1IA"} {
+		_, err := ParseCursor(s)
+		if err != ErrInvalidCursor {
+			t.Errorf("cursor %s must be ErrInvalidCursor instead of %v", s, err)
+		}
+	}
+}
+
+func TestNewEventPage(t *testing.T) {
+	start := NewDateTime(2019, 11, 25, 10, 0)
+	var events []Event
+	for i := 5; i >= 1; i-- {
+		// events 1 and 2 start at the same time, so they are ordered by id
+		eventStart := start.PlusMinutes(60 * (i / 2))
+		events = append(events, WithId(NewEvent("Meeting", eventStart, eventStart.PlusMinutes(30)), i))
+	}
+
+	ids := func(page EventPage) []int {
+		var ids []int
+		for _, event := range page.Events {
+			ids = append(ids, event.Id())
+		}
+		return ids
+	}
+
+	page := NewEventPage(events, Cursor{}, 2)
+	if !reflect.DeepEqual(ids(page), []int{1, 2}) || page.NextCursor == "" {
+		t.Errorf("first page must be [1 2] with next cursor instead of %v %q", ids(page), page.NextCursor)
+	}
+
+	cursor, _ := ParseCursor(page.NextCursor)
+	page = NewEventPage(events, cursor, 2)
+	if !reflect.DeepEqual(ids(page), []int{3, 4}) || page.NextCursor == "" {
+		t.Errorf("second page must be [3 4] with next cursor instead of %v %q", ids(page), page.NextCursor)
+	}
+
+	cursor, _ = ParseCursor(page.NextCursor)
+	page = NewEventPage(events, cursor, 2)
+	if !reflect.DeepEqual(ids(page), []int{5}) || page.NextCursor != "" {
+		t.Errorf("last page must be [5] without next cursor instead of %v %q", ids(page), page.NextCursor)
+	}
+
+	page = NewEventPage(events, Cursor{}, 0)
+	if len(page.Events) != 5 || page.NextCursor != "" {
+		t.Errorf("page without limit must have all events without next cursor instead of %v %q", ids(page), page.NextCursor)
+	}
+}
+
+func TestOccurrencesInPage(t *testing.T) {
+	start := NewDateTime(2019, 11, 25, 10, 0)
+	r, _ := ParseRecurrence("FREQ=DAILY")
+	event := WithRecurrence(WithId(NewEvent("Standup", start, start.PlusMinutes(15)), 3), r)
+
+	// infinite event without end of period is expanded only up to limit plus one occurrence
+	occurrences := event.OccurrencesInPage(&start, nil, Cursor{}, 2)
+	if len(occurrences) != 3 || !occurrences[0].Start().Equal(start) {
+		t.Fatalf("first page must have 3 occurrences from %s instead of %v", start, occurrences)
+	}
+
+	// expansion starts from cursor, occurrence of cursor is on previous page
+	cursor := NewCursor(occurrences[1])
+	occurrences = event.OccurrencesInPage(&start, nil, cursor, 2)
+	if len(occurrences) != 3 || !occurrences[0].Start().Equal(start.PlusMinutes(2*24*60)) {
+		t.Fatalf("next page must have 3 occurrences from the third day instead of %v", occurrences)
+	}
+
+	// cursor of far future page doesn't expand earlier occurrences
+	far := WithId(NewEvent("Other", start.PlusMinutes(10000*24*60), start.PlusMinutes(10000*24*60)), 1)
+	occurrences = event.OccurrencesInPage(&start, nil, NewCursor(far), 1)
+	if len(occurrences) != 2 || !occurrences[0].Start().Equal(far.Start()) {
+		t.Errorf("page after far cursor must start with occurrence at %s instead of %v", far.Start(), occurrences)
+	}
+
+	end := start.PlusMinutes(24 * 60)
+	if occurrences := event.OccurrencesInPage(&start, &end, Cursor{}, 0); len(occurrences) != 2 {
+		t.Errorf("page without limit must have all 2 occurrences of period instead of %d", len(occurrences))
+	}
+}
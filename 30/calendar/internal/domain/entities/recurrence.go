@@ -0,0 +1,497 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Max number of occurrences that will be expanded for rule without COUNT and UNTIL when period has no end boundary
+const MaxOccurrences = 1000
+
+// Layouts of UNTIL value in RRULE
+const (
+	untilDateTimeLayout = "20060102T150405Z"
+	untilDateLayout     = "20060102"
+)
+
+var ErrorInvalidRecurrence = errors.New("invalid recurrence rule")
+
+// Frequency of recurrence
+type Frequency int
+
+const (
+	Daily Frequency = iota + 1
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencyNames = map[Frequency]string{
+	Daily:   "DAILY",
+	Weekly:  "WEEKLY",
+	Monthly: "MONTHLY",
+	Yearly:  "YEARLY",
+}
+
+var weekdayNames = map[time.Weekday]string{
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+	time.Sunday:    "SU",
+}
+
+// String representation of frequency as in RRULE
+func (f Frequency) String() string {
+	return frequencyNames[f]
+}
+
+// Weekday with optional ordinal number (BYDAY item in RRULE), e.g. MO, 2TU, -1FR
+// N == 0 means every such weekday of period
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// String representation of weekday as in RRULE
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayNames[w.Weekday]
+	}
+	return strconv.Itoa(w.N) + weekdayNames[w.Weekday]
+}
+
+// Recurrence rule of event, subset of RFC 5545 RRULE: FREQ, INTERVAL, BYDAY, COUNT, UNTIL plus exception dates
+// Recurrence is immutable, all With* methods return modified copy
+type Recurrence struct {
+	freq     Frequency
+	interval int
+	byDay    []WeekdayNum
+	count    int       // 0 means no count limit
+	until    *DateTime // nil means no until limit, inclusive
+	exDates  []DateTime
+}
+
+// Constructor
+func NewRecurrence(freq Frequency, interval int) (*Recurrence, error) {
+	if _, ok := frequencyNames[freq]; !ok {
+		return nil, fmt.Errorf("%w: unknown frequency %d", ErrorInvalidRecurrence, freq)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("%w: interval must be greater than 0", ErrorInvalidRecurrence)
+	}
+	return &Recurrence{
+		freq:     freq,
+		interval: interval,
+	}, nil
+}
+
+// Parse recurrence from RRULE string, e.g. "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10"
+func ParseRecurrence(rrule string) (*Recurrence, error) {
+	rrule = strings.TrimPrefix(strings.TrimSpace(rrule), "RRULE:")
+	if rrule == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrorInvalidRecurrence)
+	}
+
+	r := &Recurrence{interval: 1}
+
+	for _, part := range strings.Split(rrule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%w: malformed part `%s`", ErrorInvalidRecurrence, part)
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		var err error
+		switch key {
+		case "FREQ":
+			r.freq, err = parseFrequency(value)
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err == nil && r.interval <= 0 {
+				err = errors.New("interval must be greater than 0")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(value)
+			if err == nil && r.count <= 0 {
+				err = errors.New("count must be greater than 0")
+			}
+		case "UNTIL":
+			var until DateTime
+			until, err = parseUntil(value)
+			r.until = &until
+		case "BYDAY":
+			r.byDay, err = parseByDay(value)
+		case "WKST":
+			if value != "MO" {
+				err = errors.New("only MO week start is supported")
+			}
+		default:
+			err = fmt.Errorf("unsupported part `%s`", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrorInvalidRecurrence, err)
+		}
+	}
+
+	if r.freq == 0 {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrorInvalidRecurrence)
+	}
+	if r.count > 0 && r.until != nil {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL must not occur both", ErrorInvalidRecurrence)
+	}
+	for _, day := range r.byDay {
+		if day.N != 0 && r.freq != Monthly {
+			return nil, fmt.Errorf("%w: ordinal BYDAY is supported only for MONTHLY frequency", ErrorInvalidRecurrence)
+		}
+	}
+	if r.freq == Yearly && len(r.byDay) > 0 {
+		return nil, fmt.Errorf("%w: BYDAY is not supported for YEARLY frequency", ErrorInvalidRecurrence)
+	}
+
+	return r, nil
+}
+
+// Clone with BYDAY
+func (r *Recurrence) WithByDay(byDay []WeekdayNum) *Recurrence {
+	clone := *r
+	clone.byDay = append([]WeekdayNum(nil), byDay...)
+	return &clone
+}
+
+// Clone with COUNT, 0 means no count limit
+func (r *Recurrence) WithCount(count int) *Recurrence {
+	clone := *r
+	clone.count = count
+	clone.until = nil
+	return &clone
+}
+
+// Clone with UNTIL, nil means no until limit
+func (r *Recurrence) WithUntil(until *DateTime) *Recurrence {
+	clone := *r
+	clone.until = until
+	clone.count = 0
+	return &clone
+}
+
+// Clone with exception dates (starts of occurrences that must be skipped)
+func (r *Recurrence) WithExDates(exDates []DateTime) *Recurrence {
+	clone := *r
+	clone.exDates = append([]DateTime(nil), exDates...)
+	return &clone
+}
+
+// Frequency getter
+func (r *Recurrence) Freq() Frequency {
+	return r.freq
+}
+
+// Interval getter
+func (r *Recurrence) Interval() int {
+	return r.interval
+}
+
+// BYDAY getter
+func (r *Recurrence) ByDay() []WeekdayNum {
+	return r.byDay
+}
+
+// COUNT getter, 0 means no count limit
+func (r *Recurrence) Count() int {
+	return r.count
+}
+
+// UNTIL getter, nil means no until limit
+func (r *Recurrence) Until() *DateTime {
+	return r.until
+}
+
+// Exception dates getter
+func (r *Recurrence) ExDates() []DateTime {
+	return r.exDates
+}
+
+// Is rule has end (COUNT or UNTIL)
+func (r *Recurrence) IsFinite() bool {
+	return r.count > 0 || r.until != nil
+}
+
+// RRULE string representation (without exception dates)
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + r.freq.String()}
+	if r.interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.interval))
+	}
+	if len(r.byDay) > 0 {
+		days := make([]string, 0, len(r.byDay))
+		for _, day := range r.byDay {
+			days = append(days, day.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.count))
+	}
+	if r.until != nil {
+		parts = append(parts, "UNTIL="+r.until.In(time.UTC).Format(untilDateTimeLayout))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Starts of occurrences of rule for event that started at `start`, sorted ascending
+// Only occurrences that started in period (boundary of period are included) are returned
+// You also can pass nil for from or to, nil has special means - no boundary for range period
+// For infinite rule and period without end boundary no more than MaxOccurrences (from `from`) are expanded
+// Periods of rule without COUNT that ended before `from` are skipped without expansion
+func (r *Recurrence) Occurrences(start DateTime, from *DateTime, to *DateTime) []DateTime {
+	var result []DateTime
+	r.eachOccurrence(start, from, to, func(occurrence DateTime) bool {
+		result = append(result, occurrence)
+		return to != nil || r.IsFinite() || len(result) < MaxOccurrences
+	})
+	return result
+}
+
+// Call fn for starts of occurrences in period in ascending order until fn returns false
+// For infinite rule and period without end boundary fn must stop expansion
+func (r *Recurrence) eachOccurrence(start DateTime, from *DateTime, to *DateTime, fn func(occurrence DateTime) bool) {
+	emitted := 0
+	for period := r.firstPeriod(start, from); ; period++ {
+		periodStart, candidates := r.candidates(start, period)
+
+		if to != nil && to.Less(periodStart) {
+			return
+		}
+		if r.until != nil && r.until.Less(periodStart) {
+			return
+		}
+
+		for _, candidate := range candidates {
+			if candidate.Less(start) {
+				continue
+			}
+			if r.until != nil && r.until.Less(candidate) {
+				return
+			}
+			if r.count > 0 && emitted >= r.count {
+				return
+			}
+
+			// exception dates are still counted by COUNT
+			emitted++
+
+			if r.isExDate(candidate) {
+				continue
+			}
+			if from != nil && candidate.Less(*from) {
+				continue
+			}
+			if to != nil && to.Less(candidate) {
+				return
+			}
+			if !fn(candidate) {
+				return
+			}
+		}
+	}
+}
+
+// Number of the first period of rule which occurrences could be not before from, 0 if from is nil
+// Every occurrence is counted by COUNT, so periods of rule with COUNT are never skipped
+// One period more is kept to be safe from time zone of from and time of day of start
+func (r *Recurrence) firstPeriod(start DateTime, from *DateTime) int {
+	if from == nil || r.count > 0 || !start.Less(*from) {
+		return 0
+	}
+
+	t := start.Time()
+	f := from.Time().In(t.Location())
+	// dates in UTC, so DST changes don't break number of days between them
+	startDate := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	fromDate := time.Date(f.Year(), f.Month(), f.Day(), 0, 0, 0, 0, time.UTC)
+
+	var units int
+	switch r.freq {
+	case Daily:
+		units = int(fromDate.Sub(startDate).Hours() / 24)
+	case Weekly:
+		shift := (int(t.Weekday()) + 6) % 7 // days since monday
+		units = int(fromDate.Sub(startDate.AddDate(0, 0, -shift)).Hours()/24) / 7
+	case Monthly:
+		units = (f.Year()-t.Year())*12 + int(f.Month()) - int(t.Month())
+	case Yearly:
+		units = f.Year() - t.Year()
+	}
+
+	period := units/r.interval - 1
+	if period < 0 {
+		return 0
+	}
+	return period
+}
+
+// Candidates of occurrences in n-th period of rule, plus start of that period
+func (r *Recurrence) candidates(start DateTime, n int) (DateTime, []DateTime) {
+	t := start.Time()
+	hour, minute := t.Hour(), t.Minute()
+	step := n * r.interval
+
+	var periodStart time.Time
+	var days []time.Time
+
+	switch r.freq {
+	case Daily:
+		periodStart = time.Date(t.Year(), t.Month(), t.Day()+step, 0, 0, 0, 0, t.Location())
+		if len(r.byDay) == 0 || r.hasWeekday(periodStart.Weekday()) {
+			days = append(days, periodStart)
+		}
+	case Weekly:
+		shift := (int(t.Weekday()) + 6) % 7 // days since monday
+		periodStart = time.Date(t.Year(), t.Month(), t.Day()-shift+7*step, 0, 0, 0, 0, t.Location())
+		if len(r.byDay) == 0 {
+			days = append(days, periodStart.AddDate(0, 0, shift))
+		} else {
+			for i := 0; i < 7; i++ {
+				day := periodStart.AddDate(0, 0, i)
+				if r.hasWeekday(day.Weekday()) {
+					days = append(days, day)
+				}
+			}
+		}
+	case Monthly:
+		periodStart = time.Date(t.Year(), t.Month()+time.Month(step), 1, 0, 0, 0, 0, t.Location())
+		if len(r.byDay) == 0 {
+			day := time.Date(periodStart.Year(), periodStart.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+			// months without such day are skipped
+			if day.Month() == periodStart.Month() {
+				days = append(days, day)
+			}
+		} else {
+			days = r.monthDays(periodStart)
+		}
+	case Yearly:
+		periodStart = time.Date(t.Year()+step, time.January, 1, 0, 0, 0, 0, t.Location())
+		day := time.Date(periodStart.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		// years without such day (29 Feb) are skipped
+		if day.Month() == t.Month() {
+			days = append(days, day)
+		}
+	}
+
+	candidates := make([]DateTime, 0, len(days))
+	for _, day := range days {
+		candidates = append(candidates, ConvertFromTime(
+			time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location()),
+		))
+	}
+
+	return ConvertFromTime(periodStart), candidates
+}
+
+// Days of month matched by BYDAY, sorted ascending
+func (r *Recurrence) monthDays(firstDay time.Time) []time.Time {
+	lastDay := firstDay.AddDate(0, 1, -1).Day()
+
+	var days []time.Time
+	for d := 1; d <= lastDay; d++ {
+		day := firstDay.AddDate(0, 0, d-1)
+		for _, byDay := range r.byDay {
+			if byDay.Weekday != day.Weekday() {
+				continue
+			}
+			nth := (d-1)/7 + 1                 // ordinal from the beginning of month
+			nthFromEnd := -((lastDay-d)/7 + 1) // ordinal from the end of month
+			if byDay.N == 0 || byDay.N == nth || byDay.N == nthFromEnd {
+				days = append(days, day)
+				break
+			}
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Before(days[j])
+	})
+
+	return days
+}
+
+// Is weekday in BYDAY list
+func (r *Recurrence) hasWeekday(weekday time.Weekday) bool {
+	for _, day := range r.byDay {
+		if day.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// Is date time one of exception dates
+func (r *Recurrence) isExDate(dateTime DateTime) bool {
+	for _, exDate := range r.exDates {
+		if exDate.Equal(dateTime) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseFrequency(value string) (Frequency, error) {
+	for freq, name := range frequencyNames {
+		if name == value {
+			return freq, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported frequency `%s`", value)
+}
+
+func parseUntil(value string) (DateTime, error) {
+	t, err := time.Parse(untilDateTimeLayout, value)
+	if err == nil {
+		return ConvertFromTime(t), nil
+	}
+	t, err = time.Parse(untilDateLayout, value)
+	if err == nil {
+		// until date is inclusive, so whole day is included
+		return ConvertFromTime(t.Add(24*time.Hour - time.Minute)), nil
+	}
+	return DateTime{}, fmt.Errorf("invalid UNTIL value `%s`", value)
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var result []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY item `%s`", item)
+		}
+		name := item[len(item)-2:]
+		weekday, ok := parseWeekday(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY weekday `%s`", name)
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n > 5 || n < -5 {
+				return nil, fmt.Errorf("invalid BYDAY ordinal `%s`", prefix)
+			}
+		}
+		result = append(result, WeekdayNum{Weekday: weekday, N: n})
+	}
+	return result, nil
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for weekday, weekdayName := range weekdayNames {
+		if weekdayName == name {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
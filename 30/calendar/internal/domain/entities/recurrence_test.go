@@ -0,0 +1,223 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	r, err := ParseRecurrence("RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if r.Freq() != Weekly || r.Interval() != 2 || r.Count() != 10 || len(r.ByDay()) != 2 {
+		t.Errorf("rule parsed wrong: %s", r)
+	}
+
+	expected := "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10"
+	if r.String() != expected {
+		t.Errorf("String() must be `%s` instead of `%s`", expected, r.String())
+	}
+
+	invalidRules := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20191231",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=YEARLY;BYDAY=MO",
+		"FREQ=DAILY;BYHOUR=10",
+	}
+
+	for _, rule := range invalidRules {
+		_, err := ParseRecurrence(rule)
+		if err == nil {
+			t.Errorf("rule `%s` must be invalid", rule)
+		}
+	}
+}
+
+func TestOccurrencesDaily(t *testing.T) {
+	r, _ := ParseRecurrence("FREQ=DAILY;INTERVAL=2;COUNT=4")
+	start := NewDateTime(2019, 11, 30, 10, 0)
+
+	occurrences := r.Occurrences(start, nil, nil)
+	expected := []DateTime{
+		NewDateTime(2019, 11, 30, 10, 0),
+		NewDateTime(2019, 12, 2, 10, 0),
+		NewDateTime(2019, 12, 4, 10, 0),
+		NewDateTime(2019, 12, 6, 10, 0),
+	}
+
+	if !reflect.DeepEqual(expected, occurrences) {
+		t.Errorf("expected %v instead of %v", expected, occurrences)
+	}
+}
+
+func TestOccurrencesWeekly(t *testing.T) {
+	r, _ := ParseRecurrence("FREQ=WEEKLY;BYDAY=MO,FR;UNTIL=20191213T235900Z")
+	// wednesday
+	start := NewDateTime(2019, 11, 27, 9, 30)
+
+	occurrences := r.Occurrences(start, nil, nil)
+	expected := []DateTime{
+		NewDateTime(2019, 11, 29, 9, 30),
+		NewDateTime(2019, 12, 2, 9, 30),
+		NewDateTime(2019, 12, 6, 9, 30),
+		NewDateTime(2019, 12, 9, 9, 30),
+		NewDateTime(2019, 12, 13, 9, 30),
+	}
+
+	if !reflect.DeepEqual(expected, occurrences) {
+		t.Errorf("expected %v instead of %v", expected, occurrences)
+	}
+}
+
+func TestOccurrencesMonthly(t *testing.T) {
+	r, _ := ParseRecurrence("FREQ=MONTHLY;COUNT=4")
+	start := NewDateTime(2019, 10, 31, 12, 0)
+
+	// months without 31st day are skipped
+	occurrences := r.Occurrences(start, nil, nil)
+	expected := []DateTime{
+		NewDateTime(2019, 10, 31, 12, 0),
+		NewDateTime(2019, 12, 31, 12, 0),
+		NewDateTime(2020, 1, 31, 12, 0),
+		NewDateTime(2020, 3, 31, 12, 0),
+	}
+
+	if !reflect.DeepEqual(expected, occurrences) {
+		t.Errorf("expected %v instead of %v", expected, occurrences)
+	}
+
+	// last friday and second tuesday of month
+	r, _ = ParseRecurrence("FREQ=MONTHLY;BYDAY=-1FR,2TU;COUNT=3")
+	start = NewDateTime(2019, 11, 1, 18, 0)
+
+	occurrences = r.Occurrences(start, nil, nil)
+	expected = []DateTime{
+		NewDateTime(2019, 11, 12, 18, 0),
+		NewDateTime(2019, 11, 29, 18, 0),
+		NewDateTime(2019, 12, 10, 18, 0),
+	}
+
+	if !reflect.DeepEqual(expected, occurrences) {
+		t.Errorf("expected %v instead of %v", expected, occurrences)
+	}
+}
+
+func TestOccurrencesYearly(t *testing.T) {
+	r, _ := ParseRecurrence("FREQ=YEARLY;COUNT=2")
+	start := NewDateTime(2020, 2, 29, 0, 0)
+
+	occurrences := r.Occurrences(start, nil, nil)
+	expected := []DateTime{
+		NewDateTime(2020, 2, 29, 0, 0),
+		NewDateTime(2024, 2, 29, 0, 0),
+	}
+
+	if !reflect.DeepEqual(expected, occurrences) {
+		t.Errorf("expected %v instead of %v", expected, occurrences)
+	}
+}
+
+func TestOccurrencesInPeriod(t *testing.T) {
+	r, _ := ParseRecurrence("FREQ=WEEKLY;COUNT=5")
+	r = r.WithExDates([]DateTime{NewDateTime(2019, 11, 25, 8, 0)})
+
+	from := NewDateTime(2019, 11, 20, 0, 0)
+	to := NewDateTime(2019, 12, 31, 23, 59)
+
+	// exception date is counted by COUNT, so 18 Dec is not occurrence
+	occurrences := r.Occurrences(NewDateTime(2019, 11, 18, 8, 0), &from, &to)
+	expected := []DateTime{
+		NewDateTime(2019, 12, 2, 8, 0),
+		NewDateTime(2019, 12, 9, 8, 0),
+		NewDateTime(2019, 12, 16, 8, 0),
+	}
+
+	if !reflect.DeepEqual(expected, occurrences) {
+		t.Errorf("expected %v instead of %v", expected, occurrences)
+	}
+
+	r, _ = ParseRecurrence("FREQ=DAILY")
+	occurrences = r.Occurrences(NewDateTime(2019, 11, 18, 8, 0), nil, nil)
+	if len(occurrences) != MaxOccurrences {
+		t.Errorf("infinite rule must be expanded to %d occurrences instead of %d", MaxOccurrences, len(occurrences))
+	}
+}
+
+// Occurrences before period are not counted by MaxOccurrences, so long running event is still found in current periods
+func TestOccurrencesOfLongRunningEvent(t *testing.T) {
+	start := NewDateTime(2014, 11, 18, 8, 0)
+	from := NewDateTime(2019, 11, 18, 0, 0)
+	to := NewDateTime(2019, 11, 24, 23, 59)
+
+	rules := map[string]int{
+		"FREQ=DAILY":                 7,
+		"FREQ=DAILY;INTERVAL=3":      2,
+		"FREQ=WEEKLY;BYDAY=MO,WE,FR": 3,
+		"FREQ=WEEKLY;INTERVAL=2":     0,
+		"FREQ=MONTHLY;BYDAY=3MO":     1,
+		"FREQ=YEARLY":                1,
+		"FREQ=DAILY;UNTIL=20191120":  3,
+	}
+	for rule, expected := range rules {
+		r, _ := ParseRecurrence(rule)
+		if occurrences := r.Occurrences(start, &from, &to); len(occurrences) != expected {
+			t.Errorf("%s must have %d occurrences in week of 18 Nov 2019 instead of %v", rule, expected, occurrences)
+		}
+
+		// result is the same as without skipping of periods
+		var all []DateTime
+		for _, occurrence := range r.Occurrences(start, nil, &to) {
+			if !occurrence.Less(from) {
+				all = append(all, occurrence)
+			}
+		}
+		if occurrences := r.Occurrences(start, &from, &to); !reflect.DeepEqual(all, occurrences) {
+			t.Errorf("%s must have occurrences %v instead of %v", rule, all, occurrences)
+		}
+	}
+
+	r, _ := ParseRecurrence("FREQ=DAILY")
+	occurrences := r.Occurrences(start, &from, nil)
+	if len(occurrences) != MaxOccurrences || !occurrences[0].Equal(NewDateTime(2019, 11, 18, 8, 0)) {
+		t.Errorf("infinite rule must be expanded to %d occurrences from 18 Nov 2019 instead of %d from %v", MaxOccurrences, len(occurrences), occurrences[0])
+	}
+}
+
+func TestEventOccurrencesInPeriod(t *testing.T) {
+	r, _ := ParseRecurrence("FREQ=DAILY")
+	event := WithRecurrence(NewEventWithId(1, "Stand-up",
+		NewDateTime(2019, 11, 18, 23, 45),
+		NewDateTime(2019, 11, 19, 0, 15),
+	), r)
+
+	from := NewDateTime(2019, 11, 20, 0, 0)
+	to := NewDateTime(2019, 11, 21, 23, 59)
+
+	occurrences := event.OccurrencesInPeriod(&from, &to)
+	if len(occurrences) != 2 {
+		t.Fatalf("expected 2 occurrences instead of %d", len(occurrences))
+	}
+
+	occurrence := occurrences[1]
+	if occurrence.Id() != 1 || occurrence.Name() != "Stand-up" {
+		t.Errorf("occurrence must has id and name of event, got %d %s", occurrence.Id(), occurrence.Name())
+	}
+	if occurrence.Start() != NewDateTime(2019, 11, 21, 23, 45) || occurrence.End() != NewDateTime(2019, 11, 22, 0, 15) {
+		t.Errorf("occurrence must be shifted with the same duration, got %s", occurrence)
+	}
+
+	single := NewEvent("Retro", NewDateTime(2019, 11, 22, 10, 0), NewDateTime(2019, 11, 22, 11, 0))
+	if len(single.OccurrencesInPeriod(&from, &to)) != 0 {
+		t.Errorf("not recurring event out of period must not has occurrences")
+	}
+	if len(single.OccurrencesInPeriod(&from, nil)) != 1 {
+		t.Errorf("not recurring event in period must has one occurrence")
+	}
+}
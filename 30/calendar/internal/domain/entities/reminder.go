@@ -0,0 +1,83 @@
+package entities
+
+import (
+	"time"
+)
+
+// Reminder of event, notification about event is due beforeMinutes before its start
+// Every reminder has its own state of delivery, for recurring event it is the latest notified occurrence
+type Reminder struct {
+	beforeMinutes int       // notify before start of event minutes
+	isNotified    bool      // was notification enqueued
+	notifiedTime  time.Time // when notification enqueued
+	notifiedStart DateTime  // start of the latest occurrence which notification enqueued, zero if unknown
+}
+
+// Constructor
+func NewReminder(beforeMinutes int) Reminder {
+	return Reminder{
+		beforeMinutes: beforeMinutes,
+	}
+}
+
+// Before minutes getter
+func (reminder Reminder) BeforeMinutes() int {
+	return reminder.beforeMinutes
+}
+
+// Was notification enqueued
+func (reminder Reminder) IsNotified() bool {
+	return reminder.isNotified
+}
+
+// When notification enqueued
+func (reminder Reminder) NotifiedTime() time.Time {
+	return reminder.notifiedTime
+}
+
+// Start of the latest occurrence of event which notification enqueued, zero if unknown
+func (reminder Reminder) NotifiedStart() DateTime {
+	return reminder.notifiedStart
+}
+
+// Copy of reminder that marked as notified at time t
+func (reminder Reminder) Notified(t time.Time) Reminder {
+	reminder.isNotified = true
+	reminder.notifiedTime = t
+	return reminder
+}
+
+// Copy of reminder that marked as notified at time t for occurrence started at start
+// Notifications are enqueued in order of time, so the latest notified occurrence is kept only
+func (reminder Reminder) OccurrenceNotified(start DateTime, t time.Time) Reminder {
+	reminder = reminder.Notified(t)
+	if reminder.notifiedStart.Less(start) {
+		reminder.notifiedStart = start
+	}
+	return reminder
+}
+
+// Notification about event by one of its reminders
+// Notification is event itself, so it has all info about event
+type Notification struct {
+	Event
+	reminder Reminder
+}
+
+// Constructor
+func NewNotification(event Event, reminder Reminder) Notification {
+	return Notification{
+		Event:    event,
+		reminder: reminder,
+	}
+}
+
+// Reminder which notification is due
+func (notification Notification) Reminder() Reminder {
+	return notification.reminder
+}
+
+// Time when notification is due: start of event minus before minutes of reminder
+func (notification Notification) Time() DateTime {
+	return notification.start.MinusMinutes(notification.reminder.beforeMinutes)
+}
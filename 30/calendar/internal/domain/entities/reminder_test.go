@@ -0,0 +1,108 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWithReminders(t *testing.T) {
+	event := NewEvent("Meeting", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 11, 0))
+	if event.IsNotifyingEnabled() || event.Reminders() != nil {
+		t.Errorf("event without reminders must not notify")
+	}
+
+	event = WithReminders(event, []Reminder{NewReminder(10), NewReminder(60), NewReminder(10)})
+
+	var minutes []int
+	for _, reminder := range event.Reminders() {
+		minutes = append(minutes, reminder.BeforeMinutes())
+	}
+	if !reflect.DeepEqual(minutes, []int{60, 10}) {
+		t.Errorf("reminders must be [60 10] instead of %v", minutes)
+	}
+
+	if !event.IsNotifyingEnabled() || event.BeforeMinutes() != 60 {
+		t.Errorf("event must notify 60 minutes before, got %d", event.BeforeMinutes())
+	}
+
+	// compatible constructor makes one reminder
+	event = NewDetailedEvent("Meeting", event.Start(), event.End(), true, 15, false, time.Time{})
+	if reminders := event.Reminders(); len(reminders) != 1 || reminders[0].BeforeMinutes() != 15 {
+		t.Errorf("must be one reminder 15 minutes before instead of %+v", reminders)
+	}
+}
+
+func TestNotificationsInPeriod(t *testing.T) {
+	event := NewEvent("Meeting", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 11, 0))
+	event = WithReminders(event, []Reminder{NewReminder(10), NewReminder(60)})
+
+	start := NewDateTime(2019, 11, 25, 9, 0)
+	end := NewDateTime(2019, 11, 25, 9, 30)
+
+	notifications := event.NotificationsInPeriod(&start, &end)
+	if len(notifications) != 1 || notifications[0].Reminder().BeforeMinutes() != 60 {
+		t.Fatalf("must be one notification of reminder 60 instead of %v", notifications)
+	}
+	if !notifications[0].Time().Equal(start) {
+		t.Errorf("time of notification must be %s instead of %s", start, notifications[0].Time())
+	}
+
+	notifications = event.NotificationsInPeriod(nil, nil)
+	if len(notifications) != 2 {
+		t.Errorf("must be notification per reminder instead of %v", notifications)
+	}
+
+	dt := time.Date(2019, 11, 25, 9, 0, 0, 0, time.UTC)
+	event, ok := event.ReminderNotified(60, event.Start(), dt)
+	if !ok {
+		t.Fatalf("reminder 60 must be found")
+	}
+	if event.IsNotified() {
+		t.Errorf("event must not be notified until all reminders are notified")
+	}
+
+	notifications = event.NotificationsInPeriod(nil, nil)
+	if len(notifications) != 1 || notifications[0].Reminder().BeforeMinutes() != 10 {
+		t.Errorf("must be only notification of reminder 10 instead of %v", notifications)
+	}
+
+	if _, ok := event.ReminderNotified(5, event.Start(), dt); ok {
+		t.Errorf("reminder 5 must not be found")
+	}
+
+	event = event.Notified(dt)
+	if !event.IsNotified() || !event.NotifiedTime().Equal(dt) || len(event.NotificationsInPeriod(nil, nil)) != 0 {
+		t.Errorf("all reminders must be notified at %s", dt)
+	}
+}
+
+func TestRecurringNotificationsInPeriod(t *testing.T) {
+	recurrence, _ := ParseRecurrence("FREQ=DAILY")
+	event := NewEvent("Standup", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 10, 15))
+	event = WithRecurrence(WithReminders(event, []Reminder{NewReminder(15)}), recurrence)
+
+	start := NewDateTime(2019, 11, 26, 0, 0)
+	end := NewDateTime(2019, 11, 27, 23, 59)
+	notifications := event.NotificationsInPeriod(&start, &end)
+	if len(notifications) != 2 || !notifications[1].Start().Equal(NewDateTime(2019, 11, 27, 10, 0)) {
+		t.Fatalf("must be notification per occurrence in period instead of %v", notifications)
+	}
+	if !notifications[0].Time().Equal(NewDateTime(2019, 11, 26, 9, 45)) {
+		t.Errorf("time of notification must be %s instead of %s", NewDateTime(2019, 11, 26, 9, 45), notifications[0].Time())
+	}
+
+	dt := time.Date(2019, 11, 26, 9, 45, 0, 0, time.UTC)
+	event, _ = event.ReminderNotified(15, notifications[0].Start(), dt)
+	notifications = event.NotificationsInPeriod(&start, &end)
+	if len(notifications) != 1 || !notifications[0].Start().Equal(NewDateTime(2019, 11, 27, 10, 0)) {
+		t.Errorf("only occurrences after notified one must be notified instead of %v", notifications)
+	}
+
+	// missed occurrences are notified once by the latest one
+	end = NewDateTime(2019, 12, 1, 9, 50)
+	notifications = event.NotificationsInPeriod(nil, &end)
+	if len(notifications) != 1 || !notifications[0].Start().Equal(NewDateTime(2019, 12, 1, 10, 0)) {
+		t.Errorf("must be notification of the latest missed occurrence instead of %v", notifications)
+	}
+}
@@ -0,0 +1,72 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Error about storage that doesn't implement Searcher
+var ErrSearchNotSupported = errors.New("search of events is not supported by storage")
+
+// Error about search query without words
+var ErrEmptySearchQuery = errors.New("search query must have at least one word")
+
+// Optional feature of storage: full-text search of events by words in name
+// Storage views (see Storage.ForOwner) of searcher are searchers too
+type Searcher interface {
+
+	// Search active events (not in trash) which name contains all words of query, case is ignored
+	// Only events that have occurrences that started in period are found, nil start or end is no boundary
+	// Events are sorted by relevance (the larger share of words of name matches query, the higher), then the latest first
+	// At most limit events are returned, limit <= 0 means no limit
+	// Query without words is ErrEmptySearchQuery
+	SearchEvents(ctx context.Context, query string, startTime *DateTime, endTime *DateTime, limit int) ([]Event, error)
+}
+
+// Split text into lower case words (sequences of letters and digits), as search query and names of events are split
+func SearchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Sort found events by relevance to words of query, then the latest first, and keep at most limit events (limit <= 0 means no limit)
+// Relevance of event is share of words of name that are words of query
+func RankSearchResults(events []Event, words []string, limit int) []Event {
+	queryWords := make(map[string]bool)
+	for _, word := range words {
+		queryWords[word] = true
+	}
+
+	relevance := make(map[int]float64, len(events))
+	for _, event := range events {
+		nameWords := SearchWords(event.Name())
+		matched := 0
+		for _, word := range nameWords {
+			if queryWords[word] {
+				matched++
+			}
+		}
+		if len(nameWords) > 0 {
+			relevance[event.Id()] = float64(matched) / float64(len(nameWords))
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if relevance[events[i].Id()] != relevance[events[j].Id()] {
+			return relevance[events[i].Id()] > relevance[events[j].Id()]
+		}
+		if !events[i].Start().Equal(events[j].Start()) {
+			return events[j].Start().Less(events[i].Start())
+		}
+		return events[i].Id() > events[j].Id()
+	})
+
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events
+}
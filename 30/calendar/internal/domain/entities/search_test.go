@@ -0,0 +1,50 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchWords(t *testing.T) {
+	words := SearchWords("  Sprint-42 RETRO, (Team Ёлка)!")
+	expected := []string{"sprint", "42", "retro", "team", "ёлка"}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("words must be %v instead of %v", expected, words)
+	}
+
+	if len(SearchWords(" ,.- ")) != 0 {
+		t.Errorf("text without letters and digits must have no words")
+	}
+}
+
+func TestRankSearchResults(t *testing.T) {
+	event := func(id int, name string, day int) Event {
+		start := NewDateTime(2019, 11, day, 10, 0)
+		return WithId(NewEvent(name, start, start.PlusMinutes(60)), id)
+	}
+
+	events := []Event{
+		event(1, "Retro of sprint 41", 1),
+		event(2, "Retro", 2),
+		event(3, "Retro of sprint 42", 15),
+		event(4, "Sprint retro", 3),
+	}
+
+	ids := func(events []Event) []int {
+		var ids []int
+		for _, event := range events {
+			ids = append(ids, event.Id())
+		}
+		return ids
+	}
+
+	ranked := RankSearchResults(events, SearchWords("retro"), 0)
+	if !reflect.DeepEqual(ids(ranked), []int{2, 4, 3, 1}) {
+		t.Errorf("events must be ranked as [2 4 3 1] instead of %v", ids(ranked))
+	}
+
+	ranked = RankSearchResults(events, SearchWords("sprint retro"), 2)
+	if !reflect.DeepEqual(ids(ranked), []int{4, 2}) {
+		t.Errorf("events must be ranked as [4 2] instead of %v", ids(ranked))
+	}
+}
@@ -1,41 +1,125 @@
 package entities
 
 import (
+	"context"
 	"errors"
 	"time"
 )
 
+// Error about event that is not found in storage view, also for event in trash (except of trash methods) and for unknown event in trash
 var StorageErrorEventNotFound = errors.New("event not found in storage")
 
+// Error about reminder that is not found in event
+var StorageErrorReminderNotFound = errors.New("reminder not found in event")
+
+// Error about attendee that is not found in event
+var StorageErrorAttendeeNotFound = errors.New("attendee not found in event")
+
+// Error about calendar that is not found in storage view, also for event that belongs to such calendar
+var StorageErrorCalendarNotFound = errors.New("calendar not found in storage")
+
+// Error about update of event which stored version is not expected one, i.e. event was changed by someone else
+var StorageErrorVersionMismatch = errors.New("version of event mismatch, event was changed by someone else")
+
+// Storage of events
+// Deleted events are moved to trash, events in trash are not found by methods other than trash ones
+// Every method takes context of request (or of background job), on cancel or deadline of context method returns its error
 type Storage interface {
 
-	// Add event
-	AddEvent(event Event) (int, error)
+	// Add event, new event has version 1
+	// Calendar of event (if it is set) must be calendar of storage view, otherwise return StorageErrorCalendarNotFound
+	AddEvent(ctx context.Context, event Event) (int, error)
+
+	// Update event, version of stored event is incremented
+	// Responses and states of invitation of attendees that are still attendees of event are kept
+	// If version of event is not 0 it is expected version of stored event, on mismatch return StorageErrorVersionMismatch
+	// Calendar of event (if it is set) must be calendar of storage view, otherwise return StorageErrorCalendarNotFound
+	UpdateEvent(ctx context.Context, id int, event Event) error
+
+	// Delete event, event is moved to trash and could be restored until it is purged
+	DeleteEvent(ctx context.Context, id int) error
 
-	// Update event
-	UpdateEvent(id int, event Event) error
+	// Get events in trash, the most recently deleted first
+	GetTrashedEvents(ctx context.Context) ([]Event, error)
 
-	// Delete event
-	DeleteEvent(id int) error
+	// Restore event from trash
+	RestoreEvent(ctx context.Context, id int) error
+
+	// Delete permanently events moved to trash before time, return number of purged events
+	PurgeEvents(ctx context.Context, before time.Time) (int, error)
+
+	// Get audit entries of event, the oldest first, history is kept for events in trash and purged events
+	// Every create, update, delete, restore, mark as notified of event and response of attendee is recorded
+	GetEventHistory(ctx context.Context, id int) ([]AuditEntry, error)
 
 	// Get one event by id
-	GetEvent(id int) (Event, error)
+	GetEvent(ctx context.Context, id int) (Event, error)
 
 	// Get all events
-	GetAllEvents() ([]Event, error)
+	GetAllEvents(ctx context.Context) ([]Event, error)
 
 	// Get events by period. start and end is inclusive
-	GetEventsByPeriod(startTime *DateTime, endTime *DateTime) ([]Event, error)
+	// If calendarIds are passed only events of these calendars are got
+	GetEventsByPeriod(ctx context.Context, startTime *DateTime, endTime *DateTime, calendarIds ...int) ([]Event, error)
+
+	// Get page of all events ordered by start and id, at most limit events (limit <= 0 means no limit)
+	// cursor is empty for the first page or next cursor of previous page, unparsable cursor is ErrInvalidCursor
+	GetAllEventsPage(ctx context.Context, limit int, cursor string) (EventPage, error)
+
+	// Get page of events by period (the same events as GetEventsByPeriod) ordered by start and id, at most limit events
+	// cursor is empty for the first page or next cursor of previous page, unparsable cursor is ErrInvalidCursor
+	GetEventsByPeriodPage(ctx context.Context, startTime *DateTime, endTime *DateTime, limit int, cursor string, calendarIds ...int) (EventPage, error)
+
+	// Get occurrences of events that overlap interval [start, end), i.e. started before end and ended after start
+	GetOverlappingEvents(ctx context.Context, start DateTime, end DateTime) ([]Event, error)
 
-	// Get events to notify, start and end is inclusive
-	GetEventsToNotify(startTime *DateTime, endTime *DateTime) ([]Event, error)
+	// Get notifications of not notified reminders which time (start of occurrence minus before minutes) is in period
+	// One notification per due reminder of every occurrence of event, start and end is inclusive
+	GetEventsToNotify(ctx context.Context, startTime *DateTime, endTime *DateTime) ([]Notification, error)
 
-	// Mark event as notified, when is time when event is mark as notified
-	MarkEventAsNotified(id int, when time.Time) error
+	// Mark all reminders of event as notified, when is time when event is mark as notified
+	// Reminders of recurring event are marked only for its first occurrence
+	MarkEventAsNotified(ctx context.Context, id int, when time.Time) error
+
+	// Mark reminder (with beforeMinutes) of occurrence of event that started at start as notified,
+	// when is time when reminder is mark as notified
+	MarkReminderAsNotified(ctx context.Context, id int, beforeMinutes int, start DateTime, when time.Time) error
+
+	// Response of attendee (with email) to invitation to event, status is one of AttendeeStatus* constants
+	// Version of event is not changed, if event has no such attendee return StorageErrorAttendeeNotFound
+	// Unknown status is ErrInvalidAttendeeStatus
+	RespondToInvitation(ctx context.Context, id int, email string, status string) error
+
+	// Get invitations that must be enqueued: one invitation per not invited attendee
+	// and per invited attendee if start or end of event is changed since invitation
+	// Events that are over at now are skipped, recurring events are never over (see Event.IsOver)
+	GetInvitationsToSend(ctx context.Context, now time.Time) ([]Invitation, error)
+
+	// Mark attendee (with email) of event as invited to event with start and end, version of event is not changed
+	MarkAttendeeAsInvited(ctx context.Context, id int, email string, start DateTime, end DateTime) error
 
 	// Count of all events
-	Count() (int, error)
+	Count(ctx context.Context) (int, error)
+
+	// Delete all events and calendars permanently, including events in trash, history of events is kept
+	ClearAll(ctx context.Context) error
+
+	// Add calendar with owner of storage view (if it is not empty), return id of new calendar
+	AddCalendar(ctx context.Context, calendar Calendar) (int, error)
+
+	// Update (rename) calendar, owner of calendar is not changed
+	UpdateCalendar(ctx context.Context, id int, calendar Calendar) error
+
+	// Delete calendar with all its events (including events in trash) permanently
+	DeleteCalendar(ctx context.Context, id int) error
+
+	// Get one calendar by id
+	GetCalendar(ctx context.Context, id int) (Calendar, error)
+
+	// Get all calendars sorted by id
+	GetCalendars(ctx context.Context) ([]Calendar, error)
 
-	// Delete all events
-	ClearAll() error
+	// Storage view that deals only with events of owner, new events are added with this owner
+	// Events of other owners are not found for view, empty owner means events of all owners
+	ForOwner(owner string) Storage
 }
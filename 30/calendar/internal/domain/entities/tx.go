@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"context"
+	"errors"
+)
+
+// Error about storage that doesn't implement Transactor
+var ErrTxNotSupported = errors.New("transactions are not supported by storage")
+
+// Optional feature of storage: atomic unit of work
+// Storage views (see Storage.ForOwner) of transactor are transactors too
+type Transactor interface {
+
+	// Run fn with transactional view of storage (with the same owner)
+	// All changes made through tx are applied atomically if fn returns nil, otherwise they are discarded and error of fn is returned
+	// Changes made through tx are seen by tx, but not by other views until fn returns
+	// tx must be used only inside fn and not concurrently, fn must not use storage itself (only tx)
+	WithTx(ctx context.Context, fn func(tx Storage) error) error
+}
+
+// Max number of operations in one batch of APIs
+const MaxBatchOperations = 1000
@@ -0,0 +1,130 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Max length of name of event in characters
+const MaxNameLength = 256
+
+// Names of fields of event used in validation errors
+const (
+	FieldName          = "name"
+	FieldStart         = "start"
+	FieldEnd           = "end"
+	FieldBeforeMinutes = "beforeMinutes"
+)
+
+// Reasons of invalid fields, every ErrInvalidField wraps one of them
+var (
+	ErrEmptyName             = errors.New("name must not be empty")
+	ErrNameTooLong           = fmt.Errorf("name must not be longer than %d characters", MaxNameLength)
+	ErrInvalidRange          = errors.New("end must not be before start")
+	ErrNegativeBeforeMinutes = errors.New("before minutes must not be negative")
+	ErrStartInPast           = errors.New("start of new event must not be in the past")
+)
+
+// Typed error about invalid field of event
+type ErrInvalidField struct {
+	field string
+	err   error
+}
+
+// Error interface
+func (e *ErrInvalidField) Error() string {
+	return e.err.Error()
+}
+
+// Name of invalid field
+func (e *ErrInvalidField) Field() string {
+	return e.field
+}
+
+// Reason why field is invalid (one of Err* errors of validation)
+func (e *ErrInvalidField) Unwrap() error {
+	return e.err
+}
+
+// Typed error about invalid event, it has all invalid fields of event
+type ErrInvalidEvent struct {
+	fields []*ErrInvalidField
+}
+
+// Error interface
+func (e *ErrInvalidEvent) Error() string {
+	messages := make([]string, 0, len(e.fields))
+	for _, field := range e.fields {
+		messages = append(messages, field.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Invalid fields of event
+func (e *ErrInvalidEvent) Fields() []*ErrInvalidField {
+	return e.fields
+}
+
+// Support of errors.Is, invalid event is any reason of its invalid fields
+func (e *ErrInvalidEvent) Is(target error) bool {
+	for _, field := range e.fields {
+		if errors.Is(field, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate event, return *ErrInvalidEvent if event is invalid
+func ValidateEvent(event Event) error {
+	var fields []*ErrInvalidField
+
+	if event.name == "" {
+		fields = append(fields, &ErrInvalidField{FieldName, ErrEmptyName})
+	} else if utf8.RuneCountInString(event.name) > MaxNameLength {
+		fields = append(fields, &ErrInvalidField{FieldName, ErrNameTooLong})
+	}
+
+	if event.end.Less(event.start) {
+		fields = append(fields, &ErrInvalidField{FieldEnd, ErrInvalidRange})
+	}
+
+	for _, reminder := range event.reminders {
+		if reminder.beforeMinutes < 0 {
+			fields = append(fields, &ErrInvalidField{FieldBeforeMinutes, ErrNegativeBeforeMinutes})
+			break
+		}
+	}
+
+	if len(fields) > 0 {
+		return &ErrInvalidEvent{fields}
+	}
+	return nil
+}
+
+// Validate new event, it also must not start in the past (first day of all day event must not be before today)
+// Return *ErrInvalidEvent if event is invalid
+func ValidateNewEvent(event Event, now time.Time) error {
+	var fields []*ErrInvalidField
+
+	err := ValidateEvent(event)
+	if err != nil {
+		fields = err.(*ErrInvalidEvent).fields
+	}
+
+	inPast := event.start.Time().Before(now)
+	if event.allDay {
+		inPast = event.StartDate().Less(ConvertDateFromTime(now.In(event.Location())))
+	}
+	if inPast {
+		fields = append(fields, &ErrInvalidField{FieldStart, ErrStartInPast})
+	}
+
+	if len(fields) > 0 {
+		return &ErrInvalidEvent{fields}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package entities
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateEvent(t *testing.T) {
+	valid := NewEvent("Meeting", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 11, 0))
+	if err := ValidateEvent(valid); err != nil {
+		t.Errorf("event must be valid, got error %s", err)
+	}
+
+	// length is counted in characters, not in bytes
+	if err := ValidateEvent(WithId(NewEvent(strings.Repeat("ж", MaxNameLength), valid.Start(), valid.End()), 1)); err != nil {
+		t.Errorf("name of %d characters must be valid, got error %s", MaxNameLength, err)
+	}
+
+	cases := []struct {
+		event    Event
+		field    string
+		expected error
+	}{
+		{NewEvent("", valid.Start(), valid.End()), FieldName, ErrEmptyName},
+		{NewEvent(strings.Repeat("x", MaxNameLength+1), valid.Start(), valid.End()), FieldName, ErrNameTooLong},
+		{NewEvent("Meeting", valid.End(), valid.Start()), FieldEnd, ErrInvalidRange},
+		{NewDetailedEvent("Meeting", valid.Start(), valid.End(), true, -1, false, time.Time{}), FieldBeforeMinutes, ErrNegativeBeforeMinutes},
+	}
+
+	for _, c := range cases {
+		err := ValidateEvent(c.event)
+		if !errors.Is(err, c.expected) {
+			t.Errorf("expected error `%s` instead of `%v`", c.expected, err)
+			continue
+		}
+
+		invalidErr, ok := err.(*ErrInvalidEvent)
+		if !ok || len(invalidErr.Fields()) != 1 || invalidErr.Fields()[0].Field() != c.field {
+			t.Errorf("expected one invalid field `%s`, got %#v", c.field, err)
+		}
+	}
+
+	err := ValidateEvent(NewEvent("", valid.End(), valid.Start()))
+	if !errors.Is(err, ErrEmptyName) || !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("all invalid fields must be reported, got `%v`", err)
+	}
+}
+
+func TestValidateNewEvent(t *testing.T) {
+	now := time.Date(2019, 11, 25, 10, 30, 0, 0, time.UTC)
+
+	event := NewEvent("Meeting", NewDateTime(2019, 11, 25, 10, 0), NewDateTime(2019, 11, 25, 11, 0))
+	if err := ValidateNewEvent(event, now); !errors.Is(err, ErrStartInPast) {
+		t.Errorf("expected error `%s` instead of `%v`", ErrStartInPast, err)
+	}
+	if err := ValidateEvent(event); err != nil {
+		t.Errorf("existing event could be in the past, got error %s", err)
+	}
+
+	event = NewEvent("Meeting", NewDateTime(2019, 11, 25, 10, 30), NewDateTime(2019, 11, 25, 11, 0))
+	if err := ValidateNewEvent(event, now); err != nil {
+		t.Errorf("event that starts now must be valid, got error %s", err)
+	}
+
+	// today in Moscow is 26 Nov already
+	moscow, _ := LoadLocation("Europe/Moscow")
+	now = time.Date(2019, 11, 25, 22, 0, 0, 0, time.UTC)
+
+	allDay := WithLocation(NewAllDayEvent("Holiday", NewDate(2019, 11, 26), NewDate(2019, 11, 26)), moscow)
+	if err := ValidateNewEvent(allDay, now); err != nil {
+		t.Errorf("all day event of today must be valid, got error %s", err)
+	}
+
+	allDay = WithLocation(NewAllDayEvent("Holiday", NewDate(2019, 11, 25), NewDate(2019, 11, 26)), moscow)
+	if err := ValidateNewEvent(allDay, now); !errors.Is(err, ErrStartInPast) {
+		t.Errorf("expected error `%s` instead of `%v`", ErrStartInPast, err)
+	}
+}
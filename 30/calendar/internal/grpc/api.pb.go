@@ -25,14 +25,32 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+// For all day event only date part of start and end matters, end is last day of event (inclusive)
+// timezone is IANA time zone of event (e.g. Europe/Moscow), empty means UTC
+// location is place of event, organizer and attendees are emails
 type Event struct {
-	Id                   int32                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name                 string               `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Start                *timestamp.Timestamp `protobuf:"bytes,3,opt,name=start,proto3" json:"start,omitempty"`
-	End                  *timestamp.Timestamp `protobuf:"bytes,4,opt,name=end,proto3" json:"end,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+	Id                   int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Start                *timestamp.Timestamp   `protobuf:"bytes,3,opt,name=start,proto3" json:"start,omitempty"`
+	End                  *timestamp.Timestamp   `protobuf:"bytes,4,opt,name=end,proto3" json:"end,omitempty"`
+	Rrule                string                 `protobuf:"bytes,5,opt,name=rrule,proto3" json:"rrule,omitempty"`
+	Exdates              []*timestamp.Timestamp `protobuf:"bytes,6,rep,name=exdates,proto3" json:"exdates,omitempty"`
+	AllDay               bool                   `protobuf:"varint,7,opt,name=all_day,json=allDay,proto3" json:"all_day,omitempty"`
+	Timezone             string                 `protobuf:"bytes,8,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Description          string                 `protobuf:"bytes,9,opt,name=description,proto3" json:"description,omitempty"`
+	Location             string                 `protobuf:"bytes,10,opt,name=location,proto3" json:"location,omitempty"`
+	Organizer            string                 `protobuf:"bytes,11,opt,name=organizer,proto3" json:"organizer,omitempty"`
+	Attendees            []string               `protobuf:"bytes,12,rep,name=attendees,proto3" json:"attendees,omitempty"`
+	Color                string                 `protobuf:"bytes,13,opt,name=color,proto3" json:"color,omitempty"`
+	Owner                string                 `protobuf:"bytes,14,opt,name=owner,proto3" json:"owner,omitempty"`
+	Reminders            []int32                `protobuf:"varint,15,rep,packed,name=reminders,proto3" json:"reminders,omitempty"`
+	DeletedTime          *timestamp.Timestamp   `protobuf:"bytes,16,opt,name=deleted_time,json=deletedTime,proto3" json:"deleted_time,omitempty"`
+	Version              int32                  `protobuf:"varint,17,opt,name=version,proto3" json:"version,omitempty"`
+	CalendarId           int32                  `protobuf:"varint,18,opt,name=calendar_id,json=calendarId,proto3" json:"calendar_id,omitempty"`
+	AttendeeStatuses     []*AttendeeStatus      `protobuf:"bytes,19,rep,name=attendee_statuses,json=attendeeStatuses,proto3" json:"attendee_statuses,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
 }
 
 func (m *Event) Reset()         { *m = Event{} }
@@ -88,6 +106,159 @@ func (m *Event) GetEnd() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *Event) GetRrule() string {
+	if m != nil {
+		return m.Rrule
+	}
+	return ""
+}
+
+func (m *Event) GetExdates() []*timestamp.Timestamp {
+	if m != nil {
+		return m.Exdates
+	}
+	return nil
+}
+
+func (m *Event) GetAllDay() bool {
+	if m != nil {
+		return m.AllDay
+	}
+	return false
+}
+
+func (m *Event) GetTimezone() string {
+	if m != nil {
+		return m.Timezone
+	}
+	return ""
+}
+
+func (m *Event) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *Event) GetLocation() string {
+	if m != nil {
+		return m.Location
+	}
+	return ""
+}
+
+func (m *Event) GetOrganizer() string {
+	if m != nil {
+		return m.Organizer
+	}
+	return ""
+}
+
+func (m *Event) GetAttendees() []string {
+	if m != nil {
+		return m.Attendees
+	}
+	return nil
+}
+
+func (m *Event) GetColor() string {
+	if m != nil {
+		return m.Color
+	}
+	return ""
+}
+
+func (m *Event) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Event) GetReminders() []int32 {
+	if m != nil {
+		return m.Reminders
+	}
+	return nil
+}
+
+func (m *Event) GetDeletedTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.DeletedTime
+	}
+	return nil
+}
+
+func (m *Event) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Event) GetCalendarId() int32 {
+	if m != nil {
+		return m.CalendarId
+	}
+	return 0
+}
+
+func (m *Event) GetAttendeeStatuses() []*AttendeeStatus {
+	if m != nil {
+		return m.AttendeeStatuses
+	}
+	return nil
+}
+
+// Status of response of attendee to invitation: needs-action, accepted, declined or tentative
+type AttendeeStatus struct {
+	Email                string   `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Status               string   `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AttendeeStatus) Reset()         { *m = AttendeeStatus{} }
+func (m *AttendeeStatus) String() string { return proto.CompactTextString(m) }
+func (*AttendeeStatus) ProtoMessage()    {}
+func (*AttendeeStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{1}
+}
+
+func (m *AttendeeStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AttendeeStatus.Unmarshal(m, b)
+}
+func (m *AttendeeStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AttendeeStatus.Marshal(b, m, deterministic)
+}
+func (m *AttendeeStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AttendeeStatus.Merge(m, src)
+}
+func (m *AttendeeStatus) XXX_Size() int {
+	return xxx_messageInfo_AttendeeStatus.Size(m)
+}
+func (m *AttendeeStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_AttendeeStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AttendeeStatus proto.InternalMessageInfo
+
+func (m *AttendeeStatus) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *AttendeeStatus) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
 type SimpleResponse struct {
 	Result               string   `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -99,7 +270,7 @@ func (m *SimpleResponse) Reset()         { *m = SimpleResponse{} }
 func (m *SimpleResponse) String() string { return proto.CompactTextString(m) }
 func (*SimpleResponse) ProtoMessage()    {}
 func (*SimpleResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_00212fb1f9d3bf1c, []int{1}
+	return fileDescriptor_00212fb1f9d3bf1c, []int{2}
 }
 
 func (m *SimpleResponse) XXX_Unmarshal(b []byte) error {
@@ -127,8 +298,10 @@ func (m *SimpleResponse) GetResult() string {
 	return ""
 }
 
+// next_cursor is cursor of next page of events (empty for the last page), it is passed as cursor of next request
 type EventListResponse struct {
 	Events               []*Event `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	NextCursor           string   `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -138,7 +311,7 @@ func (m *EventListResponse) Reset()         { *m = EventListResponse{} }
 func (m *EventListResponse) String() string { return proto.CompactTextString(m) }
 func (*EventListResponse) ProtoMessage()    {}
 func (*EventListResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_00212fb1f9d3bf1c, []int{2}
+	return fileDescriptor_00212fb1f9d3bf1c, []int{3}
 }
 
 func (m *EventListResponse) XXX_Unmarshal(b []byte) error {
@@ -166,20 +339,39 @@ func (m *EventListResponse) GetEvents() []*Event {
 	return nil
 }
 
+func (m *EventListResponse) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
+}
+
 type CreateEventRequest struct {
-	Name                 string               `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Start                *timestamp.Timestamp `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
-	End                  *timestamp.Timestamp `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+	Name                 string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Start                *timestamp.Timestamp   `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End                  *timestamp.Timestamp   `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	Rrule                string                 `protobuf:"bytes,4,opt,name=rrule,proto3" json:"rrule,omitempty"`
+	Exdates              []*timestamp.Timestamp `protobuf:"bytes,5,rep,name=exdates,proto3" json:"exdates,omitempty"`
+	AllDay               bool                   `protobuf:"varint,6,opt,name=all_day,json=allDay,proto3" json:"all_day,omitempty"`
+	Timezone             string                 `protobuf:"bytes,7,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Description          string                 `protobuf:"bytes,8,opt,name=description,proto3" json:"description,omitempty"`
+	Location             string                 `protobuf:"bytes,9,opt,name=location,proto3" json:"location,omitempty"`
+	Organizer            string                 `protobuf:"bytes,10,opt,name=organizer,proto3" json:"organizer,omitempty"`
+	Attendees            []string               `protobuf:"bytes,11,rep,name=attendees,proto3" json:"attendees,omitempty"`
+	Color                string                 `protobuf:"bytes,12,opt,name=color,proto3" json:"color,omitempty"`
+	RejectConflicts      bool                   `protobuf:"varint,13,opt,name=reject_conflicts,json=rejectConflicts,proto3" json:"reject_conflicts,omitempty"`
+	Reminders            []int32                `protobuf:"varint,14,rep,packed,name=reminders,proto3" json:"reminders,omitempty"`
+	CalendarId           int32                  `protobuf:"varint,15,opt,name=calendar_id,json=calendarId,proto3" json:"calendar_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
 }
 
 func (m *CreateEventRequest) Reset()         { *m = CreateEventRequest{} }
 func (m *CreateEventRequest) String() string { return proto.CompactTextString(m) }
 func (*CreateEventRequest) ProtoMessage()    {}
 func (*CreateEventRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_00212fb1f9d3bf1c, []int{3}
+	return fileDescriptor_00212fb1f9d3bf1c, []int{4}
 }
 
 func (m *CreateEventRequest) XXX_Unmarshal(b []byte) error {
@@ -221,21 +413,118 @@ func (m *CreateEventRequest) GetEnd() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *CreateEventRequest) GetRrule() string {
+	if m != nil {
+		return m.Rrule
+	}
+	return ""
+}
+
+func (m *CreateEventRequest) GetExdates() []*timestamp.Timestamp {
+	if m != nil {
+		return m.Exdates
+	}
+	return nil
+}
+
+func (m *CreateEventRequest) GetAllDay() bool {
+	if m != nil {
+		return m.AllDay
+	}
+	return false
+}
+
+func (m *CreateEventRequest) GetTimezone() string {
+	if m != nil {
+		return m.Timezone
+	}
+	return ""
+}
+
+func (m *CreateEventRequest) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *CreateEventRequest) GetLocation() string {
+	if m != nil {
+		return m.Location
+	}
+	return ""
+}
+
+func (m *CreateEventRequest) GetOrganizer() string {
+	if m != nil {
+		return m.Organizer
+	}
+	return ""
+}
+
+func (m *CreateEventRequest) GetAttendees() []string {
+	if m != nil {
+		return m.Attendees
+	}
+	return nil
+}
+
+func (m *CreateEventRequest) GetColor() string {
+	if m != nil {
+		return m.Color
+	}
+	return ""
+}
+
+func (m *CreateEventRequest) GetRejectConflicts() bool {
+	if m != nil {
+		return m.RejectConflicts
+	}
+	return false
+}
+
+func (m *CreateEventRequest) GetReminders() []int32 {
+	if m != nil {
+		return m.Reminders
+	}
+	return nil
+}
+
+func (m *CreateEventRequest) GetCalendarId() int32 {
+	if m != nil {
+		return m.CalendarId
+	}
+	return 0
+}
+
 type UpdateEventRequest struct {
-	Id                   int32                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name                 string               `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Start                *timestamp.Timestamp `protobuf:"bytes,3,opt,name=start,proto3" json:"start,omitempty"`
-	End                  *timestamp.Timestamp `protobuf:"bytes,4,opt,name=end,proto3" json:"end,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+	Id                   int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Start                *timestamp.Timestamp   `protobuf:"bytes,3,opt,name=start,proto3" json:"start,omitempty"`
+	End                  *timestamp.Timestamp   `protobuf:"bytes,4,opt,name=end,proto3" json:"end,omitempty"`
+	Rrule                string                 `protobuf:"bytes,5,opt,name=rrule,proto3" json:"rrule,omitempty"`
+	Exdates              []*timestamp.Timestamp `protobuf:"bytes,6,rep,name=exdates,proto3" json:"exdates,omitempty"`
+	AllDay               bool                   `protobuf:"varint,7,opt,name=all_day,json=allDay,proto3" json:"all_day,omitempty"`
+	Timezone             string                 `protobuf:"bytes,8,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Description          string                 `protobuf:"bytes,9,opt,name=description,proto3" json:"description,omitempty"`
+	Location             string                 `protobuf:"bytes,10,opt,name=location,proto3" json:"location,omitempty"`
+	Organizer            string                 `protobuf:"bytes,11,opt,name=organizer,proto3" json:"organizer,omitempty"`
+	Attendees            []string               `protobuf:"bytes,12,rep,name=attendees,proto3" json:"attendees,omitempty"`
+	Color                string                 `protobuf:"bytes,13,opt,name=color,proto3" json:"color,omitempty"`
+	RejectConflicts      bool                   `protobuf:"varint,14,opt,name=reject_conflicts,json=rejectConflicts,proto3" json:"reject_conflicts,omitempty"`
+	Reminders            []int32                `protobuf:"varint,15,rep,packed,name=reminders,proto3" json:"reminders,omitempty"`
+	Version              int32                  `protobuf:"varint,16,opt,name=version,proto3" json:"version,omitempty"`
+	CalendarId           int32                  `protobuf:"varint,17,opt,name=calendar_id,json=calendarId,proto3" json:"calendar_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
 }
 
 func (m *UpdateEventRequest) Reset()         { *m = UpdateEventRequest{} }
 func (m *UpdateEventRequest) String() string { return proto.CompactTextString(m) }
 func (*UpdateEventRequest) ProtoMessage()    {}
 func (*UpdateEventRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_00212fb1f9d3bf1c, []int{4}
+	return fileDescriptor_00212fb1f9d3bf1c, []int{5}
 }
 
 func (m *UpdateEventRequest) XXX_Unmarshal(b []byte) error {
@@ -284,6 +573,97 @@ func (m *UpdateEventRequest) GetEnd() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *UpdateEventRequest) GetRrule() string {
+	if m != nil {
+		return m.Rrule
+	}
+	return ""
+}
+
+func (m *UpdateEventRequest) GetExdates() []*timestamp.Timestamp {
+	if m != nil {
+		return m.Exdates
+	}
+	return nil
+}
+
+func (m *UpdateEventRequest) GetAllDay() bool {
+	if m != nil {
+		return m.AllDay
+	}
+	return false
+}
+
+func (m *UpdateEventRequest) GetTimezone() string {
+	if m != nil {
+		return m.Timezone
+	}
+	return ""
+}
+
+func (m *UpdateEventRequest) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *UpdateEventRequest) GetLocation() string {
+	if m != nil {
+		return m.Location
+	}
+	return ""
+}
+
+func (m *UpdateEventRequest) GetOrganizer() string {
+	if m != nil {
+		return m.Organizer
+	}
+	return ""
+}
+
+func (m *UpdateEventRequest) GetAttendees() []string {
+	if m != nil {
+		return m.Attendees
+	}
+	return nil
+}
+
+func (m *UpdateEventRequest) GetColor() string {
+	if m != nil {
+		return m.Color
+	}
+	return ""
+}
+
+func (m *UpdateEventRequest) GetRejectConflicts() bool {
+	if m != nil {
+		return m.RejectConflicts
+	}
+	return false
+}
+
+func (m *UpdateEventRequest) GetReminders() []int32 {
+	if m != nil {
+		return m.Reminders
+	}
+	return nil
+}
+
+func (m *UpdateEventRequest) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *UpdateEventRequest) GetCalendarId() int32 {
+	if m != nil {
+		return m.CalendarId
+	}
+	return 0
+}
+
 type DeleteEventRequest struct {
 	Id                   int32    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -295,7 +675,7 @@ func (m *DeleteEventRequest) Reset()         { *m = DeleteEventRequest{} }
 func (m *DeleteEventRequest) String() string { return proto.CompactTextString(m) }
 func (*DeleteEventRequest) ProtoMessage()    {}
 func (*DeleteEventRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_00212fb1f9d3bf1c, []int{5}
+	return fileDescriptor_00212fb1f9d3bf1c, []int{6}
 }
 
 func (m *DeleteEventRequest) XXX_Unmarshal(b []byte) error {
@@ -323,76 +703,1161 @@ func (m *DeleteEventRequest) GetId() int32 {
 	return 0
 }
 
-type Nothing struct {
+type RestoreEventRequest struct {
+	Id                   int32    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Nothing) Reset()         { *m = Nothing{} }
-func (m *Nothing) String() string { return proto.CompactTextString(m) }
-func (*Nothing) ProtoMessage()    {}
-func (*Nothing) Descriptor() ([]byte, []int) {
-	return fileDescriptor_00212fb1f9d3bf1c, []int{6}
+func (m *RestoreEventRequest) Reset()         { *m = RestoreEventRequest{} }
+func (m *RestoreEventRequest) String() string { return proto.CompactTextString(m) }
+func (*RestoreEventRequest) ProtoMessage()    {}
+func (*RestoreEventRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{7}
 }
 
-func (m *Nothing) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Nothing.Unmarshal(m, b)
+func (m *RestoreEventRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestoreEventRequest.Unmarshal(m, b)
 }
-func (m *Nothing) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Nothing.Marshal(b, m, deterministic)
+func (m *RestoreEventRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestoreEventRequest.Marshal(b, m, deterministic)
 }
-func (m *Nothing) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Nothing.Merge(m, src)
+func (m *RestoreEventRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestoreEventRequest.Merge(m, src)
 }
-func (m *Nothing) XXX_Size() int {
-	return xxx_messageInfo_Nothing.Size(m)
+func (m *RestoreEventRequest) XXX_Size() int {
+	return xxx_messageInfo_RestoreEventRequest.Size(m)
 }
-func (m *Nothing) XXX_DiscardUnknown() {
-	xxx_messageInfo_Nothing.DiscardUnknown(m)
+func (m *RestoreEventRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestoreEventRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Nothing proto.InternalMessageInfo
+var xxx_messageInfo_RestoreEventRequest proto.InternalMessageInfo
 
-func init() {
-	proto.RegisterType((*Event)(nil), "grpc.Event")
-	proto.RegisterType((*SimpleResponse)(nil), "grpc.SimpleResponse")
-	proto.RegisterType((*EventListResponse)(nil), "grpc.EventListResponse")
-	proto.RegisterType((*CreateEventRequest)(nil), "grpc.CreateEventRequest")
-	proto.RegisterType((*UpdateEventRequest)(nil), "grpc.UpdateEventRequest")
-	proto.RegisterType((*DeleteEventRequest)(nil), "grpc.DeleteEventRequest")
-	proto.RegisterType((*Nothing)(nil), "grpc.Nothing")
-}
+func (m *RestoreEventRequest) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type TrashRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TrashRequest) Reset()         { *m = TrashRequest{} }
+func (m *TrashRequest) String() string { return proto.CompactTextString(m) }
+func (*TrashRequest) ProtoMessage()    {}
+func (*TrashRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{8}
+}
+
+func (m *TrashRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TrashRequest.Unmarshal(m, b)
+}
+func (m *TrashRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TrashRequest.Marshal(b, m, deterministic)
+}
+func (m *TrashRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TrashRequest.Merge(m, src)
+}
+func (m *TrashRequest) XXX_Size() int {
+	return xxx_messageInfo_TrashRequest.Size(m)
+}
+func (m *TrashRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TrashRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TrashRequest proto.InternalMessageInfo
+
+type EventHistoryRequest struct {
+	Id                   int32    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EventHistoryRequest) Reset()         { *m = EventHistoryRequest{} }
+func (m *EventHistoryRequest) String() string { return proto.CompactTextString(m) }
+func (*EventHistoryRequest) ProtoMessage()    {}
+func (*EventHistoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{9}
+}
+
+func (m *EventHistoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventHistoryRequest.Unmarshal(m, b)
+}
+func (m *EventHistoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventHistoryRequest.Marshal(b, m, deterministic)
+}
+func (m *EventHistoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventHistoryRequest.Merge(m, src)
+}
+func (m *EventHistoryRequest) XXX_Size() int {
+	return xxx_messageInfo_EventHistoryRequest.Size(m)
+}
+func (m *EventHistoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventHistoryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventHistoryRequest proto.InternalMessageInfo
+
+func (m *EventHistoryRequest) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+// Change of one field of event, values are human readable strings, empty value means absent value
+type FieldChange struct {
+	Field                string   `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Before               string   `protobuf:"bytes,2,opt,name=before,proto3" json:"before,omitempty"`
+	After                string   `protobuf:"bytes,3,opt,name=after,proto3" json:"after,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FieldChange) Reset()         { *m = FieldChange{} }
+func (m *FieldChange) String() string { return proto.CompactTextString(m) }
+func (*FieldChange) ProtoMessage()    {}
+func (*FieldChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{10}
+}
+
+func (m *FieldChange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FieldChange.Unmarshal(m, b)
+}
+func (m *FieldChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FieldChange.Marshal(b, m, deterministic)
+}
+func (m *FieldChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldChange.Merge(m, src)
+}
+func (m *FieldChange) XXX_Size() int {
+	return xxx_messageInfo_FieldChange.Size(m)
+}
+func (m *FieldChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldChange proto.InternalMessageInfo
+
+func (m *FieldChange) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *FieldChange) GetBefore() string {
+	if m != nil {
+		return m.Before
+	}
+	return ""
+}
+
+func (m *FieldChange) GetAfter() string {
+	if m != nil {
+		return m.After
+	}
+	return ""
+}
+
+// Audit entry about change of event
+type AuditEntry struct {
+	Action               string               `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	Actor                string               `protobuf:"bytes,2,opt,name=actor,proto3" json:"actor,omitempty"`
+	Time                 *timestamp.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	Changes              []*FieldChange       `protobuf:"bytes,4,rep,name=changes,proto3" json:"changes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *AuditEntry) Reset()         { *m = AuditEntry{} }
+func (m *AuditEntry) String() string { return proto.CompactTextString(m) }
+func (*AuditEntry) ProtoMessage()    {}
+func (*AuditEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{11}
+}
+
+func (m *AuditEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditEntry.Unmarshal(m, b)
+}
+func (m *AuditEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditEntry.Marshal(b, m, deterministic)
+}
+func (m *AuditEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditEntry.Merge(m, src)
+}
+func (m *AuditEntry) XXX_Size() int {
+	return xxx_messageInfo_AuditEntry.Size(m)
+}
+func (m *AuditEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditEntry proto.InternalMessageInfo
+
+func (m *AuditEntry) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *AuditEntry) GetChanges() []*FieldChange {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
+type EventHistoryResponse struct {
+	Entries              []*AuditEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *EventHistoryResponse) Reset()         { *m = EventHistoryResponse{} }
+func (m *EventHistoryResponse) String() string { return proto.CompactTextString(m) }
+func (*EventHistoryResponse) ProtoMessage()    {}
+func (*EventHistoryResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{12}
+}
+
+func (m *EventHistoryResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventHistoryResponse.Unmarshal(m, b)
+}
+func (m *EventHistoryResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventHistoryResponse.Marshal(b, m, deterministic)
+}
+func (m *EventHistoryResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventHistoryResponse.Merge(m, src)
+}
+func (m *EventHistoryResponse) XXX_Size() int {
+	return xxx_messageInfo_EventHistoryResponse.Size(m)
+}
+func (m *EventHistoryResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventHistoryResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventHistoryResponse proto.InternalMessageInfo
+
+func (m *EventHistoryResponse) GetEntries() []*AuditEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+// Attendee is authenticated user, without authentication it is email
+type RespondToInvitationRequest struct {
+	Id                   int32    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Email                string   `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Status               string   `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespondToInvitationRequest) Reset()         { *m = RespondToInvitationRequest{} }
+func (m *RespondToInvitationRequest) String() string { return proto.CompactTextString(m) }
+func (*RespondToInvitationRequest) ProtoMessage()    {}
+func (*RespondToInvitationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{13}
+}
+
+func (m *RespondToInvitationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespondToInvitationRequest.Unmarshal(m, b)
+}
+func (m *RespondToInvitationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespondToInvitationRequest.Marshal(b, m, deterministic)
+}
+func (m *RespondToInvitationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespondToInvitationRequest.Merge(m, src)
+}
+func (m *RespondToInvitationRequest) XXX_Size() int {
+	return xxx_messageInfo_RespondToInvitationRequest.Size(m)
+}
+func (m *RespondToInvitationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespondToInvitationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespondToInvitationRequest proto.InternalMessageInfo
+
+func (m *RespondToInvitationRequest) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *RespondToInvitationRequest) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *RespondToInvitationRequest) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+// Periods are local days in tz (IANA time zone), empty tz means default time zone of service
+// If calendar_ids are passed only events of these calendars are returned
+// Events are returned by pages ordered by start and id, limit is size of page (0 means 100, at most 1000),
+// cursor is empty for the first page or next_cursor of previous page
+type PeriodRequest struct {
+	Tz                   string   `protobuf:"bytes,1,opt,name=tz,proto3" json:"tz,omitempty"`
+	CalendarIds          []int32  `protobuf:"varint,2,rep,packed,name=calendar_ids,json=calendarIds,proto3" json:"calendar_ids,omitempty"`
+	Limit                int32    `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor               string   `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PeriodRequest) Reset()         { *m = PeriodRequest{} }
+func (m *PeriodRequest) String() string { return proto.CompactTextString(m) }
+func (*PeriodRequest) ProtoMessage()    {}
+func (*PeriodRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{14}
+}
+
+func (m *PeriodRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PeriodRequest.Unmarshal(m, b)
+}
+func (m *PeriodRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PeriodRequest.Marshal(b, m, deterministic)
+}
+func (m *PeriodRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeriodRequest.Merge(m, src)
+}
+func (m *PeriodRequest) XXX_Size() int {
+	return xxx_messageInfo_PeriodRequest.Size(m)
+}
+func (m *PeriodRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeriodRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeriodRequest proto.InternalMessageInfo
+
+func (m *PeriodRequest) GetTz() string {
+	if m != nil {
+		return m.Tz
+	}
+	return ""
+}
+
+func (m *PeriodRequest) GetCalendarIds() []int32 {
+	if m != nil {
+		return m.CalendarIds
+	}
+	return nil
+}
+
+func (m *PeriodRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *PeriodRequest) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+// Named calendar of events (e.g. work, personal, team-X)
+// Calendar name is already taken by calendar service itself, so it is info about calendar
+type CalendarInfo struct {
+	Id                   int32    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Owner                string   `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CalendarInfo) Reset()         { *m = CalendarInfo{} }
+func (m *CalendarInfo) String() string { return proto.CompactTextString(m) }
+func (*CalendarInfo) ProtoMessage()    {}
+func (*CalendarInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{15}
+}
+
+func (m *CalendarInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CalendarInfo.Unmarshal(m, b)
+}
+func (m *CalendarInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CalendarInfo.Marshal(b, m, deterministic)
+}
+func (m *CalendarInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CalendarInfo.Merge(m, src)
+}
+func (m *CalendarInfo) XXX_Size() int {
+	return xxx_messageInfo_CalendarInfo.Size(m)
+}
+func (m *CalendarInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_CalendarInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CalendarInfo proto.InternalMessageInfo
+
+func (m *CalendarInfo) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *CalendarInfo) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CalendarInfo) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+type CreateCalendarRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateCalendarRequest) Reset()         { *m = CreateCalendarRequest{} }
+func (m *CreateCalendarRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateCalendarRequest) ProtoMessage()    {}
+func (*CreateCalendarRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{16}
+}
+
+func (m *CreateCalendarRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateCalendarRequest.Unmarshal(m, b)
+}
+func (m *CreateCalendarRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateCalendarRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateCalendarRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateCalendarRequest.Merge(m, src)
+}
+func (m *CreateCalendarRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateCalendarRequest.Size(m)
+}
+func (m *CreateCalendarRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateCalendarRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateCalendarRequest proto.InternalMessageInfo
+
+func (m *CreateCalendarRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type UpdateCalendarRequest struct {
+	Id                   int32    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateCalendarRequest) Reset()         { *m = UpdateCalendarRequest{} }
+func (m *UpdateCalendarRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateCalendarRequest) ProtoMessage()    {}
+func (*UpdateCalendarRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{17}
+}
+
+func (m *UpdateCalendarRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateCalendarRequest.Unmarshal(m, b)
+}
+func (m *UpdateCalendarRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateCalendarRequest.Marshal(b, m, deterministic)
+}
+func (m *UpdateCalendarRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateCalendarRequest.Merge(m, src)
+}
+func (m *UpdateCalendarRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateCalendarRequest.Size(m)
+}
+func (m *UpdateCalendarRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateCalendarRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateCalendarRequest proto.InternalMessageInfo
+
+func (m *UpdateCalendarRequest) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *UpdateCalendarRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+// Calendar is deleted with all its events
+type DeleteCalendarRequest struct {
+	Id                   int32    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteCalendarRequest) Reset()         { *m = DeleteCalendarRequest{} }
+func (m *DeleteCalendarRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteCalendarRequest) ProtoMessage()    {}
+func (*DeleteCalendarRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{18}
+}
+
+func (m *DeleteCalendarRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteCalendarRequest.Unmarshal(m, b)
+}
+func (m *DeleteCalendarRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteCalendarRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteCalendarRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteCalendarRequest.Merge(m, src)
+}
+func (m *DeleteCalendarRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteCalendarRequest.Size(m)
+}
+func (m *DeleteCalendarRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteCalendarRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteCalendarRequest proto.InternalMessageInfo
+
+func (m *DeleteCalendarRequest) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type CalendarsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CalendarsRequest) Reset()         { *m = CalendarsRequest{} }
+func (m *CalendarsRequest) String() string { return proto.CompactTextString(m) }
+func (*CalendarsRequest) ProtoMessage()    {}
+func (*CalendarsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{19}
+}
+
+func (m *CalendarsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CalendarsRequest.Unmarshal(m, b)
+}
+func (m *CalendarsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CalendarsRequest.Marshal(b, m, deterministic)
+}
+func (m *CalendarsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CalendarsRequest.Merge(m, src)
+}
+func (m *CalendarsRequest) XXX_Size() int {
+	return xxx_messageInfo_CalendarsRequest.Size(m)
+}
+func (m *CalendarsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CalendarsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CalendarsRequest proto.InternalMessageInfo
+
+type CalendarListResponse struct {
+	Calendars            []*CalendarInfo `protobuf:"bytes,1,rep,name=calendars,proto3" json:"calendars,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *CalendarListResponse) Reset()         { *m = CalendarListResponse{} }
+func (m *CalendarListResponse) String() string { return proto.CompactTextString(m) }
+func (*CalendarListResponse) ProtoMessage()    {}
+func (*CalendarListResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{20}
+}
+
+func (m *CalendarListResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CalendarListResponse.Unmarshal(m, b)
+}
+func (m *CalendarListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CalendarListResponse.Marshal(b, m, deterministic)
+}
+func (m *CalendarListResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CalendarListResponse.Merge(m, src)
+}
+func (m *CalendarListResponse) XXX_Size() int {
+	return xxx_messageInfo_CalendarListResponse.Size(m)
+}
+func (m *CalendarListResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CalendarListResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CalendarListResponse proto.InternalMessageInfo
+
+func (m *CalendarListResponse) GetCalendars() []*CalendarInfo {
+	if m != nil {
+		return m.Calendars
+	}
+	return nil
+}
+
+// Range is [start, end), working hours and days are local in tz (IANA time zone), empty tz means default time zone of service
+// Free gaps are returned only if min_free_minutes > 0, working hours are HH:MM, by default 09:00 - 18:00
+type FreeBusyRequest struct {
+	Start                *timestamp.Timestamp `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	End                  *timestamp.Timestamp `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	Tz                   string               `protobuf:"bytes,3,opt,name=tz,proto3" json:"tz,omitempty"`
+	MinFreeMinutes       int32                `protobuf:"varint,4,opt,name=min_free_minutes,json=minFreeMinutes,proto3" json:"min_free_minutes,omitempty"`
+	WorkStart            string               `protobuf:"bytes,5,opt,name=work_start,json=workStart,proto3" json:"work_start,omitempty"`
+	WorkEnd              string               `protobuf:"bytes,6,opt,name=work_end,json=workEnd,proto3" json:"work_end,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *FreeBusyRequest) Reset()         { *m = FreeBusyRequest{} }
+func (m *FreeBusyRequest) String() string { return proto.CompactTextString(m) }
+func (*FreeBusyRequest) ProtoMessage()    {}
+func (*FreeBusyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{21}
+}
+
+func (m *FreeBusyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FreeBusyRequest.Unmarshal(m, b)
+}
+func (m *FreeBusyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FreeBusyRequest.Marshal(b, m, deterministic)
+}
+func (m *FreeBusyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FreeBusyRequest.Merge(m, src)
+}
+func (m *FreeBusyRequest) XXX_Size() int {
+	return xxx_messageInfo_FreeBusyRequest.Size(m)
+}
+func (m *FreeBusyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FreeBusyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FreeBusyRequest proto.InternalMessageInfo
+
+func (m *FreeBusyRequest) GetStart() *timestamp.Timestamp {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *FreeBusyRequest) GetEnd() *timestamp.Timestamp {
+	if m != nil {
+		return m.End
+	}
+	return nil
+}
+
+func (m *FreeBusyRequest) GetTz() string {
+	if m != nil {
+		return m.Tz
+	}
+	return ""
+}
+
+func (m *FreeBusyRequest) GetMinFreeMinutes() int32 {
+	if m != nil {
+		return m.MinFreeMinutes
+	}
+	return 0
+}
+
+func (m *FreeBusyRequest) GetWorkStart() string {
+	if m != nil {
+		return m.WorkStart
+	}
+	return ""
+}
+
+func (m *FreeBusyRequest) GetWorkEnd() string {
+	if m != nil {
+		return m.WorkEnd
+	}
+	return ""
+}
+
+// Interval of time, start is included, end is excluded
+type Interval struct {
+	Start                *timestamp.Timestamp `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	End                  *timestamp.Timestamp `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Interval) Reset()         { *m = Interval{} }
+func (m *Interval) String() string { return proto.CompactTextString(m) }
+func (*Interval) ProtoMessage()    {}
+func (*Interval) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{22}
+}
+
+func (m *Interval) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Interval.Unmarshal(m, b)
+}
+func (m *Interval) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Interval.Marshal(b, m, deterministic)
+}
+func (m *Interval) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Interval.Merge(m, src)
+}
+func (m *Interval) XXX_Size() int {
+	return xxx_messageInfo_Interval.Size(m)
+}
+func (m *Interval) XXX_DiscardUnknown() {
+	xxx_messageInfo_Interval.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Interval proto.InternalMessageInfo
+
+func (m *Interval) GetStart() *timestamp.Timestamp {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *Interval) GetEnd() *timestamp.Timestamp {
+	if m != nil {
+		return m.End
+	}
+	return nil
+}
+
+type FreeBusyResponse struct {
+	Busy                 []*Interval `protobuf:"bytes,1,rep,name=busy,proto3" json:"busy,omitempty"`
+	Free                 []*Interval `protobuf:"bytes,2,rep,name=free,proto3" json:"free,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *FreeBusyResponse) Reset()         { *m = FreeBusyResponse{} }
+func (m *FreeBusyResponse) String() string { return proto.CompactTextString(m) }
+func (*FreeBusyResponse) ProtoMessage()    {}
+func (*FreeBusyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{23}
+}
+
+func (m *FreeBusyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FreeBusyResponse.Unmarshal(m, b)
+}
+func (m *FreeBusyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FreeBusyResponse.Marshal(b, m, deterministic)
+}
+func (m *FreeBusyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FreeBusyResponse.Merge(m, src)
+}
+func (m *FreeBusyResponse) XXX_Size() int {
+	return xxx_messageInfo_FreeBusyResponse.Size(m)
+}
+func (m *FreeBusyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FreeBusyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FreeBusyResponse proto.InternalMessageInfo
+
+func (m *FreeBusyResponse) GetBusy() []*Interval {
+	if m != nil {
+		return m.Busy
+	}
+	return nil
+}
+
+func (m *FreeBusyResponse) GetFree() []*Interval {
+	if m != nil {
+		return m.Free
+	}
+	return nil
+}
+
+// Events which name contains all words of query, sorted by relevance
+// start and end are optional boundaries of starts of occurrences, all day events are in days of tz (IANA time zone)
+// limit is max number of events (0 means 100, at most 1000)
+type SearchRequest struct {
+	Query                string               `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Start                *timestamp.Timestamp `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End                  *timestamp.Timestamp `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	Tz                   string               `protobuf:"bytes,4,opt,name=tz,proto3" json:"tz,omitempty"`
+	Limit                int32                `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *SearchRequest) Reset()         { *m = SearchRequest{} }
+func (m *SearchRequest) String() string { return proto.CompactTextString(m) }
+func (*SearchRequest) ProtoMessage()    {}
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{24}
+}
+
+func (m *SearchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchRequest.Unmarshal(m, b)
+}
+func (m *SearchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchRequest.Marshal(b, m, deterministic)
+}
+func (m *SearchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchRequest.Merge(m, src)
+}
+func (m *SearchRequest) XXX_Size() int {
+	return xxx_messageInfo_SearchRequest.Size(m)
+}
+func (m *SearchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchRequest proto.InternalMessageInfo
+
+func (m *SearchRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *SearchRequest) GetStart() *timestamp.Timestamp {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *SearchRequest) GetEnd() *timestamp.Timestamp {
+	if m != nil {
+		return m.End
+	}
+	return nil
+}
+
+func (m *SearchRequest) GetTz() string {
+	if m != nil {
+		return m.Tz
+	}
+	return ""
+}
+
+func (m *SearchRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+// Operation of batch, exactly one of create, update and delete must be set
+type BatchOperation struct {
+	Create               *CreateEventRequest `protobuf:"bytes,1,opt,name=create,proto3" json:"create,omitempty"`
+	Update               *UpdateEventRequest `protobuf:"bytes,2,opt,name=update,proto3" json:"update,omitempty"`
+	Delete               *DeleteEventRequest `protobuf:"bytes,3,opt,name=delete,proto3" json:"delete,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *BatchOperation) Reset()         { *m = BatchOperation{} }
+func (m *BatchOperation) String() string { return proto.CompactTextString(m) }
+func (*BatchOperation) ProtoMessage()    {}
+func (*BatchOperation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{25}
+}
+
+func (m *BatchOperation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchOperation.Unmarshal(m, b)
+}
+func (m *BatchOperation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchOperation.Marshal(b, m, deterministic)
+}
+func (m *BatchOperation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchOperation.Merge(m, src)
+}
+func (m *BatchOperation) XXX_Size() int {
+	return xxx_messageInfo_BatchOperation.Size(m)
+}
+func (m *BatchOperation) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchOperation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchOperation proto.InternalMessageInfo
+
+func (m *BatchOperation) GetCreate() *CreateEventRequest {
+	if m != nil {
+		return m.Create
+	}
+	return nil
+}
+
+func (m *BatchOperation) GetUpdate() *UpdateEventRequest {
+	if m != nil {
+		return m.Update
+	}
+	return nil
+}
+
+func (m *BatchOperation) GetDelete() *DeleteEventRequest {
+	if m != nil {
+		return m.Delete
+	}
+	return nil
+}
+
+// Operations (at most 1000) are applied all or nothing
+type BatchRequest struct {
+	Operations           []*BatchOperation `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *BatchRequest) Reset()         { *m = BatchRequest{} }
+func (m *BatchRequest) String() string { return proto.CompactTextString(m) }
+func (*BatchRequest) ProtoMessage()    {}
+func (*BatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{26}
+}
+
+func (m *BatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchRequest.Unmarshal(m, b)
+}
+func (m *BatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchRequest.Marshal(b, m, deterministic)
+}
+func (m *BatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchRequest.Merge(m, src)
+}
+func (m *BatchRequest) XXX_Size() int {
+	return xxx_messageInfo_BatchRequest.Size(m)
+}
+func (m *BatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchRequest proto.InternalMessageInfo
+
+func (m *BatchRequest) GetOperations() []*BatchOperation {
+	if m != nil {
+		return m.Operations
+	}
+	return nil
+}
+
+type BatchResponse struct {
+	Ids                  []int32  `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BatchResponse) Reset()         { *m = BatchResponse{} }
+func (m *BatchResponse) String() string { return proto.CompactTextString(m) }
+func (*BatchResponse) ProtoMessage()    {}
+func (*BatchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{27}
+}
+
+func (m *BatchResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchResponse.Unmarshal(m, b)
+}
+func (m *BatchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchResponse.Marshal(b, m, deterministic)
+}
+func (m *BatchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchResponse.Merge(m, src)
+}
+func (m *BatchResponse) XXX_Size() int {
+	return xxx_messageInfo_BatchResponse.Size(m)
+}
+func (m *BatchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchResponse proto.InternalMessageInfo
+
+func (m *BatchResponse) GetIds() []int32 {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Event)(nil), "grpc.Event")
+	proto.RegisterType((*AttendeeStatus)(nil), "grpc.AttendeeStatus")
+	proto.RegisterType((*SimpleResponse)(nil), "grpc.SimpleResponse")
+	proto.RegisterType((*EventListResponse)(nil), "grpc.EventListResponse")
+	proto.RegisterType((*CreateEventRequest)(nil), "grpc.CreateEventRequest")
+	proto.RegisterType((*UpdateEventRequest)(nil), "grpc.UpdateEventRequest")
+	proto.RegisterType((*DeleteEventRequest)(nil), "grpc.DeleteEventRequest")
+	proto.RegisterType((*RestoreEventRequest)(nil), "grpc.RestoreEventRequest")
+	proto.RegisterType((*TrashRequest)(nil), "grpc.TrashRequest")
+	proto.RegisterType((*EventHistoryRequest)(nil), "grpc.EventHistoryRequest")
+	proto.RegisterType((*FieldChange)(nil), "grpc.FieldChange")
+	proto.RegisterType((*AuditEntry)(nil), "grpc.AuditEntry")
+	proto.RegisterType((*EventHistoryResponse)(nil), "grpc.EventHistoryResponse")
+	proto.RegisterType((*RespondToInvitationRequest)(nil), "grpc.RespondToInvitationRequest")
+	proto.RegisterType((*PeriodRequest)(nil), "grpc.PeriodRequest")
+	proto.RegisterType((*CalendarInfo)(nil), "grpc.CalendarInfo")
+	proto.RegisterType((*CreateCalendarRequest)(nil), "grpc.CreateCalendarRequest")
+	proto.RegisterType((*UpdateCalendarRequest)(nil), "grpc.UpdateCalendarRequest")
+	proto.RegisterType((*DeleteCalendarRequest)(nil), "grpc.DeleteCalendarRequest")
+	proto.RegisterType((*CalendarsRequest)(nil), "grpc.CalendarsRequest")
+	proto.RegisterType((*CalendarListResponse)(nil), "grpc.CalendarListResponse")
+	proto.RegisterType((*FreeBusyRequest)(nil), "grpc.FreeBusyRequest")
+	proto.RegisterType((*Interval)(nil), "grpc.Interval")
+	proto.RegisterType((*FreeBusyResponse)(nil), "grpc.FreeBusyResponse")
+	proto.RegisterType((*SearchRequest)(nil), "grpc.SearchRequest")
+	proto.RegisterType((*BatchOperation)(nil), "grpc.BatchOperation")
+	proto.RegisterType((*BatchRequest)(nil), "grpc.BatchRequest")
+	proto.RegisterType((*BatchResponse)(nil), "grpc.BatchResponse")
+}
 
 func init() { proto.RegisterFile("api.proto", fileDescriptor_00212fb1f9d3bf1c) }
 
 var fileDescriptor_00212fb1f9d3bf1c = []byte{
-	// 388 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x52, 0xcd, 0x4e, 0xa3, 0x50,
-	0x14, 0xee, 0x85, 0xfe, 0x84, 0x43, 0xa6, 0x33, 0xbd, 0x99, 0xcc, 0x10, 0x36, 0x43, 0x98, 0x59,
-	0xb0, 0x98, 0xd0, 0x49, 0x67, 0x63, 0xa2, 0xc6, 0x85, 0x55, 0x37, 0xea, 0x82, 0x6a, 0x5c, 0xd3,
-	0x72, 0xa4, 0x37, 0x02, 0x17, 0xb9, 0xb7, 0x4d, 0x7c, 0x01, 0x63, 0xe2, 0x0b, 0xf8, 0xb8, 0x06,
-	0x28, 0x0d, 0x5a, 0xad, 0xed, 0xce, 0x1d, 0x9c, 0xfb, 0x9d, 0x2f, 0xdf, 0xcf, 0x01, 0xcd, 0x4f,
-	0x99, 0x9b, 0x66, 0x5c, 0x72, 0xda, 0x0c, 0xb3, 0x74, 0x62, 0xfe, 0x0a, 0x39, 0x0f, 0x23, 0xec,
-	0x17, 0xb3, 0xf1, 0xec, 0xba, 0x2f, 0x59, 0x8c, 0x42, 0xfa, 0x71, 0x5a, 0xc2, 0xec, 0x47, 0x02,
-	0xad, 0xa3, 0x39, 0x26, 0x92, 0x76, 0x41, 0x61, 0x81, 0x41, 0x2c, 0xe2, 0xb4, 0x3c, 0x85, 0x05,
-	0x94, 0x42, 0x33, 0xf1, 0x63, 0x34, 0x14, 0x8b, 0x38, 0x9a, 0x57, 0x7c, 0xd3, 0x7f, 0xd0, 0x12,
-	0xd2, 0xcf, 0xa4, 0xa1, 0x5a, 0xc4, 0xd1, 0x07, 0xa6, 0x5b, 0xd2, 0xbb, 0x15, 0xbd, 0x7b, 0x51,
-	0xd1, 0x7b, 0x25, 0x90, 0xfe, 0x05, 0x15, 0x93, 0xc0, 0x68, 0x7e, 0x88, 0xcf, 0x61, 0xb6, 0x03,
-	0xdd, 0x11, 0x8b, 0xd3, 0x08, 0x3d, 0x14, 0x29, 0x4f, 0x04, 0xd2, 0x1f, 0xd0, 0xce, 0x50, 0xcc,
-	0x22, 0x59, 0x28, 0xd3, 0xbc, 0xc5, 0x9f, 0xbd, 0x03, 0xbd, 0x42, 0xf6, 0x29, 0x13, 0x72, 0x09,
-	0xfe, 0x0d, 0x6d, 0xcc, 0x87, 0xc2, 0x20, 0x96, 0xea, 0xe8, 0x03, 0xdd, 0xcd, 0x43, 0x70, 0x0b,
-	0xa0, 0xb7, 0x78, 0xb2, 0x1f, 0x08, 0xd0, 0xc3, 0x0c, 0x7d, 0x89, 0xe5, 0x1c, 0x6f, 0x67, 0x28,
-	0xe4, 0xd2, 0x2e, 0x79, 0xcb, 0xae, 0xb2, 0xa5, 0x5d, 0x75, 0x33, 0xbb, 0x4f, 0x04, 0xe8, 0x65,
-	0x1a, 0xbc, 0x96, 0xf2, 0x19, 0x9a, 0xf8, 0x03, 0x74, 0x88, 0x11, 0xae, 0x57, 0x66, 0x6b, 0xd0,
-	0x39, 0xe7, 0x72, 0xca, 0x92, 0x70, 0x70, 0xaf, 0x42, 0x67, 0x84, 0xd9, 0x9c, 0x4d, 0x90, 0x1e,
-	0x80, 0x5e, 0x4b, 0x98, 0x1a, 0x65, 0x0d, 0xab, 0xa1, 0x9b, 0xdf, 0xcb, 0x97, 0x97, 0x9d, 0xdb,
-	0x8d, 0x9c, 0xa0, 0x96, 0x4b, 0x45, 0xb0, 0x1a, 0xd5, 0x3a, 0x82, 0x9a, 0xfc, 0x8a, 0x60, 0xd5,
-	0xd1, 0xbb, 0x04, 0xbb, 0xf0, 0xf5, 0x04, 0x65, 0x01, 0x15, 0xc7, 0x3c, 0x1b, 0xfa, 0x77, 0xf4,
-	0x4b, 0x09, 0x5d, 0x18, 0x36, 0x7f, 0xd6, 0x8e, 0xab, 0x7e, 0x85, 0x76, 0x83, 0xee, 0xc1, 0xb7,
-	0xfa, 0xf2, 0x15, 0xe2, 0xcd, 0x16, 0xdb, 0xfb, 0xd0, 0xab, 0x6f, 0x9f, 0xf1, 0x44, 0x4e, 0x37,
-	0x5f, 0x1f, 0xb7, 0x8b, 0x4a, 0xff, 0x3f, 0x07, 0x00, 0x00, 0xff, 0xff, 0xc3, 0x91, 0x08, 0x2c,
-	0x0c, 0x04, 0x00, 0x00,
+	// 1449 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x57, 0x5b, 0x6f, 0x1b, 0x45,
+	0x14, 0xae, 0xef, 0xf6, 0xb1, 0xe3, 0x38, 0x93, 0xa4, 0xdd, 0x1a, 0x50, 0xdd, 0x05, 0x44, 0xa0,
+	0xc8, 0xad, 0x4a, 0x5f, 0x10, 0xa2, 0x28, 0xb7, 0xa6, 0x45, 0x54, 0x85, 0x4d, 0x11, 0xa2, 0x2f,
+	0xd6, 0x66, 0xf7, 0x38, 0x99, 0x76, 0xbd, 0xeb, 0xce, 0x8c, 0xd3, 0x26, 0x8f, 0xf0, 0xcc, 0x33,
+	0xff, 0x80, 0x17, 0xc4, 0xcf, 0xe2, 0x7f, 0xa0, 0xb9, 0x6d, 0x76, 0x1d, 0xdb, 0x49, 0x90, 0x2a,
+	0xf1, 0xc0, 0x9b, 0xcf, 0x75, 0xcf, 0x9c, 0x39, 0xdf, 0xf9, 0xc6, 0xd0, 0xf0, 0xc7, 0xb4, 0x3f,
+	0x66, 0x89, 0x48, 0x48, 0xf9, 0x90, 0x8d, 0x83, 0xee, 0xad, 0xc3, 0x24, 0x39, 0x8c, 0xf0, 0xae,
+	0xd2, 0x1d, 0x4c, 0x86, 0x77, 0x05, 0x1d, 0x21, 0x17, 0xfe, 0x68, 0xac, 0xdd, 0xdc, 0xdf, 0x2a,
+	0x50, 0xd9, 0x3d, 0xc6, 0x58, 0x90, 0x36, 0x14, 0x69, 0xe8, 0x14, 0x7a, 0x85, 0x8d, 0x8a, 0x57,
+	0xa4, 0x21, 0x21, 0x50, 0x8e, 0xfd, 0x11, 0x3a, 0xc5, 0x5e, 0x61, 0xa3, 0xe1, 0xa9, 0xdf, 0xe4,
+	0x1e, 0x54, 0xb8, 0xf0, 0x99, 0x70, 0x4a, 0xbd, 0xc2, 0x46, 0xf3, 0x7e, 0xb7, 0xaf, 0xd3, 0xf7,
+	0x6d, 0xfa, 0xfe, 0x73, 0x9b, 0xde, 0xd3, 0x8e, 0xe4, 0x73, 0x28, 0x61, 0x1c, 0x3a, 0xe5, 0x0b,
+	0xfd, 0xa5, 0x1b, 0x59, 0x83, 0x0a, 0x63, 0x93, 0x08, 0x9d, 0x8a, 0xfa, 0xa8, 0x16, 0xc8, 0x03,
+	0xa8, 0xe1, 0xdb, 0xd0, 0x17, 0xc8, 0x9d, 0x6a, 0xaf, 0x74, 0x41, 0x1e, 0xeb, 0x4a, 0x6e, 0x40,
+	0xcd, 0x8f, 0xa2, 0x41, 0xe8, 0x9f, 0x38, 0xb5, 0x5e, 0x61, 0xa3, 0xee, 0x55, 0xfd, 0x28, 0xda,
+	0xf1, 0x4f, 0x48, 0x17, 0xea, 0xb2, 0x0b, 0xa7, 0x49, 0x8c, 0x4e, 0x5d, 0x7d, 0x27, 0x95, 0x49,
+	0x0f, 0x9a, 0x21, 0xf2, 0x80, 0xd1, 0xb1, 0xa0, 0x49, 0xec, 0x34, 0x94, 0x39, 0xab, 0x92, 0xd1,
+	0x51, 0x12, 0xf8, 0xca, 0x0c, 0x3a, 0xda, 0xca, 0xe4, 0x7d, 0x68, 0x24, 0xec, 0xd0, 0x8f, 0xe9,
+	0x29, 0x32, 0xa7, 0xa9, 0x8c, 0x67, 0x0a, 0x69, 0xf5, 0x85, 0xc0, 0x38, 0x44, 0xe4, 0x4e, 0xab,
+	0x57, 0x92, 0xd6, 0x54, 0x21, 0x8f, 0x1e, 0x24, 0x51, 0xc2, 0x9c, 0x25, 0x7d, 0x74, 0x25, 0x48,
+	0x6d, 0xf2, 0x26, 0x46, 0xe6, 0xb4, 0xb5, 0x56, 0x09, 0x32, 0x13, 0xc3, 0x11, 0x8d, 0x43, 0x64,
+	0xdc, 0x59, 0xee, 0x95, 0x36, 0x2a, 0xde, 0x99, 0x82, 0x7c, 0x0d, 0xad, 0x10, 0x23, 0x14, 0x18,
+	0x0e, 0xe4, 0xb9, 0x9c, 0xce, 0x85, 0xbd, 0x6f, 0x1a, 0x7f, 0xa9, 0x21, 0x0e, 0xd4, 0x8e, 0x91,
+	0x71, 0x79, 0xbe, 0x15, 0x35, 0x0c, 0x56, 0x24, 0xb7, 0xa0, 0x19, 0xf8, 0x11, 0xc6, 0xa1, 0xcf,
+	0x06, 0x34, 0x74, 0x88, 0xb2, 0x82, 0x55, 0x3d, 0x09, 0xc9, 0x26, 0xac, 0xd8, 0x03, 0x0d, 0xb8,
+	0xf0, 0xc5, 0x84, 0x23, 0x77, 0x56, 0xd5, 0x95, 0xad, 0xf5, 0xe5, 0x3c, 0xf6, 0x37, 0x8d, 0x79,
+	0x5f, 0x59, 0xbd, 0x8e, 0x9f, 0x93, 0x91, 0xbb, 0x0f, 0xa1, 0x9d, 0xf7, 0x91, 0x2d, 0xc0, 0x91,
+	0x4f, 0x23, 0x35, 0x9a, 0x0d, 0x4f, 0x0b, 0xe4, 0x3a, 0x54, 0xf5, 0x17, 0xcc, 0x7c, 0x1a, 0xc9,
+	0xdd, 0x80, 0xf6, 0x3e, 0x1d, 0x8d, 0x23, 0xf4, 0x90, 0x8f, 0x93, 0x98, 0xa3, 0xf4, 0x64, 0xc8,
+	0x27, 0x91, 0x30, 0x09, 0x8c, 0xe4, 0xfe, 0x0c, 0x2b, 0x6a, 0xf0, 0xbf, 0xa3, 0x5c, 0xa4, 0xce,
+	0x1f, 0x42, 0x15, 0xa5, 0x92, 0x3b, 0x05, 0x55, 0x76, 0x53, 0x97, 0xad, 0x1c, 0x3d, 0x63, 0x92,
+	0x7d, 0x88, 0xf1, 0xad, 0x18, 0x04, 0x13, 0xc6, 0x13, 0x66, 0x0a, 0x00, 0xa9, 0xda, 0x56, 0x1a,
+	0xf7, 0xd7, 0x32, 0x90, 0x6d, 0x86, 0xbe, 0x40, 0x1d, 0x88, 0xaf, 0x27, 0xc8, 0x45, 0x8a, 0xa8,
+	0xc2, 0x2c, 0x44, 0x15, 0xaf, 0x88, 0xa8, 0xd2, 0x15, 0x11, 0x55, 0x9e, 0x83, 0xa8, 0xca, 0xbf,
+	0x42, 0x54, 0x75, 0x2e, 0xa2, 0x6a, 0x8b, 0x11, 0x55, 0x5f, 0x8c, 0xa8, 0xc6, 0x22, 0x44, 0xc1,
+	0x42, 0x44, 0x35, 0xe7, 0x22, 0xaa, 0x95, 0x45, 0xd4, 0xa7, 0xd0, 0x61, 0xf8, 0x12, 0x03, 0x31,
+	0x08, 0x92, 0x78, 0x18, 0xd1, 0x40, 0x70, 0x05, 0xb9, 0xba, 0xb7, 0xac, 0xf5, 0xdb, 0x56, 0x9d,
+	0x87, 0x59, 0x7b, 0x1a, 0x66, 0x53, 0x68, 0x58, 0x9e, 0x46, 0x83, 0xfb, 0x57, 0x19, 0xc8, 0x8f,
+	0xe3, 0x70, 0x7a, 0x0a, 0xfe, 0xdf, 0xb3, 0xff, 0xc1, 0x3d, 0x3b, 0x6b, 0x2a, 0xda, 0x97, 0x98,
+	0x8a, 0x73, 0xcb, 0x37, 0xb3, 0x3d, 0x3b, 0x0b, 0xb7, 0xe7, 0xca, 0xb9, 0x79, 0xf9, 0x08, 0xc8,
+	0x8e, 0xda, 0xc3, 0x8b, 0xc6, 0xc5, 0xfd, 0x18, 0x56, 0x3d, 0xe4, 0x22, 0x61, 0x8b, 0xdd, 0xda,
+	0xd0, 0x7a, 0xce, 0x7c, 0x7e, 0x64, 0xec, 0x32, 0x4c, 0xf9, 0x3f, 0xa6, 0x32, 0xf6, 0x64, 0x5e,
+	0xd8, 0x0f, 0xd0, 0x7c, 0x44, 0x31, 0x0a, 0xb7, 0x8f, 0xfc, 0xf8, 0x10, 0x65, 0xb3, 0x86, 0x52,
+	0xb4, 0xbb, 0x57, 0x09, 0x72, 0xa3, 0x1e, 0xe0, 0x30, 0x61, 0x76, 0x66, 0x8d, 0x24, 0xbd, 0xfd,
+	0xa1, 0x40, 0xa6, 0xa6, 0xb6, 0xe1, 0x69, 0xc1, 0xfd, 0xbd, 0x00, 0xb0, 0x39, 0x09, 0xa9, 0xd8,
+	0x8d, 0x05, 0x3b, 0x91, 0xc1, 0x7e, 0xa0, 0x6e, 0xd5, 0xac, 0x63, 0x2d, 0xa9, 0xe0, 0x40, 0xa4,
+	0xeb, 0x54, 0x0b, 0xa4, 0x0f, 0x65, 0xc5, 0x61, 0x17, 0xe3, 0x40, 0xf9, 0x91, 0x3b, 0x50, 0x0b,
+	0x54, 0xe9, 0xdc, 0x29, 0xab, 0x11, 0x5e, 0xd1, 0x0b, 0x3c, 0x73, 0x28, 0xcf, 0x7a, 0xb8, 0x5b,
+	0xb0, 0x96, 0xef, 0x89, 0x21, 0x81, 0xcf, 0xa0, 0x86, 0xb1, 0x60, 0x14, 0x2d, 0x0b, 0x74, 0x0c,
+	0x79, 0xa5, 0xa7, 0xf0, 0xac, 0x83, 0xfb, 0x02, 0xba, 0x3a, 0x2e, 0x7c, 0x9e, 0x3c, 0x89, 0x8f,
+	0xa9, 0x50, 0x13, 0x3a, 0x0f, 0xeb, 0x29, 0x97, 0x15, 0x67, 0x73, 0x59, 0x29, 0xc7, 0x65, 0x63,
+	0x58, 0xfa, 0x1e, 0x19, 0x4d, 0xc2, 0x4c, 0x3a, 0x71, 0x6a, 0xfa, 0x56, 0x14, 0xa7, 0xe4, 0x36,
+	0xb4, 0x32, 0x23, 0x25, 0xa9, 0x50, 0x4e, 0x63, 0xf3, 0x6c, 0xa6, 0xd4, 0xb8, 0x47, 0x74, 0x44,
+	0xf5, 0x26, 0xa9, 0x78, 0x5a, 0x90, 0x5f, 0x34, 0xe4, 0xa5, 0x69, 0xc1, 0x48, 0xee, 0x63, 0x68,
+	0x6d, 0xdb, 0xe0, 0x78, 0x98, 0x5c, 0x6a, 0x57, 0xa5, 0x4f, 0x94, 0x52, 0xe6, 0x89, 0xe2, 0xde,
+	0x81, 0x75, 0xcd, 0x80, 0x36, 0xdf, 0x02, 0x12, 0x74, 0xbf, 0x82, 0x75, 0xbd, 0x28, 0xa7, 0x9d,
+	0x2f, 0xf1, 0x7d, 0xf7, 0x13, 0x58, 0xd7, 0xb0, 0xb9, 0x20, 0xd8, 0x25, 0xd0, 0xb1, 0x2e, 0xdc,
+	0xc2, 0xe2, 0x31, 0xac, 0x59, 0x5d, 0xee, 0x1d, 0x70, 0x0f, 0x1a, 0xb6, 0x8b, 0x76, 0x08, 0x88,
+	0x1e, 0x82, 0x6c, 0x7f, 0xbc, 0x33, 0x27, 0xf7, 0xef, 0x02, 0x2c, 0x3f, 0x62, 0x88, 0x5b, 0x13,
+	0x9e, 0xa2, 0x2b, 0x5d, 0xe3, 0x85, 0x2b, 0xae, 0xf1, 0xe2, 0xe5, 0xd6, 0xb8, 0x9e, 0x87, 0x52,
+	0x3a, 0x0f, 0x1b, 0xd0, 0x19, 0xd1, 0x78, 0x30, 0x64, 0x88, 0x83, 0x11, 0x8d, 0x27, 0x42, 0xc1,
+	0x40, 0x9e, 0xbf, 0x3d, 0xa2, 0xb1, 0xac, 0xee, 0xa9, 0xd6, 0x92, 0x0f, 0x00, 0xde, 0x24, 0xec,
+	0xd5, 0x40, 0x97, 0xa7, 0x59, 0xa0, 0x21, 0x35, 0xfb, 0xaa, 0x8c, 0x9b, 0x50, 0x57, 0x66, 0x59,
+	0x4b, 0x55, 0x19, 0x6b, 0x52, 0xde, 0x8d, 0x43, 0xf7, 0x25, 0xd4, 0x9f, 0xc4, 0x02, 0xd9, 0xb1,
+	0x1f, 0xbd, 0xeb, 0xf3, 0xb9, 0x2f, 0xa0, 0x73, 0xd6, 0x52, 0x73, 0x33, 0x2e, 0x94, 0x0f, 0x26,
+	0xfc, 0xc4, 0x5c, 0x4a, 0x5b, 0x5f, 0x8a, 0xad, 0xc8, 0x53, 0x36, 0xe9, 0x23, 0x7b, 0xe0, 0x14,
+	0x67, 0xfb, 0x48, 0x9b, 0xfb, 0x67, 0x01, 0x96, 0xf6, 0xd1, 0x67, 0x81, 0x5d, 0x91, 0x72, 0x90,
+	0x5f, 0x4f, 0x90, 0x9d, 0xd8, 0x65, 0xa7, 0x84, 0x77, 0xfe, 0x40, 0xd3, 0x77, 0x58, 0x4e, 0xef,
+	0x30, 0x05, 0x6c, 0x25, 0x03, 0x58, 0xf7, 0x8f, 0x02, 0xb4, 0xb7, 0x7c, 0x11, 0x1c, 0x3d, 0x1b,
+	0x23, 0xd3, 0x24, 0x78, 0x0f, 0xaa, 0x81, 0x42, 0x98, 0xe9, 0xbe, 0x63, 0xe6, 0xf3, 0xdc, 0xbb,
+	0xd3, 0x33, 0x7e, 0x32, 0x62, 0xa2, 0x60, 0xe6, 0x14, 0xb3, 0x11, 0xe7, 0xdf, 0x28, 0x9e, 0xf1,
+	0x93, 0x11, 0xfa, 0xaf, 0x81, 0x53, 0xca, 0x46, 0x9c, 0xa7, 0x29, 0xcf, 0xf8, 0xb9, 0x3b, 0xd0,
+	0x52, 0x75, 0xda, 0xa6, 0x3e, 0x00, 0x48, 0x6c, 0xc9, 0x16, 0x49, 0xe6, 0xbf, 0x40, 0xfe, 0x3c,
+	0x5e, 0xc6, 0xcf, 0xbd, 0x0d, 0x4b, 0x26, 0x8b, 0xb9, 0xf5, 0x0e, 0x94, 0x68, 0xa8, 0xe3, 0x2b,
+	0x9e, 0xfc, 0x79, 0xff, 0x97, 0x06, 0xd4, 0xf6, 0x91, 0x1d, 0xd3, 0x00, 0xc9, 0x37, 0xd0, 0xcc,
+	0x1c, 0x9b, 0xcc, 0xed, 0x44, 0xd7, 0x7c, 0x39, 0xff, 0x0f, 0xc1, 0xbd, 0x26, 0x13, 0x64, 0xba,
+	0x40, 0xe6, 0x36, 0x66, 0x51, 0x82, 0x4c, 0x53, 0xc8, 0xdc, 0x3e, 0xcd, 0x4d, 0xb0, 0x09, 0xad,
+	0x2c, 0xad, 0x93, 0x9b, 0xda, 0x6f, 0x06, 0xd5, 0xcf, 0x4d, 0xf1, 0x25, 0xd4, 0xf7, 0x50, 0x28,
+	0xd6, 0x27, 0x66, 0x59, 0x65, 0x9f, 0x00, 0xdd, 0x1b, 0x99, 0xff, 0x32, 0xd9, 0x65, 0xe7, 0x5e,
+	0x23, 0xdf, 0xc2, 0xf2, 0x1e, 0x8a, 0x2c, 0x19, 0xda, 0x02, 0x66, 0x3c, 0x1a, 0xba, 0xdd, 0x59,
+	0xa6, 0x34, 0xd7, 0x33, 0x58, 0x9d, 0xc1, 0x88, 0xa4, 0x97, 0x1e, 0x68, 0x0e, 0x59, 0x2e, 0x68,
+	0x4d, 0x5a, 0x1c, 0x7f, 0x94, 0x30, 0xf9, 0xb4, 0x5c, 0xd5, 0xae, 0x39, 0x76, 0x5c, 0x74, 0xbe,
+	0x2d, 0xe8, 0x64, 0x53, 0xfc, 0x84, 0xf8, 0xea, 0xca, 0x39, 0xb6, 0x61, 0x25, 0x9b, 0xe3, 0x69,
+	0x12, 0x8b, 0xa3, 0x2b, 0x27, 0x79, 0x08, 0xcd, 0x3d, 0x14, 0x76, 0xa9, 0x91, 0x75, 0xf3, 0x3a,
+	0xc9, 0xf3, 0x46, 0xf7, 0xfa, 0xb4, 0x3a, 0x13, 0xdf, 0xd2, 0x4b, 0x4b, 0xd7, 0x61, 0xbf, 0x9f,
+	0x5b, 0x64, 0x8b, 0xbe, 0x7f, 0x1f, 0x2a, 0x0a, 0x58, 0x76, 0x40, 0xb2, 0x58, 0xed, 0xae, 0xe6,
+	0x74, 0x69, 0xcc, 0x2e, 0xb4, 0xf3, 0x54, 0x4e, 0xde, 0xcb, 0x02, 0x6c, 0x8a, 0x76, 0xe7, 0x5e,
+	0xe3, 0x2e, 0xb4, 0xf3, 0x24, 0x6f, 0xd3, 0xcc, 0xa4, 0xfe, 0x45, 0x69, 0xf2, 0x74, 0x6f, 0xd3,
+	0xcc, 0x7c, 0x04, 0xcc, 0x4d, 0xb3, 0x03, 0xad, 0x3d, 0x14, 0xd6, 0x9b, 0x93, 0xeb, 0x79, 0x76,
+	0xe7, 0x53, 0xb3, 0x3e, 0xeb, 0x91, 0xe0, 0x5e, 0x3b, 0xa8, 0xaa, 0xad, 0xfe, 0xc5, 0x3f, 0x03,
+	0x00, 0xd8, 0x42, 0x7e, 0x0b, 0x78, 0x13, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -410,9 +1875,20 @@ type ServiceClient interface {
 	CreateEvent(ctx context.Context, in *CreateEventRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
 	UpdateEvent(ctx context.Context, in *UpdateEventRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
 	DeleteEvent(ctx context.Context, in *DeleteEventRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
-	GetEventsForDay(ctx context.Context, in *Nothing, opts ...grpc.CallOption) (*EventListResponse, error)
-	GetEventsForWeek(ctx context.Context, in *Nothing, opts ...grpc.CallOption) (*EventListResponse, error)
-	GetEventsForMonth(ctx context.Context, in *Nothing, opts ...grpc.CallOption) (*EventListResponse, error)
+	RestoreEvent(ctx context.Context, in *RestoreEventRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	GetTrash(ctx context.Context, in *TrashRequest, opts ...grpc.CallOption) (*EventListResponse, error)
+	GetEventHistory(ctx context.Context, in *EventHistoryRequest, opts ...grpc.CallOption) (*EventHistoryResponse, error)
+	RespondToInvitation(ctx context.Context, in *RespondToInvitationRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	GetEventsForDay(ctx context.Context, in *PeriodRequest, opts ...grpc.CallOption) (*EventListResponse, error)
+	GetEventsForWeek(ctx context.Context, in *PeriodRequest, opts ...grpc.CallOption) (*EventListResponse, error)
+	GetEventsForMonth(ctx context.Context, in *PeriodRequest, opts ...grpc.CallOption) (*EventListResponse, error)
+	GetFreeBusy(ctx context.Context, in *FreeBusyRequest, opts ...grpc.CallOption) (*FreeBusyResponse, error)
+	SearchEvents(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*EventListResponse, error)
+	Batch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	CreateCalendar(ctx context.Context, in *CreateCalendarRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	UpdateCalendar(ctx context.Context, in *UpdateCalendarRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	DeleteCalendar(ctx context.Context, in *DeleteCalendarRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	GetCalendars(ctx context.Context, in *CalendarsRequest, opts ...grpc.CallOption) (*CalendarListResponse, error)
 }
 
 type serviceClient struct {
@@ -450,7 +1926,43 @@ func (c *serviceClient) DeleteEvent(ctx context.Context, in *DeleteEventRequest,
 	return out, nil
 }
 
-func (c *serviceClient) GetEventsForDay(ctx context.Context, in *Nothing, opts ...grpc.CallOption) (*EventListResponse, error) {
+func (c *serviceClient) RestoreEvent(ctx context.Context, in *RestoreEventRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, "/grpc.Service/RestoreEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetTrash(ctx context.Context, in *TrashRequest, opts ...grpc.CallOption) (*EventListResponse, error) {
+	out := new(EventListResponse)
+	err := c.cc.Invoke(ctx, "/grpc.Service/GetTrash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetEventHistory(ctx context.Context, in *EventHistoryRequest, opts ...grpc.CallOption) (*EventHistoryResponse, error) {
+	out := new(EventHistoryResponse)
+	err := c.cc.Invoke(ctx, "/grpc.Service/GetEventHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) RespondToInvitation(ctx context.Context, in *RespondToInvitationRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, "/grpc.Service/RespondToInvitation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetEventsForDay(ctx context.Context, in *PeriodRequest, opts ...grpc.CallOption) (*EventListResponse, error) {
 	out := new(EventListResponse)
 	err := c.cc.Invoke(ctx, "/grpc.Service/GetEventsForDay", in, out, opts...)
 	if err != nil {
@@ -459,7 +1971,7 @@ func (c *serviceClient) GetEventsForDay(ctx context.Context, in *Nothing, opts .
 	return out, nil
 }
 
-func (c *serviceClient) GetEventsForWeek(ctx context.Context, in *Nothing, opts ...grpc.CallOption) (*EventListResponse, error) {
+func (c *serviceClient) GetEventsForWeek(ctx context.Context, in *PeriodRequest, opts ...grpc.CallOption) (*EventListResponse, error) {
 	out := new(EventListResponse)
 	err := c.cc.Invoke(ctx, "/grpc.Service/GetEventsForWeek", in, out, opts...)
 	if err != nil {
@@ -468,7 +1980,7 @@ func (c *serviceClient) GetEventsForWeek(ctx context.Context, in *Nothing, opts
 	return out, nil
 }
 
-func (c *serviceClient) GetEventsForMonth(ctx context.Context, in *Nothing, opts ...grpc.CallOption) (*EventListResponse, error) {
+func (c *serviceClient) GetEventsForMonth(ctx context.Context, in *PeriodRequest, opts ...grpc.CallOption) (*EventListResponse, error) {
 	out := new(EventListResponse)
 	err := c.cc.Invoke(ctx, "/grpc.Service/GetEventsForMonth", in, out, opts...)
 	if err != nil {
@@ -477,14 +1989,88 @@ func (c *serviceClient) GetEventsForMonth(ctx context.Context, in *Nothing, opts
 	return out, nil
 }
 
+func (c *serviceClient) GetFreeBusy(ctx context.Context, in *FreeBusyRequest, opts ...grpc.CallOption) (*FreeBusyResponse, error) {
+	out := new(FreeBusyResponse)
+	err := c.cc.Invoke(ctx, "/grpc.Service/GetFreeBusy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) SearchEvents(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*EventListResponse, error) {
+	out := new(EventListResponse)
+	err := c.cc.Invoke(ctx, "/grpc.Service/SearchEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) Batch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, "/grpc.Service/Batch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) CreateCalendar(ctx context.Context, in *CreateCalendarRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, "/grpc.Service/CreateCalendar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) UpdateCalendar(ctx context.Context, in *UpdateCalendarRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, "/grpc.Service/UpdateCalendar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) DeleteCalendar(ctx context.Context, in *DeleteCalendarRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, "/grpc.Service/DeleteCalendar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetCalendars(ctx context.Context, in *CalendarsRequest, opts ...grpc.CallOption) (*CalendarListResponse, error) {
+	out := new(CalendarListResponse)
+	err := c.cc.Invoke(ctx, "/grpc.Service/GetCalendars", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ServiceServer is the server API for Service service.
 type ServiceServer interface {
 	CreateEvent(context.Context, *CreateEventRequest) (*SimpleResponse, error)
 	UpdateEvent(context.Context, *UpdateEventRequest) (*SimpleResponse, error)
 	DeleteEvent(context.Context, *DeleteEventRequest) (*SimpleResponse, error)
-	GetEventsForDay(context.Context, *Nothing) (*EventListResponse, error)
-	GetEventsForWeek(context.Context, *Nothing) (*EventListResponse, error)
-	GetEventsForMonth(context.Context, *Nothing) (*EventListResponse, error)
+	RestoreEvent(context.Context, *RestoreEventRequest) (*SimpleResponse, error)
+	GetTrash(context.Context, *TrashRequest) (*EventListResponse, error)
+	GetEventHistory(context.Context, *EventHistoryRequest) (*EventHistoryResponse, error)
+	RespondToInvitation(context.Context, *RespondToInvitationRequest) (*SimpleResponse, error)
+	GetEventsForDay(context.Context, *PeriodRequest) (*EventListResponse, error)
+	GetEventsForWeek(context.Context, *PeriodRequest) (*EventListResponse, error)
+	GetEventsForMonth(context.Context, *PeriodRequest) (*EventListResponse, error)
+	GetFreeBusy(context.Context, *FreeBusyRequest) (*FreeBusyResponse, error)
+	SearchEvents(context.Context, *SearchRequest) (*EventListResponse, error)
+	Batch(context.Context, *BatchRequest) (*BatchResponse, error)
+	CreateCalendar(context.Context, *CreateCalendarRequest) (*SimpleResponse, error)
+	UpdateCalendar(context.Context, *UpdateCalendarRequest) (*SimpleResponse, error)
+	DeleteCalendar(context.Context, *DeleteCalendarRequest) (*SimpleResponse, error)
+	GetCalendars(context.Context, *CalendarsRequest) (*CalendarListResponse, error)
 }
 
 // UnimplementedServiceServer can be embedded to have forward compatible implementations.
@@ -500,15 +2086,48 @@ func (*UnimplementedServiceServer) UpdateEvent(ctx context.Context, req *UpdateE
 func (*UnimplementedServiceServer) DeleteEvent(ctx context.Context, req *DeleteEventRequest) (*SimpleResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteEvent not implemented")
 }
-func (*UnimplementedServiceServer) GetEventsForDay(ctx context.Context, req *Nothing) (*EventListResponse, error) {
+func (*UnimplementedServiceServer) RestoreEvent(ctx context.Context, req *RestoreEventRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreEvent not implemented")
+}
+func (*UnimplementedServiceServer) GetTrash(ctx context.Context, req *TrashRequest) (*EventListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrash not implemented")
+}
+func (*UnimplementedServiceServer) GetEventHistory(ctx context.Context, req *EventHistoryRequest) (*EventHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEventHistory not implemented")
+}
+func (*UnimplementedServiceServer) RespondToInvitation(ctx context.Context, req *RespondToInvitationRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RespondToInvitation not implemented")
+}
+func (*UnimplementedServiceServer) GetEventsForDay(ctx context.Context, req *PeriodRequest) (*EventListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetEventsForDay not implemented")
 }
-func (*UnimplementedServiceServer) GetEventsForWeek(ctx context.Context, req *Nothing) (*EventListResponse, error) {
+func (*UnimplementedServiceServer) GetEventsForWeek(ctx context.Context, req *PeriodRequest) (*EventListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetEventsForWeek not implemented")
 }
-func (*UnimplementedServiceServer) GetEventsForMonth(ctx context.Context, req *Nothing) (*EventListResponse, error) {
+func (*UnimplementedServiceServer) GetEventsForMonth(ctx context.Context, req *PeriodRequest) (*EventListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetEventsForMonth not implemented")
 }
+func (*UnimplementedServiceServer) GetFreeBusy(ctx context.Context, req *FreeBusyRequest) (*FreeBusyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFreeBusy not implemented")
+}
+func (*UnimplementedServiceServer) SearchEvents(ctx context.Context, req *SearchRequest) (*EventListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchEvents not implemented")
+}
+func (*UnimplementedServiceServer) Batch(ctx context.Context, req *BatchRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Batch not implemented")
+}
+func (*UnimplementedServiceServer) CreateCalendar(ctx context.Context, req *CreateCalendarRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCalendar not implemented")
+}
+func (*UnimplementedServiceServer) UpdateCalendar(ctx context.Context, req *UpdateCalendarRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCalendar not implemented")
+}
+func (*UnimplementedServiceServer) DeleteCalendar(ctx context.Context, req *DeleteCalendarRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCalendar not implemented")
+}
+func (*UnimplementedServiceServer) GetCalendars(ctx context.Context, req *CalendarsRequest) (*CalendarListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCalendars not implemented")
+}
 
 func RegisterServiceServer(s *grpc.Server, srv ServiceServer) {
 	s.RegisterService(&_Service_serviceDesc, srv)
@@ -568,8 +2187,80 @@ func _Service_DeleteEvent_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Service_RestoreEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).RestoreEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Service/RestoreEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).RestoreEvent(ctx, req.(*RestoreEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Service/GetTrash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetTrash(ctx, req.(*TrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetEventHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EventHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetEventHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Service/GetEventHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetEventHistory(ctx, req.(*EventHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_RespondToInvitation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RespondToInvitationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).RespondToInvitation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Service/RespondToInvitation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).RespondToInvitation(ctx, req.(*RespondToInvitationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Service_GetEventsForDay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Nothing)
+	in := new(PeriodRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -581,13 +2272,13 @@ func _Service_GetEventsForDay_Handler(srv interface{}, ctx context.Context, dec
 		FullMethod: "/grpc.Service/GetEventsForDay",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).GetEventsForDay(ctx, req.(*Nothing))
+		return srv.(ServiceServer).GetEventsForDay(ctx, req.(*PeriodRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
 func _Service_GetEventsForWeek_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Nothing)
+	in := new(PeriodRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -599,13 +2290,13 @@ func _Service_GetEventsForWeek_Handler(srv interface{}, ctx context.Context, dec
 		FullMethod: "/grpc.Service/GetEventsForWeek",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).GetEventsForWeek(ctx, req.(*Nothing))
+		return srv.(ServiceServer).GetEventsForWeek(ctx, req.(*PeriodRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
 func _Service_GetEventsForMonth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Nothing)
+	in := new(PeriodRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -617,7 +2308,133 @@ func _Service_GetEventsForMonth_Handler(srv interface{}, ctx context.Context, de
 		FullMethod: "/grpc.Service/GetEventsForMonth",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).GetEventsForMonth(ctx, req.(*Nothing))
+		return srv.(ServiceServer).GetEventsForMonth(ctx, req.(*PeriodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetFreeBusy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FreeBusyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetFreeBusy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Service/GetFreeBusy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetFreeBusy(ctx, req.(*FreeBusyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_SearchEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).SearchEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Service/SearchEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).SearchEvents(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_Batch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).Batch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Service/Batch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).Batch(ctx, req.(*BatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_CreateCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).CreateCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Service/CreateCalendar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).CreateCalendar(ctx, req.(*CreateCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_UpdateCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).UpdateCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Service/UpdateCalendar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).UpdateCalendar(ctx, req.(*UpdateCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_DeleteCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).DeleteCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Service/DeleteCalendar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).DeleteCalendar(ctx, req.(*DeleteCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetCalendars_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CalendarsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetCalendars(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Service/GetCalendars",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetCalendars(ctx, req.(*CalendarsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -638,6 +2455,22 @@ var _Service_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteEvent",
 			Handler:    _Service_DeleteEvent_Handler,
 		},
+		{
+			MethodName: "RestoreEvent",
+			Handler:    _Service_RestoreEvent_Handler,
+		},
+		{
+			MethodName: "GetTrash",
+			Handler:    _Service_GetTrash_Handler,
+		},
+		{
+			MethodName: "GetEventHistory",
+			Handler:    _Service_GetEventHistory_Handler,
+		},
+		{
+			MethodName: "RespondToInvitation",
+			Handler:    _Service_RespondToInvitation_Handler,
+		},
 		{
 			MethodName: "GetEventsForDay",
 			Handler:    _Service_GetEventsForDay_Handler,
@@ -650,6 +2483,34 @@ var _Service_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetEventsForMonth",
 			Handler:    _Service_GetEventsForMonth_Handler,
 		},
+		{
+			MethodName: "GetFreeBusy",
+			Handler:    _Service_GetFreeBusy_Handler,
+		},
+		{
+			MethodName: "SearchEvents",
+			Handler:    _Service_SearchEvents_Handler,
+		},
+		{
+			MethodName: "Batch",
+			Handler:    _Service_Batch_Handler,
+		},
+		{
+			MethodName: "CreateCalendar",
+			Handler:    _Service_CreateCalendar_Handler,
+		},
+		{
+			MethodName: "UpdateCalendar",
+			Handler:    _Service_UpdateCalendar_Handler,
+		},
+		{
+			MethodName: "DeleteCalendar",
+			Handler:    _Service_DeleteCalendar_Handler,
+		},
+		{
+			MethodName: "GetCalendars",
+			Handler:    _Service_GetCalendars_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api.proto",
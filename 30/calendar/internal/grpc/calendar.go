@@ -1,14 +1,17 @@
 package grpc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
 	"strings"
+	"time"
 )
 
 var ErrorNotFound = errors.New("event not found")
+var ErrorInvalidPeriod = errors.New("end of period must be after start")
 
 type ErrorEventListErrors struct {
 	errs []error
@@ -32,8 +35,10 @@ func (e *ErrorEventListErrors) Error() string {
 
 // Calendar structure for work inside grpc package
 // Clean architecture approach - not working with inner biz logic layer directly
+// Methods take context of request, it is passed to storage, so cancelled request cancels query of storage
 type Calendar struct {
 	storage entities.Storage // for now it is inner biz entity itself, for future there will be storage interface
+	now     func() time.Time // inject now time for validation of new events, need to tests
 }
 
 // Constructor
@@ -43,26 +48,84 @@ func NewCalendar(storage entities.Storage) (*Calendar, error) {
 	}
 	return &Calendar{
 		storage: storage,
+		now:     time.Now,
 	}, nil
 }
 
+// Calendar that deals only with events of owner, empty owner means events of all owners
+func (c *Calendar) ForOwner(owner string) *Calendar {
+	return &Calendar{
+		storage: c.storage.ForOwner(owner),
+		now:     c.now,
+	}
+}
+
+// Run fn with calendar of transactional view of storage, changes made through calendar of fn are applied all or nothing
+// If storage doesn't support transactions return entities.ErrTxNotSupported
+func (c *Calendar) WithTx(ctx context.Context, fn func(tx *Calendar) error) error {
+	transactor, ok := c.storage.(entities.Transactor)
+	if !ok {
+		return entities.ErrTxNotSupported
+	}
+	return transactor.WithTx(ctx, func(tx entities.Storage) error {
+		return fn(&Calendar{
+			storage: tx,
+			now:     c.now,
+		})
+	})
+}
+
 // Add Event
-func (c *Calendar) AddEvent(event *Event) (int, error) {
-	calendarEvent, err := convertToCalendarEvent(event)
+// Return *entities.ErrInvalidEvent error if event is invalid (see entities.ValidateNewEvent)
+func (c *Calendar) AddEvent(ctx context.Context, event *Event) (int, error) {
+	calendarEvent, err := c.convertToNewCalendarEvent(event)
 	if err != nil {
 		return 0, err
 	}
-	return c.storage.AddEvent(*calendarEvent)
+	return c.storage.AddEvent(ctx, *calendarEvent)
+}
+
+// Add Event if its date is not busy by other events, otherwise return *entities.ErrDateBusy error
+func (c *Calendar) AddEventIfNotBusy(ctx context.Context, event *Event) (int, error) {
+	calendarEvent, err := c.convertToNewCalendarEvent(event)
+	if err != nil {
+		return 0, err
+	}
+	err = entities.CheckDateBusy(ctx, c.storage, *calendarEvent, 0)
+	if err != nil {
+		return 0, err
+	}
+	return c.storage.AddEvent(ctx, *calendarEvent)
 }
 
 // Update Event
-func (c *Calendar) UpdateEvent(id int, event *Event) error {
-	calendarEvent, err := convertToCalendarEvent(event)
+// Return *entities.ErrInvalidEvent error if event is invalid (see entities.ValidateEvent)
+func (c *Calendar) UpdateEvent(ctx context.Context, id int, event *Event) error {
+	calendarEvent, err := convertToValidCalendarEvent(event)
+	if err != nil {
+		return err
+	}
+
+	err = c.storage.UpdateEvent(ctx, id, *calendarEvent)
+	if err != nil {
+		return fmt.Errorf("couldn't update event in storage: %w", err)
+	}
+	return nil
+}
+
+// Update Event if its new date is not busy by other events, otherwise return *entities.ErrDateBusy error
+func (c *Calendar) UpdateEventIfNotBusy(ctx context.Context, id int, event *Event) error {
+	calendarEvent, err := convertToValidCalendarEvent(event)
+	if err != nil {
+		return err
+	}
+
+	err = entities.CheckDateBusy(ctx, c.storage, *calendarEvent, id)
 	if err != nil {
 		return err
 	}
 
-	err = c.storage.UpdateEvent(id, *calendarEvent)
+	err = c.storage.UpdateEvent(ctx, id, *calendarEvent)
 	if err != nil {
 		return fmt.Errorf("couldn't update event in storage: %w", err)
 	}
@@ -70,21 +133,128 @@ func (c *Calendar) UpdateEvent(id int, event *Event) error {
 }
 
 // Delete Event
-func (c *Calendar) DeleteEvent(id int) error {
-	err := c.storage.DeleteEvent(id)
+func (c *Calendar) DeleteEvent(ctx context.Context, id int) error {
+	err := c.storage.DeleteEvent(ctx, id)
 	if err != nil {
 		return fmt.Errorf("couldn't delete event from storage: %w", err)
 	}
 	return nil
 }
 
+// Restore event from trash
+func (c *Calendar) RestoreEvent(ctx context.Context, id int) error {
+	err := c.storage.RestoreEvent(ctx, id)
+	if err != nil {
+		return fmt.Errorf("couldn't restore event from trash: %w", err)
+	}
+	return nil
+}
+
+// Response of attendee (with email) to invitation to event, status is one of entities.AttendeeStatus* constants
+func (c *Calendar) RespondToInvitation(ctx context.Context, id int, email string, status string) error {
+	err := c.storage.RespondToInvitation(ctx, id, email, status)
+	if err != nil {
+		return fmt.Errorf("couldn't respond to invitation: %w", err)
+	}
+	return nil
+}
+
+// Get events in trash, the most recently deleted first
+// Method try return max events that could be returned
+func (c *Calendar) GetTrashedEvents(ctx context.Context) ([]*Event, error) {
+	calendarEvents, err := c.storage.GetTrashedEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*Event
+	var convertErrors []error
+	var listErr error
+
+	for _, calendarEvent := range calendarEvents {
+		event, err := convertFromCalendarEvent(calendarEvent)
+		if err != nil {
+			convertErrors = append(convertErrors, err)
+		} else {
+			events = append(events, event)
+		}
+	}
+
+	if len(convertErrors) > 0 {
+		listErr = &ErrorEventListErrors{
+			errs: convertErrors,
+		}
+	}
+
+	return events, listErr
+}
+
+// Get history of changes of event (including event in trash or purged one), the oldest first
+func (c *Calendar) GetEventHistory(ctx context.Context, id int) ([]*AuditEntry, error) {
+	calendarEntries, err := c.storage.GetEventHistory(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get history of event from storage: %w", err)
+	}
+	return convertFromCalendarAuditEntries(calendarEntries)
+}
+
+// Add calendar of events, return id of new calendar
+// Return one of entities.Err*Calendar* errors if name is invalid (see entities.ValidateCalendar)
+func (c *Calendar) AddCalendar(ctx context.Context, name string) (int, error) {
+	calendar := entities.NewCalendar(name)
+	err := entities.ValidateCalendar(calendar)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := c.storage.AddCalendar(ctx, calendar)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't add calendar in storage: %w", err)
+	}
+	return id, nil
+}
+
+// Rename calendar of events
+// Return one of entities.Err*Calendar* errors if name is invalid (see entities.ValidateCalendar)
+func (c *Calendar) UpdateCalendar(ctx context.Context, id int, name string) error {
+	calendar := entities.NewCalendar(name)
+	err := entities.ValidateCalendar(calendar)
+	if err != nil {
+		return err
+	}
+
+	err = c.storage.UpdateCalendar(ctx, id, calendar)
+	if err != nil {
+		return fmt.Errorf("couldn't update calendar in storage: %w", err)
+	}
+	return nil
+}
+
+// Delete calendar with all its events
+func (c *Calendar) DeleteCalendar(ctx context.Context, id int) error {
+	err := c.storage.DeleteCalendar(ctx, id)
+	if err != nil {
+		return fmt.Errorf("couldn't delete calendar from storage: %w", err)
+	}
+	return nil
+}
+
+// Get all calendars of events sorted by id
+func (c *Calendar) GetCalendars(ctx context.Context) ([]*CalendarInfo, error) {
+	calendarCalendars, err := c.storage.GetCalendars(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get calendars from storage: %w", err)
+	}
+	return convertFromCalendarCalendars(calendarCalendars), nil
+}
+
 // Get one event
-func (c *Calendar) GetEvent(id int) (*Event, error) {
+func (c *Calendar) GetEvent(ctx context.Context, id int) (*Event, error) {
 	if id <= 0 {
 		return nil, ErrorNotFound
 	}
 
-	calendarEvent, err := c.storage.GetEvent(id)
+	calendarEvent, err := c.storage.GetEvent(ctx, id)
 	if err == entities.StorageErrorEventNotFound {
 		return nil, ErrorNotFound
 	}
@@ -99,8 +269,8 @@ func (c *Calendar) GetEvent(id int) (*Event, error) {
 }
 
 // Get all events
-func (c *Calendar) GetAllEvents() ([]*Event, error) {
-	calendarEvents, err := c.storage.GetAllEvents()
+func (c *Calendar) GetAllEvents(ctx context.Context) ([]*Event, error) {
+	calendarEvents, err := c.storage.GetAllEvents(ctx)
 
 	if err != nil {
 		return nil, err
@@ -129,13 +299,14 @@ func (c *Calendar) GetAllEvents() ([]*Event, error) {
 
 // Get all events that started in period (*Period struct) sorted by Less method of events
 // Nils has special meaning - no boundary for range period
+// If calendarIds are passed only events of these calendars are got
 // Return slice of events and slice of errors
 // Method try return max events that could be returned
-func (c *Calendar) GetEventsByPeriod(period *Period) ([]*Event, error) {
+func (c *Calendar) GetEventsByPeriod(ctx context.Context, period *Period, calendarIds ...int) ([]*Event, error) {
 	if period == nil {
-		return c.GetEventsByTimestampsPeriod(nil, nil)
+		return c.getEventsByTimestampsPeriod(ctx, nil, nil, time.UTC, calendarIds)
 	} else {
-		return c.GetEventsByTimestampsPeriod(period.start, period.end)
+		return c.getEventsByTimestampsPeriod(ctx, period.start, period.end, period.location, calendarIds)
 	}
 }
 
@@ -144,30 +315,77 @@ func (c *Calendar) GetEventsByPeriod(period *Period) ([]*Event, error) {
 // Nil has special meaning - no boundary for range period
 // Return slice of events and slice of errors
 // Method try return max events that could be returned
-func (c *Calendar) GetEventsByTimestampsPeriod(start *timestamp.Timestamp, end *timestamp.Timestamp) ([]*Event, error) {
+func (c *Calendar) GetEventsByTimestampsPeriod(ctx context.Context, start *timestamp.Timestamp, end *timestamp.Timestamp) ([]*Event, error) {
+	return c.getEventsByTimestampsPeriod(ctx, start, end, time.UTC, nil)
+}
+
+// Inner implementation of GetEventsByTimestampsPeriod, loc is time zone of period
+// Empty calendarIds means events of all calendars
+func (c *Calendar) getEventsByTimestampsPeriod(ctx context.Context, start *timestamp.Timestamp, end *timestamp.Timestamp, loc *time.Location, calendarIds []int) ([]*Event, error) {
+	startTime, endTime, err := convertToCalendarPeriod(start, end, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	calendarEvents, err := c.storage.GetEventsByPeriod(ctx, startTime, endTime, calendarIds...)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertFromCalendarEvents(calendarEvents)
+}
+
+// Get page of events that started in period (*Period struct) ordered by start and id, at most limit events
+// cursor is empty for the first page or next cursor of previous page, invalid cursor is entities.ErrInvalidCursor
+// Return slice of events, cursor of next page (empty for the last page) and error as GetEventsByPeriod
+func (c *Calendar) GetEventsPageByPeriod(ctx context.Context, period *Period, limit int, cursor string, calendarIds ...int) ([]*Event, string, error) {
+	var startTime, endTime *entities.DateTime
+	if period != nil {
+		var err error
+		startTime, endTime, err = convertToCalendarPeriod(period.start, period.end, period.location)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	page, err := c.storage.GetEventsByPeriodPage(ctx, startTime, endTime, limit, cursor, calendarIds...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	events, err := convertFromCalendarEvents(page.Events)
+	return events, page.NextCursor, err
+}
+
+// Convert boundaries of period to local times in loc, nil is kept as nil (no boundary)
+func convertToCalendarPeriod(start *timestamp.Timestamp, end *timestamp.Timestamp, loc *time.Location) (*entities.DateTime, *entities.DateTime, error) {
 	var startTime, endTime *entities.DateTime
 
 	if start != nil {
 		var err error
 		startTime, err = convertToCalendarEventTime(start)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		localStart := startTime.In(loc)
+		startTime = &localStart
 	}
 
 	if end != nil {
 		var err error
 		endTime, err = convertToCalendarEventTime(end)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		localEnd := endTime.In(loc)
+		endTime = &localEnd
 	}
 
-	calendarEvents, err := c.storage.GetEventsByPeriod(startTime, endTime)
-	if err != nil {
-		return nil, err
-	}
+	return startTime, endTime, nil
+}
 
+// Convert events of storage, events that could not be converted are skipped and reported by ErrorEventListErrors
+func convertFromCalendarEvents(calendarEvents []entities.Event) ([]*Event, error) {
 	if len(calendarEvents) == 0 {
 		return nil, nil
 	}
@@ -194,8 +412,96 @@ func (c *Calendar) GetEventsByTimestampsPeriod(start *timestamp.Timestamp, end *
 	return events, listErr
 }
 
+// Search events by words in name, nil start or end is no boundary, days of period are local days in loc
+// Events are sorted by relevance, at most limit events are returned
+// If storage doesn't support search return entities.ErrSearchNotSupported
+func (c *Calendar) SearchEvents(ctx context.Context, query string, start *timestamp.Timestamp, end *timestamp.Timestamp, loc *time.Location, limit int) ([]*Event, error) {
+	searcher, ok := c.storage.(entities.Searcher)
+	if !ok {
+		return nil, entities.ErrSearchNotSupported
+	}
+
+	startTime, endTime, err := convertToCalendarPeriod(start, end, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	calendarEvents, err := searcher.SearchEvents(ctx, query, startTime, endTime, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertFromCalendarEvents(calendarEvents)
+}
+
+// Get merged busy intervals of events in period [start, end), days of period are local days in loc
+// If minFreeMinutes > 0 free gaps within working hours not shorter than minFreeMinutes are returned too
+func (c *Calendar) GetFreeBusy(ctx context.Context, start *timestamp.Timestamp, end *timestamp.Timestamp, loc *time.Location, minFreeMinutes int, hours entities.WorkingHours) (*FreeBusyResponse, error) {
+	startTime, err := convertToCalendarEventTime(start)
+	if err != nil {
+		return nil, err
+	}
+
+	endTime, err := convertToCalendarEventTime(end)
+	if err != nil {
+		return nil, err
+	}
+
+	if !startTime.Less(*endTime) {
+		return nil, ErrorInvalidPeriod
+	}
+
+	busy, err := entities.GetBusyIntervals(ctx, c.storage, startTime.In(loc), endTime.In(loc))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &FreeBusyResponse{}
+
+	response.Busy, err = convertFromCalendarIntervals(busy)
+	if err != nil {
+		return nil, err
+	}
+
+	if minFreeMinutes > 0 {
+		free := entities.FreeIntervals(busy, startTime.In(loc), endTime.In(loc), hours, time.Duration(minFreeMinutes)*time.Minute)
+		response.Free, err = convertFromCalendarIntervals(free)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
 // Get total number of events in entities
 func (c *Calendar) getEventsTotalCount() int {
-	cnt, _ := c.storage.Count()
+	cnt, _ := c.storage.Count(context.Background())
 	return cnt
 }
+
+// Inner Helper that helps convert grpc.Event to valid entities.Event
+func convertToValidCalendarEvent(event *Event) (*entities.Event, error) {
+	calendarEvent, err := convertToCalendarEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	err = entities.ValidateEvent(*calendarEvent)
+	if err != nil {
+		return nil, err
+	}
+	return calendarEvent, nil
+}
+
+// Inner Helper that helps convert grpc.Event to valid new entities.Event
+func (c *Calendar) convertToNewCalendarEvent(event *Event) (*entities.Event, error) {
+	calendarEvent, err := convertToCalendarEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	err = entities.ValidateNewEvent(*calendarEvent, c.now())
+	if err != nil {
+		return nil, err
+	}
+	return calendarEvent, nil
+}
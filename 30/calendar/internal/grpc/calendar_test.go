@@ -1,9 +1,11 @@
 package grpc
 
 import (
+	"context"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/memory"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewCalendar(t *testing.T) {
@@ -63,14 +65,14 @@ func TestUpdateEvent(t *testing.T) {
 		End:   ts(2019, 10, 16, 1, 0),
 	}
 
-	err := service.UpdateEvent(id, event2)
+	err := service.UpdateEvent(context.Background(), id, event2)
 
 	if err != nil {
 		t.Errorf("must not be happened error on update: %s\n", err)
 		return
 	}
 
-	event, err := service.GetEvent(id)
+	event, err := service.GetEvent(context.Background(), id)
 	if err == ErrorNotFound {
 		t.Errorf("event with id = %d not found on entities service", id)
 		return
@@ -84,12 +86,12 @@ func TestUpdateEvent(t *testing.T) {
 		t.Errorf("\nevent info not updated\nexpected be:\n%+v\ngot:\n%+v\n", event2, event)
 	}
 
-	err = service.UpdateEvent(0, &Event{})
+	err = service.UpdateEvent(context.Background(), 0, &Event{})
 	if err == nil {
 		t.Error("update by id = 0 must return error")
 	}
 
-	err = service.UpdateEvent(1000, &Event{})
+	err = service.UpdateEvent(context.Background(), 1000, &Event{})
 	if err == nil {
 		t.Error("update by id of not existed event must return error")
 	}
@@ -120,17 +122,17 @@ func TestDeleteEvent(t *testing.T) {
 		return
 	}
 
-	err := service.DeleteEvent(0)
+	err := service.DeleteEvent(context.Background(), 0)
 	if err == nil {
 		t.Error("delete by id = 0 must return error")
 	}
 
-	err = service.DeleteEvent(1000)
+	err = service.DeleteEvent(context.Background(), 1000)
 	if err == nil {
 		t.Error("delete by id of not existed event must return error")
 	}
 
-	err = service.DeleteEvent(id1)
+	err = service.DeleteEvent(context.Background(), id1)
 	if err != nil {
 		t.Errorf("delete by id = %d must not return error: %s", id1, err)
 	}
@@ -139,7 +141,7 @@ func TestDeleteEvent(t *testing.T) {
 		t.Error("delete actually not happened")
 	}
 
-	err = service.DeleteEvent(id2)
+	err = service.DeleteEvent(context.Background(), id2)
 	if err != nil {
 		t.Errorf("delete by id = %d must not return error: %s", id2, err)
 	}
@@ -159,12 +161,12 @@ func TestGetEvents(t *testing.T) {
 		return
 	}
 
-	allEvents, _ := calendar.GetAllEvents()
+	allEvents, _ := calendar.GetAllEvents(context.Background())
 	if len(allEvents) != 7 {
 		t.Error("7 events must be in entities and GetAllEvents must return all of them")
 	}
 
-	allEvents2, _ := calendar.GetEventsByTimestampsPeriod(nil, nil)
+	allEvents2, _ := calendar.GetEventsByTimestampsPeriod(context.Background(), nil, nil)
 	if len(allEvents2) != 7 {
 		t.Error("7 events must be in entities and GetEventsByTimestampsPeriod(nil, nil) must return all of them")
 	}
@@ -174,7 +176,7 @@ func TestGetEvents(t *testing.T) {
 	}
 
 	eventTime := ts(2019, 11, 18, 8, 0)
-	eventList, _ := calendar.GetEventsByTimestampsPeriod(nil, eventTime)
+	eventList, _ := calendar.GetEventsByTimestampsPeriod(context.Background(), nil, eventTime)
 
 	if len(eventList) != 1 {
 		t.Errorf("Must be returned one event")
@@ -185,7 +187,7 @@ func TestGetEvents(t *testing.T) {
 	}
 
 	eventTime = ts(2019, 11, 24, 8, 0)
-	eventList, _ = calendar.GetEventsByTimestampsPeriod(eventTime, nil)
+	eventList, _ = calendar.GetEventsByTimestampsPeriod(context.Background(), eventTime, nil)
 
 	if len(eventList) != 1 {
 		t.Errorf("Must be returned one event")
@@ -197,7 +199,7 @@ func TestGetEvents(t *testing.T) {
 
 	startEventTime := ts(2019, 11, 20, 8, 0)
 	endEventTime := ts(2019, 11, 22, 8, 0)
-	eventList, _ = calendar.GetEventsByTimestampsPeriod(startEventTime, endEventTime)
+	eventList, _ = calendar.GetEventsByTimestampsPeriod(context.Background(), startEventTime, endEventTime)
 
 	if len(eventList) != 3 {
 		t.Errorf("Must be returned 3 events")
@@ -215,7 +217,7 @@ func TestGetEvents(t *testing.T) {
 
 	startEventTime = ts(2019, 11, 20, 8, 1)
 	endEventTime = ts(2019, 11, 20, 9, 59)
-	eventList, _ = calendar.GetEventsByTimestampsPeriod(startEventTime, endEventTime)
+	eventList, _ = calendar.GetEventsByTimestampsPeriod(context.Background(), startEventTime, endEventTime)
 
 	if len(eventList) != 0 {
 		t.Errorf("Must be returned 0 events")
@@ -227,7 +229,7 @@ func TestGetEvents(t *testing.T) {
 // If expectedCount input argument is greater and equal 0 check getEventsTotalCount of service
 // If adding is successful return int ID > 0, otherwise return int < 0
 func addEvent(t *testing.T, calendar *Calendar, event *Event, expectedCount int) int {
-	id, err := calendar.AddEvent(event)
+	id, err := calendar.AddEvent(context.Background(), event)
 
 	if err != nil {
 		t.Errorf("must not be error if add new event: %s", err)
@@ -297,5 +299,11 @@ func addFixedListOfEvents(t *testing.T, calendar *Calendar) {
 func NewTestCalendar() *Calendar {
 	storage := memory.NewStorage()
 	calendar, _ := NewCalendar(storage)
+	calendar.now = testNow
 	return calendar
 }
+
+// Now time of tests, events of tests are not in the past relative to it
+func testNow() time.Time {
+	return time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+}
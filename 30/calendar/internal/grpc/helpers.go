@@ -1,6 +1,7 @@
 package grpc
 
 import (
+	"fmt"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
@@ -9,7 +10,12 @@ import (
 
 // Helper for create new timestamp by 5 int components make sense for this package and application
 func NewTimestamp(year, month, day, hour, minute int) (*timestamp.Timestamp, error) {
-	t := time.Date(year, time.Month(month), day, hour, minute, 0, 0, time.UTC)
+	return NewTimestampInLocation(year, month, day, hour, minute, time.UTC)
+}
+
+// The same as NewTimestamp but components are local wall clock time in location
+func NewTimestampInLocation(year, month, day, hour, minute int, loc *time.Location) (*timestamp.Timestamp, error) {
+	t := time.Date(year, time.Month(month), day, hour, minute, 0, 0, loc)
 	return ptypes.TimestampProto(t)
 }
 
@@ -26,6 +32,11 @@ func convertToCalendarEventTime(tspb *timestamp.Timestamp) (*entities.DateTime,
 // Inner Helper that helps convert grpc.Event to entities.Event
 func convertToCalendarEvent(event *Event) (*entities.Event, error) {
 
+	loc, err := entities.LoadLocation(event.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone `%s`", event.Timezone)
+	}
+
 	startTime, err := convertToCalendarEventTime(event.Start)
 	if err != nil {
 		return nil, err
@@ -38,31 +49,157 @@ func convertToCalendarEvent(event *Event) (*entities.Event, error) {
 
 	calendarEvent := entities.NewEventWithId(int(event.Id), event.Name, *startTime, *endTime)
 
+	if event.AllDay {
+		calendarEvent = entities.WithAllDay(
+			calendarEvent,
+			entities.ConvertDateFromTime(startTime.Time()),
+			entities.ConvertDateFromTime(endTime.Time()),
+		)
+	}
+
+	recurrence, err := convertToCalendarRecurrence(event.Rrule, event.Exdates)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurrence != nil {
+		calendarEvent = entities.WithRecurrence(calendarEvent, recurrence)
+	}
+
+	calendarEvent = entities.WithDescription(calendarEvent, event.Description)
+	calendarEvent = entities.WithPlace(calendarEvent, event.Location)
+	calendarEvent = entities.WithOrganizer(calendarEvent, event.Organizer)
+	calendarEvent = entities.WithAttendees(calendarEvent, event.Attendees)
+	calendarEvent = entities.WithColor(calendarEvent, event.Color)
+	calendarEvent = entities.WithVersion(calendarEvent, int(event.Version))
+	calendarEvent = entities.WithCalendarId(calendarEvent, int(event.CalendarId))
+
+	var reminders []entities.Reminder
+	for _, beforeMinutes := range event.Reminders {
+		reminders = append(reminders, entities.NewReminder(int(beforeMinutes)))
+	}
+	calendarEvent = entities.WithReminders(calendarEvent, reminders)
+
+	calendarEvent = entities.WithLocation(calendarEvent, loc)
+
 	return &calendarEvent, nil
 }
 
+// Inner helper that convert rrule and exdates into entities.Recurrence
+// Empty rrule means not recurring event, so nil returned
+func convertToCalendarRecurrence(rrule string, exdates []*timestamp.Timestamp) (*entities.Recurrence, error) {
+	if rrule == "" {
+		return nil, nil
+	}
+
+	recurrence, err := entities.ParseRecurrence(rrule)
+	if err != nil {
+		return nil, err
+	}
+
+	var dates []entities.DateTime
+	for _, exdate := range exdates {
+		date, err := convertToCalendarEventTime(exdate)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, *date)
+	}
+
+	return recurrence.WithExDates(dates), nil
+}
+
 // Convert from inner Event entity (entities.Event) to grpc.Event
+// For all day event start and end are midnights of first and last days of event
 func convertFromCalendarEvent(calendarEvent entities.Event) (*Event, error) {
-	start, err := ptypes.TimestampProto(calendarEvent.Start().Time())
+	startTime := calendarEvent.Start().Time()
+	endTime := calendarEvent.End().Time()
+
+	if calendarEvent.IsAllDay() {
+		startTime = calendarEvent.StartDate().Time()
+		endTime = calendarEvent.EndDate().Time()
+	}
+
+	start, err := ptypes.TimestampProto(startTime)
 	if err != nil {
 		return nil, err
 	}
 
-	end, err := ptypes.TimestampProto(calendarEvent.End().Time())
+	end, err := ptypes.TimestampProto(endTime)
 	if err != nil {
 		return nil, err
 	}
 
 	event := &Event{
-		Id:    int32(calendarEvent.Id()),
-		Name:  calendarEvent.Name(),
-		Start: start,
-		End:   end,
+		Id:     int32(calendarEvent.Id()),
+		Name:   calendarEvent.Name(),
+		Start:  start,
+		End:    end,
+		AllDay: calendarEvent.IsAllDay(),
+
+		Description: calendarEvent.Description(),
+		Location:    calendarEvent.Place(),
+		Organizer:   calendarEvent.Organizer(),
+		Attendees:   calendarEvent.Attendees(),
+		Color:       calendarEvent.Color(),
+		Owner:       calendarEvent.Owner(),
+		Version:     int32(calendarEvent.Version()),
+		CalendarId:  int32(calendarEvent.CalendarId()),
+	}
+
+	if loc := calendarEvent.Location(); loc != time.UTC {
+		event.Timezone = loc.String()
+	}
+
+	for _, reminder := range calendarEvent.Reminders() {
+		event.Reminders = append(event.Reminders, int32(reminder.BeforeMinutes()))
+	}
+
+	for _, attendee := range calendarEvent.AttendeeList() {
+		event.AttendeeStatuses = append(event.AttendeeStatuses, &AttendeeStatus{
+			Email:  attendee.Email(),
+			Status: attendee.Status(),
+		})
+	}
+
+	if calendarEvent.IsDeleted() {
+		event.DeletedTime, err = ptypes.TimestampProto(calendarEvent.DeletedTime())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if recurrence := calendarEvent.Recurrence(); recurrence != nil {
+		event.Rrule = recurrence.String()
+		for _, exDate := range recurrence.ExDates() {
+			exdate, err := ptypes.TimestampProto(exDate.Time())
+			if err != nil {
+				return nil, err
+			}
+			event.Exdates = append(event.Exdates, exdate)
+		}
 	}
 
 	return event, nil
 }
 
+// Convert from inner intervals (entities.Interval) to grpc.Interval
+func convertFromCalendarIntervals(calendarIntervals []entities.Interval) ([]*Interval, error) {
+	var intervals []*Interval
+	for _, calendarInterval := range calendarIntervals {
+		start, err := ptypes.TimestampProto(calendarInterval.Start().Time())
+		if err != nil {
+			return nil, err
+		}
+		end, err := ptypes.TimestampProto(calendarInterval.End().Time())
+		if err != nil {
+			return nil, err
+		}
+		intervals = append(intervals, &Interval{Start: start, End: end})
+	}
+	return intervals, nil
+}
+
 // Is proto timestamps equals
 func isTimestampEquals(tspb1 *timestamp.Timestamp, tspb2 *timestamp.Timestamp) bool {
 	// as pointers
@@ -95,3 +232,41 @@ func ts(year, month, day, hour, minute int) *timestamp.Timestamp {
 	ts, _ := ptypes.TimestampProto(t)
 	return ts
 }
+
+// Convert from inner audit entries (entities.AuditEntry) to grpc.AuditEntry
+func convertFromCalendarAuditEntries(calendarEntries []entities.AuditEntry) ([]*AuditEntry, error) {
+	var entries []*AuditEntry
+	for _, calendarEntry := range calendarEntries {
+		t, err := ptypes.TimestampProto(calendarEntry.Time())
+		if err != nil {
+			return nil, err
+		}
+		entry := &AuditEntry{
+			Action: calendarEntry.Action(),
+			Actor:  calendarEntry.Actor(),
+			Time:   t,
+		}
+		for _, change := range calendarEntry.Changes() {
+			entry.Changes = append(entry.Changes, &FieldChange{
+				Field:  change.Field(),
+				Before: change.Before(),
+				After:  change.After(),
+			})
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Convert from inner calendars (entities.Calendar) to grpc.CalendarInfo
+func convertFromCalendarCalendars(calendarCalendars []entities.Calendar) []*CalendarInfo {
+	var calendars []*CalendarInfo
+	for _, calendarCalendar := range calendarCalendars {
+		calendars = append(calendars, &CalendarInfo{
+			Id:    int32(calendarCalendar.Id()),
+			Name:  calendarCalendar.Name(),
+			Owner: calendarCalendar.Owner(),
+		})
+	}
+	return calendars
+}
@@ -144,3 +144,43 @@ func TestIsEventEquals(t *testing.T) {
 		t.Error("Even2 must NOT be equal event3 (deep)")
 	}
 }
+
+func TestConvertAllDayEvent(t *testing.T) {
+	event := &Event{
+		Id:     100,
+		Name:   "Holidays",
+		Start:  ts(2019, 12, 31, 15, 0),
+		End:    ts(2020, 1, 2, 0, 0),
+		AllDay: true,
+	}
+
+	calendarEvent, err := convertToCalendarEvent(event)
+	if err != nil {
+		t.Fatalf("Must not be error on converting %s", err)
+	}
+
+	if !calendarEvent.IsAllDay() {
+		t.Fatal("Event must be all day")
+	}
+
+	expectedStart := "2019-12-31 00:00"
+	start := calendarEvent.Start().Format(datetimeFormat)
+	if start != expectedStart {
+		t.Errorf("Start must be %s instead of %s", expectedStart, start)
+	}
+
+	expectedEnd := "2020-01-02 23:59"
+	end := calendarEvent.End().Format(datetimeFormat)
+	if end != expectedEnd {
+		t.Errorf("End must be %s instead of %s", expectedEnd, end)
+	}
+
+	resultEvent, err := convertFromCalendarEvent(*calendarEvent)
+	if err != nil {
+		t.Fatalf("Must not be error on converting %s", err)
+	}
+
+	if !resultEvent.AllDay || !isTimestampEquals(resultEvent.Start, ts(2019, 12, 31, 0, 0)) || !isTimestampEquals(resultEvent.End, ts(2020, 1, 2, 0, 0)) {
+		t.Errorf("Unexpected converted event %+v", resultEvent)
+	}
+}
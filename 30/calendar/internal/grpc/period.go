@@ -7,33 +7,42 @@ import (
 
 // Period struct for get event lists by periods
 // start and end could be nil - means no boundary (-ies) of period
+// location is time zone of period, local days of all day events are compared with local days of period
 type Period struct {
-	start *timestamp.Timestamp
-	end   *timestamp.Timestamp
+	start    *timestamp.Timestamp
+	end      *timestamp.Timestamp
+	location *time.Location
 }
 
-// Constructor
+// Constructor, period is in UTC
 func NewPeriod(start *timestamp.Timestamp, end *timestamp.Timestamp) *Period {
+	return NewPeriodInLocation(start, end, time.UTC)
+}
+
+// Constructor of period in location
+func NewPeriodInLocation(start *timestamp.Timestamp, end *timestamp.Timestamp, loc *time.Location) *Period {
 	return &Period{
-		start: start,
-		end:   end,
+		start:    start,
+		end:      end,
+		location: loc,
 	}
 }
 
-//  Construct period of current day
+//  Construct period of current day, day is local day in location of now
 func NewDayPeriod(now time.Time) (*Period, error) {
-	start, err := NewTimestamp(now.Year(), int(now.Month()), now.Day(), 0, 0)
+	loc := now.Location()
+	start, err := NewTimestampInLocation(now.Year(), int(now.Month()), now.Day(), 0, 0, loc)
 	if err != nil {
 		return nil, err
 	}
-	end, err := NewTimestamp(now.Year(), int(now.Month()), now.Day(), 23, 59)
+	end, err := NewTimestampInLocation(now.Year(), int(now.Month()), now.Day(), 23, 59, loc)
 	if err != nil {
 		return nil, err
 	}
-	return NewPeriod(start, end), nil
+	return NewPeriodInLocation(start, end, loc), nil
 }
 
-// Construct period of current week
+// Construct period of current week in location of now
 func NewWeekPeriod(now time.Time) (*Period, error) {
 	nowWeek := now.Weekday()
 
@@ -48,33 +57,35 @@ func NewWeekPeriod(now time.Time) (*Period, error) {
 	monday := now.AddDate(0, 0, -shiftDays)
 	sunday := monday.AddDate(0, 0, 6)
 
-	start, err := NewTimestamp(monday.Year(), int(monday.Month()), monday.Day(), 0, 0)
+	loc := now.Location()
+	start, err := NewTimestampInLocation(monday.Year(), int(monday.Month()), monday.Day(), 0, 0, loc)
 	if err != nil {
 		return nil, err
 	}
-	end, err := NewTimestamp(sunday.Year(), int(sunday.Month()), sunday.Day(), 23, 59)
+	end, err := NewTimestampInLocation(sunday.Year(), int(sunday.Month()), sunday.Day(), 23, 59, loc)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewPeriod(start, end), nil
+	return NewPeriodInLocation(start, end, loc), nil
 }
 
-// // Construct period of current month
+// // Construct period of current month in location of now
 func NewMonthPeriod(now time.Time) (*Period, error) {
 	firstDayInMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 	nextMonth := firstDayInMonth.AddDate(0, 1, 0)
 	lastDayInMonth := nextMonth.AddDate(0, 0, -1)
 
-	startTime, err := NewTimestamp(firstDayInMonth.Year(), int(firstDayInMonth.Month()), firstDayInMonth.Day(), 0, 0)
+	loc := now.Location()
+	startTime, err := NewTimestampInLocation(firstDayInMonth.Year(), int(firstDayInMonth.Month()), firstDayInMonth.Day(), 0, 0, loc)
 	if err != nil {
 		return nil, err
 	}
 
-	endTime, err := NewTimestamp(lastDayInMonth.Year(), int(lastDayInMonth.Month()), lastDayInMonth.Day(), 23, 59)
+	endTime, err := NewTimestampInLocation(lastDayInMonth.Year(), int(lastDayInMonth.Month()), lastDayInMonth.Day(), 23, 59, loc)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewPeriod(startTime, endTime), nil
+	return NewPeriodInLocation(startTime, endTime, loc), nil
 }
@@ -132,3 +132,21 @@ func TestGetMonthPeriod4(t *testing.T) {
 		t.Errorf("end must be %s insteadof %s", expectedEnd, period.end)
 	}
 }
+
+func TestGetDayPeriodInLocation(t *testing.T) {
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	now := time.Date(2019, 11, 24, 22, 0, 0, 0, time.UTC).In(moscow)
+	period, err := NewDayPeriod(now)
+	if err != nil {
+		t.Fatalf("must not error happened on constuction period %s\n", err)
+	}
+	// local midnight in Moscow is 21:00 of previous day in UTC
+	expectedStart := ts(2019, 11, 24, 21, 0)
+	expectedEnd := ts(2019, 11, 25, 20, 59)
+	if !isTimestampEquals(period.start, expectedStart) {
+		t.Errorf("start must be %s insteadof %s", expectedStart, period.start)
+	}
+	if !isTimestampEquals(period.end, expectedEnd) {
+		t.Errorf("end must be %s insteadof %s", expectedEnd, period.end)
+	}
+}
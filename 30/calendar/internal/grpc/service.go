@@ -2,11 +2,16 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/auth"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 	"net"
@@ -17,8 +22,10 @@ import (
 // Clean architecture approach - not working with inner biz logic layer directly
 type Service struct {
 	Calendar
-	logger *zap.SugaredLogger
-	port   string
+	logger   *zap.SugaredLogger
+	port     string
+	location *time.Location      // default time zone of requests
+	auth     *auth.Authenticator // authenticator of callers, nil means authentication is disabled
 
 	// inject now time for getEventsForDay/getEventsForWeek/getEventsForPeriod
 	// need to tests
@@ -26,22 +33,29 @@ type Service struct {
 }
 
 // Constructor
-func NewService(port string, storage entities.Storage, logger *zap.SugaredLogger) (*Service, error) {
+// location is default time zone of requests, nil means UTC
+// authenticator is authenticator of callers, nil means authentication is disabled and all events are shared
+func NewService(port string, storage entities.Storage, logger *zap.SugaredLogger, location *time.Location, authenticator *auth.Authenticator) (*Service, error) {
 	service, err := NewCalendar(storage)
 	if err != nil {
 		return nil, err
 	}
+	if location == nil {
+		location = time.UTC
+	}
 	return &Service{
 		Calendar: *service,
 		logger:   logger,
 		port:     port,
+		location: location,
+		auth:     authenticator,
 		now:      time.Now(),
 	}, nil
 }
 
 // Run grpc entities service
 func (service *Service) Run() {
-	s := grpc.NewServer()
+	s := grpc.NewServer(grpc.UnaryInterceptor(service.authInterceptor))
 	l, err := net.Listen("tcp", ":"+service.port)
 	if err != nil && service.logger != nil {
 		service.logger.Errorf("Service.Run, net listen, return error %s", err)
@@ -55,8 +69,8 @@ func (service *Service) Run() {
 }
 
 // Run new grpc entities service
-func RunService(port string, storage entities.Storage, logger *zap.SugaredLogger) error {
-	service, err := NewService(port, storage, logger)
+func RunService(port string, storage entities.Storage, logger *zap.SugaredLogger, location *time.Location, authenticator *auth.Authenticator) error {
+	service, err := NewService(port, storage, logger, location, authenticator)
 	if err != nil {
 		return err
 	}
@@ -64,81 +78,253 @@ func RunService(port string, storage entities.Storage, logger *zap.SugaredLogger
 	return nil
 }
 
+// Interceptor to authenticate callers by `x-api-key` metadata or `authorization` metadata with bearer token
+// Authenticated user is put into context of request
+// On failure return error with codes.Unauthenticated code
+func (service *Service) authInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if service.auth == nil {
+		return handler(ctx, req)
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	user, err := service.auth.Authenticate(firstMetadataValue(md, "x-api-key"), firstMetadataValue(md, "authorization"))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return handler(auth.WithUser(ctx, user), req)
+}
+
+// Calendar of authenticated user of request
+func (service *Service) calendarFor(ctx context.Context) *Calendar {
+	return service.Calendar.ForOwner(auth.UserFromContext(ctx))
+}
+
 // Create event service method (grpc remote call)
 // On success result is  "created %d" string
 // On invalid argument return error with codes.InvalidArgument code
+// If reject_conflicts is set and event overlaps other events return error with codes.FailedPrecondition code
+// If calendar_id is set and calendar is unknown return error with codes.NotFound code
 // On other cases return some another error
 func (service *Service) CreateEvent(ctx context.Context, request *CreateEventRequest) (*SimpleResponse, error) {
-	if request.Name == "" {
-		return nil, status.Error(codes.InvalidArgument, "name must not be empty")
+	id, err := createEvent(ctx, service.calendarFor(ctx), request)
+	if err != nil {
+		return nil, err
 	}
+	return &SimpleResponse{
+		Result: fmt.Sprintf("created %d", id),
+	}, nil
+}
+
+// Inner helper that creates event by request in calendar, errors are as of CreateEvent
+func createEvent(ctx context.Context, calendar *Calendar, request *CreateEventRequest) (int, error) {
 	if request.Start == nil {
-		return nil, status.Error(codes.InvalidArgument, "start date must not be empty")
+		return 0, status.Error(codes.InvalidArgument, "start date must not be empty")
 	}
 	if request.End == nil {
-		return nil, status.Error(codes.InvalidArgument, "end date must not be empty")
+		return 0, status.Error(codes.InvalidArgument, "end date must not be empty")
+	}
+	if err := validateRecurrence(request.Rrule, request.Exdates); err != nil {
+		return 0, err
+	}
+	if err := validateTimezone(request.Timezone); err != nil {
+		return 0, err
+	}
+	if err := validateEmails(request.Organizer, request.Attendees); err != nil {
+		return 0, err
 	}
 	event := &Event{
-		Name:  request.Name,
-		Start: request.Start,
-		End:   request.End,
+		Name:        request.Name,
+		Start:       request.Start,
+		End:         request.End,
+		Rrule:       request.Rrule,
+		Exdates:     request.Exdates,
+		AllDay:      request.AllDay,
+		Timezone:    request.Timezone,
+		Description: request.Description,
+		Location:    request.Location,
+		Organizer:   request.Organizer,
+		Attendees:   request.Attendees,
+		Color:       request.Color,
+		Reminders:   request.Reminders,
+		CalendarId:  request.CalendarId,
+	}
+	var id int
+	var err error
+	if request.RejectConflicts {
+		id, err = calendar.AddEventIfNotBusy(ctx, event)
+	} else {
+		id, err = calendar.AddEvent(ctx, event)
 	}
-	id, err := service.AddEvent(event)
 	if err != nil {
-		return nil, err
+		return 0, convertError(err)
 	}
-	return &SimpleResponse{
-		Result: fmt.Sprintf("created %d", id),
-	}, nil
+	return id, nil
 }
 
 // Update event service method (grpc remote call)
 // On success result is "updated" string
 // On invalid argument return error with codes.InvalidArgument code
+// If reject_conflicts is set and event overlaps other events return error with codes.FailedPrecondition code
+// If version is set and event was changed since then return error with codes.Aborted code
+// If calendar_id is set and calendar is unknown return error with codes.NotFound code
 // On other cases return some another error
 func (service *Service) UpdateEvent(ctx context.Context, request *UpdateEventRequest) (*SimpleResponse, error) {
+	err := updateEvent(ctx, service.calendarFor(ctx), request)
+	if err != nil {
+		return nil, err
+	}
+	return &SimpleResponse{
+		Result: "updated",
+	}, nil
+}
+
+// Inner helper that updates event by request in calendar, errors are as of UpdateEvent
+func updateEvent(ctx context.Context, calendar *Calendar, request *UpdateEventRequest) error {
 	id := request.GetId()
 	if id <= 0 {
-		return nil, status.Error(codes.InvalidArgument, "id must be greater 0")
-	}
-	if request.Name == "" {
-		return nil, status.Error(codes.InvalidArgument, "name must not be empty")
+		return status.Error(codes.InvalidArgument, "id must be greater 0")
 	}
 	if request.Start == nil {
-		return nil, status.Error(codes.InvalidArgument, "start date must not be empty")
+		return status.Error(codes.InvalidArgument, "start date must not be empty")
 	}
 	if request.End == nil {
-		return nil, status.Error(codes.InvalidArgument, "end date must not be empty")
+		return status.Error(codes.InvalidArgument, "end date must not be empty")
+	}
+	if err := validateRecurrence(request.Rrule, request.Exdates); err != nil {
+		return err
+	}
+	if err := validateTimezone(request.Timezone); err != nil {
+		return err
+	}
+	if err := validateEmails(request.Organizer, request.Attendees); err != nil {
+		return err
 	}
 	event := &Event{
-		Name:  request.Name,
-		Start: request.Start,
-		End:   request.End,
+		Name:        request.Name,
+		Start:       request.Start,
+		End:         request.End,
+		Rrule:       request.Rrule,
+		Exdates:     request.Exdates,
+		AllDay:      request.AllDay,
+		Timezone:    request.Timezone,
+		Description: request.Description,
+		Location:    request.Location,
+		Organizer:   request.Organizer,
+		Attendees:   request.Attendees,
+		Color:       request.Color,
+		Reminders:   request.Reminders,
+		Version:     request.Version,
+		CalendarId:  request.CalendarId,
 	}
-	err := service.Calendar.UpdateEvent(int(id), event)
+	var err error
+	if request.RejectConflicts {
+		err = calendar.UpdateEventIfNotBusy(ctx, int(id), event)
+	} else {
+		err = calendar.UpdateEvent(ctx, int(id), event)
+	}
+	return convertError(err)
+}
+
+// Delete event service method (grpc remote call)
+// On success result is "deleted" string
+// On invalid argument return error with codes.InvalidArgument code
+// On other cases return some another error
+func (service *Service) DeleteEvent(ctx context.Context, request *DeleteEventRequest) (*SimpleResponse, error) {
+	err := deleteEvent(ctx, service.calendarFor(ctx), request)
 	if err != nil {
 		return nil, err
 	}
 	return &SimpleResponse{
-		Result: "updated",
+		Result: "deleted",
 	}, nil
 }
 
-// Delete event service method (grpc remote call)
-// On success result is "deleted" string
+// Inner helper that deletes event by request in calendar, errors are as of DeleteEvent
+func deleteEvent(ctx context.Context, calendar *Calendar, request *DeleteEventRequest) error {
+	id := request.GetId()
+	if id <= 0 {
+		return status.Error(codes.InvalidArgument, "id must be greater 0")
+	}
+	return calendar.DeleteEvent(ctx, int(id))
+}
+
+// Restore event from trash service method (grpc remote call)
+// On success result is "restored"
 // On invalid argument return error with codes.InvalidArgument code
 // On other cases return some another error
-func (service *Service) DeleteEvent(ctx context.Context, request *DeleteEventRequest) (*SimpleResponse, error) {
+func (service *Service) RestoreEvent(ctx context.Context, request *RestoreEventRequest) (*SimpleResponse, error) {
 	id := request.GetId()
 	if id <= 0 {
 		return nil, status.Error(codes.InvalidArgument, "id must be greater 0")
 	}
-	err := service.Calendar.DeleteEvent(int(id))
+	err := service.calendarFor(ctx).RestoreEvent(ctx, int(id))
 	if err != nil {
 		return nil, err
 	}
 	return &SimpleResponse{
-		Result: "deleted",
+		Result: "restored",
+	}, nil
+}
+
+// Get events in trash service method (grpc remote call)
+// On full success result is list of events in trash, the most recently deleted first
+// On partial success (if only some events could be received) return as list as error about other events
+// Otherwise return some another error
+func (service *Service) GetTrash(ctx context.Context, request *TrashRequest) (*EventListResponse, error) {
+	events, err := service.calendarFor(ctx).GetTrashedEvents(ctx)
+	if events == nil && err != nil {
+		return nil, err
+	}
+	return &EventListResponse{
+		Events: events,
+	}, err
+}
+
+// Get history of event service method (grpc remote call)
+// On success result is list of audit entries, the oldest first
+// On invalid argument return error with codes.InvalidArgument code
+// If event not found return error with codes.NotFound code
+func (service *Service) GetEventHistory(ctx context.Context, request *EventHistoryRequest) (*EventHistoryResponse, error) {
+	id := request.GetId()
+	if id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id must be greater 0")
+	}
+	entries, err := service.calendarFor(ctx).GetEventHistory(ctx, int(id))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &EventHistoryResponse{
+		Entries: entries,
+	}, nil
+}
+
+// Respond to invitation to event service method (grpc remote call)
+// Attendee is authenticated user, without authentication it is email of request
+// Attendee is not owner of event, so event is looked up among events of all owners
+// On success result is "responded"
+// On invalid argument (e.g. unknown status) return error with codes.InvalidArgument code
+// If event or attendee not found return error with codes.NotFound code
+func (service *Service) RespondToInvitation(ctx context.Context, request *RespondToInvitationRequest) (*SimpleResponse, error) {
+	id := request.GetId()
+	if id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id must be greater 0")
+	}
+	email := auth.UserFromContext(ctx)
+	if email == "" {
+		email = request.GetEmail()
+	}
+	if email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+	err := entities.ValidateAttendeeStatus(request.GetStatus())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	err = service.Calendar.RespondToInvitation(ctx, int(id), email, request.GetStatus())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &SimpleResponse{
+		Result: "responded",
 	}, nil
 }
 
@@ -146,47 +332,375 @@ func (service *Service) DeleteEvent(ctx context.Context, request *DeleteEventReq
 // On full success result is list of events
 // On partial success (if only some events could be received) return as list as error about other events
 // Otherwise return some another error
-func (service *Service) GetEventsForDay(ctx context.Context, _ *Nothing) (*EventListResponse, error) {
-	period, err := NewDayPeriod(service.now)
+func (service *Service) GetEventsForDay(ctx context.Context, request *PeriodRequest) (*EventListResponse, error) {
+	loc, err := service.requestLocation(request.GetTz())
+	if err != nil {
+		return nil, err
+	}
+	period, err := NewDayPeriod(service.now.In(loc))
 	if err != nil {
 		return nil, err
 	}
-	return service.getEventsForPeriod(period)
+	return service.getEventsForPeriod(ctx, period, request)
 }
 
 // Get events for current week service method (grpc remote call)
 // On full success result is list of events
 // On partial success (if only some events could be received) return as list as error about other events
 // Otherwise return some another error
-func (service *Service) GetEventsForWeek(ctx context.Context, _ *Nothing) (*EventListResponse, error) {
-	period, err := NewWeekPeriod(service.now)
+func (service *Service) GetEventsForWeek(ctx context.Context, request *PeriodRequest) (*EventListResponse, error) {
+	loc, err := service.requestLocation(request.GetTz())
+	if err != nil {
+		return nil, err
+	}
+	period, err := NewWeekPeriod(service.now.In(loc))
 	if err != nil {
 		return nil, err
 	}
-	return service.getEventsForPeriod(period)
+	return service.getEventsForPeriod(ctx, period, request)
 }
 
 // Get events for current week service method (grpc remote call)
 // On full success result is list of events
 // On partial success (if only some events could be received) return as list as error about other events
 // Otherwise return some another error
-func (service *Service) GetEventsForMonth(ctx context.Context, _ *Nothing) (*EventListResponse, error) {
-	period, err := NewMonthPeriod(service.now)
+func (service *Service) GetEventsForMonth(ctx context.Context, request *PeriodRequest) (*EventListResponse, error) {
+	loc, err := service.requestLocation(request.GetTz())
+	if err != nil {
+		return nil, err
+	}
+	period, err := NewMonthPeriod(service.now.In(loc))
+	if err != nil {
+		return nil, err
+	}
+	return service.getEventsForPeriod(ctx, period, request)
+}
+
+// Get free/busy service method (grpc remote call)
+// Result is merged busy intervals of events in [start, end) and free gaps within working hours if min_free_minutes > 0
+// On invalid argument return error with codes.InvalidArgument code
+// Otherwise return some another error
+func (service *Service) GetFreeBusy(ctx context.Context, request *FreeBusyRequest) (*FreeBusyResponse, error) {
+	if request.Start == nil || request.End == nil {
+		return nil, status.Error(codes.InvalidArgument, "start and end must not be empty")
+	}
+	if request.MinFreeMinutes < 0 {
+		return nil, status.Error(codes.InvalidArgument, "min_free_minutes must not be less than 0")
+	}
+
+	loc, err := service.requestLocation(request.GetTz())
+	if err != nil {
+		return nil, err
+	}
+
+	workStart := request.WorkStart
+	if workStart == "" {
+		workStart = entities.DefaultWorkStart
+	}
+	workEnd := request.WorkEnd
+	if workEnd == "" {
+		workEnd = entities.DefaultWorkEnd
+	}
+	hours, err := entities.NewWorkingHours(workStart, workEnd)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	response, err := service.calendarFor(ctx).GetFreeBusy(ctx, request.Start, request.End, loc, int(request.MinFreeMinutes), hours)
+	if err == ErrorInvalidPeriod {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Batch service method (grpc remote call)
+// Operations are applied all or nothing, result is ids of events of operations in order of operations
+// On invalid operations (not from 1 to 1000 operations, operation without exactly one of create, update and delete)
+// return error with codes.InvalidArgument code
+// If operation fails return error with its code (as of CreateEvent, UpdateEvent and DeleteEvent)
+// and message that starts with index of operation
+// If storage doesn't support transactions return error with codes.Unimplemented code
+func (service *Service) Batch(ctx context.Context, request *BatchRequest) (*BatchResponse, error) {
+	operations := request.GetOperations()
+	if len(operations) == 0 || len(operations) > entities.MaxBatchOperations {
+		return nil, status.Errorf(codes.InvalidArgument, "operations must be from 1 to %d", entities.MaxBatchOperations)
+	}
+	for i, operation := range operations {
+		set := 0
+		for _, isSet := range []bool{operation.Create != nil, operation.Update != nil, operation.Delete != nil} {
+			if isSet {
+				set++
+			}
+		}
+		if set != 1 {
+			return nil, status.Errorf(codes.InvalidArgument, "operation %d: exactly one of create, update and delete must be set", i)
+		}
+	}
+
+	var ids []int32
+	err := service.calendarFor(ctx).WithTx(ctx, func(tx *Calendar) error {
+		ids = make([]int32, 0, len(operations))
+		for i, operation := range operations {
+			id, err := applyBatchOperation(ctx, tx, operation)
+			if err != nil {
+				return convertBatchOperationError(i, err)
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err == entities.ErrTxNotSupported {
+		return nil, status.Error(codes.Unimplemented, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &BatchResponse{
+		Ids: ids,
+	}, nil
+}
+
+// Search events service method (grpc remote call)
+// Result is list of events sorted by relevance
+// On empty query or invalid limit return error with codes.InvalidArgument code
+// If storage doesn't support search return error with codes.Unimplemented code
+func (service *Service) SearchEvents(ctx context.Context, request *SearchRequest) (*EventListResponse, error) {
+	loc, err := service.requestLocation(request.GetTz())
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int(request.GetLimit())
+	if limit < 0 || limit > entities.MaxPageLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be from 0 to %d", entities.MaxPageLimit)
+	}
+	if limit == 0 {
+		limit = entities.DefaultPageLimit
+	}
+
+	events, err := service.calendarFor(ctx).SearchEvents(ctx, request.GetQuery(), request.GetStart(), request.GetEnd(), loc, limit)
+	switch {
+	case err == entities.ErrEmptySearchQuery:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err == entities.ErrSearchNotSupported:
+		return nil, status.Error(codes.Unimplemented, err.Error())
+	case events == nil && err != nil:
+		return nil, err
+	}
+	return &EventListResponse{
+		Events: events,
+	}, err
+}
+
+// Create calendar service method (grpc remote call)
+// On success result is "created %d" string
+// On invalid name return error with codes.InvalidArgument code
+func (service *Service) CreateCalendar(ctx context.Context, request *CreateCalendarRequest) (*SimpleResponse, error) {
+	id, err := service.calendarFor(ctx).AddCalendar(ctx, request.GetName())
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return &SimpleResponse{
+		Result: fmt.Sprintf("created %d", id),
+	}, nil
+}
+
+// Rename calendar service method (grpc remote call)
+// On success result is "updated" string
+// On invalid argument return error with codes.InvalidArgument code
+// If calendar not found return error with codes.NotFound code
+func (service *Service) UpdateCalendar(ctx context.Context, request *UpdateCalendarRequest) (*SimpleResponse, error) {
+	id := request.GetId()
+	if id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id must be greater 0")
+	}
+	err := service.calendarFor(ctx).UpdateCalendar(ctx, int(id), request.GetName())
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return &SimpleResponse{
+		Result: "updated",
+	}, nil
+}
+
+// Delete calendar with all its events service method (grpc remote call)
+// On success result is "deleted" string
+// On invalid argument return error with codes.InvalidArgument code
+// If calendar not found return error with codes.NotFound code
+func (service *Service) DeleteCalendar(ctx context.Context, request *DeleteCalendarRequest) (*SimpleResponse, error) {
+	id := request.GetId()
+	if id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id must be greater 0")
+	}
+	err := service.calendarFor(ctx).DeleteCalendar(ctx, int(id))
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return &SimpleResponse{
+		Result: "deleted",
+	}, nil
+}
+
+// Get calendars service method (grpc remote call)
+// On success result is list of calendars sorted by id
+func (service *Service) GetCalendars(ctx context.Context, request *CalendarsRequest) (*CalendarListResponse, error) {
+	calendars, err := service.calendarFor(ctx).GetCalendars(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return service.getEventsForPeriod(period)
+	return &CalendarListResponse{
+		Calendars: calendars,
+	}, nil
 }
 
 // Helper for GetEventsFor* methods to reduce code duplication
-func (service *Service) getEventsForPeriod(period *Period) (*EventListResponse, error) {
-	events, err := service.Calendar.GetEventsByPeriod(period)
-	if events == nil {
+// Empty calendar ids of request means events of all calendars, events are got by page of request
+// Return error with codes.InvalidArgument code on invalid limit or cursor
+func (service *Service) getEventsForPeriod(ctx context.Context, period *Period, request *PeriodRequest) (*EventListResponse, error) {
+	var ids []int
+	for _, calendarId := range request.GetCalendarIds() {
+		ids = append(ids, int(calendarId))
+	}
+
+	limit := int(request.GetLimit())
+	if limit < 0 || limit > entities.MaxPageLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be from 0 to %d", entities.MaxPageLimit)
+	}
+	if limit == 0 {
+		limit = entities.DefaultPageLimit
+	}
+
+	events, nextCursor, err := service.calendarFor(ctx).GetEventsPageByPeriod(ctx, period, limit, request.GetCursor(), ids...)
+	if errors.Is(err, entities.ErrInvalidCursor) {
+		return nil, status.Error(codes.InvalidArgument, "invalid cursor, it must be next_cursor of previous page")
+	}
+	if events == nil && err != nil {
 		return nil, err
 	}
 	response := &EventListResponse{
-		Events: events,
+		Events:     events,
+		NextCursor: nextCursor,
 	}
 	return response, err
 
 }
+
+// Time zone of request by IANA name, empty name means default time zone of service
+// Return error with codes.InvalidArgument code if time zone is unknown
+func (service *Service) requestLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return service.location, nil
+	}
+	loc, err := entities.LoadLocation(tz)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown time zone `%s`", tz)
+	}
+	return loc, nil
+}
+
+// Convert error of calendar to error with proper status code
+// codes.InvalidArgument with errdetails.BadRequest details (invalid fields) for invalid event
+// codes.FailedPrecondition for busy date
+// codes.Aborted for version mismatch of updated event
+// codes.InvalidArgument for invalid name of calendar, codes.NotFound for unknown calendar
+// Other errors are returned as is
+func convertError(err error) error {
+	var invalidErr *entities.ErrInvalidEvent
+	if errors.As(err, &invalidErr) {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range invalidErr.Fields() {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field(),
+				Description: field.Error(),
+			})
+		}
+		st, detailsErr := status.New(codes.InvalidArgument, err.Error()).WithDetails(badRequest)
+		if detailsErr != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return st.Err()
+	}
+
+	var busyErr *entities.ErrDateBusy
+	if errors.As(err, &busyErr) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	if errors.Is(err, entities.StorageErrorVersionMismatch) {
+		return status.Error(codes.Aborted, err.Error())
+	}
+
+	if errors.Is(err, entities.ErrEmptyCalendarName) || errors.Is(err, entities.ErrCalendarNameTooLong) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if errors.Is(err, entities.StorageErrorCalendarNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return err
+}
+
+// Inner helper that applies operation of batch in calendar, return id of event of operation
+func applyBatchOperation(ctx context.Context, calendar *Calendar, operation *BatchOperation) (int32, error) {
+	switch {
+	case operation.Create != nil:
+		id, err := createEvent(ctx, calendar, operation.Create)
+		return int32(id), err
+	case operation.Update != nil:
+		return operation.Update.GetId(), updateEvent(ctx, calendar, operation.Update)
+	default:
+		return operation.Delete.GetId(), deleteEvent(ctx, calendar, operation.Delete)
+	}
+}
+
+// Inner helper that converts error of operation of batch to error with the same code and index of operation in message
+func convertBatchOperationError(index int, err error) error {
+	st := status.Convert(err)
+	return status.Errorf(st.Code(), "operation %d: %s", index, st.Message())
+}
+
+// First value of metadata key, empty string if there is no value
+func firstMetadataValue(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Validate time zone of event, return error with codes.InvalidArgument code if it is unknown
+func validateTimezone(tz string) error {
+	if _, err := entities.LoadLocation(tz); err != nil {
+		return status.Errorf(codes.InvalidArgument, "unknown time zone `%s`", tz)
+	}
+	return nil
+}
+
+// Validate emails of organizer (could be empty) and attendees, return error with codes.InvalidArgument code if they are invalid
+func validateEmails(organizer string, attendees []string) error {
+	if organizer != "" {
+		if err := entities.ValidateEmail(organizer); err != nil {
+			return status.Errorf(codes.InvalidArgument, "organizer: %s", err)
+		}
+	}
+	for _, attendee := range attendees {
+		if err := entities.ValidateEmail(attendee); err != nil {
+			return status.Errorf(codes.InvalidArgument, "attendees: %s", err)
+		}
+	}
+	return nil
+}
+
+// Validate recurrence arguments of request, return error with codes.InvalidArgument code if they are invalid
+func validateRecurrence(rrule string, exdates []*timestamp.Timestamp) error {
+	if rrule == "" && len(exdates) > 0 {
+		return status.Error(codes.InvalidArgument, "exdates could be set only for recurring event")
+	}
+	if _, err := convertToCalendarRecurrence(rrule, exdates); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
@@ -3,14 +3,21 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/auth"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/memory"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 	"net"
+	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -134,7 +141,7 @@ func TestUpdateEventOK(t *testing.T) {
 		t.Errorf("must be `updated` result on update")
 	}
 
-	event, err := service.GetEvent(id)
+	event, err := service.GetEvent(context.Background(), id)
 	if err != nil {
 		t.Errorf("must not be error on get %s", err)
 	}
@@ -179,7 +186,7 @@ func TestUpdateEventNotExisting(t *testing.T) {
 		t.Errorf("error must be nil instread of %+v", response)
 	}
 
-	expected := "couldn't update event in storage: event not found"
+	expected := "couldn't update event in storage: event not found in storage"
 	if status.Convert(err).Message() != expected {
 		t.Errorf("expected error `%s` instread of `%s`", expected, status.Convert(err).Message())
 	}
@@ -316,7 +323,7 @@ func TestDeleteEventOK(t *testing.T) {
 		t.Errorf("result must be `deleted` instread of %s", response.Result)
 	}
 
-	_, err = service.GetEvent(id)
+	_, err = service.GetEvent(context.Background(), id)
 	if err != ErrorNotFound {
 		t.Errorf("event might not deleted, expected error `%s` instread of `%s`", ErrorNotFound, err)
 	}
@@ -347,7 +354,7 @@ func TestDeleteEventNotExisting(t *testing.T) {
 		t.Errorf("error must be nil instread of %+v", response)
 	}
 
-	expected := "couldn't delete event from storage: event not found"
+	expected := "couldn't delete event from storage: event not found in storage"
 	if status.Convert(err).Message() != expected {
 		t.Errorf("expected error `%s` instread of `%s`", expected, status.Convert(err).Message())
 	}
@@ -386,7 +393,7 @@ func TestGetEventsForDay(t *testing.T) {
 	// set deterministic now time for test
 	service.now = time.Date(2019, 11, 21, 8, 0, 0, 0, time.UTC)
 
-	response, err := client.GetEventsForDay(context.Background(), &Nothing{})
+	response, err := client.GetEventsForDay(context.Background(), &PeriodRequest{})
 	if err != nil {
 		t.Errorf("must not be error instread of %s", err)
 		return
@@ -413,7 +420,7 @@ func TestGetEventsForWeek(t *testing.T) {
 	// set deterministic now time for test
 	service.now = time.Date(2019, 11, 21, 8, 0, 0, 0, time.UTC)
 
-	response, err := client.GetEventsForWeek(context.Background(), &Nothing{})
+	response, err := client.GetEventsForWeek(context.Background(), &PeriodRequest{})
 	if err != nil {
 		t.Errorf("must not be error instread of %s", err)
 		return
@@ -446,7 +453,7 @@ func TestGetEventsForMonth(t *testing.T) {
 	// set deterministic now time for test
 	service.now = time.Date(2019, 11, 21, 8, 0, 0, 0, time.UTC)
 
-	response, err := client.GetEventsForMonth(context.Background(), &Nothing{})
+	response, err := client.GetEventsForMonth(context.Background(), &PeriodRequest{})
 	if err != nil {
 		t.Errorf("must not be error instread of %s", err)
 		return
@@ -458,6 +465,326 @@ func TestGetEventsForMonth(t *testing.T) {
 	}
 }
 
+// Month is got by pages, pages follow each other without gaps and duplicates
+func TestGetEventsForMonthByPages(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+
+	addFixedListOfEvents(t, &service.Calendar)
+
+	service.now = time.Date(2019, 11, 21, 8, 0, 0, 0, time.UTC)
+
+	var ids []int32
+	request := &PeriodRequest{Limit: 3}
+	for page := 0; ; page++ {
+		response, err := client.GetEventsForMonth(context.Background(), request)
+		if err != nil {
+			t.Fatalf("must not be error instead of %s", err)
+		}
+		if page > 3 {
+			t.Fatalf("pages must end")
+		}
+		if response.NextCursor != "" && len(response.Events) != 3 {
+			t.Errorf("page that is not last must have 3 events instead of %d", len(response.Events))
+		}
+		for _, event := range response.Events {
+			ids = append(ids, event.Id)
+		}
+		if response.NextCursor == "" {
+			break
+		}
+		request.Cursor = response.NextCursor
+	}
+
+	if !reflect.DeepEqual(ids, []int32{1, 2, 3, 4, 5, 6, 7}) {
+		t.Errorf("events of pages must be %v instead of %v", []int32{1, 2, 3, 4, 5, 6, 7}, ids)
+	}
+
+	for _, request := range []*PeriodRequest{{Limit: -1}, {Limit: 1001}, {Cursor: "x"}} {
+		_, err := client.GetEventsForMonth(context.Background(), request)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("must be InvalidArgument error for %v instead of %v", request, err)
+		}
+	}
+}
+
+func TestCreateRecurringEvent(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+
+	request := &CreateEventRequest{
+		Name:    "Stand-up",
+		Start:   ts(2019, 11, 18, 10, 0),
+		End:     ts(2019, 11, 18, 10, 15),
+		Rrule:   "FREQ=DAILY;COUNT=10",
+		Exdates: []*timestamp.Timestamp{ts(2019, 11, 20, 10, 0)},
+	}
+
+	_, err := client.CreateEvent(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Create event must not return err %s", err)
+	}
+
+	// set deterministic now time for test
+	service.now = time.Date(2019, 11, 21, 8, 0, 0, 0, time.UTC)
+
+	response, err := client.GetEventsForWeek(context.Background(), &PeriodRequest{})
+	if err != nil {
+		t.Fatalf("must not be error instread of %s", err)
+	}
+
+	// 18 Nov - 24 Nov without 20 Nov
+	if len(response.Events) != 6 {
+		t.Errorf("event list must has 6 occurrences instead of %d", len(response.Events))
+		return
+	}
+
+	event := response.Events[2]
+	if !isTimestampEquals(event.Start, ts(2019, 11, 21, 10, 0)) || !isTimestampEquals(event.End, ts(2019, 11, 21, 10, 15)) {
+		t.Errorf("unexpected occurrence %s - %s", event.Start, event.End)
+	}
+	if event.Rrule != "FREQ=DAILY;COUNT=10" || len(event.Exdates) != 1 {
+		t.Errorf("occurrence must has recurrence of event, got `%s` %v", event.Rrule, event.Exdates)
+	}
+}
+
+func TestCreateEventInvalidRrule(t *testing.T) {
+	_, client := RunTestGrpcPipe(t)
+
+	request := &CreateEventRequest{
+		Name:  "Stand-up",
+		Start: ts(2019, 11, 18, 10, 0),
+		End:   ts(2019, 11, 18, 10, 15),
+		Rrule: "FREQ=DAILY;COUNT=-1",
+	}
+
+	response, err := client.CreateEvent(context.Background(), request)
+
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected status code %d (invalid argument) instread of %d", codes.InvalidArgument, status.Code(err))
+	}
+
+	if response != nil {
+		t.Errorf("response must be nil instread of %+v", response)
+	}
+}
+
+func TestGetEventsForDayInTimezone(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+
+	// 25 Nov 01:00 in Moscow
+	request := &CreateEventRequest{
+		Name:     "Night flight",
+		Start:    ts(2019, 11, 24, 22, 0),
+		End:      ts(2019, 11, 25, 2, 0),
+		Timezone: "Europe/Moscow",
+	}
+
+	_, err := client.CreateEvent(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Create event must not return err %s", err)
+	}
+
+	// set deterministic now time for test
+	service.now = time.Date(2019, 11, 25, 10, 0, 0, 0, time.UTC)
+
+	response, err := client.GetEventsForDay(context.Background(), &PeriodRequest{})
+	if err != nil {
+		t.Fatalf("must not be error instread of %s", err)
+	}
+	if len(response.Events) != 0 {
+		t.Errorf("event list must be empty for day in UTC instead of %d events", len(response.Events))
+	}
+
+	response, err = client.GetEventsForDay(context.Background(), &PeriodRequest{Tz: "Europe/Moscow"})
+	if err != nil {
+		t.Fatalf("must not be error instread of %s", err)
+	}
+	if len(response.Events) != 1 {
+		t.Fatalf("event list must has one event for day in Moscow instead of %d", len(response.Events))
+	}
+
+	event := response.Events[0]
+	if event.Timezone != "Europe/Moscow" || !isTimestampEquals(event.Start, ts(2019, 11, 24, 22, 0)) {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	_, err = client.GetEventsForDay(context.Background(), &PeriodRequest{Tz: "Mars/Olympus"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected status code %d (invalid argument) instread of %d", codes.InvalidArgument, status.Code(err))
+	}
+
+	request.Timezone = "Mars/Olympus"
+	_, err = client.CreateEvent(context.Background(), request)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected status code %d (invalid argument) instread of %d", codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestCreateEventWithDetails(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+
+	request := &CreateEventRequest{
+		Name:        "Planning",
+		Start:       ts(2019, 11, 25, 10, 0),
+		End:         ts(2019, 11, 25, 11, 0),
+		Description: "Quarterly planning",
+		Location:    "Room 42",
+		Organizer:   "boss@example.com",
+		Attendees:   []string{"alice@example.com", "bob@example.com"},
+		Color:       "#ff0000",
+	}
+
+	_, err := client.CreateEvent(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Create event must not return err %s", err)
+	}
+
+	service.now = time.Date(2019, 11, 25, 8, 0, 0, 0, time.UTC)
+
+	response, err := client.GetEventsForDay(context.Background(), &PeriodRequest{})
+	if err != nil {
+		t.Fatalf("must not be error instread of %s", err)
+	}
+	if len(response.Events) != 1 {
+		t.Fatalf("event list must has one event instead of %d", len(response.Events))
+	}
+
+	event := response.Events[0]
+	if event.Description != "Quarterly planning" || event.Location != "Room 42" || event.Organizer != "boss@example.com" ||
+		!reflect.DeepEqual(event.Attendees, request.Attendees) || event.Color != "#ff0000" {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	request.Attendees = []string{"bob"}
+	_, err = client.CreateEvent(context.Background(), request)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected status code %d (invalid argument) instread of %d", codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestCreateEventValidation(t *testing.T) {
+	_, client := RunTestGrpcPipe(t)
+
+	request := &CreateEventRequest{
+		Name:  strings.Repeat("x", 257),
+		Start: ts(2019, 9, 15, 20, 0),
+		End:   ts(2019, 9, 15, 19, 0),
+	}
+
+	_, err := client.CreateEvent(context.Background(), request)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected status code %d (invalid argument) instread of %d", codes.InvalidArgument, status.Code(err))
+	}
+
+	var fields []string
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.FieldViolations {
+				fields = append(fields, violation.Field)
+			}
+		}
+	}
+
+	expected := []string{"name", "end", "start"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected field violations %v instead of %v", expected, fields)
+	}
+}
+
+func TestCreateEventRejectConflicts(t *testing.T) {
+	_, client := RunTestGrpcPipe(t)
+
+	request := &CreateEventRequest{
+		Name:     "Booking",
+		Start:    ts(2019, 11, 25, 10, 0),
+		End:      ts(2019, 11, 25, 11, 0),
+		Location: "Room 42",
+	}
+
+	_, err := client.CreateEvent(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Create event must not return err %s", err)
+	}
+
+	request.Start = ts(2019, 11, 25, 10, 30)
+	request.End = ts(2019, 11, 25, 11, 30)
+	request.RejectConflicts = true
+	_, err = client.CreateEvent(context.Background(), request)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected status code %d (failed precondition) instread of %d", codes.FailedPrecondition, status.Code(err))
+	}
+
+	request.Start = ts(2019, 11, 25, 11, 0)
+	request.End = ts(2019, 11, 25, 12, 0)
+	_, err = client.CreateEvent(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Create back to back event must not return err %s", err)
+	}
+
+	_, err = client.UpdateEvent(context.Background(), &UpdateEventRequest{
+		Id:              1,
+		Name:            "Booking",
+		Start:           ts(2019, 11, 25, 10, 0),
+		End:             ts(2019, 11, 25, 11, 15),
+		RejectConflicts: true,
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected status code %d (failed precondition) instread of %d", codes.FailedPrecondition, status.Code(err))
+	}
+}
+
+func TestAuthenticatedAccess(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+	service.auth = auth.NewAuthenticator(map[string]string{"key-alice": "alice"}, "secret")
+	service.now = time.Date(2019, 11, 25, 8, 0, 0, 0, time.UTC)
+
+	request := &CreateEventRequest{
+		Name:  "Team sync",
+		Start: ts(2019, 11, 25, 10, 0),
+		End:   ts(2019, 11, 25, 11, 0),
+	}
+
+	_, err := client.CreateEvent(context.Background(), request)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected status code %d (unauthenticated) instread of %d", codes.Unauthenticated, status.Code(err))
+	}
+
+	aliceCtx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "key-alice")
+	_, err = client.CreateEvent(aliceCtx, request)
+	if err != nil {
+		t.Fatalf("Create event must not return err %s", err)
+	}
+
+	response, err := client.GetEventsForDay(aliceCtx, &PeriodRequest{})
+	if err != nil {
+		t.Fatalf("must not be error instread of %s", err)
+	}
+	if len(response.Events) != 1 || response.Events[0].Owner != "alice" {
+		t.Fatalf("alice must see her own event, got %+v", response.Events)
+	}
+
+	token, _ := service.auth.IssueToken("bob", time.Hour)
+	bobCtx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+
+	response, err = client.GetEventsForDay(bobCtx, &PeriodRequest{})
+	if err != nil {
+		t.Fatalf("must not be error instread of %s", err)
+	}
+	if len(response.Events) != 0 {
+		t.Errorf("bob must not see events of alice, got %d events", len(response.Events))
+	}
+
+	_, err = client.DeleteEvent(bobCtx, &DeleteEventRequest{Id: 1})
+	if err == nil {
+		t.Errorf("bob must not be able to delete event of alice")
+	}
+
+	badCtx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token+"x")
+	_, err = client.GetEventsForDay(badCtx, &PeriodRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected status code %d (unauthenticated) instread of %d", codes.Unauthenticated, status.Code(err))
+	}
+}
+
 func RunTestGrpcPipe(t *testing.T) (*Service, ServiceClient) {
 
 	listener := bufconn.Listen(bufConnSize)
@@ -519,14 +846,15 @@ func RunTestService(listener *bufconn.Listener) (service *Service, resultCh chan
 	resultCh = make(chan error, 1)
 
 	storage := memory.NewStorage()
-	service, err := NewService("", storage, nil)
+	service, err := NewService("", storage, nil, nil, nil)
 
 	if err != nil {
 		resultCh <- fmt.Errorf("test server exited with error %s", err)
 		return
 	}
+	service.Calendar.now = testNow
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(grpc.UnaryInterceptor(service.authInterceptor))
 	RegisterServiceServer(s, service)
 
 	go func() {
@@ -538,3 +866,512 @@ func RunTestService(listener *bufconn.Listener) (service *Service, resultCh chan
 
 	return
 }
+
+func TestCreateEventWithReminders(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+
+	request := &CreateEventRequest{
+		Name:      "Planning",
+		Start:     ts(2019, 11, 25, 10, 0),
+		End:       ts(2019, 11, 25, 11, 0),
+		Reminders: []int32{10, 60, 10},
+	}
+
+	_, err := client.CreateEvent(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Create event must not return err %s", err)
+	}
+
+	service.now = time.Date(2019, 11, 25, 8, 0, 0, 0, time.UTC)
+
+	response, err := client.GetEventsForDay(context.Background(), &PeriodRequest{})
+	if err != nil {
+		t.Fatalf("must not be error instread of %s", err)
+	}
+	if len(response.Events) != 1 {
+		t.Fatalf("event list must has one event instead of %d", len(response.Events))
+	}
+
+	expected := []int32{60, 10}
+	if !reflect.DeepEqual(response.Events[0].Reminders, expected) {
+		t.Errorf("reminders must be %v instead of %v", expected, response.Events[0].Reminders)
+	}
+
+	request.Reminders = []int32{-5}
+	_, err = client.CreateEvent(context.Background(), request)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected status code %d (invalid argument) instread of %d", codes.InvalidArgument, status.Code(err))
+	}
+}
+
+// Storage that hides optional features (search, transactions) of memory storage
+type storageWithoutFeatures struct {
+	entities.Storage
+}
+
+func (s storageWithoutFeatures) ForOwner(owner string) entities.Storage {
+	return storageWithoutFeatures{s.Storage.ForOwner(owner)}
+}
+
+func TestSearchEvents(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+
+	events := []*Event{
+		{Name: "Retro of sprint 41", Start: ts(2019, 11, 1, 10, 0), End: ts(2019, 11, 1, 11, 0)},
+		{Name: "Retro", Start: ts(2019, 11, 8, 10, 0), End: ts(2019, 11, 8, 11, 0)},
+		{Name: "Sprint planning", Start: ts(2019, 11, 15, 10, 0), End: ts(2019, 11, 15, 11, 0)},
+	}
+	for _, event := range events {
+		if _, err := service.AddEvent(context.Background(), event); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+	}
+
+	response, err := client.SearchEvents(context.Background(), &SearchRequest{Query: "retro"})
+	if err != nil {
+		t.Fatalf("must not be error instead of %s", err)
+	}
+	if len(response.Events) != 2 || response.Events[0].Name != "Retro" || response.Events[1].Name != "Retro of sprint 41" {
+		t.Errorf("must be found `Retro` and `Retro of sprint 41` instead of %v", response.Events)
+	}
+
+	response, err = client.SearchEvents(context.Background(), &SearchRequest{
+		Query: "retro",
+		Start: ts(2019, 10, 31, 0, 0),
+		End:   ts(2019, 11, 5, 0, 0),
+	})
+	if err != nil || len(response.Events) != 1 || response.Events[0].Name != "Retro of sprint 41" {
+		t.Errorf("must be found only `Retro of sprint 41` in period instead of %v, %v", response, err)
+	}
+
+	for _, request := range []*SearchRequest{{Query: " "}, {Query: "retro", Limit: -1}} {
+		_, err := client.SearchEvents(context.Background(), request)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("must be InvalidArgument error for %v instead of %v", request, err)
+		}
+	}
+
+	withoutSearch, _ := NewService("", storageWithoutFeatures{memory.NewStorage()}, nil, nil, nil)
+	_, err = withoutSearch.SearchEvents(context.Background(), &SearchRequest{Query: "retro"})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("must be Unimplemented error for storage without search instead of %v", err)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+
+	id, err := service.AddEvent(context.Background(), &Event{Name: "Meeting", Start: ts(2019, 11, 25, 10, 0), End: ts(2019, 11, 25, 11, 0)})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	deletedId, _ := service.AddEvent(context.Background(), &Event{Name: "Lunch", Start: ts(2019, 11, 25, 13, 0), End: ts(2019, 11, 25, 14, 0)})
+
+	response, err := client.Batch(context.Background(), &BatchRequest{Operations: []*BatchOperation{
+		{Create: &CreateEventRequest{Name: "Retro", Start: ts(2019, 11, 26, 10, 0), End: ts(2019, 11, 26, 11, 0)}},
+		{Update: &UpdateEventRequest{Id: int32(id), Name: "Planning", Start: ts(2019, 11, 25, 10, 0), End: ts(2019, 11, 25, 11, 0)}},
+		{Delete: &DeleteEventRequest{Id: int32(deletedId)}},
+	}})
+	if err != nil {
+		t.Fatalf("must not be error instead of %s", err)
+	}
+	if len(response.Ids) != 3 || response.Ids[1] != int32(id) || response.Ids[2] != int32(deletedId) {
+		t.Fatalf("must be ids of 3 events instead of %v", response.Ids)
+	}
+	if event, err := service.GetEvent(context.Background(), int(response.Ids[0])); err != nil || event.Name != "Retro" {
+		t.Errorf("event must be created, got %v %v", event, err)
+	}
+	if event, err := service.GetEvent(context.Background(), id); err != nil || event.Name != "Planning" {
+		t.Errorf("event must be updated, got %v %v", event, err)
+	}
+	if _, err := service.GetEvent(context.Background(), deletedId); err == nil {
+		t.Errorf("event must be deleted")
+	}
+
+	// the last operation fails, so nothing is applied
+	_, err = client.Batch(context.Background(), &BatchRequest{Operations: []*BatchOperation{
+		{Create: &CreateEventRequest{Name: "Demo", Start: ts(2019, 11, 27, 10, 0), End: ts(2019, 11, 27, 11, 0)}},
+		{Update: &UpdateEventRequest{Id: int32(id), Name: "Review", Start: ts(2019, 11, 25, 10, 0), End: ts(2019, 11, 25, 11, 0)}},
+		{Update: &UpdateEventRequest{Id: int32(id), Name: "Sync", Start: ts(2019, 11, 25, 10, 0), End: ts(2019, 11, 25, 11, 0), Version: 2}},
+	}})
+	if status.Code(err) != codes.Aborted || !strings.HasPrefix(status.Convert(err).Message(), "operation 2: ") {
+		t.Errorf("must be Aborted error of operation 2 instead of %v", err)
+	}
+	events, _ := service.GetAllEvents(context.Background())
+	if len(events) != 2 {
+		t.Errorf("must be 2 events after failed batch instead of %v", events)
+	}
+	if event, _ := service.GetEvent(context.Background(), id); event.Name != "Planning" || event.Version != 2 {
+		t.Errorf("event must not be updated by failed batch, got %v", event)
+	}
+
+	for _, request := range []*BatchRequest{
+		{},
+		{Operations: []*BatchOperation{{}}},
+		{Operations: []*BatchOperation{{Delete: &DeleteEventRequest{Id: 1}, Create: &CreateEventRequest{}}}},
+		{Operations: []*BatchOperation{{Delete: &DeleteEventRequest{}}}},
+		{Operations: []*BatchOperation{{Create: &CreateEventRequest{Name: "Demo"}}}},
+	} {
+		_, err := client.Batch(context.Background(), request)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("must be InvalidArgument error for %v instead of %v", request, err)
+		}
+	}
+
+	withoutTx, _ := NewService("", storageWithoutFeatures{memory.NewStorage()}, nil, nil, nil)
+	_, err = withoutTx.Batch(context.Background(), &BatchRequest{Operations: []*BatchOperation{{Delete: &DeleteEventRequest{Id: 1}}}})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("must be Unimplemented error for storage without transactions instead of %v", err)
+	}
+}
+
+func TestGetFreeBusy(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+
+	moscow, _ := entities.LoadLocation("Europe/Moscow")
+	tsm := func(day, hour, minute int) *timestamp.Timestamp {
+		tspb, _ := NewTimestampInLocation(2019, 11, day, hour, minute, moscow)
+		return tspb
+	}
+
+	events := []*Event{
+		{Name: "Morning", Start: tsm(25, 9, 0), End: tsm(25, 10, 0)},
+		{Name: "Stand-up", Start: tsm(25, 10, 0), End: tsm(25, 10, 30)},
+		{Name: "Lunch", Start: tsm(25, 12, 0), End: tsm(25, 13, 0)},
+		{Name: "Review", Start: tsm(25, 12, 30), End: tsm(25, 14, 0)},
+	}
+	for _, event := range events {
+		if _, err := service.AddEvent(context.Background(), event); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+	}
+
+	response, err := client.GetFreeBusy(context.Background(), &FreeBusyRequest{
+		Start:          tsm(25, 0, 0),
+		End:            tsm(26, 0, 0),
+		Tz:             "Europe/Moscow",
+		MinFreeMinutes: 60,
+	})
+	if err != nil {
+		t.Fatalf("must not be error instread of %s", err)
+	}
+
+	expectedBusy := [][2]*timestamp.Timestamp{{tsm(25, 9, 0), tsm(25, 10, 30)}, {tsm(25, 12, 0), tsm(25, 14, 0)}}
+	expectedFree := [][2]*timestamp.Timestamp{{tsm(25, 10, 30), tsm(25, 12, 0)}, {tsm(25, 14, 0), tsm(25, 18, 0)}}
+	if !isIntervalsEquals(response.Busy, expectedBusy) {
+		t.Errorf("unexpected busy intervals %v", response.Busy)
+	}
+	if !isIntervalsEquals(response.Free, expectedFree) {
+		t.Errorf("unexpected free intervals %v", response.Free)
+	}
+
+	_, err = client.GetFreeBusy(context.Background(), &FreeBusyRequest{Start: tsm(26, 0, 0), End: tsm(25, 0, 0)})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected status code %d (invalid argument) instread of %d", codes.InvalidArgument, status.Code(err))
+	}
+
+	_, err = client.GetFreeBusy(context.Background(), &FreeBusyRequest{Start: tsm(25, 0, 0), End: tsm(26, 0, 0), WorkStart: "18:00"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected status code %d (invalid argument) instread of %d", codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func isIntervalsEquals(intervals []*Interval, expected [][2]*timestamp.Timestamp) bool {
+	if len(intervals) != len(expected) {
+		return false
+	}
+	for i, interval := range intervals {
+		if !isTimestampEquals(interval.Start, expected[i][0]) || !isTimestampEquals(interval.End, expected[i][1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTrashAndRestoreEvent(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+
+	event1 := &Event{
+		Name:  "Do homework",
+		Start: ts(2019, 10, 15, 2, 0),
+		End:   ts(2019, 10, 15, 22, 0),
+	}
+
+	id := addEvent(t, &service.Calendar, event1, 1)
+	if id <= 0 {
+		return
+	}
+
+	_, err := client.DeleteEvent(context.Background(), &DeleteEventRequest{Id: int32(id)})
+	if err != nil {
+		t.Fatalf("must not be error on delete %s", err)
+	}
+
+	trashResponse, err := client.GetTrash(context.Background(), &TrashRequest{})
+	if err != nil {
+		t.Fatalf("must not be error on get trash %s", err)
+	}
+
+	if len(trashResponse.Events) != 1 || trashResponse.Events[0].Id != int32(id) || trashResponse.Events[0].DeletedTime == nil {
+		t.Fatalf("trash must have one deleted event with id = %d instead of %+v", id, trashResponse.Events)
+	}
+
+	response, err := client.RestoreEvent(context.Background(), &RestoreEventRequest{Id: int32(id)})
+	if err != nil {
+		t.Fatalf("must not be error on restore %s", err)
+	}
+
+	if response.Result != "restored" {
+		t.Errorf("result must be `restored` instread of %s", response.Result)
+	}
+
+	restored, err := service.GetEvent(context.Background(), id)
+	if err != nil || restored.DeletedTime != nil {
+		t.Errorf("event with id = %d must be restored from trash, got error %v", id, err)
+	}
+
+	// event is not in trash anymore
+	_, err = client.RestoreEvent(context.Background(), &RestoreEventRequest{Id: int32(id)})
+	expected := "couldn't restore event from trash: event not found in storage"
+	if status.Convert(err).Message() != expected {
+		t.Errorf("expected error `%s` instread of `%s`", expected, status.Convert(err).Message())
+	}
+
+	_, err = client.RestoreEvent(context.Background(), &RestoreEventRequest{Id: 0})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected code %s instead of %s", codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestUpdateEventVersion(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+
+	event1 := &Event{
+		Name:  "Do homework",
+		Start: ts(2019, 10, 15, 2, 0),
+		End:   ts(2019, 10, 15, 22, 0),
+	}
+
+	id := addEvent(t, &service.Calendar, event1, 1)
+	if id <= 0 {
+		return
+	}
+
+	event, _ := service.GetEvent(context.Background(), id)
+	if event.Version != 1 {
+		t.Fatalf("version of new event must be 1 instead of %d", event.Version)
+	}
+
+	request := &UpdateEventRequest{
+		Id:      int32(id),
+		Name:    "Watch movie",
+		Start:   event1.Start,
+		End:     event1.End,
+		Version: event.Version,
+	}
+
+	_, err := client.UpdateEvent(context.Background(), request)
+	if err != nil {
+		t.Fatalf("must not be error on update %s", err)
+	}
+
+	// event was changed since version 1
+	request.Name = "Read book"
+	_, err = client.UpdateEvent(context.Background(), request)
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("expected code %s instead of %s", codes.Aborted, status.Code(err))
+	}
+
+	event, _ = service.GetEvent(context.Background(), id)
+	if event.Name != "Watch movie" || event.Version != 2 {
+		t.Errorf("must be event `Watch movie` of version 2 instead of `%s` of version %d", event.Name, event.Version)
+	}
+}
+
+func TestGetEventHistory(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+
+	event1 := &Event{
+		Name:  "Do homework",
+		Start: ts(2019, 10, 15, 2, 0),
+		End:   ts(2019, 10, 15, 22, 0),
+	}
+
+	id := addEvent(t, &service.Calendar, event1, 1)
+	if id <= 0 {
+		return
+	}
+
+	_, err := client.UpdateEvent(context.Background(), &UpdateEventRequest{
+		Id:    int32(id),
+		Name:  "Watch movie",
+		Start: event1.Start,
+		End:   event1.End,
+	})
+	if err != nil {
+		t.Fatalf("must not be error on update %s", err)
+	}
+
+	response, err := client.GetEventHistory(context.Background(), &EventHistoryRequest{Id: int32(id)})
+	if err != nil {
+		t.Fatalf("must not be error on get history %s", err)
+	}
+
+	if len(response.Entries) != 2 || response.Entries[0].Action != "create" || response.Entries[1].Action != "update" {
+		t.Fatalf("history must have create and update entries instead of %+v", response.Entries)
+	}
+
+	changes := response.Entries[1].Changes
+	if len(changes) != 1 || changes[0].Field != "name" || changes[0].Before != "Do homework" || changes[0].After != "Watch movie" {
+		t.Errorf("update must change only name instead of %+v", changes)
+	}
+
+	_, err = client.GetEventHistory(context.Background(), &EventHistoryRequest{Id: 100})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected code %s instead of %s", codes.NotFound, status.Code(err))
+	}
+}
+
+func TestCalendars(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+
+	response, err := client.CreateCalendar(context.Background(), &CreateCalendarRequest{Name: "work"})
+	if err != nil {
+		t.Fatalf("must not be error on create calendar %s", err)
+	}
+	workId, _ := strconv.Atoi(strings.TrimPrefix(response.Result, "created "))
+	if workId <= 0 {
+		t.Fatalf("result must be `created <id>` instead of %s", response.Result)
+	}
+
+	personalId, _ := service.AddCalendar(context.Background(), "personal")
+
+	_, err = client.CreateCalendar(context.Background(), &CreateCalendarRequest{Name: ""})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected code %s instead of %s", codes.InvalidArgument, status.Code(err))
+	}
+
+	_, err = client.UpdateCalendar(context.Background(), &UpdateCalendarRequest{Id: int32(personalId), Name: "home"})
+	if err != nil {
+		t.Fatalf("must not be error on update calendar %s", err)
+	}
+
+	listResponse, err := client.GetCalendars(context.Background(), &CalendarsRequest{})
+	if err != nil {
+		t.Fatalf("must not be error on get calendars %s", err)
+	}
+	if len(listResponse.Calendars) != 2 || listResponse.Calendars[0].Name != "work" || listResponse.Calendars[1].Name != "home" {
+		t.Fatalf("calendars must be work and home instead of %+v", listResponse.Calendars)
+	}
+
+	_, err = client.CreateEvent(context.Background(), &CreateEventRequest{
+		Name:       "Meeting",
+		Start:      ts(2019, 11, 21, 10, 0),
+		End:        ts(2019, 11, 21, 11, 0),
+		CalendarId: int32(workId),
+	})
+	if err != nil {
+		t.Fatalf("must not be error on create event %s", err)
+	}
+
+	_, err = client.CreateEvent(context.Background(), &CreateEventRequest{
+		Name:       "Meeting",
+		Start:      ts(2019, 11, 21, 10, 0),
+		End:        ts(2019, 11, 21, 11, 0),
+		CalendarId: 1000,
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected code %s instead of %s", codes.NotFound, status.Code(err))
+	}
+
+	addEvent(t, &service.Calendar, &Event{Name: "Dinner", Start: ts(2019, 11, 21, 19, 0), End: ts(2019, 11, 21, 20, 0), CalendarId: int32(personalId)}, 2)
+	addEvent(t, &service.Calendar, &Event{Name: "Walk", Start: ts(2019, 11, 21, 12, 0), End: ts(2019, 11, 21, 13, 0)}, 3)
+
+	service.now = time.Date(2019, 11, 21, 8, 0, 0, 0, time.UTC)
+
+	names := func(calendarIds ...int32) []string {
+		response, err := client.GetEventsForDay(context.Background(), &PeriodRequest{CalendarIds: calendarIds})
+		if err != nil {
+			t.Fatalf("must not be error on get events %s", err)
+		}
+		var names []string
+		for _, event := range response.Events {
+			names = append(names, event.Name)
+		}
+		return names
+	}
+
+	if got := names(); !reflect.DeepEqual(got, []string{"Meeting", "Walk", "Dinner"}) {
+		t.Errorf("without filter all events must be got instead of %v", got)
+	}
+
+	if got := names(int32(workId), int32(personalId)); !reflect.DeepEqual(got, []string{"Meeting", "Dinner"}) {
+		t.Errorf("only events of work and home calendars must be got instead of %v", got)
+	}
+
+	_, err = client.DeleteCalendar(context.Background(), &DeleteCalendarRequest{Id: int32(personalId)})
+	if err != nil {
+		t.Fatalf("must not be error on delete calendar %s", err)
+	}
+
+	if got := names(); !reflect.DeepEqual(got, []string{"Meeting", "Walk"}) {
+		t.Errorf("events of deleted calendar must be deleted, left events %v", got)
+	}
+
+	_, err = client.DeleteCalendar(context.Background(), &DeleteCalendarRequest{Id: int32(personalId)})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected code %s instead of %s", codes.NotFound, status.Code(err))
+	}
+}
+
+func TestRespondToInvitation(t *testing.T) {
+	service, client := RunTestGrpcPipe(t)
+
+	_, err := client.CreateEvent(context.Background(), &CreateEventRequest{
+		Name:      "Meeting",
+		Start:     ts(2019, 11, 21, 10, 0),
+		End:       ts(2019, 11, 21, 11, 0),
+		Attendees: []string{"alice@example.com", "bob@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("must not be error on create event %s", err)
+	}
+
+	events, _ := service.GetAllEvents(context.Background())
+	if len(events) != 1 {
+		t.Fatalf("must be 1 event instead of %d", len(events))
+	}
+	id := events[0].Id
+
+	response, err := client.RespondToInvitation(context.Background(), &RespondToInvitationRequest{
+		Id:     id,
+		Email:  "bob@example.com",
+		Status: "tentative",
+	})
+	if err != nil {
+		t.Fatalf("must not be error on respond to invitation %s", err)
+	}
+	if response.Result != "responded" {
+		t.Errorf("result must be `responded` instead of %s", response.Result)
+	}
+
+	event, _ := service.GetEvent(context.Background(), int(id))
+	var statuses []string
+	for _, attendeeStatus := range event.AttendeeStatuses {
+		statuses = append(statuses, attendeeStatus.Email+" "+attendeeStatus.Status)
+	}
+	expectedStatuses := []string{"alice@example.com needs-action", "bob@example.com tentative"}
+	if !reflect.DeepEqual(statuses, expectedStatuses) {
+		t.Errorf("statuses of attendees must be %v instead of %v", expectedStatuses, statuses)
+	}
+
+	_, err = client.RespondToInvitation(context.Background(), &RespondToInvitationRequest{Id: id, Email: "bob@example.com", Status: "maybe"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected code %s instead of %s", codes.InvalidArgument, status.Code(err))
+	}
+
+	_, err = client.RespondToInvitation(context.Background(), &RespondToInvitationRequest{Id: id, Email: "carol@example.com", Status: "accepted"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected code %s instead of %s", codes.NotFound, status.Code(err))
+	}
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+)
+
+// Operations of batch
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+// Operation of batch: create, update or delete of event
+type BatchOperation struct {
+	Op              string `json:"op"`                        // one of BatchOp* constants
+	Id              int    `json:"id,omitempty"`              // id of updated or deleted event
+	Event           *Event `json:"event,omitempty"`           // created or updated event, version of event is expected version for update
+	RejectConflicts bool   `json:"rejectConflicts,omitempty"` // fail if created or updated event overlaps other events
+}
+
+// Error of operation of batch, batch is not applied at all
+type ErrorBatchOperation struct {
+	Index   int  // index of failed operation
+	Invalid bool // operation is malformed (unknown op, missing id or event, unparsable event), batch is not even started
+	err     error
+}
+
+func (e *ErrorBatchOperation) Error() string {
+	return fmt.Sprintf("operation %d: %s", e.Index, e.err)
+}
+
+func (e *ErrorBatchOperation) Unwrap() error {
+	return e.err
+}
+
+// Run fn with calendar of transactional view of storage, changes made through calendar of fn are applied all or nothing
+// If storage doesn't support transactions return entities.ErrTxNotSupported
+func (thisCalendar *Calendar) WithTx(ctx context.Context, fn func(tx *Calendar) error) error {
+	transactor, ok := thisCalendar.storage.(entities.Transactor)
+	if !ok {
+		return entities.ErrTxNotSupported
+	}
+	return transactor.WithTx(ctx, func(tx entities.Storage) error {
+		return fn(&Calendar{
+			storage: tx,
+			now:     thisCalendar.now,
+		})
+	})
+}
+
+// Apply operations in one transaction, return ids of events of operations
+// If any operation fails none of them is applied and *ErrorBatchOperation is returned
+func (thisCalendar *Calendar) ApplyBatch(ctx context.Context, operations []BatchOperation) ([]int, error) {
+	for i, operation := range operations {
+		err := validateBatchOperation(operation)
+		if err != nil {
+			return nil, &ErrorBatchOperation{Index: i, Invalid: true, err: err}
+		}
+	}
+
+	var ids []int
+	err := thisCalendar.WithTx(ctx, func(tx *Calendar) error {
+		ids = make([]int, 0, len(operations))
+		for i, operation := range operations {
+			id, err := tx.applyBatchOperation(ctx, operation)
+			if err != nil {
+				return &ErrorBatchOperation{Index: i, err: err}
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (thisCalendar *Calendar) applyBatchOperation(ctx context.Context, operation BatchOperation) (int, error) {
+	switch operation.Op {
+	case BatchOpCreate:
+		if operation.RejectConflicts {
+			return thisCalendar.AddEventIfNotBusy(ctx, operation.Event)
+		}
+		return thisCalendar.AddEvent(ctx, operation.Event)
+	case BatchOpUpdate:
+		if operation.RejectConflicts {
+			return operation.Id, thisCalendar.UpdateEventIfNotBusy(ctx, operation.Id, operation.Event)
+		}
+		return operation.Id, thisCalendar.UpdateEvent(ctx, operation.Id, operation.Event)
+	default:
+		return operation.Id, thisCalendar.DeleteEvent(ctx, operation.Id)
+	}
+}
+
+// Inner helper that check that operation could be applied: known op, id and event that could be converted
+func validateBatchOperation(operation BatchOperation) error {
+	switch operation.Op {
+	case BatchOpCreate:
+	case BatchOpUpdate, BatchOpDelete:
+		if operation.Id <= 0 {
+			return errors.New("id must be int greater than 0")
+		}
+	default:
+		return fmt.Errorf("unknown op `%s`, must be %s, %s or %s", operation.Op, BatchOpCreate, BatchOpUpdate, BatchOpDelete)
+	}
+
+	if operation.Op == BatchOpDelete {
+		return nil
+	}
+	if operation.Event == nil {
+		return errors.New("event must not be empty")
+	}
+	_, err := convertToCalendarEvent(operation.Event)
+	return err
+}
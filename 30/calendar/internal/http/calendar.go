@@ -1,15 +1,19 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"time"
 )
 
 // Calendar structure for work inside http package
 // Clean architecture approach - not working with inner biz logic layer directly
+// Methods take context of request, it is passed to storage, so cancelled request cancels query of storage
 type Calendar struct {
 	storage entities.Storage // for now it is inner biz entity itself, for future there will be storage interface
+	now     func() time.Time // inject now time for validation of new events, need to tests
 }
 
 // Constructor
@@ -19,27 +23,72 @@ func NewCalendar(storage entities.Storage) (*Calendar, error) {
 	}
 	return &Calendar{
 		storage: storage,
+		now:     time.Now,
 	}, nil
 }
 
+// Calendar that deals only with events of owner, empty owner means events of all owners
+func (thisCalendar *Calendar) ForOwner(owner string) *Calendar {
+	return &Calendar{
+		storage: thisCalendar.storage.ForOwner(owner),
+		now:     thisCalendar.now,
+	}
+}
+
 // Add Event
-func (thisCalendar *Calendar) AddEvent(event *Event) (int, error) {
-	calendarEvent, err := convertToCalendarEvent(event)
+// Return *entities.ErrInvalidEvent error if event is invalid (see entities.ValidateNewEvent)
+func (thisCalendar *Calendar) AddEvent(ctx context.Context, event *Event) (int, error) {
+	calendarEvent, err := thisCalendar.convertToNewCalendarEvent(event)
+	if err != nil {
+		return 0, err
+	}
+
+	return thisCalendar.storage.AddEvent(ctx, *calendarEvent)
+}
+
+// Add Event if its date is not busy by other events, otherwise return *entities.ErrDateBusy error
+func (thisCalendar *Calendar) AddEventIfNotBusy(ctx context.Context, event *Event) (int, error) {
+	calendarEvent, err := thisCalendar.convertToNewCalendarEvent(event)
+	if err != nil {
+		return 0, err
+	}
+
+	err = entities.CheckDateBusy(ctx, thisCalendar.storage, *calendarEvent, 0)
 	if err != nil {
 		return 0, err
 	}
 
-	return thisCalendar.storage.AddEvent(*calendarEvent)
+	return thisCalendar.storage.AddEvent(ctx, *calendarEvent)
 }
 
 // Update Event
-func (thisCalendar *Calendar) UpdateEvent(id int, event *Event) error {
-	calendarEvent, err := convertToCalendarEvent(event)
+// Return *entities.ErrInvalidEvent error if event is invalid (see entities.ValidateEvent)
+func (thisCalendar *Calendar) UpdateEvent(ctx context.Context, id int, event *Event) error {
+	calendarEvent, err := convertToValidCalendarEvent(event)
 	if err != nil {
 		return err
 	}
 
-	err = thisCalendar.storage.UpdateEvent(id, *calendarEvent)
+	err = thisCalendar.storage.UpdateEvent(ctx, id, *calendarEvent)
+	if err != nil {
+		return fmt.Errorf("couldn't update event in storage: %w", err)
+	}
+	return nil
+}
+
+// Update Event if its new date is not busy by other events, otherwise return *entities.ErrDateBusy error
+func (thisCalendar *Calendar) UpdateEventIfNotBusy(ctx context.Context, id int, event *Event) error {
+	calendarEvent, err := convertToValidCalendarEvent(event)
+	if err != nil {
+		return err
+	}
+
+	err = entities.CheckDateBusy(ctx, thisCalendar.storage, *calendarEvent, id)
+	if err != nil {
+		return err
+	}
+
+	err = thisCalendar.storage.UpdateEvent(ctx, id, *calendarEvent)
 	if err != nil {
 		return fmt.Errorf("couldn't update event in storage: %w", err)
 	}
@@ -47,21 +96,112 @@ func (thisCalendar *Calendar) UpdateEvent(id int, event *Event) error {
 }
 
 // Delete Event
-func (thisCalendar *Calendar) DeleteEvent(id int) error {
-	err := thisCalendar.storage.DeleteEvent(id)
+func (thisCalendar *Calendar) DeleteEvent(ctx context.Context, id int) error {
+	err := thisCalendar.storage.DeleteEvent(ctx, id)
 	if err != nil {
 		return fmt.Errorf("couldn't delete event from storage: %w", err)
 	}
 	return nil
 }
 
+// Get events in trash, the most recently deleted first
+func (thisCalendar *Calendar) GetTrashedEvents(ctx context.Context) ([]*Event, error) {
+	calendarEvents, err := thisCalendar.storage.GetTrashedEvents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get events from trash: %w", err)
+	}
+	var events []*Event
+	for _, calendarEvent := range calendarEvents {
+		events = append(events, ConvertFromCalendarEvent(calendarEvent))
+	}
+	return events, nil
+}
+
+// Restore event from trash
+func (thisCalendar *Calendar) RestoreEvent(ctx context.Context, id int) error {
+	err := thisCalendar.storage.RestoreEvent(ctx, id)
+	if err != nil {
+		return fmt.Errorf("couldn't restore event from trash: %w", err)
+	}
+	return nil
+}
+
+// Response of attendee (with email) to invitation to event, status is one of entities.AttendeeStatus* constants
+func (thisCalendar *Calendar) RespondToInvitation(ctx context.Context, id int, email string, status string) error {
+	err := thisCalendar.storage.RespondToInvitation(ctx, id, email, status)
+	if err != nil {
+		return fmt.Errorf("couldn't respond to invitation: %w", err)
+	}
+	return nil
+}
+
+// Get history of changes of event (including event in trash or purged one), the oldest first
+// Times of changes are in location
+func (thisCalendar *Calendar) GetEventHistoryInLocation(ctx context.Context, id int, loc *time.Location) ([]*AuditEntry, error) {
+	calendarEntries, err := thisCalendar.storage.GetEventHistory(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get history of event from storage: %w", err)
+	}
+	return convertFromCalendarAuditEntries(calendarEntries, loc), nil
+}
+
+// Add calendar of events, return id of new calendar
+// Return one of entities.Err*Calendar* errors if name is invalid (see entities.ValidateCalendar)
+func (thisCalendar *Calendar) AddCalendar(ctx context.Context, name string) (int, error) {
+	calendar := entities.NewCalendar(name)
+	err := entities.ValidateCalendar(calendar)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := thisCalendar.storage.AddCalendar(ctx, calendar)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't add calendar in storage: %w", err)
+	}
+	return id, nil
+}
+
+// Rename calendar of events
+// Return one of entities.Err*Calendar* errors if name is invalid (see entities.ValidateCalendar)
+func (thisCalendar *Calendar) UpdateCalendar(ctx context.Context, id int, name string) error {
+	calendar := entities.NewCalendar(name)
+	err := entities.ValidateCalendar(calendar)
+	if err != nil {
+		return err
+	}
+
+	err = thisCalendar.storage.UpdateCalendar(ctx, id, calendar)
+	if err != nil {
+		return fmt.Errorf("couldn't update calendar in storage: %w", err)
+	}
+	return nil
+}
+
+// Delete calendar with all its events
+func (thisCalendar *Calendar) DeleteCalendar(ctx context.Context, id int) error {
+	err := thisCalendar.storage.DeleteCalendar(ctx, id)
+	if err != nil {
+		return fmt.Errorf("couldn't delete calendar from storage: %w", err)
+	}
+	return nil
+}
+
+// Get all calendars of events sorted by id
+func (thisCalendar *Calendar) GetCalendars(ctx context.Context) ([]*CalendarInfo, error) {
+	calendarCalendars, err := thisCalendar.storage.GetCalendars(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get calendars from storage: %w", err)
+	}
+	return convertFromCalendarCalendars(calendarCalendars), nil
+}
+
 // Get one event
-func (thisCalendar *Calendar) GetEvent(id int) (*Event, bool) {
+func (thisCalendar *Calendar) GetEvent(ctx context.Context, id int) (*Event, bool) {
 	if id <= 0 {
 		return nil, false
 	}
 
-	calendarEvent, err := thisCalendar.storage.GetEvent(id)
+	calendarEvent, err := thisCalendar.storage.GetEvent(ctx, id)
 	if err == entities.StorageErrorEventNotFound {
 		return nil, false
 	}
@@ -72,8 +212,8 @@ func (thisCalendar *Calendar) GetEvent(id int) (*Event, bool) {
 }
 
 // Get all events
-func (thisCalendar *Calendar) GetAllEvents() ([]*Event, error) {
-	calendarEvents, err := thisCalendar.storage.GetAllEvents()
+func (thisCalendar *Calendar) GetAllEvents(ctx context.Context) ([]*Event, error) {
+	calendarEvents, err := thisCalendar.storage.GetAllEvents(ctx)
 	if err != nil {
 		return nil, nil
 	}
@@ -88,28 +228,89 @@ func (thisCalendar *Calendar) GetAllEvents() ([]*Event, error) {
 }
 
 // Get all events that started in period (boundary of period are included) sorted by Less method of events
-// start/end are datetime values represented by string in format on this module (see http.dateTimeLayout)
+// start/end are datetime values represented by string in format on this module (see http.dateTimeLayout) in UTC
 // Empty string has special meaning - no boundary for range period
-func (thisCalendar *Calendar) GetEventsByPeriod(start string, end string) ([]*Event, error) {
+func (thisCalendar *Calendar) GetEventsByPeriod(ctx context.Context, start string, end string) ([]*Event, error) {
+	return thisCalendar.GetEventsByPeriodInLocation(ctx, start, end, time.UTC)
+}
+
+// The same as GetEventsByPeriod but start/end are local times in location
+// If calendarIds are passed only events of these calendars are got
+func (thisCalendar *Calendar) GetEventsByPeriodInLocation(ctx context.Context, start string, end string, loc *time.Location, calendarIds ...int) ([]*Event, error) {
+	startTime, endTime, err := convertToCalendarPeriodInLocation(start, end, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	calendarEvents, err := thisCalendar.storage.GetEventsByPeriod(ctx, startTime, endTime, calendarIds...)
+	if len(calendarEvents) == 0 {
+		return nil, err
+	}
+	var events []*Event
+	for _, calendarEvent := range calendarEvents {
+		events = append(events, ConvertFromCalendarEvent(calendarEvent))
+	}
+	return events, nil
+}
+
+// Get page of events that started in period ordered by start and id, start/end are local times in location
+// cursor is empty for the first page or next cursor of previous page, invalid cursor is entities.ErrInvalidCursor
+// Return events of page and cursor of next page (empty for the last page)
+func (thisCalendar *Calendar) GetEventsPageByPeriodInLocation(ctx context.Context, start string, end string, loc *time.Location, limit int, cursor string, calendarIds ...int) ([]*Event, string, error) {
+	startTime, endTime, err := convertToCalendarPeriodInLocation(start, end, loc)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page, err := thisCalendar.storage.GetEventsByPeriodPage(ctx, startTime, endTime, limit, cursor, calendarIds...)
+	if err != nil {
+		return nil, "", err
+	}
+	var events []*Event
+	for _, calendarEvent := range page.Events {
+		events = append(events, ConvertFromCalendarEvent(calendarEvent))
+	}
+	return events, page.NextCursor, nil
+}
+
+// Convert boundaries of period (local times in location) to times of events, empty string is nil (no boundary)
+func convertToCalendarPeriodInLocation(start string, end string, loc *time.Location) (*entities.DateTime, *entities.DateTime, error) {
 	var startTime, endTime *entities.DateTime
 	var err error
 
 	if start != "" {
-		startTime, err = ConvertToCalendarEventTime(start)
+		startTime, err = ConvertToCalendarEventTimeInLocation(start, loc)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	if end != "" {
-		endTime, err = ConvertToCalendarEventTime(end)
+		endTime, err = ConvertToCalendarEventTimeInLocation(end, loc)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	calendarEvents, err := thisCalendar.storage.GetEventsByPeriod(startTime, endTime)
-	if len(calendarEvents) == 0 {
+	return startTime, endTime, nil
+}
+
+// Search events by words in name, start/end are local times in location, empty string is no boundary
+// Events are sorted by relevance, at most limit events are returned
+// If storage doesn't support search return entities.ErrSearchNotSupported
+func (thisCalendar *Calendar) SearchEventsInLocation(ctx context.Context, query string, start string, end string, loc *time.Location, limit int) ([]*Event, error) {
+	searcher, ok := thisCalendar.storage.(entities.Searcher)
+	if !ok {
+		return nil, entities.ErrSearchNotSupported
+	}
+
+	startTime, endTime, err := convertToCalendarPeriodInLocation(start, end, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	calendarEvents, err := searcher.SearchEvents(ctx, query, startTime, endTime, limit)
+	if err != nil {
 		return nil, err
 	}
 	var events []*Event
@@ -119,9 +320,43 @@ func (thisCalendar *Calendar) GetEventsByPeriod(start string, end string) ([]*Ev
 	return events, nil
 }
 
+// Get merged busy intervals of events in period [start, end), start/end are local times in location (see http.dateTimeLayout)
+// If minFreeMinutes > 0 free gaps within working hours not shorter than minFreeMinutes are returned too
+func (thisCalendar *Calendar) GetFreeBusyInLocation(ctx context.Context, start string, end string, loc *time.Location, minFreeMinutes int, hours entities.WorkingHours) (*FreeBusy, error) {
+	startTime, err := ConvertToCalendarEventTimeInLocation(start, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	endTime, err := ConvertToCalendarEventTimeInLocation(end, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if !startTime.Less(*endTime) {
+		return nil, ErrorInvalidPeriod
+	}
+
+	busy, err := entities.GetBusyIntervals(ctx, thisCalendar.storage, *startTime, *endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	freeBusy := &FreeBusy{
+		Busy: convertFromCalendarIntervals(busy, loc),
+	}
+
+	if minFreeMinutes > 0 {
+		free := entities.FreeIntervals(busy, *startTime, *endTime, hours, time.Duration(minFreeMinutes)*time.Minute)
+		freeBusy.Free = convertFromCalendarIntervals(free, loc)
+	}
+
+	return freeBusy, nil
+}
+
 // Get total number of events in entities
 func (thisCalendar *Calendar) getEventsTotalCount() int {
-	cnt, _ := thisCalendar.storage.Count()
+	cnt, _ := thisCalendar.storage.Count(context.Background())
 	return cnt
 }
 
@@ -133,3 +368,29 @@ func convertToCalendarEvent(event *Event) (*entities.Event, error) {
 	}
 	return calendarEvent, nil
 }
+
+// Inner Helper that helps convert http.Event to valid entities.Event
+func convertToValidCalendarEvent(event *Event) (*entities.Event, error) {
+	calendarEvent, err := convertToCalendarEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	err = entities.ValidateEvent(*calendarEvent)
+	if err != nil {
+		return nil, err
+	}
+	return calendarEvent, nil
+}
+
+// Inner Helper that helps convert http.Event to valid new entities.Event
+func (thisCalendar *Calendar) convertToNewCalendarEvent(event *Event) (*entities.Event, error) {
+	calendarEvent, err := convertToCalendarEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	err = entities.ValidateNewEvent(*calendarEvent, thisCalendar.now())
+	if err != nil {
+		return nil, err
+	}
+	return calendarEvent, nil
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+)
+
+// Named calendar of events (e.g. work, personal, team-X) for work inside http package
+// Calendar name is already taken by calendar service itself, so it is info about calendar
+type CalendarInfo struct {
+	Id    int    `json:"id"`
+	Name  string `json:"name"`
+	Owner string `json:"owner,omitempty"` // id of user that owns calendar, it is set by service from credentials
+}
+
+// Convert from inner calendars (entities.Calendar) to http calendars
+func convertFromCalendarCalendars(calendarCalendars []entities.Calendar) []*CalendarInfo {
+	calendars := make([]*CalendarInfo, 0, len(calendarCalendars))
+	for _, calendarCalendar := range calendarCalendars {
+		calendars = append(calendars, &CalendarInfo{
+			Id:    calendarCalendar.Id(),
+			Name:  calendarCalendar.Name(),
+			Owner: calendarCalendar.Owner(),
+		})
+	}
+	return calendars
+}
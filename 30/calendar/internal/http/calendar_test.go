@@ -1,9 +1,11 @@
 package http
 
 import (
+	"context"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/memory"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewCalendar(t *testing.T) {
@@ -97,14 +99,14 @@ func TestUpdateEvent1(t *testing.T) {
 		End:   "2019-10-16 01:00",
 	}
 
-	err := service.UpdateEvent(id, event2)
+	err := service.UpdateEvent(context.Background(), id, event2)
 
 	if err != nil {
 		t.Errorf("must not be happened error on update: %s\n", err)
 		return
 	}
 
-	event, found := service.GetEvent(id)
+	event, found := service.GetEvent(context.Background(), id)
 	if !found {
 		t.Errorf("event with id = %d not found on entities service", id)
 		return
@@ -114,12 +116,12 @@ func TestUpdateEvent1(t *testing.T) {
 		t.Errorf("\nevent info not updated\nexpected be:\n%+v\ngot:\n%+v\n", event2, event)
 	}
 
-	err = service.UpdateEvent(0, &Event{})
+	err = service.UpdateEvent(context.Background(), 0, &Event{})
 	if err == nil {
 		t.Error("update by id = 0 must return error")
 	}
 
-	err = service.UpdateEvent(1000, &Event{})
+	err = service.UpdateEvent(context.Background(), 1000, &Event{})
 	if err == nil {
 		t.Error("update by id of not existed event must return error")
 	}
@@ -147,14 +149,14 @@ func TestUpdateEvent2(t *testing.T) {
 		BeforeMinutes:      10,
 	}
 
-	err := service.UpdateEvent(id, event2)
+	err := service.UpdateEvent(context.Background(), id, event2)
 
 	if err != nil {
 		t.Errorf("must not be happened error on update: %s\n", err)
 		return
 	}
 
-	event, found := service.GetEvent(id)
+	event, found := service.GetEvent(context.Background(), id)
 	if !found {
 		t.Errorf("event with id = %d not found on entities service", id)
 		return
@@ -168,12 +170,12 @@ func TestUpdateEvent2(t *testing.T) {
 		t.Errorf("\nevent info not updated\nexpected be:\n%+v\ngot:\n%+v\n", event2, event)
 	}
 
-	err = service.UpdateEvent(0, &Event{})
+	err = service.UpdateEvent(context.Background(), 0, &Event{})
 	if err == nil {
 		t.Error("update by id = 0 must return error")
 	}
 
-	err = service.UpdateEvent(1000, &Event{})
+	err = service.UpdateEvent(context.Background(), 1000, &Event{})
 	if err == nil {
 		t.Error("update by id of not existed event must return error")
 	}
@@ -204,17 +206,17 @@ func TestDeleteEvent(t *testing.T) {
 		return
 	}
 
-	err := service.DeleteEvent(0)
+	err := service.DeleteEvent(context.Background(), 0)
 	if err == nil {
 		t.Error("delete by id = 0 must return error")
 	}
 
-	err = service.DeleteEvent(1000)
+	err = service.DeleteEvent(context.Background(), 1000)
 	if err == nil {
 		t.Error("delete by id of not existed event must return error")
 	}
 
-	err = service.DeleteEvent(id1)
+	err = service.DeleteEvent(context.Background(), id1)
 	if err != nil {
 		t.Errorf("delete by id = %d must not return error: %s", id1, err)
 	}
@@ -223,7 +225,7 @@ func TestDeleteEvent(t *testing.T) {
 		t.Error("delete actually not happened")
 	}
 
-	err = service.DeleteEvent(id2)
+	err = service.DeleteEvent(context.Background(), id2)
 	if err != nil {
 		t.Errorf("delete by id = %d must not return error: %s", id2, err)
 	}
@@ -243,12 +245,12 @@ func TestGetEvents(t *testing.T) {
 		return
 	}
 
-	allEvents, _ := calendar.GetAllEvents()
+	allEvents, _ := calendar.GetAllEvents(context.Background())
 	if len(allEvents) != 7 {
 		t.Error("7 events must be in entities and GetAllEvents must return all of them")
 	}
 
-	allEvents2, _ := calendar.GetEventsByPeriod("", "")
+	allEvents2, _ := calendar.GetEventsByPeriod(context.Background(), "", "")
 	if len(allEvents2) != 7 {
 		t.Error("7 events must be in entities and GetEventsByTimestampsPeriod(nil, nil) must return all of them")
 	}
@@ -258,7 +260,7 @@ func TestGetEvents(t *testing.T) {
 	}
 
 	eventTime := "2019-11-18 08:00"
-	eventList, _ := calendar.GetEventsByPeriod("", eventTime)
+	eventList, _ := calendar.GetEventsByPeriod(context.Background(), "", eventTime)
 
 	if len(eventList) != 1 {
 		t.Errorf("Must be returned one event")
@@ -269,7 +271,7 @@ func TestGetEvents(t *testing.T) {
 	}
 
 	eventTime = "2019-11-24 08:00"
-	eventList, _ = calendar.GetEventsByPeriod(eventTime, "")
+	eventList, _ = calendar.GetEventsByPeriod(context.Background(), eventTime, "")
 
 	if len(eventList) != 1 {
 		t.Errorf("Must be returned one event")
@@ -281,7 +283,7 @@ func TestGetEvents(t *testing.T) {
 
 	startEventTime := "2019-11-20 08:00"
 	endEventTime := "2019-11-22 08:00"
-	eventList, _ = calendar.GetEventsByPeriod(startEventTime, endEventTime)
+	eventList, _ = calendar.GetEventsByPeriod(context.Background(), startEventTime, endEventTime)
 
 	if len(eventList) != 3 {
 		t.Errorf("Must be returned 3 events")
@@ -299,7 +301,7 @@ func TestGetEvents(t *testing.T) {
 
 	startEventTime = "2019-11-20 08:01"
 	endEventTime = "2019-11-20 09:59"
-	eventList, _ = calendar.GetEventsByPeriod(startEventTime, endEventTime)
+	eventList, _ = calendar.GetEventsByPeriod(context.Background(), startEventTime, endEventTime)
 
 	if len(eventList) != 0 {
 		t.Errorf("Must be returned 0 events")
@@ -311,7 +313,7 @@ func TestGetEvents(t *testing.T) {
 // If expectedCount input argument is greater and equal 0 check getEventsTotalCount of service
 // If adding is successful return int ID > 0, otherwise return int < 0
 func addEvent(t *testing.T, calendar *Calendar, event *Event, expectedCount int) int {
-	id, err := calendar.AddEvent(event)
+	id, err := calendar.AddEvent(context.Background(), event)
 
 	if err != nil {
 		t.Errorf("must not be error if add new event: %s", err)
@@ -380,5 +382,11 @@ func addFixedListOfEvents(t *testing.T, calendar *Calendar) {
 func NewTestCalendar() *Calendar {
 	storage := memory.NewStorage()
 	calendar, _ := NewCalendar(storage)
+	calendar.now = testNow
 	return calendar
 }
+
+// Now time of tests, events of tests are not in the past relative to it
+func testNow() time.Time {
+	return time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -2,12 +2,14 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
 	"time"
 )
 
 // Inside http package and for communication with outer world by http we deal with "Y-m-d H:i" and "Y-m-d" date/datetime strings
+// Datetime strings are local wall clock times in time zone of event (or of request for periods)
 const dateTimeLayout = "2006-01-02 15:04"
 const dateLayout = "2006-01-02"
 
@@ -23,36 +25,62 @@ func (e *ErrorInvalidDatetime) Error() string {
 
 // Default invalid datetime error
 var DefaultErrorInvalidDatetime = &ErrorInvalidDatetime{
-	fmt.Errorf("invalid format of datetime - must be Y-m-d H:i (e.g %s)", dateTimeLayout),
+	fmt.Errorf("invalid format of datetime - must be Y-m-d H:i (e.g %s) or Y-m-d for all day event", dateTimeLayout),
+}
+
+// Error about mixing of date and datetime in one event
+var ErrorMixedDateAndDatetime = &ErrorInvalidDatetime{
+	errors.New("start and end must be both Y-m-d H:i or both Y-m-d (for all day event)"),
 }
 
 // Event structure for work inside http package
 // Clean architecture approach - not working with inner biz logic layer directly
 type Event struct {
-	Id                 int    `json:"id,omitempty"`
-	Name               string `json:"name"`
-	Start              string `json:"start"` // Y-m-d H:i
-	End                string `json:"end"`   // Y-m-d H:i
-	IsNotifyingEnabled bool   `json:"isNotifyingEnabled,omitempty"`
-	BeforeMinutes      int    `json:"beforeMinutes,omitempty"`
+	Id                 int               `json:"id,omitempty"`
+	Name               string            `json:"name"`
+	Start              string            `json:"start"` // Y-m-d H:i or Y-m-d for all day event
+	End                string            `json:"end"`   // Y-m-d H:i or Y-m-d (inclusive) for all day event
+	AllDay             bool              `json:"allDay,omitempty"`
+	IsNotifyingEnabled bool              `json:"isNotifyingEnabled,omitempty"`
+	BeforeMinutes      int               `json:"beforeMinutes,omitempty"`
+	Reminders          []int             `json:"reminders,omitempty"` // before minutes of all reminders of event, every reminder is notified separately
+	Rrule              string            `json:"rrule,omitempty"`     // RRULE, e.g. FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10
+	ExDates            []string          `json:"exdates,omitempty"`   // Y-m-d H:i starts of skipped occurrences
+	Timezone           string            `json:"timezone,omitempty"`  // IANA time zone of event (e.g. Europe/Moscow), empty means UTC
+	Description        string            `json:"description,omitempty"`
+	Location           string            `json:"location,omitempty"`         // place of event, e.g. meeting room or address
+	Organizer          string            `json:"organizer,omitempty"`        // email of organizer
+	Attendees          []string          `json:"attendees,omitempty"`        // emails of attendees
+	AttendeeStatuses   map[string]string `json:"attendeeStatuses,omitempty"` // statuses of responses of attendees by emails, only for output
+	Color              string            `json:"color,omitempty"`            // color or category of event
+	Owner              string            `json:"owner,omitempty"`            // id of user that owns event, it is set by service from credentials
+	DeletedTime        string            `json:"deletedTime,omitempty"`      // Y-m-d H:i when event was moved to trash, only for events in trash
+	Version            int               `json:"version,omitempty"`          // version of stored event, for update it is expected version (0 means without check)
+	CalendarId         int               `json:"calendarId,omitempty"`       // id of calendar event belongs to, 0 means event without calendar
 }
 
 // Constructor
+// If start and end are both Y-m-d dates event is all day event
 func NewEvent(name, start, end string, isNotifyingEnabled bool, beforeMinutes int) (*Event, error) {
-	_, err := time.Parse(dateTimeLayout, start)
+	_, allDay, err := parseDateOrDatetime(start, time.UTC)
 	if err != nil {
 		return nil, DefaultErrorInvalidDatetime
 	}
 
-	_, err = time.Parse(dateTimeLayout, end)
+	_, endAllDay, err := parseDateOrDatetime(end, time.UTC)
 	if err != nil {
 		return nil, DefaultErrorInvalidDatetime
 	}
 
+	if allDay != endAllDay {
+		return nil, ErrorMixedDateAndDatetime
+	}
+
 	event := &Event{
 		Name:               name,
 		Start:              start,
 		End:                end,
+		AllDay:             allDay,
 		IsNotifyingEnabled: isNotifyingEnabled,
 		BeforeMinutes:      beforeMinutes,
 	}
@@ -60,7 +88,62 @@ func NewEvent(name, start, end string, isNotifyingEnabled bool, beforeMinutes in
 	return event, nil
 }
 
+// Set recurrence of event, rrule is RRULE string, exDates are datetimes in format on this module (see http.dateTimeLayout)
+// Empty rrule means not recurring event
+func (event *Event) SetRecurrence(rrule string, exDates []string) error {
+	if rrule == "" && len(exDates) > 0 {
+		return errors.New("exdates could be set only for recurring event")
+	}
+
+	_, err := convertToCalendarRecurrence(rrule, exDates, time.UTC)
+	if err != nil {
+		return err
+	}
+
+	event.Rrule = rrule
+	event.ExDates = exDates
+
+	return nil
+}
+
+// Set reminders of event, every reminder is before minutes, they are added to reminder of BeforeMinutes if notifying is enabled
+func (event *Event) SetReminders(reminders []int) {
+	event.Reminders = reminders
+}
+
+// Set details of event, organizer and attendees must be emails
+func (event *Event) SetDetails(description, location, organizer string, attendees []string, color string) error {
+	err := validateEmails(organizer, attendees)
+	if err != nil {
+		return err
+	}
+
+	event.Description = description
+	event.Location = location
+	event.Organizer = organizer
+	event.Attendees = attendees
+	event.Color = color
+
+	return nil
+}
+
+// Set time zone of event, tz is IANA time zone name, empty tz means UTC
+func (event *Event) SetTimezone(tz string) error {
+	_, err := entities.LoadLocation(tz)
+	if err != nil {
+		return fmt.Errorf("unknown time zone `%s`", tz)
+	}
+
+	if tz == "UTC" {
+		tz = ""
+	}
+	event.Timezone = tz
+
+	return nil
+}
+
 // Convert from inner Event entity (entities.Event) to http.Event
+// Datetimes are in time zone of event
 func ConvertFromCalendarEvent(calendarEvent entities.Event) *Event {
 	event := &Event{
 		Id:                 calendarEvent.Id(),
@@ -70,41 +153,164 @@ func ConvertFromCalendarEvent(calendarEvent entities.Event) *Event {
 		IsNotifyingEnabled: calendarEvent.IsNotifyingEnabled(),
 		BeforeMinutes:      calendarEvent.BeforeMinutes(),
 	}
+
+	if loc := calendarEvent.Location(); loc != time.UTC {
+		event.Timezone = loc.String()
+	}
+
+	for _, reminder := range calendarEvent.Reminders() {
+		event.Reminders = append(event.Reminders, reminder.BeforeMinutes())
+	}
+
+	event.Description = calendarEvent.Description()
+	event.Location = calendarEvent.Place()
+	event.Organizer = calendarEvent.Organizer()
+	event.Attendees = calendarEvent.Attendees()
+	for _, attendee := range calendarEvent.AttendeeList() {
+		if event.AttendeeStatuses == nil {
+			event.AttendeeStatuses = make(map[string]string)
+		}
+		event.AttendeeStatuses[attendee.Email()] = attendee.Status()
+	}
+	event.Color = calendarEvent.Color()
+	event.Owner = calendarEvent.Owner()
+	event.Version = calendarEvent.Version()
+	event.CalendarId = calendarEvent.CalendarId()
+
+	if calendarEvent.IsDeleted() {
+		event.DeletedTime = calendarEvent.DeletedTime().In(calendarEvent.Location()).Format(dateTimeLayout)
+	}
+
+	if calendarEvent.IsAllDay() {
+		event.Start = calendarEvent.StartDate().Format(dateLayout)
+		event.End = calendarEvent.EndDate().Format(dateLayout)
+		event.AllDay = true
+	}
+
+	if recurrence := calendarEvent.Recurrence(); recurrence != nil {
+		event.Rrule = recurrence.String()
+		for _, exDate := range recurrence.ExDates() {
+			event.ExDates = append(event.ExDates, exDate.Format(dateTimeLayout))
+		}
+	}
+
 	return event
 }
 
 // Convert from http.Entity to inner Event entity (entities.Event)
 func (event *Event) ConvertToCalendarEvent() (*entities.Event, error) {
-	var startTime, endTime time.Time
-	var err error
+	loc, err := entities.LoadLocation(event.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone `%s`", event.Timezone)
+	}
 
-	startTime, err = time.Parse(dateTimeLayout, event.Start)
+	startTime, allDay, err := parseDateOrDatetime(event.Start, loc)
 	if err != nil {
 		return nil, &ErrorInvalidDatetime{
 			fmt.Errorf("couldn't parse start datetime: %w", err),
 		}
 	}
 
-	endTime, err = time.Parse(dateTimeLayout, event.End)
+	endTime, endAllDay, err := parseDateOrDatetime(event.End, loc)
 	if err != nil {
 		return nil, &ErrorInvalidDatetime{
 			fmt.Errorf("couldn't parse end datetime: %w", err),
 		}
 	}
 
-	calendarEvent := entities.NewDetailedEvent(
+	if allDay != endAllDay || (event.AllDay && !allDay) {
+		return nil, ErrorMixedDateAndDatetime
+	}
+
+	calendarEvent := entities.NewEvent(
 		event.Name,
 		entities.ConvertFromTime(startTime),
 		entities.ConvertFromTime(endTime),
-		event.IsNotifyingEnabled,
-		event.BeforeMinutes,
-		false,
-		time.Time{},
 	)
 
+	var reminders []entities.Reminder
+	if event.IsNotifyingEnabled {
+		reminders = append(reminders, entities.NewReminder(event.BeforeMinutes))
+	}
+	for _, beforeMinutes := range event.Reminders {
+		reminders = append(reminders, entities.NewReminder(beforeMinutes))
+	}
+	calendarEvent = entities.WithReminders(calendarEvent, reminders)
+
+	if allDay {
+		calendarEvent = entities.WithAllDay(
+			calendarEvent,
+			entities.ConvertDateFromTime(startTime),
+			entities.ConvertDateFromTime(endTime),
+		)
+	}
+
+	recurrence, err := convertToCalendarRecurrence(event.Rrule, event.ExDates, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurrence != nil {
+		calendarEvent = entities.WithRecurrence(calendarEvent, recurrence)
+	}
+
+	err = validateEmails(event.Organizer, event.Attendees)
+	if err != nil {
+		return nil, err
+	}
+
+	calendarEvent = entities.WithDescription(calendarEvent, event.Description)
+	calendarEvent = entities.WithPlace(calendarEvent, event.Location)
+	calendarEvent = entities.WithOrganizer(calendarEvent, event.Organizer)
+	calendarEvent = entities.WithAttendees(calendarEvent, event.Attendees)
+	calendarEvent = entities.WithColor(calendarEvent, event.Color)
+	calendarEvent = entities.WithVersion(calendarEvent, event.Version)
+	calendarEvent = entities.WithCalendarId(calendarEvent, event.CalendarId)
+
 	return &calendarEvent, nil
 }
 
+// Inner helper that validate emails of organizer (could be empty) and attendees
+func validateEmails(organizer string, attendees []string) error {
+	if organizer != "" {
+		if err := entities.ValidateEmail(organizer); err != nil {
+			return fmt.Errorf("organizer: %w", err)
+		}
+	}
+	for _, attendee := range attendees {
+		if err := entities.ValidateEmail(attendee); err != nil {
+			return fmt.Errorf("attendees: %w", err)
+		}
+	}
+	return nil
+}
+
+// Inner helper that convert rrule and exdates in format on this module (local times in loc) into entities.Recurrence
+// Empty rrule means not recurring event, so nil returned
+func convertToCalendarRecurrence(rrule string, exDates []string, loc *time.Location) (*entities.Recurrence, error) {
+	if rrule == "" {
+		return nil, nil
+	}
+
+	recurrence, err := entities.ParseRecurrence(rrule)
+	if err != nil {
+		return nil, err
+	}
+
+	var dates []entities.DateTime
+	for _, exDate := range exDates {
+		date, err := ConvertToCalendarEventTimeInLocation(exDate, loc)
+		if err != nil {
+			return nil, &ErrorInvalidDatetime{
+				fmt.Errorf("couldn't parse exdate datetime: %w", err),
+			}
+		}
+		dates = append(dates, *date)
+	}
+
+	return recurrence.WithExDates(dates), nil
+}
+
 // Json unmarshal function for event
 func JsonUnmarshal(data []byte) (*Event, error) {
 	event := &Event{}
@@ -122,9 +328,27 @@ func (event *Event) JsonMarshall() ([]byte, error) {
 	return json.Marshal(event)
 }
 
-// Datetime in format on current package (dateTimeLayout)
+// Parse datetime (dateTimeLayout) or date (dateLayout) value in location, second result says is it date
+func parseDateOrDatetime(value string, loc *time.Location) (time.Time, bool, error) {
+	t, err := time.ParseInLocation(dateTimeLayout, value, loc)
+	if err == nil {
+		return t, false, nil
+	}
+	t, dateErr := time.ParseInLocation(dateLayout, value, loc)
+	if dateErr == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, err
+}
+
+// Datetime in format on current package (dateTimeLayout) in UTC
 func ConvertToCalendarEventTime(datetime string) (*entities.DateTime, error) {
-	t, err := time.Parse(dateTimeLayout, datetime)
+	return ConvertToCalendarEventTimeInLocation(datetime, time.UTC)
+}
+
+// Datetime in format on current package (dateTimeLayout), that is local wall clock time in location
+func ConvertToCalendarEventTimeInLocation(datetime string, loc *time.Location) (*entities.DateTime, error) {
+	t, err := time.ParseInLocation(dateTimeLayout, datetime, loc)
 	if err != nil {
 		return nil, DefaultErrorInvalidDatetime
 	}
@@ -132,14 +356,14 @@ func ConvertToCalendarEventTime(datetime string) (*entities.DateTime, error) {
 	return &eventTime, nil
 }
 
-//  Helper that calculated period for day
+//  Helper that calculated period for day, day is local day in location of now
 func GetDayPeriod(now time.Time) (string, string) {
 	startTime := now.Format(dateLayout) + " 00:00"
 	endTime := now.Format(dateLayout) + " 23:59"
 	return startTime, endTime
 }
 
-// Helper that calculated period for week
+// Helper that calculated period for week in location of now
 func GetWeekPeriod(now time.Time) (string, string) {
 	nowWeek := now.Weekday()
 
@@ -160,9 +384,9 @@ func GetWeekPeriod(now time.Time) (string, string) {
 	return startTime, endTime
 }
 
-// Helper that calculated period for month
+// Helper that calculated period for month in location of now
 func GetMonthPeriod(now time.Time) (string, string) {
-	firstDayInMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	firstDayInMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 	nextMonth := firstDayInMonth.AddDate(0, 1, 0)
 	lastDayInMonth := nextMonth.AddDate(0, 0, -1)
 
@@ -2,6 +2,7 @@ package http
 
 import (
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -83,7 +84,7 @@ func TestConvertToCalendarEvent(t *testing.T) {
 		t.Errorf("must not be error while converting %s", err)
 	}
 
-	if calendarEvent != *resultCalendarEvent {
+	if !reflect.DeepEqual(calendarEvent, *resultCalendarEvent) {
 		t.Errorf("\nexpect entities.Event:\n`%#v`\ngot entities.Event:\n`%#v`\n",
 			calendarEvent,
 			*resultCalendarEvent)
@@ -108,7 +109,7 @@ func TestJsonUnmarshal1(t *testing.T) {
 		End:   "2019-11-15 22:00",
 	}
 
-	if expectedEvent != *event {
+	if !reflect.DeepEqual(expectedEvent, *event) {
 		t.Errorf("expect event %+v, got event %+v\n",
 			expectedEvent,
 			*event)
@@ -137,7 +138,7 @@ func TestJsonUnmarshal2(t *testing.T) {
 		BeforeMinutes:      5,
 	}
 
-	if expectedEvent != *event {
+	if !reflect.DeepEqual(expectedEvent, *event) {
 		t.Errorf("expect event %+v, got event %+v\n",
 			expectedEvent,
 			*event)
@@ -291,3 +292,117 @@ func TestGetMonthPeriod4(t *testing.T) {
 		t.Errorf("end must be %s insteadof %s", end, expectedEnd)
 	}
 }
+
+func TestSetRecurrence(t *testing.T) {
+	event, _ := NewEvent("Stand-up", "2019-11-18 10:00", "2019-11-18 10:15", false, 0)
+
+	err := event.SetRecurrence("FREQ=WEEKLY;BYDAY=MO,WE", []string{"2019-11-20 10:00"})
+	if err != nil {
+		t.Errorf("must not be error %s", err)
+	}
+
+	calendarEvent, err := event.ConvertToCalendarEvent()
+	if err != nil {
+		t.Fatalf("must not be error while converting %s", err)
+	}
+
+	if !calendarEvent.IsRecurring() {
+		t.Fatal("entities.Event must be recurring")
+	}
+
+	resultEvent := ConvertFromCalendarEvent(*calendarEvent)
+	if resultEvent.Rrule != "FREQ=WEEKLY;BYDAY=MO,WE" {
+		t.Errorf("unexpected rrule `%s`", resultEvent.Rrule)
+	}
+	if !reflect.DeepEqual(resultEvent.ExDates, []string{"2019-11-20 10:00"}) {
+		t.Errorf("unexpected exdates %v", resultEvent.ExDates)
+	}
+
+	err = event.SetRecurrence("FREQ=SECONDLY", nil)
+	if err == nil {
+		t.Error("must be error on invalid rrule")
+	}
+
+	err = event.SetRecurrence("FREQ=DAILY", []string{"20.11.2019"})
+	if _, ok := err.(*ErrorInvalidDatetime); !ok {
+		t.Errorf("must be ErrorInvalidDatetime on invalid exdate, not `%+v`", err)
+	}
+
+	err = event.SetRecurrence("", []string{"2019-11-20 10:00"})
+	if err == nil {
+		t.Error("must be error on exdates without rrule")
+	}
+}
+
+func TestNewAllDayEvent(t *testing.T) {
+	event, err := NewEvent("Holidays", "2019-12-31", "2020-01-02", false, 0)
+	if err != nil {
+		t.Fatalf("http.Event construction must be ok, not failed because of `%s`", err)
+	}
+
+	if !event.AllDay {
+		t.Error("http.Event must be all day event")
+	}
+
+	calendarEvent, err := event.ConvertToCalendarEvent()
+	if err != nil {
+		t.Fatalf("must not be error while converting %s", err)
+	}
+
+	if !calendarEvent.IsAllDay() || calendarEvent.EndDate() != entities.NewDate(2020, 1, 2) {
+		t.Errorf("unexpected entities.Event %s", calendarEvent)
+	}
+
+	resultEvent := ConvertFromCalendarEvent(*calendarEvent)
+	if !reflect.DeepEqual(*event, *resultEvent) {
+		t.Errorf("expect event %+v, got event %+v\n", *event, *resultEvent)
+	}
+
+	_, err = NewEvent("Holidays", "2019-12-31", "2020-01-02 10:00", false, 0)
+	if err != ErrorMixedDateAndDatetime {
+		t.Errorf("http.Event construction must return ErrorMixedDateAndDatetime, not `%+v`", err)
+	}
+}
+
+func TestSetTimezone(t *testing.T) {
+	event, _ := NewEvent("Meeting", "2019-11-25 10:00", "2019-11-25 11:00", false, 0)
+
+	err := event.SetTimezone("Europe/Moscow")
+	if err != nil {
+		t.Fatalf("must not be error %s", err)
+	}
+
+	calendarEvent, err := event.ConvertToCalendarEvent()
+	if err != nil {
+		t.Fatalf("must not be error while converting %s", err)
+	}
+
+	if calendarEvent.Start().Time().UTC().Format(dateTimeLayout) != "2019-11-25 07:00" {
+		t.Errorf("start must be local time in time zone of event, got %s", calendarEvent.Start().Time().UTC())
+	}
+
+	resultEvent := ConvertFromCalendarEvent(*calendarEvent)
+	if !reflect.DeepEqual(resultEvent, event) {
+		t.Errorf("Expected\n`%+v`\ngot\n`%+v`", *event, *resultEvent)
+	}
+
+	err = event.SetTimezone("Mars/Olympus")
+	if err == nil {
+		t.Error("must be error on unknown time zone")
+	}
+
+	_ = event.SetTimezone("UTC")
+	if event.Timezone != "" {
+		t.Errorf("UTC time zone must be empty, not `%s`", event.Timezone)
+	}
+}
+
+func TestGetDayPeriodInLocation(t *testing.T) {
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	now := time.Date(2019, 11, 24, 22, 0, 0, 0, time.UTC)
+
+	start, end := GetDayPeriod(now.In(moscow))
+	if start != "2019-11-25 00:00" || end != "2019-11-25 23:59" {
+		t.Errorf("period must be local day in Moscow, got %s - %s", start, end)
+	}
+}
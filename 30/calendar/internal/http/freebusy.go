@@ -0,0 +1,35 @@
+package http
+
+import (
+	"errors"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"time"
+)
+
+// Error about period which end is not after start
+var ErrorInvalidPeriod = errors.New("end of period must be after start")
+
+// Interval of time, start is included, end is excluded
+// Datetimes are local times in time zone of request (see http.dateTimeLayout)
+type Interval struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Merged busy intervals and free gaps within working hours
+type FreeBusy struct {
+	Busy []Interval `json:"busy"`
+	Free []Interval `json:"free,omitempty"` // only if free gaps are requested
+}
+
+// Convert from inner intervals (entities.Interval) to http intervals in location
+func convertFromCalendarIntervals(calendarIntervals []entities.Interval, loc *time.Location) []Interval {
+	intervals := make([]Interval, 0, len(calendarIntervals))
+	for _, interval := range calendarIntervals {
+		intervals = append(intervals, Interval{
+			Start: interval.Start().In(loc).Format(dateTimeLayout),
+			End:   interval.End().In(loc).Format(dateTimeLayout),
+		})
+	}
+	return intervals
+}
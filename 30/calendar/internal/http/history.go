@@ -0,0 +1,46 @@
+package http
+
+import (
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"time"
+)
+
+// Layout of time of change in audit entries, time is local time in time zone of request
+const auditTimeLayout = "2006-01-02 15:04:05"
+
+// Change of one field of event, values are human readable strings, empty value means absent value
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Audit entry about change of event
+type AuditEntry struct {
+	Action  string        `json:"action"`          // create, update, delete, restore, notified or respond
+	Actor   string        `json:"actor,omitempty"` // id of user who changed event, empty for changes by system
+	Time    string        `json:"time"`            // Y-m-d H:i:s
+	Changes []FieldChange `json:"changes"`
+}
+
+// Convert from inner audit entries (entities.AuditEntry) to http audit entries, times of change are in location
+func convertFromCalendarAuditEntries(calendarEntries []entities.AuditEntry, loc *time.Location) []*AuditEntry {
+	entries := make([]*AuditEntry, 0, len(calendarEntries))
+	for _, calendarEntry := range calendarEntries {
+		entry := &AuditEntry{
+			Action:  calendarEntry.Action(),
+			Actor:   calendarEntry.Actor(),
+			Time:    calendarEntry.Time().In(loc).Format(auditTimeLayout),
+			Changes: make([]FieldChange, 0, len(calendarEntry.Changes())),
+		}
+		for _, change := range calendarEntry.Changes() {
+			entry.Changes = append(entry.Changes, FieldChange{
+				Field:  change.Field(),
+				Before: change.Before(),
+				After:  change.After(),
+			})
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
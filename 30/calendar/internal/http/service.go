@@ -2,11 +2,14 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/auth"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/monitoring"
 
 	"github.com/gorilla/mux"
@@ -20,12 +23,46 @@ type OkResponse struct {
 }
 
 // Ok json response with list of events
+// NextCursor is cursor of next page for paged lists, it is omitted for the last page
 type EventListResponse struct {
-	Result []*Event `json:"result"`
+	Result     []*Event `json:"result"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+// Ok json response with list of calendars
+type CalendarListResponse struct {
+	Result []*CalendarInfo `json:"result"`
+}
+
+// Ok json response with history of event
+type AuditEntryListResponse struct {
+	Result []*AuditEntry `json:"result"`
+}
+
+// Ok json response with free/busy
+type FreeBusyResponse struct {
+	Result *FreeBusy `json:"result"`
+}
+
+// Ok json response with ids of events of operations of batch
+type BatchResponse struct {
+	Result []int `json:"result"`
+}
+
+// Json request of batch of operations
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations"`
 }
 
 // Error json response
 type ErrorResponse struct {
+	Error  string               `json:"error"`
+	Fields []FieldErrorResponse `json:"fields,omitempty"` // invalid fields of event
+}
+
+// Invalid field of event in error json response
+type FieldErrorResponse struct {
+	Field string `json:"field"`
 	Error string `json:"error"`
 }
 
@@ -33,23 +70,33 @@ type ErrorResponse struct {
 // Clean architecture approach - not working with inner biz logic layer directly
 type Service struct {
 	Calendar
-	logger  *zap.SugaredLogger
-	port    string
-	metrics *monitoring.HttpMetrics // http metrics manager
+	logger   *zap.SugaredLogger
+	port     string
+	metrics  *monitoring.HttpMetrics // http metrics manager
+	location *time.Location          // default time zone of requests
+	auth     *auth.Authenticator     // authenticator of callers, nil means authentication is disabled
 }
 
 // Constructor
-func NewService(port string, storage entities.Storage, logger *zap.SugaredLogger, metrics *monitoring.HttpMetrics) (*Service, error) {
+// location is default time zone of requests, nil means UTC
+// authenticator is authenticator of callers, nil means authentication is disabled and all events are shared
+func NewService(port string, storage entities.Storage, logger *zap.SugaredLogger, metrics *monitoring.HttpMetrics, location *time.Location, authenticator *auth.Authenticator) (*Service, error) {
 	service, err := NewCalendar(storage)
 	if err != nil {
 		return nil, err
 	}
 
+	if location == nil {
+		location = time.UTC
+	}
+
 	srv := &Service{
 		Calendar: *service,
 		logger:   logger,
 		port:     port,
 		metrics:  metrics,
+		location: location,
+		auth:     authenticator,
 	}
 
 	return srv, nil
@@ -67,6 +114,28 @@ func (service *Service) requestLogMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// Middleware to authenticate callers by X-Api-Key header or Authorization header with bearer token
+// Authenticated user is put into context of request
+func (service *Service) authMiddleware(next http.Handler) http.Handler {
+	// if not authenticator - no middleware
+	if service.auth == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := service.auth.Authenticate(r.Header.Get("X-Api-Key"), r.Header.Get("Authorization"))
+		if err != nil {
+			service.writeErrorResponse(w, err.Error(), 401)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+	})
+}
+
+// Calendar of authenticated user of request
+func (service *Service) calendarFor(r *http.Request) *Calendar {
+	return service.Calendar.ForOwner(auth.UserFromContext(r.Context()))
+}
+
 // Register middleware for measure http metrics and run exporter on proper port
 func (service *Service) metricsMiddleware(next http.Handler) http.Handler {
 	if service.metrics == nil {
@@ -82,11 +151,24 @@ func (service *Service) Run() {
 	router.HandleFunc("/create_event", service.CreateEvent).Methods("POST")
 	router.HandleFunc("/update_event", service.UpdateEvent).Methods("POST")
 	router.HandleFunc("/delete_event", service.DeleteEvent).Methods("POST")
+	router.HandleFunc("/restore_event", service.RestoreEvent).Methods("POST")
+	router.HandleFunc("/trash", service.GetTrash).Methods("GET")
+	router.HandleFunc("/respond_invitation", service.RespondToInvitation).Methods("POST")
+	router.HandleFunc("/events/{id}/history", service.GetEventHistory).Methods("GET")
 	router.HandleFunc("/events_for_day", service.GetEventsForDay).Methods("GET")
 	router.HandleFunc("/events_for_week", service.GetEventsForWeek).Methods("GET")
 	router.HandleFunc("/events_for_month", service.GetEventsForMonth).Methods("GET")
+	router.HandleFunc("/free_busy", service.GetFreeBusy).Methods("GET")
+	router.HandleFunc("/search", service.SearchEvents).Methods("GET")
+	router.HandleFunc("/batch", service.Batch).Methods("POST")
+	router.HandleFunc("/create_calendar", service.CreateCalendar).Methods("POST")
+	router.HandleFunc("/update_calendar", service.UpdateCalendar).Methods("POST")
+	router.HandleFunc("/delete_calendar", service.DeleteCalendar).Methods("POST")
+	router.HandleFunc("/calendars", service.GetCalendars).Methods("GET")
 
-	handler := service.requestLogMiddleware(router)
+	handler := service.authMiddleware(router)
+
+	handler = service.requestLogMiddleware(handler)
 
 	handler = service.metricsMiddleware(handler)
 
@@ -101,8 +183,8 @@ func (service *Service) Run() {
 }
 
 // Run new http entities service
-func RunService(port string, storage entities.Storage, logger *zap.SugaredLogger, metrics *monitoring.HttpMetrics) error {
-	service, err := NewService(port, storage, logger, metrics)
+func RunService(port string, storage entities.Storage, logger *zap.SugaredLogger, metrics *monitoring.HttpMetrics, location *time.Location, authenticator *auth.Authenticator) error {
+	service, err := NewService(port, storage, logger, metrics, location, authenticator)
 	if err != nil {
 		return err
 	}
@@ -111,6 +193,11 @@ func RunService(port string, storage entities.Storage, logger *zap.SugaredLogger
 }
 
 // Create event handler
+// start, end and exdates are local times in `timezone` of event, by default it is time zone of request
+// `reminders` is comma separated list of before minutes, every reminder is notified separately
+// `calendarId` is id of calendar event belongs to, unknown calendar is error with 404 status code
+// If `rejectConflicts` parameter is true and event overlaps other events response is error with 409 status code
+// Invalid event (see entities.ValidateNewEvent) is error with 422 status code and invalid fields in response
 // On success response by ok json response with "create %d" result string
 func (service *Service) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	service.parseForm(r)
@@ -130,9 +217,44 @@ func (service *Service) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := service.AddEvent(event)
+	err = service.parseTimezoneParameter(r, event)
 	if err != nil {
-		service.writeErrorResponse(w, err.Error(), 200)
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	err = parseRecurrenceParameters(r, event)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	err = parseDetailsParameters(r, event)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	err = parseRemindersParameter(r, event)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	err = parseCalendarIdParameter(r, event)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	var id int
+	if parseRejectConflictsParameter(r) {
+		id, err = service.calendarFor(r).AddEventIfNotBusy(r.Context(), event)
+	} else {
+		id, err = service.calendarFor(r).AddEvent(r.Context(), event)
+	}
+	if err != nil {
+		service.writeCalendarErrorResponse(w, err)
 		return
 	}
 
@@ -140,6 +262,9 @@ func (service *Service) CreateEvent(w http.ResponseWriter, r *http.Request) {
 }
 
 // Update event handler
+// If `rejectConflicts` parameter is true and event overlaps other events response is error with 409 status code
+// If expected version is passed (`version` parameter or If-Match header) and event was changed since then response is error with 409 status code
+// Invalid event (see entities.ValidateEvent) is error with 422 status code and invalid fields in response
 // On success response by ok json response with "updated" result string
 func (service *Service) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 	service.parseForm(r)
@@ -165,9 +290,49 @@ func (service *Service) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = service.Calendar.UpdateEvent(id, event)
+	err = service.parseTimezoneParameter(r, event)
 	if err != nil {
-		service.writeErrorResponse(w, err.Error(), 200)
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	err = parseRecurrenceParameters(r, event)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	err = parseDetailsParameters(r, event)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	err = parseRemindersParameter(r, event)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	err = parseVersionParameter(r, event)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	err = parseCalendarIdParameter(r, event)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	if parseRejectConflictsParameter(r) {
+		err = service.calendarFor(r).UpdateEventIfNotBusy(r.Context(), id, event)
+	} else {
+		err = service.calendarFor(r).UpdateEvent(r.Context(), id, event)
+	}
+	if err != nil {
+		service.writeCalendarErrorResponse(w, err)
 		return
 	}
 
@@ -185,16 +350,190 @@ func (service *Service) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = service.Calendar.DeleteEvent(id)
+	err = service.calendarFor(r).DeleteEvent(r.Context(), id)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 200)
+		return
+	}
+
+	service.writeOkResponse(w, "deleted", 200)
+}
+
+// Restore event from trash handler
+// On success response by ok json response with "restored" result string
+func (service *Service) RestoreEvent(w http.ResponseWriter, r *http.Request) {
+	service.parseForm(r)
+
+	id, err := strconv.Atoi(r.Form.Get("id"))
+	if err != nil || id <= 0 {
+		service.writeErrorResponse(w, "invalid id parameter, must be int greater than 0", 400)
+		return
+	}
+
+	err = service.calendarFor(r).RestoreEvent(r.Context(), id)
 	if err != nil {
 		service.writeErrorResponse(w, err.Error(), 200)
 		return
 	}
 
+	service.writeOkResponse(w, "restored", 200)
+}
+
+// Respond to invitation to event handler, `status` is one of needs-action, accepted, declined or tentative
+// Attendee is authenticated user, without authentication it is `email` parameter
+// Attendee is not owner of event, so event is looked up among events of all owners
+// On success response by ok json response with "responded" result string,
+// unknown event or attendee is error with 404 status code
+func (service *Service) RespondToInvitation(w http.ResponseWriter, r *http.Request) {
+	service.parseForm(r)
+
+	id, err := strconv.Atoi(r.Form.Get("id"))
+	if err != nil || id <= 0 {
+		service.writeErrorResponse(w, "invalid id parameter, must be int greater than 0", 400)
+		return
+	}
+
+	email := auth.UserFromContext(r.Context())
+	if email == "" {
+		email = r.Form.Get("email")
+	}
+	if email == "" {
+		service.writeErrorResponse(w, "email parameter is required", 400)
+		return
+	}
+
+	status := r.Form.Get("status")
+	err = entities.ValidateAttendeeStatus(status)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	err = service.Calendar.RespondToInvitation(r.Context(), id, email, status)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 404)
+		return
+	}
+
+	service.writeOkResponse(w, "responded", 200)
+}
+
+// Get events in trash handler
+// response by ok json response with list of events in trash, the most recently deleted first
+func (service *Service) GetTrash(w http.ResponseWriter, r *http.Request) {
+	events, err := service.calendarFor(r).GetTrashedEvents(r.Context())
+	if err != nil {
+		service.writeErrorResponse(w, "internal server error", 500)
+		if service.logger != nil {
+			service.logger.Errorf("Service.GetTrash, error Calendar.GetTrashedEvents %s", err)
+		}
+		return
+	}
+
+	service.writeEventListResponse(w, events, 200)
+}
+
+// Get history of event handler, id of event is part of path: /events/{id}/history
+// Times of changes are local times in time zone of request (`tz` parameter or X-Timezone header)
+// response by ok json response with list of audit entries, the oldest first, or error with 404 status code if event not found
+func (service *Service) GetEventHistory(w http.ResponseWriter, r *http.Request) {
+	service.parseForm(r)
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		service.writeErrorResponse(w, "invalid id of event, must be int greater than 0", 400)
+		return
+	}
+
+	loc, err := service.requestLocation(r)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	entries, err := service.calendarFor(r).GetEventHistoryInLocation(r.Context(), id, loc)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 404)
+		return
+	}
+
+	service.writeAuditEntryListResponse(w, entries, 200)
+}
+
+// Create calendar handler, `name` is name of calendar
+// On success response by ok json response with "created %d" result string
+func (service *Service) CreateCalendar(w http.ResponseWriter, r *http.Request) {
+	service.parseForm(r)
+
+	id, err := service.calendarFor(r).AddCalendar(r.Context(), r.Form.Get("name"))
+	if err != nil {
+		service.writeCalendarErrorResponse(w, err)
+		return
+	}
+
+	service.writeOkResponse(w, fmt.Sprintf("created %d", id), 200)
+}
+
+// Rename calendar handler
+// On success response by ok json response with "updated" result string, unknown calendar is error with 404 status code
+func (service *Service) UpdateCalendar(w http.ResponseWriter, r *http.Request) {
+	service.parseForm(r)
+
+	id, err := strconv.Atoi(r.Form.Get("id"))
+	if err != nil || id <= 0 {
+		service.writeErrorResponse(w, "invalid id parameter, must be int greater than 0", 400)
+		return
+	}
+
+	err = service.calendarFor(r).UpdateCalendar(r.Context(), id, r.Form.Get("name"))
+	if err != nil {
+		service.writeCalendarErrorResponse(w, err)
+		return
+	}
+
+	service.writeOkResponse(w, "updated", 200)
+}
+
+// Delete calendar with all its events handler
+// On success response by ok json response with "deleted" result string, unknown calendar is error with 404 status code
+func (service *Service) DeleteCalendar(w http.ResponseWriter, r *http.Request) {
+	service.parseForm(r)
+
+	id, err := strconv.Atoi(r.Form.Get("id"))
+	if err != nil || id <= 0 {
+		service.writeErrorResponse(w, "invalid id parameter, must be int greater than 0", 400)
+		return
+	}
+
+	err = service.calendarFor(r).DeleteCalendar(r.Context(), id)
+	if err != nil {
+		service.writeCalendarErrorResponse(w, err)
+		return
+	}
+
 	service.writeOkResponse(w, "deleted", 200)
 }
 
+// Get calendars handler
+// response by ok json response with list of calendars sorted by id
+func (service *Service) GetCalendars(w http.ResponseWriter, r *http.Request) {
+	calendars, err := service.calendarFor(r).GetCalendars(r.Context())
+	if err != nil {
+		service.writeErrorResponse(w, "internal server error", 500)
+		if service.logger != nil {
+			service.logger.Errorf("Service.GetCalendars, error Calendar.GetCalendars %s", err)
+		}
+		return
+	}
+
+	service.writeCalendarListResponse(w, calendars, 200)
+}
+
 // Get events for current day handler
+// Day is local day in time zone of request (`tz` parameter or X-Timezone header)
+// `calendars` is comma separated list of ids of calendars, if it is passed only events of these calendars are got
+// Events are got by pages ordered by start and id: `limit` is size of page (100 by default, at most 1000),
+// `cursor` is `nextCursor` of previous page, response has `nextCursor` if there are more events
 // response by ok json response with list of events
 func (service *Service) GetEventsForDay(w http.ResponseWriter, r *http.Request) {
 	service.getEventsForDay(time.Now(), w, r)
@@ -202,8 +541,13 @@ func (service *Service) GetEventsForDay(w http.ResponseWriter, r *http.Request)
 
 // Inner method for testing, in test we want pass own 'now'
 func (service *Service) getEventsForDay(now time.Time, w http.ResponseWriter, r *http.Request) {
-	startTime, endTime := GetDayPeriod(now)
-	service.getEventsForPeriod(startTime, endTime, w, r)
+	loc, err := service.requestLocation(r)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+	startTime, endTime := GetDayPeriod(now.In(loc))
+	service.getEventsForPeriod(startTime, endTime, loc, w, r)
 }
 
 // Get events for current week handler
@@ -214,8 +558,13 @@ func (service *Service) GetEventsForWeek(w http.ResponseWriter, r *http.Request)
 
 // Inner method for testing, in test we want pass own 'now'
 func (service *Service) getEventsForWeek(now time.Time, w http.ResponseWriter, r *http.Request) {
-	startTime, endTime := GetWeekPeriod(now)
-	service.getEventsForPeriod(startTime, endTime, w, r)
+	loc, err := service.requestLocation(r)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+	startTime, endTime := GetWeekPeriod(now.In(loc))
+	service.getEventsForPeriod(startTime, endTime, loc, w, r)
 }
 
 // Get events for current month handler
@@ -226,24 +575,211 @@ func (service *Service) GetEventsForMonth(w http.ResponseWriter, r *http.Request
 
 // Inner method for testing, in test we want pass own 'now'
 func (service *Service) getEventsForMonth(now time.Time, w http.ResponseWriter, r *http.Request) {
-	startTime, endTime := GetMonthPeriod(now)
-	service.getEventsForPeriod(startTime, endTime, w, r)
+	loc, err := service.requestLocation(r)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+	startTime, endTime := GetMonthPeriod(now.In(loc))
+	service.getEventsForPeriod(startTime, endTime, loc, w, r)
 }
 
 // Helper for GetEventsFor* methods to reduce code duplication
-func (service *Service) getEventsForPeriod(start, end string, w http.ResponseWriter, r *http.Request) {
-	events, err := service.Calendar.GetEventsByPeriod(start, end)
+func (service *Service) getEventsForPeriod(start, end string, loc *time.Location, w http.ResponseWriter, r *http.Request) {
+	calendarIds, err := parseCalendarsParameter(r)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	limit, err := parseLimitParameter(r)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	events, nextCursor, err := service.calendarFor(r).GetEventsPageByPeriodInLocation(r.Context(), start, end, loc, limit, r.FormValue("cursor"), calendarIds...)
+
+	if errors.Is(err, entities.ErrInvalidCursor) {
+		service.writeErrorResponse(w, "invalid cursor parameter, must be nextCursor of previous page", 400)
+		return
+	}
 
 	if err != nil {
 		service.writeErrorResponse(w, "internal server error", 500)
 		if service.logger != nil {
-			service.logger.Errorf("Service.GetEventsForDay, error Calendar.GetEventsByTimestampsPeriod %s", err)
+			service.logger.Errorf("Service.getEventsForPeriod, error Calendar.GetEventsPageByPeriodInLocation %s", err)
 		}
+		return
+	}
+
+	service.writeEventPageResponse(w, events, nextCursor, 200)
+}
+
+// Get free/busy handler
+// `start` and `end` (Y-m-d H:i) are local times in time zone of request, end is excluded
+// Response has merged busy intervals of events (overlapping and adjacent events are merged)
+// If `minFreeMinutes` parameter is set response also has free gaps not shorter than it within working hours
+// Working hours are `workStart` and `workEnd` (HH:MM) parameters, by default 09:00 - 18:00
+func (service *Service) GetFreeBusy(w http.ResponseWriter, r *http.Request) {
+	service.parseForm(r)
+
+	loc, err := service.requestLocation(r)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	minFreeMinutes := 0
+	if minFreeMinutesStr := r.Form.Get("minFreeMinutes"); minFreeMinutesStr != "" {
+		minFreeMinutes, err = strconv.Atoi(minFreeMinutesStr)
+		if err != nil || minFreeMinutes < 0 {
+			service.writeErrorResponse(w, "invalid minFreeMinutes parameter, must be int not less than 0", 400)
+			return
+		}
+	}
+
+	hours, err := parseWorkingHoursParameters(r)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	freeBusy, err := service.calendarFor(r).GetFreeBusyInLocation(r.Context(), r.Form.Get("start"), r.Form.Get("end"), loc, minFreeMinutes, hours)
+	if err != nil {
+		var datetimeErr *ErrorInvalidDatetime
+		if errors.As(err, &datetimeErr) || err == ErrorInvalidPeriod {
+			service.writeErrorResponse(w, err.Error(), 400)
+			return
+		}
+		service.writeErrorResponse(w, "internal server error", 500)
+		if service.logger != nil {
+			service.logger.Errorf("Service.GetFreeBusy, error Calendar.GetFreeBusyInLocation %s", err)
+		}
+		return
+	}
+
+	service.writeFreeBusyResponse(w, freeBusy, 200)
+}
+
+// Search events handler
+// `q` is words that must be in name of event, `start` and `end` (Y-m-d H:i) are optional local times in time zone of request
+// Response has events which occurrences started in period sorted by relevance, at most `limit` (100 by default, at most 1000)
+// If storage doesn't support search response is 501 status code
+func (service *Service) SearchEvents(w http.ResponseWriter, r *http.Request) {
+	service.parseForm(r)
+
+	loc, err := service.requestLocation(r)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	limit, err := parseLimitParameter(r)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	events, err := service.calendarFor(r).SearchEventsInLocation(r.Context(), r.Form.Get("q"), r.Form.Get("start"), r.Form.Get("end"), loc, limit)
+	if err != nil {
+		var datetimeErr *ErrorInvalidDatetime
+		switch {
+		case errors.As(err, &datetimeErr):
+			service.writeErrorResponse(w, err.Error(), 400)
+		case err == entities.ErrEmptySearchQuery:
+			service.writeErrorResponse(w, "invalid q parameter, "+err.Error(), 400)
+		case err == entities.ErrSearchNotSupported:
+			service.writeErrorResponse(w, err.Error(), 501)
+		default:
+			service.writeErrorResponse(w, "internal server error", 500)
+			if service.logger != nil {
+				service.logger.Errorf("Service.SearchEvents, error Calendar.SearchEventsInLocation %s", err)
+			}
+		}
+		return
 	}
 
 	service.writeEventListResponse(w, events, 200)
 }
 
+// Batch handler
+// Body is json BatchRequest with create, update and delete operations (at most 1000), they are applied all or nothing
+// Events without timezone are in time zone of request
+// Malformed body or operation is error with 400 status code, other errors of operations are as for single operations
+// (422, 409, 404), message of error starts with index of failed operation
+// If storage doesn't support transactions response is 501 status code
+// On success response by json response with ids of events of operations
+func (service *Service) Batch(w http.ResponseWriter, r *http.Request) {
+	request := BatchRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		service.writeErrorResponse(w, "invalid body, must be json with operations", 400)
+		return
+	}
+	if len(request.Operations) == 0 || len(request.Operations) > entities.MaxBatchOperations {
+		service.writeErrorResponse(w, fmt.Sprintf("invalid operations, must be from 1 to %d operations", entities.MaxBatchOperations), 400)
+		return
+	}
+
+	loc, err := service.requestLocation(r)
+	if err != nil {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+	for _, operation := range request.Operations {
+		if operation.Event != nil && operation.Event.Timezone == "" {
+			operation.Event.Timezone = loc.String()
+		}
+	}
+
+	ids, err := service.calendarFor(r).ApplyBatch(r.Context(), request.Operations)
+	if err != nil {
+		var operationErr *ErrorBatchOperation
+		switch {
+		case errors.As(err, &operationErr) && operationErr.Invalid:
+			service.writeErrorResponse(w, err.Error(), 400)
+		case err == entities.ErrTxNotSupported:
+			service.writeErrorResponse(w, err.Error(), 501)
+		default:
+			service.writeCalendarErrorResponse(w, err)
+		}
+		return
+	}
+
+	service.writeBatchResponse(w, ids, 200)
+}
+
+// Time zone of request: `tz` parameter, X-Timezone header or default time zone of service
+func (service *Service) requestLocation(r *http.Request) (*time.Location, error) {
+	tz := r.FormValue("tz")
+	if tz == "" {
+		tz = r.Header.Get("X-Timezone")
+	}
+	if tz == "" {
+		return service.location, nil
+	}
+
+	loc, err := entities.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone `%s`", tz)
+	}
+	return loc, nil
+}
+
+// Parse `timezone` parameter and set time zone of event, by default it is time zone of request
+func (service *Service) parseTimezoneParameter(r *http.Request, event *Event) error {
+	tz := r.Form.Get("timezone")
+	if tz == "" {
+		loc, err := service.requestLocation(r)
+		if err != nil {
+			return err
+		}
+		tz = loc.String()
+	}
+	return event.SetTimezone(tz)
+}
+
 // inner helper for parse form
 func (service *Service) parseForm(r *http.Request) {
 	err := r.ParseForm()
@@ -278,9 +814,47 @@ func (service *Service) writeOkResponse(w http.ResponseWriter, result string, co
 	}
 }
 
+// inner helper for write error json response on error of calendar
+// 422 with invalid fields for invalid event, 409 for busy date and version mismatch, 400 for invalid calendar,
+// 404 for unknown calendar, for other errors 200 (error is described in json response)
+func (service *Service) writeCalendarErrorResponse(w http.ResponseWriter, err error) {
+	var invalidErr *entities.ErrInvalidEvent
+	if errors.As(err, &invalidErr) {
+		fields := make([]FieldErrorResponse, 0, len(invalidErr.Fields()))
+		for _, field := range invalidErr.Fields() {
+			fields = append(fields, FieldErrorResponse{Field: field.Field(), Error: field.Error()})
+		}
+		service.writeErrorResponseWithFields(w, err.Error(), fields, 422)
+		return
+	}
+
+	var busyErr *entities.ErrDateBusy
+	if errors.As(err, &busyErr) || errors.Is(err, entities.StorageErrorVersionMismatch) {
+		service.writeErrorResponse(w, err.Error(), 409)
+		return
+	}
+
+	if errors.Is(err, entities.ErrEmptyCalendarName) || errors.Is(err, entities.ErrCalendarNameTooLong) {
+		service.writeErrorResponse(w, err.Error(), 400)
+		return
+	}
+
+	if errors.Is(err, entities.StorageErrorCalendarNotFound) {
+		service.writeErrorResponse(w, err.Error(), 404)
+		return
+	}
+
+	service.writeErrorResponse(w, err.Error(), 200)
+}
+
 // inner helper for write error json response
 func (service *Service) writeErrorResponse(w http.ResponseWriter, result string, code int) {
-	response := &ErrorResponse{result}
+	service.writeErrorResponseWithFields(w, result, nil, code)
+}
+
+// inner helper for write error json response with invalid fields
+func (service *Service) writeErrorResponseWithFields(w http.ResponseWriter, result string, fields []FieldErrorResponse, code int) {
+	response := &ErrorResponse{Error: result, Fields: fields}
 	data, err := json.Marshal(response)
 
 	if err != nil {
@@ -306,7 +880,12 @@ func (service *Service) writeErrorResponse(w http.ResponseWriter, result string,
 
 // inner helper for write ok json response with list of events
 func (service *Service) writeEventListResponse(w http.ResponseWriter, evens []*Event, code int) {
-	response := &EventListResponse{evens}
+	service.writeEventPageResponse(w, evens, "", code)
+}
+
+// inner helper for write ok json response with page of events and cursor of next page
+func (service *Service) writeEventPageResponse(w http.ResponseWriter, evens []*Event, nextCursor string, code int) {
+	response := &EventListResponse{evens, nextCursor}
 
 	if response.Result == nil {
 		response.Result = make([]*Event, 0) // empty slice must always covert to [] (empty array)
@@ -337,6 +916,200 @@ func (service *Service) writeEventListResponse(w http.ResponseWriter, evens []*E
 	w.Header().Set("Content-Type", "application/json")
 }
 
+// inner helper for write ok json response with list of calendars
+func (service *Service) writeCalendarListResponse(w http.ResponseWriter, calendars []*CalendarInfo, code int) {
+	response := &CalendarListResponse{calendars}
+	data, err := json.Marshal(response)
+
+	if err != nil {
+		if service.logger != nil {
+			service.logger.Errorf("Service.writeCalendarListResponse, marshal response error %s", err)
+		}
+		w.WriteHeader(500)
+		_, writeErr := w.Write([]byte("internal server error"))
+		if writeErr != nil && service.logger != nil {
+			service.logger.Errorf("Service.writeCalendarListResponse, write `internal server error` error %s", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_, writeErr := w.Write(data)
+	if writeErr != nil && service.logger != nil {
+		service.logger.Errorf("Service.writeCalendarListResponse, write `CalendarListResponse` error %s", err)
+	}
+}
+
+// inner helper for write ok json response with history of event
+func (service *Service) writeAuditEntryListResponse(w http.ResponseWriter, entries []*AuditEntry, code int) {
+	response := &AuditEntryListResponse{entries}
+	data, err := json.Marshal(response)
+
+	if err != nil {
+		if service.logger != nil {
+			service.logger.Errorf("Service.writeAuditEntryListResponse, marshal response error %s", err)
+		}
+		w.WriteHeader(500)
+		_, writeErr := w.Write([]byte("internal server error"))
+		if writeErr != nil && service.logger != nil {
+			service.logger.Errorf("Service.writeAuditEntryListResponse, write `internal server error` error %s", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_, writeErr := w.Write(data)
+	if writeErr != nil && service.logger != nil {
+		service.logger.Errorf("Service.writeAuditEntryListResponse, write `AuditEntryListResponse` error %s", err)
+	}
+}
+
+// inner helper for write ok json response with free/busy
+func (service *Service) writeFreeBusyResponse(w http.ResponseWriter, freeBusy *FreeBusy, code int) {
+	response := &FreeBusyResponse{freeBusy}
+	data, err := json.Marshal(response)
+
+	if err != nil {
+		if service.logger != nil {
+			service.logger.Errorf("Service.writeFreeBusyResponse, marshal response error %s", err)
+		}
+		w.WriteHeader(500)
+		_, writeErr := w.Write([]byte("internal server error"))
+		if writeErr != nil && service.logger != nil {
+			service.logger.Errorf("Service.writeFreeBusyResponse, write `internal server error` error %s", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_, writeErr := w.Write(data)
+	if writeErr != nil && service.logger != nil {
+		service.logger.Errorf("Service.writeFreeBusyResponse, write `FreeBusyResponse` error %s", err)
+	}
+}
+
+// inner helper for write json response with ids of events of operations of batch
+func (service *Service) writeBatchResponse(w http.ResponseWriter, ids []int, code int) {
+	response := &BatchResponse{ids}
+	data, err := json.Marshal(response)
+
+	if err != nil {
+		if service.logger != nil {
+			service.logger.Errorf("Service.writeBatchResponse, marshal response error %s", err)
+		}
+		w.WriteHeader(500)
+		_, writeErr := w.Write([]byte("internal server error"))
+		if writeErr != nil && service.logger != nil {
+			service.logger.Errorf("Service.writeBatchResponse, write `internal server error` error %s", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_, writeErr := w.Write(data)
+	if writeErr != nil && service.logger != nil {
+		service.logger.Errorf("Service.writeBatchResponse, write `BatchResponse` error %s", err)
+	}
+}
+
+// Parse `workStart` and `workEnd` (HH:MM) parameters, missing parameters are default working hours
+func parseWorkingHoursParameters(r *http.Request) (entities.WorkingHours, error) {
+	workStart := r.Form.Get("workStart")
+	if workStart == "" {
+		workStart = entities.DefaultWorkStart
+	}
+	workEnd := r.Form.Get("workEnd")
+	if workEnd == "" {
+		workEnd = entities.DefaultWorkEnd
+	}
+	return entities.NewWorkingHours(workStart, workEnd)
+}
+
+// Parse expected version of updated event: `version` parameter or If-Match header with ETag of event ("<version>")
+// Missing version means update without check
+func parseVersionParameter(r *http.Request, event *Event) error {
+	versionStr := r.Form.Get("version")
+	if versionStr == "" {
+		versionStr = strings.TrimPrefix(r.Header.Get("If-Match"), "W/")
+		versionStr = strings.Trim(versionStr, `"`)
+	}
+
+	if versionStr == "" {
+		return nil
+	}
+
+	version, err := strconv.Atoi(versionStr)
+	if err != nil || version <= 0 {
+		return errors.New("invalid version parameter (or If-Match header), must be int greater than 0")
+	}
+
+	event.Version = version
+	return nil
+}
+
+// Parse `calendarId` parameter and set calendar of event, missing parameter means event without calendar
+func parseCalendarIdParameter(r *http.Request, event *Event) error {
+	calendarIdStr := r.Form.Get("calendarId")
+	if calendarIdStr == "" {
+		return nil
+	}
+
+	calendarId, err := strconv.Atoi(calendarIdStr)
+	if err != nil || calendarId <= 0 {
+		return errors.New("invalid calendarId parameter, must be int greater than 0")
+	}
+
+	event.CalendarId = calendarId
+	return nil
+}
+
+// Parse `calendars` parameter (comma separated list of ids of calendars), missing parameter means all calendars
+func parseCalendarsParameter(r *http.Request) ([]int, error) {
+	calendarsStr := r.FormValue("calendars")
+	if calendarsStr == "" {
+		return nil, nil
+	}
+
+	var calendarIds []int
+	for _, calendarIdStr := range strings.Split(calendarsStr, ",") {
+		calendarId, err := strconv.Atoi(strings.TrimSpace(calendarIdStr))
+		if err != nil || calendarId <= 0 {
+			return nil, errors.New("invalid calendars parameter, must be comma separated list of ids of calendars")
+		}
+		calendarIds = append(calendarIds, calendarId)
+	}
+
+	return calendarIds, nil
+}
+
+// Parse `limit` parameter (size of page of events), default page size if it is missing
+func parseLimitParameter(r *http.Request) (int, error) {
+	limitStr := r.FormValue("limit")
+	if limitStr == "" {
+		return entities.DefaultPageLimit, nil
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 || limit > entities.MaxPageLimit {
+		return 0, fmt.Errorf("invalid limit parameter, must be int from 1 to %d", entities.MaxPageLimit)
+	}
+	return limit, nil
+}
+
+// Parse `rejectConflicts` parameter (1 or true), false if it is missing or invalid
+func parseRejectConflictsParameter(r *http.Request) bool {
+	rejectConflicts, err := strconv.ParseBool(r.Form.Get("rejectConflicts"))
+	return err == nil && rejectConflicts
+}
+
 func parseBeforeMinutesParameter(r *http.Request) (bool, int) {
 
 	beforeMinutesStr := r.Form.Get("beforeMinutes")
@@ -354,3 +1127,49 @@ func parseBeforeMinutesParameter(r *http.Request) (bool, int) {
 
 	return isNotifyingEnabled, beforeMinutes
 }
+
+// Parse `reminders` parameter (comma separated list of before minutes) and set reminders of event
+func parseRemindersParameter(r *http.Request, event *Event) error {
+	remindersStr := r.Form.Get("reminders")
+	if remindersStr == "" {
+		return nil
+	}
+
+	var reminders []int
+	for _, beforeMinutesStr := range strings.Split(remindersStr, ",") {
+		beforeMinutes, err := strconv.Atoi(strings.TrimSpace(beforeMinutesStr))
+		if err != nil {
+			return errors.New("invalid reminders parameter, must be comma separated list of minutes")
+		}
+		reminders = append(reminders, beforeMinutes)
+	}
+
+	event.SetReminders(reminders)
+
+	return nil
+}
+
+// Parse `description`, `location`, `organizer`, `attendees` (comma separated list of emails) and `color` parameters
+// and set details of event
+func parseDetailsParameters(r *http.Request, event *Event) error {
+	var attendees []string
+	if attendeesStr := r.Form.Get("attendees"); attendeesStr != "" {
+		attendees = strings.Split(attendeesStr, ",")
+	}
+	return event.SetDetails(
+		r.Form.Get("description"),
+		r.Form.Get("location"),
+		r.Form.Get("organizer"),
+		attendees,
+		r.Form.Get("color"),
+	)
+}
+
+// Parse `rrule` and `exdates` (comma separated list of Y-m-d H:i datetimes) parameters and set recurrence of event
+func parseRecurrenceParameters(r *http.Request, event *Event) error {
+	var exDates []string
+	if exDatesStr := r.Form.Get("exdates"); exDatesStr != "" {
+		exDates = strings.Split(exDatesStr, ",")
+	}
+	return event.SetRecurrence(r.Form.Get("rrule"), exDates)
+}
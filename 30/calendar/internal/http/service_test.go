@@ -1,16 +1,22 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorilla/mux"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/auth"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/memory"
 )
 
@@ -69,7 +75,7 @@ func TestCreateEventOK(t *testing.T) {
 		return
 	}
 
-	event, ok := service.Calendar.GetEvent(id)
+	event, ok := service.Calendar.GetEvent(context.Background(), id)
 
 	if !ok {
 		t.Error("Expected event be present in calendar")
@@ -83,9 +89,11 @@ func TestCreateEventOK(t *testing.T) {
 		End:                "2019-10-15 22:00",
 		IsNotifyingEnabled: true,
 		BeforeMinutes:      10,
+		Reminders:          []int{10},
+		Version:            1,
 	}
 
-	if *event != expectedEvent {
+	if !reflect.DeepEqual(*event, expectedEvent) {
 		t.Errorf("Expected\n`%+v`\ngot\n`%+v`", expectedEvent, *event)
 	}
 }
@@ -153,6 +161,7 @@ func TestUpdateEventOK(t *testing.T) {
 		End:                "2019-10-16 01:00",
 		IsNotifyingEnabled: true,
 		BeforeMinutes:      5,
+		Reminders:          []int{5},
 	}
 
 	data := url.Values{}
@@ -193,7 +202,7 @@ func TestUpdateEventOK(t *testing.T) {
 		return
 	}
 
-	event, found := service.GetEvent(id)
+	event, found := service.GetEvent(context.Background(), id)
 	if !found {
 		t.Errorf("event with id = %d not found on entities service", id)
 		return
@@ -201,7 +210,8 @@ func TestUpdateEventOK(t *testing.T) {
 
 	expectedEvent := *event2
 	expectedEvent.Id = id
-	if expectedEvent != *event {
+	expectedEvent.Version = 2
+	if !reflect.DeepEqual(expectedEvent, *event) {
 		t.Errorf("\nevent info not updated\nexpected be:\n%#v\ngot:\n%#v\n", expectedEvent, event)
 	}
 
@@ -263,7 +273,7 @@ func TestUpdateEventInvalidId(t *testing.T) {
 		t.Errorf("unexpected error %s, must be %s", errResp.Error, expectedErr)
 	}
 
-	event, found := service.GetEvent(id)
+	event, found := service.GetEvent(context.Background(), id)
 	if !found {
 		t.Errorf("event with id = %d not found on entities service", id)
 		return
@@ -330,7 +340,7 @@ func TestUpdateEventInvalidDate(t *testing.T) {
 		t.Errorf("unexpected error `%s` instread of `%s`", errResp.Error, DefaultErrorInvalidDatetime.Error())
 	}
 
-	event, found := service.GetEvent(id)
+	event, found := service.GetEvent(context.Background(), id)
 	if !found {
 		t.Errorf("event with id = %d not found on entities service", id)
 		return
@@ -446,7 +456,7 @@ func TestDeleteEventOK(t *testing.T) {
 		return
 	}
 
-	_, found := service.GetEvent(id)
+	_, found := service.GetEvent(context.Background(), id)
 	if found {
 		t.Errorf("event with id = %d have not be deleted on entities service", id)
 		return
@@ -675,8 +685,1192 @@ func TestGetEventsForMonth(t *testing.T) {
 	}
 }
 
+// Month is got by pages, pages follow each other without gaps and duplicates
+func TestGetEventsForMonthByPages(t *testing.T) {
+	service := NewTestService()
+
+	addFixedListOfEvents(t, &service.Calendar)
+
+	now := time.Date(2019, 11, 21, 8, 0, 0, 0, time.UTC)
+
+	getPage := func(params url.Values) (int, *EventListResponse) {
+		req := httptest.NewRequest("GET", "http://test.com/events_for_month?"+params.Encode(), nil)
+		w := httptest.NewRecorder()
+		service.getEventsForMonth(now, w, req)
+
+		resp := w.Result()
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+
+		eventListResp := &EventListResponse{}
+		_ = json.Unmarshal(respBody, eventListResp)
+		return resp.StatusCode, eventListResp
+	}
+
+	var ids []int
+	params := url.Values{"limit": {"3"}}
+	for page := 0; ; page++ {
+		code, resp := getPage(params)
+		if code != 200 {
+			t.Fatalf("must be status code 200 not %d", code)
+		}
+		if page > 3 {
+			t.Fatalf("pages must end")
+		}
+		if resp.NextCursor != "" && len(resp.Result) != 3 {
+			t.Errorf("page that is not last must have 3 events instead of %d", len(resp.Result))
+		}
+		for _, event := range resp.Result {
+			ids = append(ids, event.Id)
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		params.Set("cursor", resp.NextCursor)
+	}
+
+	if !reflect.DeepEqual(ids, []int{1, 2, 3, 4, 5, 6, 7}) {
+		t.Errorf("events of pages must be %v instead of %v", []int{1, 2, 3, 4, 5, 6, 7}, ids)
+	}
+
+	for _, params := range []url.Values{{"limit": {"0"}}, {"limit": {"1001"}}, {"limit": {"x"}}, {"cursor": {"x"}}} {
+		code, _ := getPage(params)
+		if code != 400 {
+			t.Errorf("must be status code 400 for %v not %d", params, code)
+		}
+	}
+}
+
+func TestCreateRecurringEvent(t *testing.T) {
+	service := NewTestService()
+
+	data := url.Values{}
+	data.Set("name", "Stand-up")
+	data.Set("start", "2019-11-18 10:00")
+	data.Set("end", "2019-11-18 10:15")
+	data.Set("rrule", "FREQ=WEEKLY;BYDAY=MO,TH")
+	data.Set("exdates", "2019-11-21 10:00,2019-11-25 10:00")
+
+	req := httptest.NewRequest("POST", "http://test.com/create_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+
+	service.CreateEvent(w, req)
+
+	if w.Result().StatusCode != 200 {
+		t.Fatalf("must be status code 200 not %d", w.Result().StatusCode)
+	}
+
+	req = httptest.NewRequest("GET", "http://test.com/events_for_month", nil)
+	w = httptest.NewRecorder()
+
+	now := time.Date(2019, 11, 21, 8, 0, 0, 0, time.UTC)
+	service.getEventsForMonth(now, w, req)
+
+	respBody, _ := ioutil.ReadAll(w.Result().Body)
+
+	eventListResp := &EventListResponse{}
+	err := json.Unmarshal(respBody, eventListResp)
+	if err != nil {
+		t.Fatalf("failed on unmarshal json %s", err)
+	}
+
+	var starts []string
+	for _, event := range eventListResp.Result {
+		starts = append(starts, event.Start)
+	}
+
+	expectedStarts := []string{"2019-11-18 10:00", "2019-11-28 10:00"}
+	if !reflect.DeepEqual(expectedStarts, starts) {
+		t.Errorf("expected occurrences %v instead of %v", expectedStarts, starts)
+	}
+}
+
+func TestCreateRecurringEventInvalidRrule(t *testing.T) {
+	service := NewTestService()
+
+	data := url.Values{}
+	data.Set("name", "Stand-up")
+	data.Set("start", "2019-11-18 10:00")
+	data.Set("end", "2019-11-18 10:15")
+	data.Set("rrule", "FREQ=WEEKLY;BYDAY=XX")
+
+	req := httptest.NewRequest("POST", "http://test.com/create_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+
+	service.CreateEvent(w, req)
+
+	if w.Result().StatusCode != 400 {
+		t.Errorf("must be status code 400 not %d", w.Result().StatusCode)
+	}
+
+	if service.Calendar.getEventsTotalCount() != 0 {
+		t.Errorf("unexpected count of events in entities, must be 0 instead of %d", service.Calendar.getEventsTotalCount())
+	}
+}
+
+func TestGetEventsForWeekWithAllDayEvent(t *testing.T) {
+	service := NewTestService()
+
+	addEvent(t, &service.Calendar, &Event{
+		Name:   "Holidays",
+		Start:  "2019-11-15",
+		End:    "2019-11-18",
+		AllDay: true,
+	}, 1)
+
+	req := httptest.NewRequest("GET", "http://test.com/events_for_week", nil)
+	w := httptest.NewRecorder()
+
+	now := time.Date(2019, 11, 21, 8, 0, 0, 0, time.UTC)
+	service.getEventsForWeek(now, w, req)
+
+	respBody, _ := ioutil.ReadAll(w.Result().Body)
+
+	eventListResp := &EventListResponse{}
+	err := json.Unmarshal(respBody, eventListResp)
+	if err != nil {
+		t.Fatalf("failed on unmarshal json %s", err)
+	}
+
+	if len(eventListResp.Result) != 1 {
+		t.Fatalf("event list must has 1 event instead of %d", len(eventListResp.Result))
+	}
+
+	event := eventListResp.Result[0]
+	if !event.AllDay || event.Start != "2019-11-15" || event.End != "2019-11-18" {
+		t.Errorf("unexpected event %+v", *event)
+	}
+}
+
+func TestGetEventsForDayInTimezone(t *testing.T) {
+	service := NewTestService()
+
+	data := url.Values{}
+	data.Set("name", "Night flight")
+	data.Set("start", "2019-11-25 01:00")
+	data.Set("end", "2019-11-25 05:00")
+	data.Set("timezone", "Europe/Moscow")
+
+	req := httptest.NewRequest("POST", "http://test.com/create_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	service.CreateEvent(w, req)
+	if w.Result().StatusCode != 200 {
+		t.Fatalf("must be status code 200 not %d", w.Result().StatusCode)
+	}
+
+	// 25 Nov 01:00 in Moscow is 24 Nov 22:00 in UTC
+	now := time.Date(2019, 11, 25, 10, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		url      string
+		header   string
+		expected int
+	}{
+		{"http://test.com/events_for_day", "", 0},
+		{"http://test.com/events_for_day?tz=Europe/Moscow", "", 1},
+		{"http://test.com/events_for_day", "Europe/Moscow", 1},
+		{"http://test.com/events_for_day?tz=UTC", "Europe/Moscow", 0},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", c.url, nil)
+		if c.header != "" {
+			req.Header.Set("X-Timezone", c.header)
+		}
+		w := httptest.NewRecorder()
+
+		service.getEventsForDay(now, w, req)
+
+		respBody, _ := ioutil.ReadAll(w.Result().Body)
+		eventListResp := &EventListResponse{}
+		err := json.Unmarshal(respBody, eventListResp)
+		if err != nil {
+			t.Fatalf("failed on unmarshal json %s", err)
+		}
+
+		if len(eventListResp.Result) != c.expected {
+			t.Errorf("%s (X-Timezone: %s) event list must has %d events instead of %d", c.url, c.header, c.expected, len(eventListResp.Result))
+			continue
+		}
+
+		if c.expected == 1 {
+			event := eventListResp.Result[0]
+			if event.Start != "2019-11-25 01:00" || event.Timezone != "Europe/Moscow" {
+				t.Errorf("event must be in its own time zone, got %+v", *event)
+			}
+		}
+	}
+
+	req = httptest.NewRequest("GET", "http://test.com/events_for_day?tz=Mars/Olympus", nil)
+	w = httptest.NewRecorder()
+	service.getEventsForDay(now, w, req)
+	if w.Result().StatusCode != 400 {
+		t.Errorf("must be status code 400 on unknown time zone not %d", w.Result().StatusCode)
+	}
+}
+
+func TestCreateEventWithDetails(t *testing.T) {
+	service := NewTestService()
+
+	data := url.Values{}
+	data.Set("name", "Planning")
+	data.Set("start", "2019-11-25 10:00")
+	data.Set("end", "2019-11-25 11:00")
+	data.Set("description", "Quarterly planning")
+	data.Set("location", "Room 42")
+	data.Set("organizer", "boss@example.com")
+	data.Set("attendees", "alice@example.com,bob@example.com")
+	data.Set("color", "#ff0000")
+
+	req := httptest.NewRequest("POST", "http://test.com/create_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	service.CreateEvent(w, req)
+	if w.Result().StatusCode != 200 {
+		t.Fatalf("must be status code 200 not %d", w.Result().StatusCode)
+	}
+
+	events, _ := service.Calendar.GetEventsByPeriod(context.Background(), "", "")
+	if len(events) != 1 {
+		t.Fatalf("calendar must has 1 event instead of %d", len(events))
+	}
+
+	expectedEvent := Event{
+		Id:          events[0].Id,
+		Name:        "Planning",
+		Start:       "2019-11-25 10:00",
+		End:         "2019-11-25 11:00",
+		Description: "Quarterly planning",
+		Location:    "Room 42",
+		Organizer:   "boss@example.com",
+		Attendees:   []string{"alice@example.com", "bob@example.com"},
+		AttendeeStatuses: map[string]string{
+			"alice@example.com": "needs-action",
+			"bob@example.com":   "needs-action",
+		},
+		Color:   "#ff0000",
+		Version: 1,
+	}
+
+	if !reflect.DeepEqual(*events[0], expectedEvent) {
+		t.Errorf("Expected\n`%+v`\ngot\n`%+v`", expectedEvent, *events[0])
+	}
+
+	data.Set("attendees", "alice@example.com,bob")
+	req = httptest.NewRequest("POST", "http://test.com/create_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+
+	service.CreateEvent(w, req)
+	if w.Result().StatusCode != 400 {
+		t.Errorf("must be status code 400 on invalid attendee email not %d", w.Result().StatusCode)
+	}
+}
+
+func TestCreateEventValidation(t *testing.T) {
+	service := NewTestService()
+
+	cases := []struct {
+		name          string
+		start         string
+		end           string
+		beforeMinutes string
+		field         string
+	}{
+		{"", "2019-10-15 20:00", "2019-10-15 22:00", "", "name"},
+		{strings.Repeat("x", 257), "2019-10-15 20:00", "2019-10-15 22:00", "", "name"},
+		{"Do homework", "2019-10-15 20:00", "2019-10-15 19:00", "", "end"},
+		{"Do homework", "2019-10-15 20:00", "2019-10-15 22:00", "-5", "beforeMinutes"},
+		{"Do homework", "2019-09-15 20:00", "2019-09-15 22:00", "", "start"},
+	}
+
+	for _, c := range cases {
+		data := url.Values{}
+		data.Set("name", c.name)
+		data.Set("start", c.start)
+		data.Set("end", c.end)
+		data.Set("beforeMinutes", c.beforeMinutes)
+
+		req := httptest.NewRequest("POST", "http://test.com/create_event", strings.NewReader(data.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		service.CreateEvent(w, req)
+
+		if w.Result().StatusCode != 422 {
+			t.Errorf("must be status code 422 on invalid %s not %d", c.field, w.Result().StatusCode)
+			continue
+		}
+
+		respBody, _ := ioutil.ReadAll(w.Result().Body)
+		errResp := &ErrorResponse{}
+		err := json.Unmarshal(respBody, errResp)
+		if err != nil {
+			t.Fatalf("failed on unmarshal json %s", err)
+		}
+
+		if len(errResp.Fields) != 1 || errResp.Fields[0].Field != c.field || errResp.Fields[0].Error != errResp.Error {
+			t.Errorf("response must has one invalid field `%s` instead of %+v", c.field, errResp)
+		}
+	}
+
+	if service.Calendar.getEventsTotalCount() != 0 {
+		t.Errorf("unexpected count of events in entities, must be 0 instead of %d", service.Calendar.getEventsTotalCount())
+	}
+
+	// existing event could be moved to the past, but it still must be valid
+	id, _ := service.AddEvent(context.Background(), &Event{Name: "Do homework", Start: "2019-10-15 20:00", End: "2019-10-15 22:00"})
+
+	data := url.Values{}
+	data.Set("id", strconv.Itoa(id))
+	data.Set("name", "Do homework")
+	data.Set("start", "2019-09-15 20:00")
+	data.Set("end", "2019-09-15 22:00")
+
+	req := httptest.NewRequest("POST", "http://test.com/update_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	service.UpdateEvent(w, req)
+	if w.Result().StatusCode != 200 {
+		t.Errorf("must be status code 200 on moving event to the past not %d", w.Result().StatusCode)
+	}
+
+	data.Set("end", "2019-09-15 19:00")
+	req = httptest.NewRequest("POST", "http://test.com/update_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	service.UpdateEvent(w, req)
+	if w.Result().StatusCode != 422 {
+		t.Errorf("must be status code 422 on invalid range not %d", w.Result().StatusCode)
+	}
+}
+
+func TestCreateEventRejectConflicts(t *testing.T) {
+	service := NewTestService()
+
+	data := url.Values{}
+	data.Set("name", "Booking")
+	data.Set("start", "2019-11-25 10:00")
+	data.Set("end", "2019-11-25 11:00")
+	data.Set("location", "Room 42")
+
+	createEvent := func(data url.Values) int {
+		req := httptest.NewRequest("POST", "http://test.com/create_event", strings.NewReader(data.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		service.CreateEvent(w, req)
+		return w.Result().StatusCode
+	}
+
+	if code := createEvent(data); code != 200 {
+		t.Fatalf("must be status code 200 not %d", code)
+	}
+
+	data.Set("start", "2019-11-25 10:30")
+	data.Set("end", "2019-11-25 11:30")
+	data.Set("rejectConflicts", "1")
+	if code := createEvent(data); code != 409 {
+		t.Errorf("must be status code 409 on busy date not %d", code)
+	}
+
+	data.Set("start", "2019-11-25 11:00")
+	data.Set("end", "2019-11-25 12:00")
+	if code := createEvent(data); code != 200 {
+		t.Errorf("must be status code 200 for back to back event not %d", code)
+	}
+
+	// update of first event overlaps second event
+	data.Set("id", "1")
+	data.Set("start", "2019-11-25 10:00")
+	data.Set("end", "2019-11-25 11:15")
+	req := httptest.NewRequest("POST", "http://test.com/update_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	service.UpdateEvent(w, req)
+	if w.Result().StatusCode != 409 {
+		t.Errorf("must be status code 409 on busy date not %d", w.Result().StatusCode)
+	}
+
+	// without opt-in overlapping is allowed
+	data.Del("rejectConflicts")
+	data.Del("id")
+	if code := createEvent(data); code != 200 {
+		t.Errorf("must be status code 200 without rejectConflicts not %d", code)
+	}
+}
+
+func TestAuthenticatedAccess(t *testing.T) {
+	authenticator := auth.NewAuthenticator(map[string]string{"key-alice": "alice", "key-bob": "bob"}, "secret")
+	service, _ := NewService("", memory.NewStorage(), nil, nil, nil, authenticator)
+	service.now = testNow
+
+	createEvent := service.authMiddleware(http.HandlerFunc(service.CreateEvent))
+	deleteEvent := service.authMiddleware(http.HandlerFunc(service.DeleteEvent))
+	now := time.Date(2019, 11, 25, 10, 0, 0, 0, time.UTC)
+	getEventsForDay := service.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		service.getEventsForDay(now, w, r)
+	}))
+
+	data := url.Values{}
+	data.Set("name", "Team sync")
+	data.Set("start", "2019-11-25 11:00")
+	data.Set("end", "2019-11-25 12:00")
+
+	// without credentials
+	req := httptest.NewRequest("POST", "http://test.com/create_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	createEvent.ServeHTTP(w, req)
+	if w.Result().StatusCode != 401 {
+		t.Errorf("must be status code 401 without credentials not %d", w.Result().StatusCode)
+	}
+
+	// invalid api key
+	req = httptest.NewRequest("POST", "http://test.com/create_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Api-Key", "key-eve")
+	w = httptest.NewRecorder()
+	createEvent.ServeHTTP(w, req)
+	if w.Result().StatusCode != 401 {
+		t.Errorf("must be status code 401 on invalid api key not %d", w.Result().StatusCode)
+	}
+
+	req = httptest.NewRequest("POST", "http://test.com/create_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Api-Key", "key-alice")
+	w = httptest.NewRecorder()
+	createEvent.ServeHTTP(w, req)
+	if w.Result().StatusCode != 200 {
+		t.Fatalf("must be status code 200 not %d", w.Result().StatusCode)
+	}
+
+	token, _ := authenticator.IssueToken("bob", time.Hour)
+
+	getEvents := func(header, value string) []*Event {
+		req := httptest.NewRequest("GET", "http://test.com/events_for_day", nil)
+		req.Header.Set(header, value)
+		w := httptest.NewRecorder()
+		getEventsForDay.ServeHTTP(w, req)
+
+		respBody, _ := ioutil.ReadAll(w.Result().Body)
+		eventListResp := &EventListResponse{}
+		err := json.Unmarshal(respBody, eventListResp)
+		if err != nil {
+			t.Fatalf("failed on unmarshal json %s", err)
+		}
+		return eventListResp.Result
+	}
+
+	events := getEvents("X-Api-Key", "key-alice")
+	if len(events) != 1 || events[0].Owner != "alice" {
+		t.Fatalf("alice must see her own event, got %+v", events)
+	}
+
+	events = getEvents("Authorization", "Bearer "+token)
+	if len(events) != 0 {
+		t.Errorf("bob must not see events of alice, got %d events", len(events))
+	}
+
+	// bob couldn't delete event of alice
+	data = url.Values{}
+	data.Set("id", strconv.Itoa(1))
+	req = httptest.NewRequest("POST", "http://test.com/delete_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	deleteEvent.ServeHTTP(w, req)
+
+	respBody, _ := ioutil.ReadAll(w.Result().Body)
+	errorResp := &ErrorResponse{}
+	err := json.Unmarshal(respBody, errorResp)
+	if err != nil || errorResp.Error == "" {
+		t.Errorf("bob must not be able to delete event of alice, got %s", respBody)
+	}
+
+	if len(getEvents("X-Api-Key", "key-alice")) != 1 {
+		t.Errorf("event of alice must not be deleted")
+	}
+}
+
 func NewTestService() *Service {
 	storage := memory.NewStorage()
-	service, _ := NewService("", storage, nil, nil)
+	service, _ := NewService("", storage, nil, nil, nil, nil)
+	service.now = testNow
 	return service
 }
+
+func TestCreateEventWithReminders(t *testing.T) {
+	service := NewTestService()
+
+	data := url.Values{}
+	data.Set("name", "Planning")
+	data.Set("start", "2019-11-25 10:00")
+	data.Set("end", "2019-11-25 11:00")
+	data.Set("beforeMinutes", "10")
+	data.Set("reminders", "60,10,1440")
+
+	req := httptest.NewRequest("POST", "http://test.com/create_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	service.CreateEvent(w, req)
+	if w.Result().StatusCode != 200 {
+		t.Fatalf("must be status code 200 not %d", w.Result().StatusCode)
+	}
+
+	events, _ := service.Calendar.GetEventsByPeriod(context.Background(), "", "")
+	if len(events) != 1 {
+		t.Fatalf("calendar must has 1 event instead of %d", len(events))
+	}
+
+	expectedReminders := []int{1440, 60, 10}
+	if !reflect.DeepEqual(events[0].Reminders, expectedReminders) {
+		t.Errorf("reminders must be %v instead of %v", expectedReminders, events[0].Reminders)
+	}
+
+	data.Set("reminders", "60,soon")
+	req = httptest.NewRequest("POST", "http://test.com/create_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+
+	service.CreateEvent(w, req)
+	if w.Result().StatusCode != 400 {
+		t.Errorf("must be status code 400 on invalid reminders not %d", w.Result().StatusCode)
+	}
+
+	data.Set("reminders", "60,-5")
+	req = httptest.NewRequest("POST", "http://test.com/create_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+
+	service.CreateEvent(w, req)
+	if w.Result().StatusCode != 422 {
+		t.Errorf("must be status code 422 on negative reminder not %d", w.Result().StatusCode)
+	}
+}
+
+func TestGetFreeBusy(t *testing.T) {
+	service := NewTestService()
+
+	events := []*Event{
+		{Name: "Morning", Start: "2019-11-25 09:00", End: "2019-11-25 10:00", Timezone: "Europe/Moscow"},
+		{Name: "Stand-up", Start: "2019-11-25 10:00", End: "2019-11-25 10:30", Timezone: "Europe/Moscow"},
+		{Name: "Lunch", Start: "2019-11-25 12:00", End: "2019-11-25 13:00", Timezone: "Europe/Moscow"},
+		{Name: "Review", Start: "2019-11-25 12:30", End: "2019-11-25 14:00", Timezone: "Europe/Moscow"},
+	}
+	for _, event := range events {
+		if _, err := service.AddEvent(context.Background(), event); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "http://test.com/free_busy?start=2019-11-25+00:00&end=2019-11-26+00:00&tz=Europe/Moscow&minFreeMinutes=60", nil)
+	w := httptest.NewRecorder()
+
+	service.GetFreeBusy(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("must be status code 200 not %d", resp.StatusCode)
+	}
+
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	freeBusyResp := &FreeBusyResponse{}
+	err := json.Unmarshal(respBody, freeBusyResp)
+	if err != nil {
+		t.Fatalf("failed on unmarshal json %s", err)
+	}
+
+	expected := &FreeBusy{
+		Busy: []Interval{
+			{"2019-11-25 09:00", "2019-11-25 10:30"},
+			{"2019-11-25 12:00", "2019-11-25 14:00"},
+		},
+		Free: []Interval{
+			{"2019-11-25 10:30", "2019-11-25 12:00"},
+			{"2019-11-25 14:00", "2019-11-25 18:00"},
+		},
+	}
+	if !reflect.DeepEqual(freeBusyResp.Result, expected) {
+		t.Errorf("Expected\n`%+v`\ngot\n`%+v`", expected, freeBusyResp.Result)
+	}
+
+	invalidQueries := []string{
+		"start=2019-11-26+00:00&end=2019-11-25+00:00",
+		"start=2019-11-25&end=2019-11-26+00:00",
+		"start=2019-11-25+00:00&end=2019-11-26+00:00&minFreeMinutes=-1",
+		"start=2019-11-25+00:00&end=2019-11-26+00:00&minFreeMinutes=60&workStart=25:00",
+	}
+	for _, query := range invalidQueries {
+		req := httptest.NewRequest("GET", "http://test.com/free_busy?"+query, nil)
+		w := httptest.NewRecorder()
+
+		service.GetFreeBusy(w, req)
+		if w.Result().StatusCode != 400 {
+			t.Errorf("must be status code 400 for `%s` not %d", query, w.Result().StatusCode)
+		}
+	}
+}
+
+// Storage that hides optional features (search, transactions) of memory storage
+type storageWithoutFeatures struct {
+	entities.Storage
+}
+
+func (s storageWithoutFeatures) ForOwner(owner string) entities.Storage {
+	return storageWithoutFeatures{s.Storage.ForOwner(owner)}
+}
+
+func TestSearchEvents(t *testing.T) {
+	service := NewTestService()
+
+	events := []*Event{
+		{Name: "Retro of sprint 41", Start: "2019-11-01 10:00", End: "2019-11-01 11:00"},
+		{Name: "Retro", Start: "2019-11-08 10:00", End: "2019-11-08 11:00"},
+		{Name: "Sprint planning", Start: "2019-11-15 10:00", End: "2019-11-15 11:00"},
+	}
+	for _, event := range events {
+		if _, err := service.AddEvent(context.Background(), event); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+	}
+
+	search := func(service *Service, params url.Values) (int, *EventListResponse) {
+		req := httptest.NewRequest("GET", "http://test.com/search?"+params.Encode(), nil)
+		w := httptest.NewRecorder()
+		service.SearchEvents(w, req)
+
+		resp := w.Result()
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		eventListResp := &EventListResponse{}
+		_ = json.Unmarshal(respBody, eventListResp)
+		return resp.StatusCode, eventListResp
+	}
+
+	code, resp := search(service, url.Values{"q": {"retro"}})
+	if code != 200 {
+		t.Fatalf("must be status code 200 not %d", code)
+	}
+	if len(resp.Result) != 2 || resp.Result[0].Name != "Retro" || resp.Result[1].Name != "Retro of sprint 41" {
+		t.Errorf("must be found `Retro` and `Retro of sprint 41` instead of %v", resp.Result)
+	}
+
+	code, resp = search(service, url.Values{"q": {"retro"}, "start": {"2019-10-31 00:00"}, "end": {"2019-11-05 00:00"}})
+	if code != 200 || len(resp.Result) != 1 || resp.Result[0].Name != "Retro of sprint 41" {
+		t.Errorf("must be found only `Retro of sprint 41` in period instead of %d %v", code, resp.Result)
+	}
+
+	for _, params := range []url.Values{{"q": {""}}, {"q": {"retro"}, "start": {"x"}}, {"q": {"retro"}, "limit": {"0"}}} {
+		code, _ := search(service, params)
+		if code != 400 {
+			t.Errorf("must be status code 400 for %v not %d", params, code)
+		}
+	}
+
+	// storage without search
+	service, _ = NewService("", storageWithoutFeatures{memory.NewStorage()}, nil, nil, nil, nil)
+	code, _ = search(service, url.Values{"q": {"retro"}})
+	if code != 501 {
+		t.Errorf("must be status code 501 for storage without search not %d", code)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	service := NewTestService()
+
+	id, err := service.AddEvent(context.Background(), &Event{Name: "Meeting", Start: "2019-11-25 10:00", End: "2019-11-25 11:00"})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	deletedId, _ := service.AddEvent(context.Background(), &Event{Name: "Lunch", Start: "2019-11-25 13:00", End: "2019-11-25 14:00"})
+
+	batch := func(service *Service, body string) (int, *BatchResponse, *ErrorResponse) {
+		req := httptest.NewRequest("POST", "http://test.com/batch", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		service.Batch(w, req)
+
+		resp := w.Result()
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		batchResp := &BatchResponse{}
+		errorResp := &ErrorResponse{}
+		_ = json.Unmarshal(respBody, batchResp)
+		_ = json.Unmarshal(respBody, errorResp)
+		return resp.StatusCode, batchResp, errorResp
+	}
+
+	code, resp, _ := batch(service, `{"operations": [
+		{"op": "create", "event": {"name": "Retro", "start": "2019-11-26 10:00", "end": "2019-11-26 11:00"}},
+		{"op": "update", "id": `+strconv.Itoa(id)+`, "event": {"name": "Planning", "start": "2019-11-25 10:00", "end": "2019-11-25 11:00"}},
+		{"op": "delete", "id": `+strconv.Itoa(deletedId)+`}
+	]}`)
+	if code != 200 {
+		t.Fatalf("must be status code 200 not %d", code)
+	}
+	if len(resp.Result) != 3 || resp.Result[1] != id || resp.Result[2] != deletedId {
+		t.Fatalf("must be ids of 3 events instead of %v", resp.Result)
+	}
+	if event, ok := service.GetEvent(context.Background(), resp.Result[0]); !ok || event.Name != "Retro" {
+		t.Errorf("event must be created, got %v", event)
+	}
+	if event, ok := service.GetEvent(context.Background(), id); !ok || event.Name != "Planning" {
+		t.Errorf("event must be updated, got %v", event)
+	}
+	if _, ok := service.GetEvent(context.Background(), deletedId); ok {
+		t.Errorf("event must be deleted")
+	}
+
+	// the last operation fails, so nothing is applied
+	code, _, errResp := batch(service, `{"operations": [
+		{"op": "create", "event": {"name": "Demo", "start": "2019-11-27 10:00", "end": "2019-11-27 11:00"}},
+		{"op": "update", "id": `+strconv.Itoa(id)+`, "event": {"name": "Review", "start": "2019-11-25 10:00", "end": "2019-11-25 11:00"}},
+		{"op": "update", "id": `+strconv.Itoa(id)+`, "event": {"name": "Sync", "start": "2019-11-25 10:00", "end": "2019-11-25 11:00", "version": 2}}
+	]}`)
+	if code != 409 || !strings.HasPrefix(errResp.Error, "operation 2: ") {
+		t.Errorf("must be status code 409 with error of operation 2 not %d %s", code, errResp.Error)
+	}
+	events, _ := service.GetAllEvents(context.Background())
+	if len(events) != 2 {
+		t.Errorf("must be 2 events after failed batch instead of %v", events)
+	}
+	if event, _ := service.GetEvent(context.Background(), id); event.Name != "Planning" || event.Version != 2 {
+		t.Errorf("event must not be updated by failed batch, got %v", event)
+	}
+
+	for _, body := range []string{
+		`x`,
+		`{"operations": []}`,
+		`{"operations": [{"op": "move", "id": 1}]}`,
+		`{"operations": [{"op": "delete"}]}`,
+		`{"operations": [{"op": "create"}]}`,
+		`{"operations": [{"op": "create", "event": {"name": "Demo", "start": "x", "end": "2019-11-27 11:00"}}]}`,
+	} {
+		code, _, _ := batch(service, body)
+		if code != 400 {
+			t.Errorf("must be status code 400 for %s not %d", body, code)
+		}
+	}
+
+	// storage without transactions
+	service, _ = NewService("", storageWithoutFeatures{memory.NewStorage()}, nil, nil, nil, nil)
+	code, _, _ = batch(service, `{"operations": [{"op": "delete", "id": 1}]}`)
+	if code != 501 {
+		t.Errorf("must be status code 501 for storage without transactions not %d", code)
+	}
+}
+
+func TestTrashAndRestoreEvent(t *testing.T) {
+	service := NewTestService()
+
+	event := &Event{
+		Name:  "Do homework",
+		Start: "2019-10-15 20:00",
+		End:   "2019-10-15 22:00",
+	}
+
+	id := addEvent(t, &service.Calendar, event, 1)
+	if id <= 0 {
+		return
+	}
+
+	err := service.Calendar.DeleteEvent(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	req := httptest.NewRequest("GET", "http://test.com/trash", nil)
+	w := httptest.NewRecorder()
+
+	service.GetTrash(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("must be status code 200 not %d", resp.StatusCode)
+	}
+
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	listResp := &EventListResponse{}
+	err = json.Unmarshal(respBody, listResp)
+	if err != nil {
+		t.Fatalf("failed on unmarshal json %s", err)
+	}
+
+	if len(listResp.Result) != 1 || listResp.Result[0].Id != id || listResp.Result[0].DeletedTime == "" {
+		t.Fatalf("trash must have one deleted event with id = %d instead of %+v", id, listResp.Result)
+	}
+
+	data := url.Values{}
+	data.Set("id", strconv.Itoa(id))
+
+	req = httptest.NewRequest("POST", "http://test.com/restore_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+
+	service.RestoreEvent(w, req)
+
+	respBody, _ = ioutil.ReadAll(w.Result().Body)
+	okResp := &OkResponse{}
+	err = json.Unmarshal(respBody, okResp)
+	if err != nil {
+		t.Fatalf("failed on unmarshal json %s", err)
+	}
+
+	if okResp.Result != "restored" {
+		t.Fatalf("unexpected OkResponse.Result value `%s`", okResp.Result)
+	}
+
+	restored, found := service.GetEvent(context.Background(), id)
+	if !found || restored.DeletedTime != "" {
+		t.Errorf("event with id = %d must be restored from trash", id)
+	}
+
+	// event is not in trash anymore
+	req = httptest.NewRequest("POST", "http://test.com/restore_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+
+	service.RestoreEvent(w, req)
+
+	respBody, _ = ioutil.ReadAll(w.Result().Body)
+	errResp := &ErrorResponse{}
+	_ = json.Unmarshal(respBody, errResp)
+	if errResp.Error == "" {
+		t.Errorf("restore of event that is not in trash must fail")
+	}
+}
+
+func TestUpdateEventVersion(t *testing.T) {
+	service := NewTestService()
+
+	event := &Event{
+		Name:  "Do homework",
+		Start: "2019-10-15 20:00",
+		End:   "2019-10-15 22:00",
+	}
+
+	id := addEvent(t, &service.Calendar, event, 1)
+	if id <= 0 {
+		return
+	}
+
+	update := func(name string, version string, ifMatch string) int {
+		data := url.Values{}
+		data.Set("id", strconv.Itoa(id))
+		data.Set("name", name)
+		data.Set("start", event.Start)
+		data.Set("end", event.End)
+		if version != "" {
+			data.Set("version", version)
+		}
+
+		req := httptest.NewRequest("POST", "http://test.com/update_event", strings.NewReader(data.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+
+		w := httptest.NewRecorder()
+		service.UpdateEvent(w, req)
+		return w.Result().StatusCode
+	}
+
+	// both clients got event of version 1, the first one wins
+	if code := update("Watch movie", "1", ""); code != 200 {
+		t.Errorf("must be status code 200 not %d", code)
+	}
+	if code := update("Read book", "", `"1"`); code != 409 {
+		t.Errorf("must be status code 409 on version mismatch not %d", code)
+	}
+
+	dbEvent, _ := service.GetEvent(context.Background(), id)
+	if dbEvent.Name != "Watch movie" || dbEvent.Version != 2 {
+		t.Errorf("must be event `Watch movie` of version 2 instead of `%s` of version %d", dbEvent.Name, dbEvent.Version)
+	}
+
+	if code := update("Read book", "", `W/"2"`); code != 200 {
+		t.Errorf("must be status code 200 not %d", code)
+	}
+
+	if code := update("Read book", "abc", ""); code != 400 {
+		t.Errorf("must be status code 400 on invalid version not %d", code)
+	}
+}
+
+func TestGetEventHistory(t *testing.T) {
+	service := NewTestService()
+
+	event := &Event{
+		Name:  "Do homework",
+		Start: "2019-10-15 20:00",
+		End:   "2019-10-15 22:00",
+	}
+
+	id := addEvent(t, &service.Calendar, event, 1)
+	if id <= 0 {
+		return
+	}
+
+	moved := &Event{
+		Name:  "Do homework",
+		Start: "2019-10-15 21:00",
+		End:   "2019-10-15 23:00",
+	}
+	if err := service.Calendar.UpdateEvent(context.Background(), id, moved); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	req := httptest.NewRequest("GET", "http://test.com/events/"+strconv.Itoa(id)+"/history", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": strconv.Itoa(id)})
+	w := httptest.NewRecorder()
+
+	service.GetEventHistory(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("must be status code 200 not %d", resp.StatusCode)
+	}
+
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	historyResp := &AuditEntryListResponse{}
+	err := json.Unmarshal(respBody, historyResp)
+	if err != nil {
+		t.Fatalf("failed on unmarshal json %s", err)
+	}
+
+	if len(historyResp.Result) != 2 || historyResp.Result[0].Action != "create" || historyResp.Result[1].Action != "update" {
+		t.Fatalf("history must have create and update entries instead of %+v", historyResp.Result)
+	}
+
+	expectedChanges := []FieldChange{
+		{Field: "start", Before: "2019-10-15 20:00", After: "2019-10-15 21:00"},
+		{Field: "end", Before: "2019-10-15 22:00", After: "2019-10-15 23:00"},
+	}
+	if !reflect.DeepEqual(historyResp.Result[1].Changes, expectedChanges) {
+		t.Errorf("Expected\n`%+v`\ngot\n`%+v`", expectedChanges, historyResp.Result[1].Changes)
+	}
+
+	req = httptest.NewRequest("GET", "http://test.com/events/100/history", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "100"})
+	w = httptest.NewRecorder()
+
+	service.GetEventHistory(w, req)
+	if w.Result().StatusCode != 404 {
+		t.Errorf("must be status code 404 for unknown event not %d", w.Result().StatusCode)
+	}
+}
+
+func TestCalendars(t *testing.T) {
+	service := NewTestService()
+
+	post := func(path string, data url.Values, handler http.HandlerFunc) *http.Response {
+		req := httptest.NewRequest("POST", "http://test.com"+path, strings.NewReader(data.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w.Result()
+	}
+
+	resp := post("/create_calendar", url.Values{"name": {"work"}}, service.CreateCalendar)
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	okResp := &OkResponse{}
+	_ = json.Unmarshal(respBody, okResp)
+
+	if resp.StatusCode != 200 || !regexp.MustCompile(`^created \d+$`).MatchString(okResp.Result) {
+		t.Fatalf("calendar must be created instead of status %d and response %s", resp.StatusCode, respBody)
+	}
+	workId, _ := strconv.Atoi(strings.TrimPrefix(okResp.Result, "created "))
+
+	personalId, _ := service.AddCalendar(context.Background(), "personal")
+
+	resp = post("/create_calendar", url.Values{"name": {""}}, service.CreateCalendar)
+	if resp.StatusCode != 400 {
+		t.Errorf("calendar with empty name must be error with status 400 instead of %d", resp.StatusCode)
+	}
+
+	resp = post("/update_calendar", url.Values{"id": {strconv.Itoa(personalId)}, "name": {"home"}}, service.UpdateCalendar)
+	if resp.StatusCode != 200 {
+		t.Errorf("calendar must be renamed instead of status %d", resp.StatusCode)
+	}
+
+	req := httptest.NewRequest("GET", "http://test.com/calendars", nil)
+	w := httptest.NewRecorder()
+	service.GetCalendars(w, req)
+
+	respBody, _ = ioutil.ReadAll(w.Result().Body)
+	listResp := &CalendarListResponse{}
+	err := json.Unmarshal(respBody, listResp)
+	if err != nil {
+		t.Fatalf("failed on unmarshal json %s", err)
+	}
+
+	expectedCalendars := []*CalendarInfo{{Id: workId, Name: "work"}, {Id: personalId, Name: "home"}}
+	if !reflect.DeepEqual(listResp.Result, expectedCalendars) {
+		t.Fatalf("calendars must be %+v instead of %s", expectedCalendars, respBody)
+	}
+
+	// events in calendars
+	data := url.Values{"name": {"Meeting"}, "start": {"2019-11-21 10:00"}, "end": {"2019-11-21 11:00"}, "calendarId": {strconv.Itoa(workId)}}
+	resp = post("/create_event", data, service.CreateEvent)
+	if resp.StatusCode != 200 {
+		t.Fatalf("event in calendar must be created instead of status %d", resp.StatusCode)
+	}
+
+	data.Set("calendarId", "1000")
+	resp = post("/create_event", data, service.CreateEvent)
+	if resp.StatusCode != 404 {
+		t.Errorf("event in unknown calendar must be error with status 404 instead of %d", resp.StatusCode)
+	}
+
+	_ = addEvent(t, &service.Calendar, &Event{Name: "Dinner", Start: "2019-11-21 19:00", End: "2019-11-21 20:00", CalendarId: personalId}, 2)
+	_ = addEvent(t, &service.Calendar, &Event{Name: "Walk", Start: "2019-11-21 12:00", End: "2019-11-21 13:00"}, 3)
+
+	now := time.Date(2019, 11, 21, 8, 0, 0, 0, time.UTC)
+	names := func(query string) []string {
+		req := httptest.NewRequest("GET", "http://test.com/events_for_day?"+query, nil)
+		w := httptest.NewRecorder()
+		service.getEventsForDay(now, w, req)
+
+		respBody, _ := ioutil.ReadAll(w.Result().Body)
+		eventListResp := &EventListResponse{}
+		_ = json.Unmarshal(respBody, eventListResp)
+
+		var names []string
+		for _, event := range eventListResp.Result {
+			names = append(names, event.Name)
+		}
+		return names
+	}
+
+	if got := names(""); !reflect.DeepEqual(got, []string{"Meeting", "Walk", "Dinner"}) {
+		t.Errorf("without filter all events must be got instead of %v", got)
+	}
+
+	if got := names("calendars=" + strconv.Itoa(workId)); !reflect.DeepEqual(got, []string{"Meeting"}) {
+		t.Errorf("only events of work calendar must be got instead of %v", got)
+	}
+
+	if got := names("calendars=" + strconv.Itoa(workId) + "," + strconv.Itoa(personalId)); !reflect.DeepEqual(got, []string{"Meeting", "Dinner"}) {
+		t.Errorf("only events of work and home calendars must be got instead of %v", got)
+	}
+
+	req = httptest.NewRequest("GET", "http://test.com/events_for_day?calendars=work", nil)
+	w = httptest.NewRecorder()
+	service.getEventsForDay(now, w, req)
+	if w.Result().StatusCode != 400 {
+		t.Errorf("invalid calendars parameter must be error with status 400 instead of %d", w.Result().StatusCode)
+	}
+
+	// delete calendar with its events
+	resp = post("/delete_calendar", url.Values{"id": {strconv.Itoa(personalId)}}, service.DeleteCalendar)
+	if resp.StatusCode != 200 {
+		t.Errorf("calendar must be deleted instead of status %d", resp.StatusCode)
+	}
+
+	if got := names(""); !reflect.DeepEqual(got, []string{"Meeting", "Walk"}) {
+		t.Errorf("events of deleted calendar must be deleted, left events %v", got)
+	}
+
+	resp = post("/delete_calendar", url.Values{"id": {strconv.Itoa(personalId)}}, service.DeleteCalendar)
+	if resp.StatusCode != 404 {
+		t.Errorf("deleting of unknown calendar must be error with status 404 instead of %d", resp.StatusCode)
+	}
+}
+
+func TestRespondToInvitation(t *testing.T) {
+	service := NewTestService()
+
+	// organizer is alice, bob is invited
+	event := &Event{
+		Name:      "Do homework",
+		Start:     "2019-10-15 20:00",
+		End:       "2019-10-15 22:00",
+		Attendees: []string{"bob@example.com"},
+	}
+
+	id := addEvent(t, service.Calendar.ForOwner("alice"), event, 1)
+	if id <= 0 {
+		return
+	}
+
+	respond := func(req *http.Request) *http.Response {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		service.RespondToInvitation(w, req)
+		return w.Result()
+	}
+
+	newRequest := func(data url.Values) *http.Request {
+		return httptest.NewRequest("POST", "http://test.com/respond_invitation", strings.NewReader(data.Encode()))
+	}
+
+	// attendee is authenticated user, email parameter is ignored
+	req := newRequest(url.Values{"id": {strconv.Itoa(id)}, "email": {"carol@example.com"}, "status": {"accepted"}})
+	req = req.WithContext(auth.WithUser(req.Context(), "bob@example.com"))
+	resp := respond(req)
+	if resp.StatusCode != 200 {
+		t.Fatalf("must be status code 200 not %d", resp.StatusCode)
+	}
+
+	dbEvent, _ := service.Calendar.GetEvent(context.Background(), id)
+	expectedStatuses := map[string]string{"bob@example.com": "accepted"}
+	if !reflect.DeepEqual(dbEvent.AttendeeStatuses, expectedStatuses) {
+		t.Errorf("statuses of attendees must be %v instead of %v", expectedStatuses, dbEvent.AttendeeStatuses)
+	}
+
+	resp = respond(newRequest(url.Values{"id": {strconv.Itoa(id)}, "email": {"bob@example.com"}, "status": {"maybe"}}))
+	if resp.StatusCode != 400 {
+		t.Errorf("must be status code 400 for invalid status not %d", resp.StatusCode)
+	}
+
+	resp = respond(newRequest(url.Values{"id": {strconv.Itoa(id)}, "email": {"carol@example.com"}, "status": {"declined"}}))
+	if resp.StatusCode != 404 {
+		t.Errorf("must be status code 404 for not invited attendee not %d", resp.StatusCode)
+	}
+
+	resp = respond(newRequest(url.Values{"id": {"100"}, "email": {"bob@example.com"}, "status": {"declined"}}))
+	if resp.StatusCode != 404 {
+		t.Errorf("must be status code 404 for unknown event not %d", resp.StatusCode)
+	}
+}
+
+// Cancelled request must not change storage
+func TestCancelledRequest(t *testing.T) {
+	service := NewTestService()
+
+	event := &Event{
+		Name:  "Do homework",
+		Start: "2019-10-15 20:00",
+		End:   "2019-10-15 22:00",
+	}
+
+	id := addEvent(t, &service.Calendar, event, 1)
+	if id <= 0 {
+		return
+	}
+
+	data := url.Values{}
+	data.Set("id", strconv.Itoa(id))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := httptest.NewRequest("POST", "http://test.com/delete_event", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(ctx)
+
+	w := httptest.NewRecorder()
+
+	service.DeleteEvent(w, req)
+
+	if _, ok := service.Calendar.GetEvent(context.Background(), id); !ok {
+		t.Errorf("event must not be deleted by cancelled request")
+	}
+}
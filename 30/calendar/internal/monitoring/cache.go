@@ -0,0 +1,59 @@
+package monitoring
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Hit and miss counters of storage cache by query (event, period or page)
+// Methods are safe for nil metrics, so cache could be used without metrics
+type CacheMetrics struct {
+	hits   *prometheus.CounterVec
+	misses *prometheus.CounterVec
+}
+
+// Register counters in registerer, e.g. prometheus.DefaultRegisterer to export them by exporter of http metrics
+func NewCacheMetrics(registerer prometheus.Registerer, logger *zap.SugaredLogger) *CacheMetrics {
+	hitsOpts := prometheus.CounterOpts{
+		Subsystem: "storage_cache",
+		Name:      "hits_count",
+		Help:      "Total number of queries of storage answered from cache",
+	}
+	hits := prometheus.NewCounterVec(hitsOpts, []string{"query"})
+	if err := registerer.Register(hits); err != nil {
+		hits = nil
+		if logger != nil {
+			logger.Errorf("can't register counter vector `%s` metric: %s", hitsOpts.Name, err)
+		}
+	}
+
+	missesOpts := prometheus.CounterOpts{
+		Subsystem: "storage_cache",
+		Name:      "misses_count",
+		Help:      "Total number of queries of storage passed to storage by cache",
+	}
+	misses := prometheus.NewCounterVec(missesOpts, []string{"query"})
+	if err := registerer.Register(misses); err != nil {
+		misses = nil
+		if logger != nil {
+			logger.Errorf("can't register counter vector `%s` metric: %s", missesOpts.Name, err)
+		}
+	}
+
+	return &CacheMetrics{
+		hits:   hits,
+		misses: misses,
+	}
+}
+
+func (m *CacheMetrics) IncHits(query string) {
+	if m != nil && m.hits != nil {
+		m.hits.WithLabelValues(query).Inc()
+	}
+}
+
+func (m *CacheMetrics) IncMisses(query string) {
+	if m != nil && m.misses != nil {
+		m.misses.WithLabelValues(query).Inc()
+	}
+}
@@ -0,0 +1,68 @@
+package monitoring
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Latency histogram and error counter of calls of storage by method (AddEvent, GetEventsByPeriod...)
+// Storage type (memory, sql, file) is constant label, so storages could be compared in dashboards
+// Methods are safe for nil metrics, so instrumented storage could be used without metrics
+type StorageMetrics struct {
+	latency *prometheus.HistogramVec
+	errors  *prometheus.CounterVec
+}
+
+// Register metrics in registerer, e.g. prometheus.DefaultRegisterer to export them by exporter of http metrics
+func NewStorageMetrics(registerer prometheus.Registerer, storageType string, logger *zap.SugaredLogger) *StorageMetrics {
+	constLabels := prometheus.Labels{"storage": storageType}
+
+	latencyOpts := prometheus.HistogramOpts{
+		Subsystem:   "storage",
+		Name:        "call_duration_seconds",
+		Help:        "Latency of calls of storage methods",
+		ConstLabels: constLabels,
+		Buckets:     []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}
+	latency := prometheus.NewHistogramVec(latencyOpts, []string{"method"})
+	if err := registerer.Register(latency); err != nil {
+		latency = nil
+		if logger != nil {
+			logger.Errorf("can't register histogram vector `%s` metric: %s", latencyOpts.Name, err)
+		}
+	}
+
+	errorsOpts := prometheus.CounterOpts{
+		Subsystem:   "storage",
+		Name:        "errors_count",
+		Help:        "Total number of calls of storage methods that returned error",
+		ConstLabels: constLabels,
+	}
+	errors := prometheus.NewCounterVec(errorsOpts, []string{"method"})
+	if err := registerer.Register(errors); err != nil {
+		errors = nil
+		if logger != nil {
+			logger.Errorf("can't register counter vector `%s` metric: %s", errorsOpts.Name, err)
+		}
+	}
+
+	return &StorageMetrics{
+		latency: latency,
+		errors:  errors,
+	}
+}
+
+// Observe call of method that lasted elapsed and returned err
+func (m *StorageMetrics) Observe(method string, elapsed time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	if m.latency != nil {
+		m.latency.WithLabelValues(method).Observe(elapsed.Seconds())
+	}
+	if err != nil && m.errors != nil {
+		m.errors.WithLabelValues(method).Inc()
+	}
+}
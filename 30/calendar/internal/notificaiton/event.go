@@ -3,26 +3,83 @@ package notificaiton
 import (
 	"encoding/json"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"time"
 )
 
 const dateTimeLayout = "2006-01-02 15:04"
 
+// Types of messages in queue
+const (
+	EventInfoTypeReminder   = "reminder"   // due reminder of event, for organizer and attending attendees
+	EventInfoTypeInvitation = "invitation" // invitation of attendee to event
+	EventInfoTypeUpdate     = "update"     // time of event is changed since attendee was invited
+)
+
 // Event main info that will pushed into queue
+// Start and end are local times in time zone of event
 type EventInfo struct {
-	Id    int    `json:"id"`
-	Name  string `json:"name"`
-	Start string `json:"start"`
-	End   string `json:"end"`
+	Type      string `json:"type,omitempty"`      // one of EventInfoType* constants
+	Recipient string `json:"recipient,omitempty"` // email of attendee for invitation and update
+
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone,omitempty"` // IANA time zone of event, empty means UTC
+
+	Description string   `json:"description,omitempty"`
+	Location    string   `json:"location,omitempty"`  // place of event
+	Organizer   string   `json:"organizer,omitempty"` // email of organizer
+	Attendees   []string `json:"attendees,omitempty"` // emails of attendees
+	Color       string   `json:"color,omitempty"`
+
+	BeforeMinutes int `json:"beforeMinutes"` // before minutes of reminder which notification is due
 }
 
 // Extract main event info from biz event entity
 func extractEventInfo(event entities.Event) EventInfo {
-	return EventInfo{
+	eventInfo := EventInfo{
 		Id:    event.Id(),
 		Name:  event.Name(),
 		Start: event.Start().Time().Format(dateTimeLayout),
 		End:   event.End().Time().Format(dateTimeLayout),
+
+		Description: event.Description(),
+		Location:    event.Place(),
+		Organizer:   event.Organizer(),
+		Attendees:   event.Attendees(),
+		Color:       event.Color(),
+	}
+	if loc := event.Location(); loc != time.UTC {
+		eventInfo.Timezone = loc.String()
+	}
+	return eventInfo
+}
+
+// Extract main event info with due reminder from notification, every reminder of event is separate message
+// Only attendees that accepted (or tentatively accepted) invitation get reminder
+func extractNotificationInfo(notification entities.Notification) EventInfo {
+	eventInfo := extractEventInfo(notification.Event)
+	eventInfo.Type = EventInfoTypeReminder
+	eventInfo.BeforeMinutes = notification.Reminder().BeforeMinutes()
+	eventInfo.Attendees = nil
+	for _, attendee := range notification.AttendeeList() {
+		if attendee.IsAttending() {
+			eventInfo.Attendees = append(eventInfo.Attendees, attendee.Email())
+		}
+	}
+	return eventInfo
+}
+
+// Extract main event info from invitation, every attendee gets separate message
+func extractInvitationInfo(invitation entities.Invitation) EventInfo {
+	eventInfo := extractEventInfo(invitation.Event)
+	eventInfo.Type = EventInfoTypeInvitation
+	if invitation.IsUpdate() {
+		eventInfo.Type = EventInfoTypeUpdate
 	}
+	eventInfo.Recipient = invitation.Attendee().Email()
+	return eventInfo
 }
 
 // serialize event info for queue
@@ -0,0 +1,52 @@
+package notificaiton
+
+import (
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"reflect"
+	"testing"
+)
+
+func TestExtractEventInfo(t *testing.T) {
+	moscow, _ := entities.LoadLocation("Europe/Moscow")
+
+	event := entities.NewEventWithId(1, "Planning",
+		entities.NewDateTimeInLocation(2019, 11, 25, 10, 0, moscow),
+		entities.NewDateTimeInLocation(2019, 11, 25, 11, 0, moscow),
+	)
+	event = entities.WithDescription(event, "Quarterly planning")
+	event = entities.WithPlace(event, "Room 42")
+	event = entities.WithOrganizer(event, "boss@example.com")
+	event = entities.WithAttendees(event, []string{"alice@example.com"})
+
+	expected := EventInfo{
+		Id:          1,
+		Name:        "Planning",
+		Start:       "2019-11-25 10:00",
+		End:         "2019-11-25 11:00",
+		Timezone:    "Europe/Moscow",
+		Description: "Quarterly planning",
+		Location:    "Room 42",
+		Organizer:   "boss@example.com",
+		Attendees:   []string{"alice@example.com"},
+	}
+
+	eventInfo := extractEventInfo(event)
+	if !reflect.DeepEqual(eventInfo, expected) {
+		t.Errorf("expected %+v instead of %+v", expected, eventInfo)
+	}
+
+	data, err := serializeEvent(eventInfo)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	unSerialized := EventInfo{}
+	err = unSerializeEvent(data, &unSerialized)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if !reflect.DeepEqual(unSerialized, expected) {
+		t.Errorf("expected %+v instead of %+v", expected, unSerialized)
+	}
+}
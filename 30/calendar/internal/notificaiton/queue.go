@@ -10,7 +10,8 @@ var ErrQueueEmpty = errors.New("queue is empty")
 
 // Simple queue interface
 type Queue interface {
-	Push(event entities.Event) error    // push main info about biz event entity into queue
-	Consume() (<-chan EventInfo, error) // subscribe on event info channel, from where event info items will be read
+	Push(notification entities.Notification) error       // push main info about biz event entity and its due reminder into queue
+	PushInvitation(invitation entities.Invitation) error // push invitation (or update) of attendee to biz event entity into queue
+	Consume() (<-chan EventInfo, error)                  // subscribe on event info channel, from where event info items will be read
 	io.Closer
 }
@@ -105,10 +105,20 @@ func NewRabbitQueue(cfg Config) (*Rabbit, error) {
 	return rabbit, nil
 }
 
-// Push main info about biz event entity into Queue
-func (r *Rabbit) Push(event entities.Event) error {
+// Push main info about biz event entity and its due reminder into Queue
+func (r *Rabbit) Push(notification entities.Notification) error {
+	return r.publish(extractNotificationInfo(notification))
+}
+
+// Push invitation (or update) of attendee to biz event entity into Queue
+func (r *Rabbit) PushInvitation(invitation entities.Invitation) error {
+	return r.publish(extractInvitationInfo(invitation))
+}
+
+// Inner helper that publish event info into Queue
+func (r *Rabbit) publish(eventInfo EventInfo) error {
 
-	msg, err := serializeEvent(extractEventInfo(event))
+	msg, err := serializeEvent(eventInfo)
 	if err != nil {
 		return err
 	}
@@ -13,11 +13,19 @@ import (
 var ErrorQueueNotInitialized = errors.New("queue not initialized")
 var ErrorStorageNotInitialized = errors.New("storage not initialized")
 
+// Lease of claimed reminders and invitations if it is not set
+const defaultClaimLease = time.Minute
+
 // Notification scheduler
-// Scan storage with some freq and put events info into queue
-// Once event info pushed into queue even mark as notified
+// Scan storage with some freq and put events info into queue, one message per due reminder of event
+// Once event info pushed into queue reminder of event mark as notified
+// Also put invitations into queue, one message per not invited attendee and per invited attendee when time of event is changed
+// If storage is entities.Claimer due reminders and invitations are claimed, so any number of schedulers could run without duplicates,
+// reminder or invitation which push failed (or which scheduler crashed) is retried when its claim lease expires
+// Claims of memory and file storages live in process, so only schedulers that share storage of one process are deduplicated
 type Scheduler struct {
 	scanTimeout time.Duration    // frequency of scan
+	claimLease  time.Duration    // lease of claimed reminders and invitations, default if it is not positive
 	storage     entities.Storage // calendar storage
 	start       *time.Time       // start of interval for get events by interval for notification
 	logger      *zap.SugaredLogger
@@ -27,9 +35,10 @@ type Scheduler struct {
 }
 
 // Constructor
-func NewScheduler(scanTimeout time.Duration, storage entities.Storage, queue Queue, logger *zap.SugaredLogger) *Scheduler {
+func NewScheduler(scanTimeout time.Duration, claimLease time.Duration, storage entities.Storage, queue Queue, logger *zap.SugaredLogger) *Scheduler {
 	return &Scheduler{
 		scanTimeout: scanTimeout,
+		claimLease:  claimLease,
 		storage:     storage,
 		logger:      logger,
 		queue:       queue,
@@ -65,7 +74,7 @@ func (s *Scheduler) Stop() {
 // Inner helper that run scan process. Take into account context.Done()
 func (s *Scheduler) run(ctx context.Context) {
 	ticker := time.NewTicker(s.scanTimeout)
-	s.scan()
+	s.scan(ctx)
 	for {
 		select {
 		case <-ctx.Done():
@@ -76,15 +85,16 @@ func (s *Scheduler) run(ctx context.Context) {
 			}
 			return
 		case <-ticker.C:
-			s.scan()
+			s.scan(ctx)
 		}
 	}
 }
 
-// scan db to find events to notify about
-// push event info into queue
-// once event info pushed into queue mark event as notified
-func (s *Scheduler) scan() {
+// scan db to find reminders of events to notify about and invitations to send
+// push event info into queue per reminder
+// once event info pushed into queue mark reminder of event as notified
+// Scan is cancelled on stop of scheduler
+func (s *Scheduler) scan(ctx context.Context) {
 	var start, end *entities.DateTime
 
 	// not first scan
@@ -99,31 +109,88 @@ func (s *Scheduler) scan() {
 	dt := entities.ConvertFromTime(endTime)
 	end = &dt
 
-	events, err := s.storage.GetEventsToNotify(start, end)
+	notifications, err := s.dueNotifications(ctx, start, end, endTime)
 
-	s.logInfof("%d event(s) push into queue (%s, %s)", len(events), start, end)
+	s.logInfof("%d notification(s) push into queue (%s, %s)", len(notifications), start, end)
 
 	if err != nil {
-		s.logErrorf("Scheduler.scan, storage.GetEventsToNotify return error %w", err)
+		s.logErrorf("Scheduler.scan, storage.ClaimEventsToNotify or storage.GetEventsToNotify return error %w", err)
 	}
 
-	s.enqueueEvents(events)
+	s.enqueueEvents(ctx, notifications)
 
 	s.start = &endTime
 
+	invitations, err := s.dueInvitations(ctx, s.now())
+
+	if len(invitations) > 0 {
+		s.logInfof("%d invitation(s) push into queue", len(invitations))
+	}
+
+	if err != nil {
+		s.logErrorf("Scheduler.scan, storage.ClaimInvitationsToSend or storage.GetInvitationsToSend return error %w", err)
+	}
+
+	s.enqueueInvitations(ctx, invitations)
 }
 
-// push event info into queue and mark as notified
-func (s *Scheduler) enqueueEvents(events []entities.Event) {
+// Claim due notifications if storage is claimer, otherwise just get them
+func (s *Scheduler) dueNotifications(ctx context.Context, start *entities.DateTime, end *entities.DateTime, now time.Time) ([]entities.Notification, error) {
+	if claimer, ok := s.storage.(entities.Claimer); ok {
+		notifications, err := claimer.ClaimEventsToNotify(ctx, start, end, now, s.lease())
+		if err != entities.ErrClaimNotSupported {
+			return notifications, err
+		}
+	}
+	return s.storage.GetEventsToNotify(ctx, start, end)
+}
+
+// Claim invitations to send if storage is claimer, otherwise just get them
+func (s *Scheduler) dueInvitations(ctx context.Context, now time.Time) ([]entities.Invitation, error) {
+	if claimer, ok := s.storage.(entities.Claimer); ok {
+		invitations, err := claimer.ClaimInvitationsToSend(ctx, now, s.lease())
+		if err != entities.ErrClaimNotSupported {
+			return invitations, err
+		}
+	}
+	return s.storage.GetInvitationsToSend(ctx, now)
+}
 
-	for _, event := range events {
-		err := s.queue.Push(event)
+// Lease of claims, default if it is not set
+func (s *Scheduler) lease() time.Duration {
+	if s.claimLease <= 0 {
+		return defaultClaimLease
+	}
+	return s.claimLease
+}
+
+// push event info into queue and mark reminder of occurrence as notified
+func (s *Scheduler) enqueueEvents(ctx context.Context, notifications []entities.Notification) {
+
+	for _, notification := range notifications {
+		err := s.queue.Push(notification)
 		if err != nil {
 			s.logErrorf("Scheduler.enqueueEvents, queue.Push return error %s", err)
 		} else {
-			err = s.storage.MarkEventAsNotified(event.Id(), s.now())
+			err = s.storage.MarkReminderAsNotified(ctx, notification.Id(), notification.Reminder().BeforeMinutes(), notification.Start(), s.now())
+			if err != nil {
+				s.logErrorf("Scheduler.enqueueEvents, storage.MarkReminderAsNotified return error %s", err)
+			}
+		}
+	}
+}
+
+// push invitation into queue and mark attendee as invited to event with its current start
+func (s *Scheduler) enqueueInvitations(ctx context.Context, invitations []entities.Invitation) {
+
+	for _, invitation := range invitations {
+		err := s.queue.PushInvitation(invitation)
+		if err != nil {
+			s.logErrorf("Scheduler.enqueueInvitations, queue.PushInvitation return error %s", err)
+		} else {
+			err = s.storage.MarkAttendeeAsInvited(ctx, invitation.Id(), invitation.Attendee().Email(), invitation.Start(), invitation.End())
 			if err != nil {
-				s.logErrorf("Scheduler.enqueueEvents, storage.MarkEventAsNotified return error %s", err)
+				s.logErrorf("Scheduler.enqueueInvitations, storage.MarkAttendeeAsInvited return error %s", err)
 			}
 		}
 	}
@@ -1,6 +1,7 @@
 package notificaiton
 
 import (
+	"context"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/memory"
 	"reflect"
@@ -13,8 +14,13 @@ type testQueue struct {
 	readTimeout time.Duration
 }
 
-func (c *testQueue) Push(event entities.Event) error {
-	c.ch <- extractEventInfo(event)
+func (c *testQueue) Push(notification entities.Notification) error {
+	c.ch <- extractNotificationInfo(notification)
+	return nil
+}
+
+func (c *testQueue) PushInvitation(invitation entities.Invitation) error {
+	c.ch <- extractInvitationInfo(invitation)
 	return nil
 }
 
@@ -95,14 +101,14 @@ func TestSchedulerScan1(t *testing.T) {
 	}
 
 	for _, event := range originalEvents {
-		_, err := scheduler.storage.AddEvent(event)
+		_, err := scheduler.storage.AddEvent(context.Background(), event)
 		if err != nil {
 			t.Errorf("Error while insert event %s: %s", event, err)
 			return
 		}
 	}
 
-	scheduler.scan()
+	scheduler.scan(context.Background())
 
 	queue := scheduler.queue.(*testQueue)
 	events := queue.ReadAllEvents()
@@ -117,7 +123,7 @@ func TestSchedulerScan1(t *testing.T) {
 		t.Errorf("Queue must be empty after read all")
 	}
 
-	scheduler.scan()
+	scheduler.scan(context.Background())
 
 	_, err = scheduler.queue.(*testQueue).ReadEvent()
 	if err != ErrQueueEmpty {
@@ -131,7 +137,7 @@ func TestSchedulerScan2(t *testing.T) {
 
 	deviation := 1 * time.Minute
 
-	scheduler.scan()
+	scheduler.scan(context.Background())
 
 	lastScanTime := *scheduler.start
 
@@ -214,7 +220,7 @@ func TestSchedulerScan2(t *testing.T) {
 	}
 
 	for _, event := range originalEvents {
-		_, err := scheduler.storage.AddEvent(event)
+		_, err := scheduler.storage.AddEvent(context.Background(), event)
 		if err != nil {
 			t.Errorf("Error while insert event %s: %s", event, err)
 			return
@@ -226,7 +232,7 @@ func TestSchedulerScan2(t *testing.T) {
 		return scheduler.start.Add(scheduler.scanTimeout)
 	}
 
-	scheduler.scan()
+	scheduler.scan(context.Background())
 
 	queue := scheduler.queue.(*testQueue)
 	events := queue.ReadAllEvents()
@@ -238,7 +244,7 @@ func TestSchedulerScan2(t *testing.T) {
 		return
 	}
 
-	scheduler.scan()
+	scheduler.scan(context.Background())
 
 	queue = scheduler.queue.(*testQueue)
 	events = queue.ReadAllEvents()
@@ -250,7 +256,7 @@ func TestSchedulerScan2(t *testing.T) {
 		return
 	}
 
-	scheduler.scan()
+	scheduler.scan(context.Background())
 
 	queue = scheduler.queue.(*testQueue)
 	events = queue.ReadAllEvents()
@@ -268,7 +274,7 @@ func TestSchedulerScan3(t *testing.T) {
 	scheduler := newScheduler()
 
 	_, _ = scheduler.storage.AddEvent(
-		entities.NewDetailedEvent(
+		context.Background(), entities.NewDetailedEvent(
 			"TestEvent",
 			entities.NewDateTime(2019, 11, 18, 8, 0),
 			entities.NewDateTime(2019, 11, 18, 10, 0),
@@ -279,7 +285,7 @@ func TestSchedulerScan3(t *testing.T) {
 		),
 	)
 
-	scheduler.scan()
+	scheduler.scan(context.Background())
 
 	eventInfo, err := scheduler.queue.(*testQueue).ReadEvent()
 
@@ -289,7 +295,7 @@ func TestSchedulerScan3(t *testing.T) {
 	}
 
 	id := eventInfo.Id
-	event, err := scheduler.storage.GetEvent(id)
+	event, err := scheduler.storage.GetEvent(context.Background(), id)
 
 	if err != nil {
 		t.Errorf("Must not be error %s", err)
@@ -308,3 +314,249 @@ func extractNames(events []EventInfo) []string {
 	}
 	return names
 }
+
+func TestSchedulerScanReminders(t *testing.T) {
+	scheduler := newScheduler()
+
+	event := entities.NewEvent(
+		"TestEvent",
+		entities.NewDateTime(2019, 11, 18, 8, 0),
+		entities.NewDateTime(2019, 11, 18, 10, 0),
+	)
+	event = entities.WithReminders(event, []entities.Reminder{entities.NewReminder(30), entities.NewReminder(10)})
+
+	id, _ := scheduler.storage.AddEvent(context.Background(), event)
+
+	// the first reminder is due, the second one is not yet
+	scheduler.nowTimeFn = func() time.Time {
+		return time.Date(2019, 11, 18, 7, 40, 0, 0, time.UTC)
+	}
+
+	scheduler.scan(context.Background())
+
+	queue := scheduler.queue.(*testQueue)
+	events := queue.ReadAllEvents()
+	if len(events) != 1 || events[0].BeforeMinutes != 30 {
+		t.Fatalf("must be one message of reminder 30 instead of %+v", events)
+	}
+
+	dbEvent, _ := scheduler.storage.GetEvent(context.Background(), id)
+	if dbEvent.IsNotified() {
+		t.Errorf("event must not be marked as notified until all reminders are notified")
+	}
+
+	scheduler.nowTimeFn = func() time.Time {
+		return time.Date(2019, 11, 18, 7, 50, 0, 0, time.UTC)
+	}
+
+	scheduler.scan(context.Background())
+
+	events = queue.ReadAllEvents()
+	if len(events) != 1 || events[0].BeforeMinutes != 10 {
+		t.Fatalf("must be one message of reminder 10 instead of %+v", events)
+	}
+
+	dbEvent, _ = scheduler.storage.GetEvent(context.Background(), id)
+	if !dbEvent.IsNotified() {
+		t.Errorf("event must be marked as notified when all reminders are notified")
+	}
+}
+
+// Reminder of daily event is enqueued for every occurrence
+func TestSchedulerScanRecurringReminders(t *testing.T) {
+	scheduler := newScheduler()
+	queue := scheduler.queue.(*testQueue)
+
+	recurrence, _ := entities.ParseRecurrence("FREQ=DAILY")
+	event := entities.NewEvent("Standup", entities.NewDateTime(2019, 11, 25, 10, 0), entities.NewDateTime(2019, 11, 25, 10, 15))
+	event = entities.WithRecurrence(entities.WithReminders(event, []entities.Reminder{entities.NewReminder(15)}), recurrence)
+	_, _ = scheduler.storage.AddEvent(context.Background(), event)
+
+	now := time.Date(2019, 11, 24, 12, 0, 0, 0, time.UTC)
+	scheduler.nowTimeFn = func() time.Time {
+		return now
+	}
+	scheduler.scan(context.Background())
+
+	var starts []string
+	for _, day := range []int{25, 26, 27} {
+		now = time.Date(2019, 11, day, 9, 50, 0, 0, time.UTC)
+		scheduler.scan(context.Background())
+		now = time.Date(2019, 11, day, 12, 0, 0, 0, time.UTC)
+		scheduler.scan(context.Background())
+	}
+	for _, eventInfo := range queue.ReadAllEvents() {
+		starts = append(starts, eventInfo.Start)
+	}
+
+	expected := []string{"2019-11-25 10:00", "2019-11-26 10:00", "2019-11-27 10:00"}
+	if !reflect.DeepEqual(starts, expected) {
+		t.Errorf("reminders must be enqueued for occurrences %v instead of %v", expected, starts)
+	}
+}
+
+func TestSchedulerSkipTrashedEvents(t *testing.T) {
+	scheduler := newScheduler()
+
+	event := entities.NewDetailedEvent(
+		"TestEvent",
+		entities.NewDateTime(2019, 11, 18, 8, 0),
+		entities.NewDateTime(2019, 11, 18, 10, 0),
+		true,
+		10,
+		false,
+		time.Time{},
+	)
+
+	id, _ := scheduler.storage.AddEvent(context.Background(), event)
+	_ = scheduler.storage.DeleteEvent(context.Background(), id)
+
+	scheduler.nowTimeFn = func() time.Time {
+		return time.Date(2019, 11, 18, 7, 55, 0, 0, time.UTC)
+	}
+
+	scheduler.scan(context.Background())
+
+	events := scheduler.queue.(*testQueue).ReadAllEvents()
+	if len(events) != 0 {
+		t.Errorf("trashed event must not be notified, got %+v", events)
+	}
+}
+
+func TestSchedulerScanInvitations(t *testing.T) {
+	scheduler := newScheduler()
+
+	event := entities.NewEvent(
+		"TestEvent",
+		entities.NewDateTime(2019, 11, 18, 8, 0),
+		entities.NewDateTime(2019, 11, 18, 10, 0),
+	)
+	event = entities.WithAttendees(event, []string{"alice@example.com", "bob@example.com"})
+	event = entities.WithReminders(event, []entities.Reminder{entities.NewReminder(10)})
+
+	id, _ := scheduler.storage.AddEvent(context.Background(), event)
+
+	scheduler.nowTimeFn = func() time.Time {
+		return time.Date(2019, 11, 18, 7, 0, 0, 0, time.UTC)
+	}
+
+	scheduler.scan(context.Background())
+
+	queue := scheduler.queue.(*testQueue)
+	events := queue.ReadAllEvents()
+	if len(events) != 2 ||
+		events[0].Type != EventInfoTypeInvitation || events[0].Recipient != "alice@example.com" ||
+		events[1].Type != EventInfoTypeInvitation || events[1].Recipient != "bob@example.com" {
+		t.Fatalf("must be invitations of alice and bob instead of %+v", events)
+	}
+
+	// invitations are sent only once
+	scheduler.scan(context.Background())
+
+	events = queue.ReadAllEvents()
+	if len(events) != 0 {
+		t.Fatalf("must be no messages instead of %+v", events)
+	}
+
+	_ = scheduler.storage.RespondToInvitation(context.Background(), id, "alice@example.com", entities.AttendeeStatusAccepted)
+	_ = scheduler.storage.RespondToInvitation(context.Background(), id, "bob@example.com", entities.AttendeeStatusDeclined)
+
+	// organizer changes time of event, responses of attendees are kept
+	event = entities.NewEvent(
+		"TestEvent",
+		entities.NewDateTime(2019, 11, 18, 9, 0),
+		entities.NewDateTime(2019, 11, 18, 10, 0),
+	)
+	event = entities.WithAttendees(event, []string{"alice@example.com", "bob@example.com"})
+	event = entities.WithReminders(event, []entities.Reminder{entities.NewReminder(10)})
+	_ = scheduler.storage.UpdateEvent(context.Background(), id, event)
+
+	scheduler.scan(context.Background())
+
+	events = queue.ReadAllEvents()
+	if len(events) != 2 ||
+		events[0].Type != EventInfoTypeUpdate || events[0].Recipient != "alice@example.com" || events[0].Start != "2019-11-18 09:00" ||
+		events[1].Type != EventInfoTypeUpdate || events[1].Recipient != "bob@example.com" {
+		t.Fatalf("must be updates for alice and bob instead of %+v", events)
+	}
+
+	// only attending attendees get reminder
+	scheduler.nowTimeFn = func() time.Time {
+		return time.Date(2019, 11, 18, 8, 55, 0, 0, time.UTC)
+	}
+
+	scheduler.scan(context.Background())
+
+	events = queue.ReadAllEvents()
+	if len(events) != 1 || events[0].Type != EventInfoTypeReminder || !reflect.DeepEqual(events[0].Attendees, []string{"alice@example.com"}) {
+		t.Fatalf("must be reminder for alice only instead of %+v", events)
+	}
+}
+
+// Queue that fails to push notifications and invitations, like scheduler that crashed after claim
+type failingQueue struct {
+	*testQueue
+}
+
+func (c failingQueue) Push(notification entities.Notification) error {
+	return ErrQueueEmpty
+}
+
+func (c failingQueue) PushInvitation(invitation entities.Invitation) error {
+	return ErrQueueEmpty
+}
+
+func TestSchedulerReplicas(t *testing.T) {
+	storage := memory.NewStorage()
+	now := time.Date(2019, 11, 18, 7, 55, 0, 0, time.UTC)
+
+	newReplica := func(queue Queue) *Scheduler {
+		return &Scheduler{
+			scanTimeout: 1 * time.Hour,
+			claimLease:  1 * time.Minute,
+			queue:       queue,
+			storage:     storage,
+			nowTimeFn:   func() time.Time { return now },
+		}
+	}
+
+	event := entities.WithReminders(entities.NewEvent(
+		"TestEvent",
+		entities.NewDateTime(2019, 11, 18, 8, 0),
+		entities.NewDateTime(2019, 11, 18, 10, 0),
+	), []entities.Reminder{entities.NewReminder(10)})
+	event = entities.WithAttendees(event, []string{"alice@example.com"})
+	_, _ = storage.AddEvent(context.Background(), event)
+
+	crashed := newReplica(failingQueue{newTestQueue()})
+	first, second := newReplica(newTestQueue()), newReplica(newTestQueue())
+
+	crashed.scan(context.Background())
+	first.scan(context.Background())
+	second.scan(context.Background())
+
+	if events := append(first.queue.(*testQueue).ReadAllEvents(), second.queue.(*testQueue).ReadAllEvents()...); len(events) != 0 {
+		t.Fatalf("reminder and invitation claimed by crashed replica must not be pushed until lease expires instead of %+v", events)
+	}
+
+	// lease of crashed replica is expired, so reminder and invitation are retried by one of replicas
+	now = now.Add(time.Minute)
+	first.scan(context.Background())
+	second.scan(context.Background())
+
+	events := append(first.queue.(*testQueue).ReadAllEvents(), second.queue.(*testQueue).ReadAllEvents()...)
+	types := make(map[string]int)
+	for _, event := range events {
+		types[event.Type]++
+	}
+	if len(events) != 2 || types[EventInfoTypeReminder] != 1 || types[EventInfoTypeInvitation] != 1 {
+		t.Errorf("reminder and invitation must be pushed once instead of %+v", events)
+	}
+
+	// invited attendee is not claimed again by any replica
+	first.scan(context.Background())
+	second.scan(context.Background())
+	if events := append(first.queue.(*testQueue).ReadAllEvents(), second.queue.(*testQueue).ReadAllEvents()...); len(events) != 0 {
+		t.Errorf("must be no messages after invitation instead of %+v", events)
+	}
+}
@@ -0,0 +1,168 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Bounded LRU of results of queries with time to live
+// Entries are indexed by ids of their events, so change of event invalidates only entries with this event,
+// and entries of period queries keep their periods, so new position of event invalidates only entries of overlapping periods
+type lru struct {
+	mx         sync.Mutex
+	size       int
+	ttl        time.Duration
+	now        func() time.Time
+	entries    map[string]*list.Element
+	order      *list.List              // of *entry, the most recently used first
+	byEvent    map[int]map[string]bool // keys of entries by ids of their events
+	generation uint64                  // incremented by every invalidation
+}
+
+// Cached result of query
+type entry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+	ids     []int   // ids of events of result
+	period  *period // period of query, nil if it is not period query
+}
+
+// Period of query or of occurrences of event, nil boundary means no boundary
+type period struct {
+	start *time.Time
+	end   *time.Time
+}
+
+// Periods overlap, boundaries are inclusive
+func (p period) overlaps(that period) bool {
+	return (p.start == nil || that.end == nil || !that.end.Before(*p.start)) &&
+		(p.end == nil || that.start == nil || !p.end.Before(*that.start))
+}
+
+func newLru(size int, ttl time.Duration) *lru {
+	return &lru{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		byEvent: make(map[int]map[string]bool),
+	}
+}
+
+// Get not expired value by key, got entry becomes the most recently used
+func (c *lru) get(key string) (interface{}, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := element.Value.(*entry)
+	if !c.now().Before(e.expires) {
+		c.remove(element)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return e.value, true
+}
+
+// Current generation, it must be got before query of storage and passed to put of result of query
+func (c *lru) currentGeneration() uint64 {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.generation
+}
+
+// Put result of query, the least recently used entries are evicted if cache is full
+// Result is not put if there were invalidations since generation, because result could be got before invalidated write
+func (c *lru) put(generation uint64, key string, value interface{}, ids []int, p *period) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{
+		key:     key,
+		value:   value,
+		expires: c.now().Add(c.ttl),
+		ids:     ids,
+		period:  p,
+	})
+	for _, id := range ids {
+		keys, ok := c.byEvent[id]
+		if !ok {
+			keys = make(map[string]bool)
+			c.byEvent[id] = keys
+		}
+		keys[key] = true
+	}
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// Remove entries with event
+func (c *lru) invalidateEvent(id int) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.generation++
+	for key := range c.byEvent[id] {
+		c.remove(c.entries[key])
+	}
+}
+
+// Remove entries of period queries which periods overlap period
+func (c *lru) invalidatePeriod(p period) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.generation++
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		if e := element.Value.(*entry); e.period != nil && e.period.overlaps(p) {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+// Remove all entries
+func (c *lru) clear() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.generation++
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	c.byEvent = make(map[int]map[string]bool)
+}
+
+// Number of entries, including expired ones that are not removed yet
+func (c *lru) len() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.order.Len()
+}
+
+// Must be called under lock
+func (c *lru) remove(element *list.Element) {
+	e := c.order.Remove(element).(*entry)
+	delete(c.entries, e.key)
+	for _, id := range e.ids {
+		delete(c.byEvent[id], e.key)
+		if len(c.byEvent[id]) == 0 {
+			delete(c.byEvent, id)
+		}
+	}
+}
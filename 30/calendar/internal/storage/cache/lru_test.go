@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTestPeriod(startHour, endHour int) *period {
+	start := time.Date(2019, 11, 25, startHour, 0, 0, 0, time.UTC)
+	end := time.Date(2019, 11, 25, endHour, 0, 0, 0, time.UTC)
+	return &period{start: &start, end: &end}
+}
+
+func TestLruEviction(t *testing.T) {
+	c := newLru(3, time.Minute)
+
+	for i := 1; i <= 3; i++ {
+		c.put(c.currentGeneration(), fmt.Sprint(i), i, []int{i}, nil)
+	}
+	// 1 becomes the most recently used, so 2 is evicted
+	if _, ok := c.get("1"); !ok {
+		t.Fatalf("1 must be in cache")
+	}
+	c.put(c.currentGeneration(), "4", 4, []int{4}, nil)
+
+	if _, ok := c.get("2"); ok {
+		t.Errorf("2 must be evicted")
+	}
+	for _, key := range []string{"1", "3", "4"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("%s must be in cache", key)
+		}
+	}
+	if len(c.byEvent) != 3 {
+		t.Errorf("index must have 3 events instead of %v", c.byEvent)
+	}
+}
+
+func TestLruTTL(t *testing.T) {
+	now := time.Date(2019, 11, 25, 10, 0, 0, 0, time.UTC)
+	c := newLru(10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.put(c.currentGeneration(), "key", 1, nil, nil)
+
+	now = now.Add(59 * time.Second)
+	if value, ok := c.get("key"); !ok || value != 1 {
+		t.Errorf("value must be in cache before TTL instead of %v", value)
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := c.get("key"); ok {
+		t.Errorf("value must be expired after TTL")
+	}
+	if c.len() != 0 {
+		t.Errorf("expired entry must be removed instead of %d entries", c.len())
+	}
+}
+
+func TestLruInvalidation(t *testing.T) {
+	c := newLru(10, time.Minute)
+
+	c.put(c.currentGeneration(), "event 1", 1, []int{1}, nil)
+	c.put(c.currentGeneration(), "morning", "1, 2", []int{1, 2}, newTestPeriod(9, 12))
+	c.put(c.currentGeneration(), "evening", "3", []int{3}, newTestPeriod(18, 21))
+	c.put(c.currentGeneration(), "all", "1, 2, 3", []int{1, 2, 3}, &period{})
+
+	c.invalidateEvent(2)
+	if _, ok := c.get("morning"); ok {
+		t.Errorf("morning must be invalidated with event 2")
+	}
+	if _, ok := c.get("event 1"); !ok {
+		t.Errorf("event 1 must not be invalidated with event 2")
+	}
+
+	c.put(c.currentGeneration(), "morning", "1, 2", []int{1, 2}, newTestPeriod(9, 12))
+	c.invalidatePeriod(*newTestPeriod(12, 13))
+	if _, ok := c.get("morning"); ok {
+		t.Errorf("morning must be invalidated by overlapping period")
+	}
+	if _, ok := c.get("all"); ok {
+		t.Errorf("unbounded period must be invalidated by any period")
+	}
+	if _, ok := c.get("evening"); !ok {
+		t.Errorf("evening must not be invalidated by not overlapping period")
+	}
+	if _, ok := c.get("event 1"); !ok {
+		t.Errorf("event 1 must not be invalidated by period")
+	}
+
+	c.invalidatePeriod(period{})
+	if _, ok := c.get("evening"); ok {
+		t.Errorf("evening must be invalidated by unbounded period")
+	}
+
+	c.clear()
+	if c.len() != 0 || len(c.byEvent) != 0 {
+		t.Errorf("cache must be empty instead of %d entries", c.len())
+	}
+}
+
+// Result that is got before invalidation could be stale, so it is not put
+func TestLruGeneration(t *testing.T) {
+	c := newLru(10, time.Minute)
+
+	generation := c.currentGeneration()
+	c.invalidateEvent(1)
+	c.put(generation, "event 1", 1, []int{1}, nil)
+
+	if _, ok := c.get("event 1"); ok {
+		t.Errorf("result got before invalidation must not be put")
+	}
+}
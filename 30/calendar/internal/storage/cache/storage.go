@@ -0,0 +1,354 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/monitoring"
+)
+
+// Queries of cache, they are labels of metrics
+const (
+	queryEvent  = "event"
+	queryPeriod = "period"
+	queryPage   = "page"
+)
+
+// All day events are got by local days, so occurrences of event could be got by periods that are up to a day away from it
+const periodMargin = 24 * time.Hour
+
+type Config struct {
+	Size int           // max number of cached results of queries, 10000 by default
+	TTL  time.Duration // time to live of cached result, 1m by default
+}
+
+func NewConfig(m map[string]string) (*Config, error) {
+	size := 10000
+	if val, ok := m["size"]; ok && val != "" {
+		var err error
+		size, err = strconv.Atoi(val)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("size key must be positive integer: %s", val)
+		}
+	}
+
+	ttl := time.Minute
+	if val, ok := m["ttl"]; ok && val != "" {
+		var err error
+		ttl, err = time.ParseDuration(val)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("ttl key must be positive duration: %s", val)
+		}
+	}
+
+	return &Config{
+		Size: size,
+		TTL:  ttl,
+	}, nil
+}
+
+// Read-through cache of storage: results of GetEvent, GetEventsByPeriod and GetEventsByPeriodPage are kept in bounded LRU with TTL
+// Write through cache invalidates results with changed event (by id) and results of period queries which periods overlap new position of event
+// Write is invalidated even if it fails, failed write could be applied (e.g. on timeout)
+// Writes that bypass cache (e.g. by scheduler or purger processes) are seen after TTL
+// Other methods are passed to storage, Searcher, Transactor and Claimer features of storage are kept
+type Storage struct {
+	entities.Storage                          // storage (view) under cache
+	cache            *lru                     // shared by views
+	metrics          *monitoring.CacheMetrics // could be nil
+	owner            string                   // owner of view, results are cached per owner
+	tx               *txInvalidations         // not nil for transactional view
+}
+
+// Invalidations of writes of transactional view, they are applied when transaction is committed
+// Transactional view doesn't use cache, because it sees its own not committed writes
+type txInvalidations struct {
+	invalidations []func(c *lru)
+}
+
+// Constructor, metrics could be nil
+func NewStorage(storage entities.Storage, cfg Config, metrics *monitoring.CacheMetrics) *Storage {
+	return &Storage{
+		Storage: storage,
+		cache:   newLru(cfg.Size, cfg.TTL),
+		metrics: metrics,
+	}
+}
+
+// Storage view of owner, it shares cache with storage
+func (s *Storage) ForOwner(owner string) entities.Storage {
+	return &Storage{
+		Storage: s.Storage.ForOwner(owner),
+		cache:   s.cache,
+		metrics: s.metrics,
+		owner:   owner,
+		tx:      s.tx,
+	}
+}
+
+func (s *Storage) GetEvent(ctx context.Context, id int) (entities.Event, error) {
+	key := fmt.Sprintf("%s|%s|%d", queryEvent, s.owner, id)
+	value, err := s.cached(key, queryEvent, nil, func() (interface{}, []int, error) {
+		event, err := s.Storage.GetEvent(ctx, id)
+		return event, []int{id}, err
+	})
+	if err != nil {
+		return entities.Event{}, err
+	}
+	return value.(entities.Event), nil
+}
+
+func (s *Storage) GetEventsByPeriod(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime, calendarIds ...int) ([]entities.Event, error) {
+	key := fmt.Sprintf("%s|%s|%s|%s|%v", queryPeriod, s.owner, timeKey(startTime), timeKey(endTime), calendarIds)
+	value, err := s.cached(key, queryPeriod, queryPeriodOf(startTime, endTime), func() (interface{}, []int, error) {
+		events, err := s.Storage.GetEventsByPeriod(ctx, startTime, endTime, calendarIds...)
+		return copyEvents(events), eventIds(events), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// callers could sort or change returned slice, so cached slice is never returned
+	return copyEvents(value.([]entities.Event)), nil
+}
+
+func (s *Storage) GetEventsByPeriodPage(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime, limit int, cursor string, calendarIds ...int) (entities.EventPage, error) {
+	key := fmt.Sprintf("%s|%s|%s|%s|%d|%s|%v", queryPage, s.owner, timeKey(startTime), timeKey(endTime), limit, cursor, calendarIds)
+	value, err := s.cached(key, queryPage, queryPeriodOf(startTime, endTime), func() (interface{}, []int, error) {
+		page, err := s.Storage.GetEventsByPeriodPage(ctx, startTime, endTime, limit, cursor, calendarIds...)
+		page.Events = copyEvents(page.Events)
+		return page, eventIds(page.Events), err
+	})
+	if err != nil {
+		return entities.EventPage{}, err
+	}
+	page := value.(entities.EventPage)
+	page.Events = copyEvents(page.Events)
+	return page, nil
+}
+
+func (s *Storage) AddEvent(ctx context.Context, event entities.Event) (int, error) {
+	id, err := s.Storage.AddEvent(ctx, event)
+	s.invalidate(func(c *lru) {
+		c.invalidatePeriod(eventPeriod(event))
+	})
+	return id, err
+}
+
+func (s *Storage) UpdateEvent(ctx context.Context, id int, event entities.Event) error {
+	err := s.Storage.UpdateEvent(ctx, id, event)
+	s.invalidate(func(c *lru) {
+		c.invalidateEvent(id)
+		c.invalidatePeriod(eventPeriod(event))
+	})
+	return err
+}
+
+func (s *Storage) DeleteEvent(ctx context.Context, id int) error {
+	err := s.Storage.DeleteEvent(ctx, id)
+	s.invalidateEvent(id)
+	return err
+}
+
+// Restored event is got to invalidate periods of its position, if it is not got all periods are invalidated
+func (s *Storage) RestoreEvent(ctx context.Context, id int) error {
+	err := s.Storage.RestoreEvent(ctx, id)
+	p := period{}
+	if event, getErr := s.Storage.GetEvent(ctx, id); getErr == nil {
+		p = eventPeriod(event)
+	}
+	s.invalidate(func(c *lru) {
+		c.invalidateEvent(id)
+		c.invalidatePeriod(p)
+	})
+	return err
+}
+
+func (s *Storage) MarkEventAsNotified(ctx context.Context, id int, when time.Time) error {
+	err := s.Storage.MarkEventAsNotified(ctx, id, when)
+	s.invalidateEvent(id)
+	return err
+}
+
+func (s *Storage) MarkReminderAsNotified(ctx context.Context, id int, beforeMinutes int, start entities.DateTime, when time.Time) error {
+	err := s.Storage.MarkReminderAsNotified(ctx, id, beforeMinutes, start, when)
+	s.invalidateEvent(id)
+	return err
+}
+
+func (s *Storage) RespondToInvitation(ctx context.Context, id int, email string, status string) error {
+	err := s.Storage.RespondToInvitation(ctx, id, email, status)
+	s.invalidateEvent(id)
+	return err
+}
+
+func (s *Storage) MarkAttendeeAsInvited(ctx context.Context, id int, email string, start entities.DateTime, end entities.DateTime) error {
+	err := s.Storage.MarkAttendeeAsInvited(ctx, id, email, start, end)
+	s.invalidateEvent(id)
+	return err
+}
+
+func (s *Storage) ClearAll(ctx context.Context) error {
+	err := s.Storage.ClearAll(ctx)
+	s.invalidate(func(c *lru) {
+		c.clear()
+	})
+	return err
+}
+
+// Events of deleted calendar are not known, so all results are invalidated
+func (s *Storage) DeleteCalendar(ctx context.Context, id int) error {
+	err := s.Storage.DeleteCalendar(ctx, id)
+	s.invalidate(func(c *lru) {
+		c.clear()
+	})
+	return err
+}
+
+// Search is not cached, if storage is not entities.Searcher return entities.ErrSearchNotSupported
+func (s *Storage) SearchEvents(ctx context.Context, query string, startTime *entities.DateTime, endTime *entities.DateTime, limit int) ([]entities.Event, error) {
+	searcher, ok := s.Storage.(entities.Searcher)
+	if !ok {
+		return nil, entities.ErrSearchNotSupported
+	}
+	return searcher.SearchEvents(ctx, query, startTime, endTime, limit)
+}
+
+// Claim is not cached and doesn't invalidate cache, claims of reminders are not part of events
+// If storage is not entities.Claimer return entities.ErrClaimNotSupported
+func (s *Storage) ClaimEventsToNotify(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime, now time.Time, lease time.Duration) ([]entities.Notification, error) {
+	claimer, ok := s.Storage.(entities.Claimer)
+	if !ok {
+		return nil, entities.ErrClaimNotSupported
+	}
+	return claimer.ClaimEventsToNotify(ctx, startTime, endTime, now, lease)
+}
+
+// Claim is not cached and doesn't invalidate cache, claims of attendees are not part of events
+// If storage is not entities.Claimer return entities.ErrClaimNotSupported
+func (s *Storage) ClaimInvitationsToSend(ctx context.Context, now time.Time, lease time.Duration) ([]entities.Invitation, error) {
+	claimer, ok := s.Storage.(entities.Claimer)
+	if !ok {
+		return nil, entities.ErrClaimNotSupported
+	}
+	return claimer.ClaimInvitationsToSend(ctx, now, lease)
+}
+
+// Transaction of storage, writes of transaction invalidate cache when it is committed
+// If storage is not entities.Transactor return entities.ErrTxNotSupported
+func (s *Storage) WithTx(ctx context.Context, fn func(tx entities.Storage) error) error {
+	transactor, ok := s.Storage.(entities.Transactor)
+	if !ok {
+		return entities.ErrTxNotSupported
+	}
+
+	invalidations := s.tx
+	if invalidations == nil {
+		invalidations = &txInvalidations{}
+	}
+
+	err := transactor.WithTx(ctx, func(tx entities.Storage) error {
+		return fn(&Storage{
+			Storage: tx,
+			cache:   s.cache,
+			metrics: s.metrics,
+			owner:   s.owner,
+			tx:      invalidations,
+		})
+	})
+	if err != nil || s.tx != nil {
+		// nested transaction is invalidated with outer one
+		return err
+	}
+
+	for _, invalidation := range invalidations.invalidations {
+		invalidation(s.cache)
+	}
+	return nil
+}
+
+// Get result of query from cache or load it and put into cache, errors are not cached
+// Transactional view doesn't use cache
+func (s *Storage) cached(key string, query string, p *period, load func() (interface{}, []int, error)) (interface{}, error) {
+	if s.tx != nil {
+		value, _, err := load()
+		return value, err
+	}
+
+	if value, ok := s.cache.get(key); ok {
+		s.metrics.IncHits(query)
+		return value, nil
+	}
+	s.metrics.IncMisses(query)
+
+	generation := s.cache.currentGeneration()
+	value, ids, err := load()
+	if err != nil {
+		return nil, err
+	}
+	s.cache.put(generation, key, value, ids, p)
+	return value, nil
+}
+
+func (s *Storage) invalidateEvent(id int) {
+	s.invalidate(func(c *lru) {
+		c.invalidateEvent(id)
+	})
+}
+
+// Invalidate cache or record invalidation to apply it on commit for transactional view
+func (s *Storage) invalidate(invalidation func(c *lru)) {
+	if s.tx != nil {
+		s.tx.invalidations = append(s.tx.invalidations, invalidation)
+		return
+	}
+	invalidation(s.cache)
+}
+
+// Period where occurrences of event could be got, it is unbounded for recurring event
+func eventPeriod(event entities.Event) period {
+	if event.IsRecurring() {
+		return period{}
+	}
+	start := event.Start().Time().Add(-periodMargin)
+	end := event.End().Time().Add(periodMargin)
+	return period{start: &start, end: &end}
+}
+
+func queryPeriodOf(startTime *entities.DateTime, endTime *entities.DateTime) *period {
+	p := &period{}
+	if startTime != nil {
+		start := startTime.Time()
+		p.start = &start
+	}
+	if endTime != nil {
+		end := endTime.Time()
+		p.end = &end
+	}
+	return p
+}
+
+// Key of boundary of period, time zone matters because all day events are got by local days of boundaries
+func timeKey(t *entities.DateTime) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Time().Format(time.RFC3339Nano)
+}
+
+func eventIds(events []entities.Event) []int {
+	ids := make([]int, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.Id())
+	}
+	return ids
+}
+
+func copyEvents(events []entities.Event) []entities.Event {
+	if events == nil {
+		return nil
+	}
+	return append(make([]entities.Event, 0, len(events)), events...)
+}
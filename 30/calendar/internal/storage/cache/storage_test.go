@@ -0,0 +1,268 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/monitoring"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/memory"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/storagetest"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Memory storage that counts cached queries
+type countingStorage struct {
+	*memory.Storage
+	queries *int
+}
+
+func (s countingStorage) ForOwner(owner string) entities.Storage {
+	return countingStorage{s.Storage.ForOwner(owner).(*memory.Storage), s.queries}
+}
+
+func (s countingStorage) GetEvent(ctx context.Context, id int) (entities.Event, error) {
+	*s.queries++
+	return s.Storage.GetEvent(ctx, id)
+}
+
+func (s countingStorage) GetEventsByPeriod(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime, calendarIds ...int) ([]entities.Event, error) {
+	*s.queries++
+	return s.Storage.GetEventsByPeriod(ctx, startTime, endTime, calendarIds...)
+}
+
+func (s countingStorage) GetEventsByPeriodPage(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime, limit int, cursor string, calendarIds ...int) (entities.EventPage, error) {
+	*s.queries++
+	return s.Storage.GetEventsByPeriodPage(ctx, startTime, endTime, limit, cursor, calendarIds...)
+}
+
+// Storage that hides optional features (search, transactions) of memory storage
+type storageWithoutFeatures struct {
+	entities.Storage
+}
+
+func (s storageWithoutFeatures) ForOwner(owner string) entities.Storage {
+	return storageWithoutFeatures{s.Storage.ForOwner(owner)}
+}
+
+func newTestStorage() (*Storage, *int) {
+	queries := 0
+	storage := NewStorage(countingStorage{memory.NewStorage(), &queries}, Config{Size: 100, TTL: time.Minute}, nil)
+	return storage, &queries
+}
+
+func newTestEvent(name string, day int) entities.Event {
+	start := entities.NewDateTime(2019, 11, day, 10, 0)
+	return entities.NewEvent(name, start, start.PlusMinutes(60))
+}
+
+func TestReadThrough(t *testing.T) {
+	ctx := context.Background()
+	storage, queries := newTestStorage()
+
+	id, _ := storage.AddEvent(ctx, newTestEvent("Meeting", 25))
+	start, end := entities.NewDateTime(2019, 11, 1, 0, 0), entities.NewDateTime(2019, 11, 30, 23, 59)
+
+	for i := 0; i < 3; i++ {
+		event, err := storage.GetEvent(ctx, id)
+		if err != nil || event.Name() != "Meeting" {
+			t.Fatalf("event must be got instead of %v, error %v", event, err)
+		}
+		events, _ := storage.GetEventsByPeriod(ctx, &start, &end)
+		if len(events) != 1 {
+			t.Fatalf("must be 1 event in period instead of %v", events)
+		}
+		page, _ := storage.GetEventsByPeriodPage(ctx, &start, &end, 10, "")
+		if len(page.Events) != 1 {
+			t.Fatalf("must be 1 event in page instead of %v", page)
+		}
+	}
+	if *queries != 3 {
+		t.Errorf("storage must be queried 3 times instead of %d", *queries)
+	}
+
+	// errors are not cached
+	for i := 0; i < 2; i++ {
+		if _, err := storage.GetEvent(ctx, id+1); err == nil {
+			t.Errorf("unknown event must not be got")
+		}
+	}
+	if *queries != 5 {
+		t.Errorf("storage must be queried 5 times instead of %d", *queries)
+	}
+
+	// views of owners are cached separately
+	if _, err := storage.ForOwner("alice").GetEvent(ctx, id); err == nil {
+		t.Errorf("event of other owner must not be got")
+	}
+}
+
+func TestInvalidation(t *testing.T) {
+	ctx := context.Background()
+	storage, queries := newTestStorage()
+
+	meetingId, _ := storage.AddEvent(ctx, newTestEvent("Meeting", 5))
+	retroId, _ := storage.AddEvent(ctx, newTestEvent("Retro", 25))
+
+	firstWeekStart, firstWeekEnd := entities.NewDateTime(2019, 11, 1, 0, 0), entities.NewDateTime(2019, 11, 7, 23, 59)
+	lastWeekStart, lastWeekEnd := entities.NewDateTime(2019, 11, 24, 0, 0), entities.NewDateTime(2019, 11, 30, 23, 59)
+
+	query := func() ([]entities.Event, []entities.Event) {
+		firstWeek, _ := storage.GetEventsByPeriod(ctx, &firstWeekStart, &firstWeekEnd)
+		lastWeek, _ := storage.GetEventsByPeriod(ctx, &lastWeekStart, &lastWeekEnd)
+		return firstWeek, lastWeek
+	}
+	_, _ = query()
+	_, _ = storage.GetEvent(ctx, meetingId)
+	*queries = 0
+
+	// event is changed in its week, so only this week and event itself are invalidated
+	_ = storage.UpdateEvent(ctx, retroId, entities.WithDescription(newTestEvent("Retro", 25), "Sprint 42"))
+	_, lastWeek := query()
+	_, _ = storage.GetEvent(ctx, meetingId)
+	if *queries != 1 || len(lastWeek) != 1 || lastWeek[0].Description() != "Sprint 42" {
+		t.Errorf("only last week must be queried again instead of %d queries, %v", *queries, lastWeek)
+	}
+
+	// event is moved to other week, so both weeks are invalidated: old week by id, new week by period
+	*queries = 0
+	_ = storage.UpdateEvent(ctx, meetingId, newTestEvent("Meeting", 26))
+	firstWeek, lastWeek := query()
+	meeting, _ := storage.GetEvent(ctx, meetingId)
+	if *queries != 3 || len(firstWeek) != 0 || len(lastWeek) != 2 || !meeting.Start().Equal(entities.NewDateTime(2019, 11, 26, 10, 0)) {
+		t.Errorf("both weeks and event must be queried again instead of %d queries, %v, %v, %v", *queries, firstWeek, lastWeek, meeting)
+	}
+
+	*queries = 0
+	_ = storage.DeleteEvent(ctx, retroId)
+	_, _ = storage.AddEvent(ctx, newTestEvent("Planning", 2))
+	firstWeek, lastWeek = query()
+	if *queries != 2 || len(firstWeek) != 1 || len(lastWeek) != 1 {
+		t.Errorf("both weeks must be queried again instead of %d queries, %v, %v", *queries, firstWeek, lastWeek)
+	}
+
+	_ = storage.RestoreEvent(ctx, retroId)
+	*queries = 0
+	firstWeek, lastWeek = query()
+	if *queries != 1 || len(firstWeek) != 1 || len(lastWeek) != 2 {
+		t.Errorf("only last week must be queried again after restore instead of %d queries, %v, %v", *queries, firstWeek, lastWeek)
+	}
+
+	// writes through views of other owners invalidate cache too
+	*queries = 0
+	_ = storage.ForOwner("").DeleteEvent(ctx, meetingId)
+	if _, err := storage.GetEvent(ctx, meetingId); err == nil {
+		t.Errorf("deleted event must not be got")
+	}
+	if _, lastWeek = query(); *queries != 2 || len(lastWeek) != 1 {
+		t.Errorf("event and last week must be queried again instead of %d queries, %v", *queries, lastWeek)
+	}
+
+	*queries = 0
+	_ = storage.ClearAll(ctx)
+	if firstWeek, lastWeek = query(); *queries != 2 || len(firstWeek) != 0 || len(lastWeek) != 0 {
+		t.Errorf("both weeks must be empty after clear instead of %d queries, %v, %v", *queries, firstWeek, lastWeek)
+	}
+}
+
+func TestInvalidationOfRecurringEvent(t *testing.T) {
+	ctx := context.Background()
+	storage, _ := newTestStorage()
+
+	recurrence, _ := entities.ParseRecurrence("FREQ=WEEKLY")
+	id, _ := storage.AddEvent(ctx, newTestEvent("Standup", 4))
+
+	start, end := entities.NewDateTime(2019, 12, 1, 0, 0), entities.NewDateTime(2019, 12, 31, 23, 59)
+	if events, _ := storage.GetEventsByPeriod(ctx, &start, &end); len(events) != 0 {
+		t.Fatalf("must be no events in december instead of %v", events)
+	}
+
+	_ = storage.UpdateEvent(ctx, id, entities.WithRecurrence(newTestEvent("Standup", 4), recurrence))
+	if events, _ := storage.GetEventsByPeriod(ctx, &start, &end); len(events) != 5 {
+		t.Errorf("must be 5 occurrences in december instead of %v", events)
+	}
+}
+
+func TestTx(t *testing.T) {
+	ctx := context.Background()
+	storage := NewStorage(memory.NewStorage(), Config{Size: 100, TTL: time.Minute}, nil)
+
+	id, _ := storage.AddEvent(ctx, newTestEvent("Meeting", 25))
+	start, end := entities.NewDateTime(2019, 11, 1, 0, 0), entities.NewDateTime(2019, 11, 30, 23, 59)
+	_, _ = storage.GetEventsByPeriod(ctx, &start, &end)
+
+	txErr := errors.New("test error")
+	err := storage.WithTx(ctx, func(tx entities.Storage) error {
+		if _, err := tx.AddEvent(ctx, newTestEvent("Retro", 26)); err != nil {
+			return err
+		}
+		if events, _ := tx.GetEventsByPeriod(ctx, &start, &end); len(events) != 2 {
+			t.Errorf("transaction must see its own event instead of %v", events)
+		}
+		return txErr
+	})
+	if err != txErr {
+		t.Fatalf("error of transaction must be returned instead of %v", err)
+	}
+	if events, _ := storage.GetEventsByPeriod(ctx, &start, &end); len(events) != 1 {
+		t.Errorf("must be 1 event after rollback instead of %v", events)
+	}
+
+	err = storage.WithTx(ctx, func(tx entities.Storage) error {
+		return tx.DeleteEvent(ctx, id)
+	})
+	if err != nil {
+		t.Fatalf("transaction must be committed, got %s", err)
+	}
+	if events, _ := storage.GetEventsByPeriod(ctx, &start, &end); len(events) != 0 {
+		t.Errorf("must be no events after commit instead of %v", events)
+	}
+	if _, err := storage.GetEvent(ctx, id); err == nil {
+		t.Errorf("deleted event must not be got after commit")
+	}
+
+	// features of storage are kept
+	if _, err := storage.SearchEvents(ctx, "meeting", nil, nil, 0); err != nil {
+		t.Errorf("search must be passed to storage, got %s", err)
+	}
+	withoutFeatures := NewStorage(storageWithoutFeatures{memory.NewStorage()}, Config{Size: 100, TTL: time.Minute}, nil)
+	if err := withoutFeatures.WithTx(ctx, func(tx entities.Storage) error { return nil }); err != entities.ErrTxNotSupported {
+		t.Errorf("must be ErrTxNotSupported for storage without transactions instead of %v", err)
+	}
+	if _, err := withoutFeatures.SearchEvents(ctx, "meeting", nil, nil, 0); err != entities.ErrSearchNotSupported {
+		t.Errorf("must be ErrSearchNotSupported for storage without search instead of %v", err)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+	registry := prometheus.NewRegistry()
+	storage := NewStorage(memory.NewStorage(), Config{Size: 100, TTL: time.Minute}, monitoring.NewCacheMetrics(registry, nil))
+
+	id, _ := storage.AddEvent(ctx, newTestEvent("Meeting", 25))
+	for i := 0; i < 3; i++ {
+		_, _ = storage.GetEvent(ctx, id)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("can't gather metrics %s", err)
+	}
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			values[family.GetName()+" "+metric.GetLabel()[0].GetValue()] = metric.GetCounter().GetValue()
+		}
+	}
+	if values["storage_cache_hits_count event"] != 2 || values["storage_cache_misses_count event"] != 1 {
+		t.Errorf("must be 2 hits and 1 miss of event instead of %v", values)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) (entities.Storage, func()) {
+		return NewStorage(memory.NewStorage(), Config{Size: 100, TTL: time.Minute}, nil), nil
+	})
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+// +build !windows
+
+package file
+
+import (
+	"os"
+	"syscall"
+)
+
+// Files are protected from other processes by lock
+const canLock = true
+
+// Take exclusive lock of file without waiting, lock is released when file is closed (or process exits)
+func lockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
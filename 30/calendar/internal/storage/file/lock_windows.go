@@ -0,0 +1,11 @@
+package file
+
+import "os"
+
+// Files are not protected from other processes, warning is logged on open
+const canLock = false
+
+// Locking is not supported on windows, it is up to user to not run several processes on the same files
+func lockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,154 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/memory"
+)
+
+var ErrCorruptedLog = errors.New("log of storage is corrupted")
+
+// Batch of changes of one operation, it is one line of log: `<crc32 of json in hex> <json>\n`
+type batchRecord struct {
+	Seq     int64                 `json:"seq"` // sequence number of batch, it is incremented by every batch
+	Changes []StorageChangeRecord `json:"changes"`
+}
+
+// Append-only log of changes, it implements memory.Journal
+type changeLog struct {
+	file      *os.File // opened for appending
+	size      int64    // size of written batches, failed write is truncated to it
+	sync      bool     // fsync after every batch
+	seq       int64    // sequence number of the last written batch
+	batches   int      // number of batches written since the last snapshot
+	threshold int      // number of batches after which snapshot is requested
+	snapshot  chan struct{}
+}
+
+// Write batch of changes, batch is durable when Write returns (if sync is on)
+// Called under lock of memory storage, so batches are written one by one
+func (l *changeLog) Write(changes []memory.Change) error {
+	batch := batchRecord{
+		Seq:     l.seq + 1,
+		Changes: make([]StorageChangeRecord, 0, len(changes)),
+	}
+	for _, change := range changes {
+		batch.Changes = append(batch.Changes, convertChangeToRecord(change))
+	}
+
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	line := make([]byte, 0, len(data)+10)
+	line = append(line, fmt.Sprintf("%08x ", crc32.ChecksumIEEE(data))...)
+	line = append(line, data...)
+	line = append(line, '\n')
+
+	_, err = l.file.Write(line)
+	if err == nil && l.sync {
+		err = l.file.Sync()
+	}
+	if err != nil {
+		// changes are not applied, so partially written batch must not be replayed
+		_ = l.file.Truncate(l.size)
+		return fmt.Errorf("can't write log of storage %w", err)
+	}
+
+	l.size += int64(len(line))
+	l.seq = batch.Seq
+	l.batches++
+	if l.threshold > 0 && l.batches >= l.threshold {
+		select {
+		case l.snapshot <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Drop all batches, they are in snapshot now
+func (l *changeLog) reset() error {
+	err := l.file.Truncate(0)
+	if err != nil {
+		return err
+	}
+	l.size = 0
+	l.batches = 0
+	return l.file.Sync()
+}
+
+// Read changes of batches with sequence number greater than after
+// Returns changes, sequence number of the last batch and size of valid part of log
+// Torn last line (e.g. after crash during write) is not error, it is ignored and must be truncated by caller
+func readLog(r io.Reader, after int64) ([]memory.Change, int64, int64, error) {
+	reader := bufio.NewReader(r)
+
+	var changes []memory.Change
+	seq := after
+	var size int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// line without new line is torn write
+			return changes, seq, size, nil
+		}
+		if err != nil {
+			return nil, 0, 0, err
+		}
+
+		batch, batchErr := parseBatch(line)
+		if batchErr != nil {
+			if _, err := reader.Peek(1); err == io.EOF {
+				// the last line is torn write
+				return changes, seq, size, nil
+			}
+			return nil, 0, 0, fmt.Errorf("%w: at offset %d %s", ErrCorruptedLog, size, batchErr)
+		}
+		size += int64(len(line))
+
+		if batch.Seq <= after {
+			// batch is already in snapshot, e.g. crash happened between writing of snapshot and reset of log
+			continue
+		}
+		if batch.Seq != seq+1 {
+			return nil, 0, 0, fmt.Errorf("%w: batch %d follows batch %d", ErrCorruptedLog, batch.Seq, seq)
+		}
+		seq = batch.Seq
+
+		for _, changeRecord := range batch.Changes {
+			change, err := convertRecordToChange(changeRecord)
+			if err != nil {
+				return nil, 0, 0, fmt.Errorf("%w: batch %d %s", ErrCorruptedLog, batch.Seq, err)
+			}
+			changes = append(changes, change)
+		}
+	}
+}
+
+func parseBatch(line []byte) (batchRecord, error) {
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	parts := bytes.SplitN(line, []byte{' '}, 2)
+	if len(parts) != 2 {
+		return batchRecord{}, errors.New("invalid line")
+	}
+
+	var checksum uint32
+	_, err := fmt.Sscanf(string(parts[0]), "%08x", &checksum)
+	if err != nil || checksum != crc32.ChecksumIEEE(parts[1]) {
+		return batchRecord{}, errors.New("checksum mismatch")
+	}
+
+	var batch batchRecord
+	err = json.Unmarshal(parts[1], &batch)
+	return batch, err
+}
@@ -0,0 +1,369 @@
+package file
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/memory"
+)
+
+const dateLayout = "2006-01-02"
+
+// Json record of event, times are in UTC, wall clock times are restored by time zone of event
+type EventRecord struct {
+	Id          int              `json:"id"`
+	Name        string           `json:"name"`
+	Start       time.Time        `json:"start"`
+	End         time.Time        `json:"end"`
+	Timezone    string           `json:"timezone"`            // IANA name of event time zone
+	StartDate   string           `json:"startDate,omitempty"` // first day of all day event
+	EndDate     string           `json:"endDate,omitempty"`   // last day of all day event
+	Rrule       string           `json:"rrule,omitempty"`
+	ExDates     []time.Time      `json:"exdates,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Location    string           `json:"location,omitempty"` // place of event
+	Organizer   string           `json:"organizer,omitempty"`
+	Attendees   []AttendeeRecord `json:"attendees,omitempty"`
+	Color       string           `json:"color,omitempty"`
+	Owner       string           `json:"owner,omitempty"`
+	Reminders   []ReminderRecord `json:"reminders,omitempty"`
+	DeletedTime *time.Time       `json:"deletedTime,omitempty"`
+	Version     int              `json:"version"`
+	CalendarId  int              `json:"calendarId,omitempty"`
+}
+
+type AttendeeRecord struct {
+	Email        string     `json:"email"`
+	Status       string     `json:"status"`
+	InvitedStart *time.Time `json:"invitedStart,omitempty"` // start of event in the last enqueued invitation
+	InvitedEnd   *time.Time `json:"invitedEnd,omitempty"`   // end of event in the last enqueued invitation
+}
+
+type ReminderRecord struct {
+	BeforeMinutes int        `json:"beforeMinutes"`
+	NotifiedTime  *time.Time `json:"notifiedTime,omitempty"`
+	NotifiedStart *time.Time `json:"notifiedStart,omitempty"` // start of the latest notified occurrence
+}
+
+type CalendarRecord struct {
+	Id    int    `json:"id"`
+	Name  string `json:"name"`
+	Owner string `json:"owner,omitempty"`
+}
+
+type AuditRecord struct {
+	EventId int            `json:"eventId"`
+	Owner   string         `json:"owner,omitempty"`
+	Actor   string         `json:"actor,omitempty"`
+	Action  string         `json:"action"`
+	Time    time.Time      `json:"time"`
+	Changes []ChangeRecord `json:"changes,omitempty"`
+}
+
+// Change of field of event in audit record
+type ChangeRecord struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Json record of change of storage data, only fields of its operation are set
+type StorageChangeRecord struct {
+	Op       string          `json:"op"`
+	Id       int             `json:"id,omitempty"`
+	Event    *EventRecord    `json:"event,omitempty"`
+	Calendar *CalendarRecord `json:"calendar,omitempty"`
+	Entry    *AuditRecord    `json:"entry,omitempty"`
+}
+
+// Json record of snapshot, Seq is sequence number of the last batch of log included into snapshot
+type SnapshotRecord struct {
+	Seq         int64            `json:"seq"`
+	EventSeq    int              `json:"eventSeq"`
+	CalendarSeq int              `json:"calendarSeq"`
+	Events      []EventRecord    `json:"events"`
+	Trash       []EventRecord    `json:"trash"`
+	Calendars   []CalendarRecord `json:"calendars"`
+	Audit       []AuditRecord    `json:"audit"`
+}
+
+func convertEventToRecord(event entities.Event) EventRecord {
+	record := EventRecord{
+		Id:          event.Id(),
+		Name:        event.Name(),
+		Start:       event.Start().Time().UTC(),
+		End:         event.End().Time().UTC(),
+		Timezone:    event.Location().String(),
+		Description: event.Description(),
+		Location:    event.Place(),
+		Organizer:   event.Organizer(),
+		Color:       event.Color(),
+		Owner:       event.Owner(),
+		Version:     event.Version(),
+		CalendarId:  event.CalendarId(),
+	}
+
+	if event.IsAllDay() {
+		record.StartDate = event.StartDate().Format(dateLayout)
+		record.EndDate = event.EndDate().Format(dateLayout)
+	}
+
+	if event.IsRecurring() {
+		record.Rrule = event.Recurrence().String()
+		for _, exDate := range event.Recurrence().ExDates() {
+			record.ExDates = append(record.ExDates, exDate.Time().UTC())
+		}
+	}
+
+	for _, attendee := range event.AttendeeList() {
+		attendeeRecord := AttendeeRecord{Email: attendee.Email(), Status: attendee.Status()}
+		if attendee.IsInvited() {
+			invitedStart := attendee.InvitedStart().Time().UTC()
+			attendeeRecord.InvitedStart = &invitedStart
+			if !attendee.InvitedEnd().Time().IsZero() {
+				invitedEnd := attendee.InvitedEnd().Time().UTC()
+				attendeeRecord.InvitedEnd = &invitedEnd
+			}
+		}
+		record.Attendees = append(record.Attendees, attendeeRecord)
+	}
+
+	for _, reminder := range event.Reminders() {
+		reminderRecord := ReminderRecord{BeforeMinutes: reminder.BeforeMinutes()}
+		if reminder.IsNotified() {
+			notifiedTime := reminder.NotifiedTime().UTC()
+			reminderRecord.NotifiedTime = &notifiedTime
+			if !reminder.NotifiedStart().Time().IsZero() {
+				notifiedStart := reminder.NotifiedStart().Time().UTC()
+				reminderRecord.NotifiedStart = &notifiedStart
+			}
+		}
+		record.Reminders = append(record.Reminders, reminderRecord)
+	}
+
+	if event.IsDeleted() {
+		deletedTime := event.DeletedTime().UTC()
+		record.DeletedTime = &deletedTime
+	}
+
+	return record
+}
+
+func convertRecordToEvent(record EventRecord) (entities.Event, error) {
+	event := entities.NewEventWithId(record.Id, record.Name,
+		entities.ConvertFromTime(record.Start), entities.ConvertFromTime(record.End))
+
+	var reminders []entities.Reminder
+	for _, reminderRecord := range record.Reminders {
+		reminder := entities.NewReminder(reminderRecord.BeforeMinutes)
+		if reminderRecord.NotifiedTime != nil {
+			reminder = reminder.Notified(*reminderRecord.NotifiedTime)
+			if reminderRecord.NotifiedStart != nil {
+				reminder = reminder.OccurrenceNotified(entities.ConvertFromTime(*reminderRecord.NotifiedStart), *reminderRecord.NotifiedTime)
+			}
+		}
+		reminders = append(reminders, reminder)
+	}
+	event = entities.WithReminders(event, reminders)
+
+	if record.StartDate != "" && record.EndDate != "" {
+		startDate, err := time.Parse(dateLayout, record.StartDate)
+		if err != nil {
+			return entities.Event{}, fmt.Errorf("start date preparing error: %w", err)
+		}
+		endDate, err := time.Parse(dateLayout, record.EndDate)
+		if err != nil {
+			return entities.Event{}, fmt.Errorf("end date preparing error: %w", err)
+		}
+		event = entities.WithAllDay(event, entities.ConvertDateFromTime(startDate), entities.ConvertDateFromTime(endDate))
+	}
+
+	if record.Rrule != "" {
+		recurrence, err := entities.ParseRecurrence(record.Rrule)
+		if err != nil {
+			return entities.Event{}, fmt.Errorf("recurrence preparing error: %w", err)
+		}
+		if len(record.ExDates) > 0 {
+			exDates := make([]entities.DateTime, 0, len(record.ExDates))
+			for _, exDate := range record.ExDates {
+				exDates = append(exDates, entities.ConvertFromTime(exDate))
+			}
+			recurrence = recurrence.WithExDates(exDates)
+		}
+		event = entities.WithRecurrence(event, recurrence)
+	}
+
+	event = entities.WithDescription(event, record.Description)
+	event = entities.WithPlace(event, record.Location)
+	event = entities.WithOrganizer(event, record.Organizer)
+	event = entities.WithColor(event, record.Color)
+	event = entities.WithOwner(event, record.Owner)
+	event = entities.WithVersion(event, record.Version)
+	event = entities.WithCalendarId(event, record.CalendarId)
+	if record.DeletedTime != nil {
+		event = entities.WithDeletedTime(event, *record.DeletedTime)
+	}
+
+	var attendees []entities.Attendee
+	for _, attendeeRecord := range record.Attendees {
+		attendee := entities.NewAttendee(attendeeRecord.Email).Responded(attendeeRecord.Status)
+		if attendeeRecord.InvitedStart != nil {
+			// invitation of records written before end was kept has unknown end
+			var invitedEnd entities.DateTime
+			if attendeeRecord.InvitedEnd != nil {
+				invitedEnd = entities.ConvertFromTime(*attendeeRecord.InvitedEnd)
+			}
+			attendee = attendee.Invited(entities.ConvertFromTime(*attendeeRecord.InvitedStart), invitedEnd)
+		}
+		attendees = append(attendees, attendee)
+	}
+	event = entities.WithAttendeeList(event, attendees)
+
+	loc, err := entities.LoadLocation(record.Timezone)
+	if err != nil {
+		return entities.Event{}, fmt.Errorf("timezone preparing error: %w", err)
+	}
+	event = entities.WithLocation(event, loc)
+
+	return event, nil
+}
+
+func convertCalendarToRecord(calendar entities.Calendar) CalendarRecord {
+	return CalendarRecord{
+		Id:    calendar.Id(),
+		Name:  calendar.Name(),
+		Owner: calendar.Owner(),
+	}
+}
+
+func convertRecordToCalendar(record CalendarRecord) entities.Calendar {
+	calendar := entities.CalendarWithId(entities.NewCalendar(record.Name), record.Id)
+	return entities.CalendarWithOwner(calendar, record.Owner)
+}
+
+func convertAuditEntryToRecord(entry entities.AuditEntry) AuditRecord {
+	record := AuditRecord{
+		EventId: entry.EventId(),
+		Owner:   entry.Owner(),
+		Actor:   entry.Actor(),
+		Action:  entry.Action(),
+		Time:    entry.Time().UTC(),
+	}
+	for _, change := range entry.Changes() {
+		record.Changes = append(record.Changes, ChangeRecord{
+			Field:  change.Field(),
+			Before: change.Before(),
+			After:  change.After(),
+		})
+	}
+	return record
+}
+
+func convertRecordToAuditEntry(record AuditRecord) entities.AuditEntry {
+	var changes []entities.FieldChange
+	for _, change := range record.Changes {
+		changes = append(changes, entities.NewFieldChange(change.Field, change.Before, change.After))
+	}
+	return entities.NewAuditEntry(record.EventId, record.Owner, record.Actor, record.Action, record.Time, changes)
+}
+
+func convertChangeToRecord(change memory.Change) StorageChangeRecord {
+	record := StorageChangeRecord{Op: change.Op}
+	switch change.Op {
+	case memory.ChangePutEvent, memory.ChangeTrashEvent:
+		eventRecord := convertEventToRecord(change.Event)
+		record.Event = &eventRecord
+	case memory.ChangePutCalendar:
+		calendarRecord := convertCalendarToRecord(change.Calendar)
+		record.Calendar = &calendarRecord
+	case memory.ChangeAudit:
+		auditRecord := convertAuditEntryToRecord(change.Entry)
+		record.Entry = &auditRecord
+	default:
+		record.Id = change.Id
+	}
+	return record
+}
+
+func convertRecordToChange(record StorageChangeRecord) (memory.Change, error) {
+	change := memory.Change{Op: record.Op, Id: record.Id}
+	switch record.Op {
+	case memory.ChangePutEvent, memory.ChangeTrashEvent:
+		if record.Event == nil {
+			return memory.Change{}, fmt.Errorf("change `%s` without event", record.Op)
+		}
+		event, err := convertRecordToEvent(*record.Event)
+		if err != nil {
+			return memory.Change{}, err
+		}
+		change.Event = event
+	case memory.ChangePutCalendar:
+		if record.Calendar == nil {
+			return memory.Change{}, fmt.Errorf("change `%s` without calendar", record.Op)
+		}
+		change.Calendar = convertRecordToCalendar(*record.Calendar)
+	case memory.ChangeAudit:
+		if record.Entry == nil {
+			return memory.Change{}, fmt.Errorf("change `%s` without audit entry", record.Op)
+		}
+		change.Entry = convertRecordToAuditEntry(*record.Entry)
+	case memory.ChangeRemoveEvent, memory.ChangeRemoveCalendar:
+	default:
+		return memory.Change{}, fmt.Errorf("unknown change `%s`", record.Op)
+	}
+	return change, nil
+}
+
+func convertSnapshotToRecord(snapshot memory.Snapshot, seq int64) SnapshotRecord {
+	record := SnapshotRecord{
+		Seq:         seq,
+		EventSeq:    snapshot.EventSeq,
+		CalendarSeq: snapshot.CalendarSeq,
+		Events:      make([]EventRecord, 0, len(snapshot.Events)),
+		Trash:       make([]EventRecord, 0, len(snapshot.Trash)),
+		Calendars:   make([]CalendarRecord, 0, len(snapshot.Calendars)),
+		Audit:       make([]AuditRecord, 0, len(snapshot.Audit)),
+	}
+	for _, event := range snapshot.Events {
+		record.Events = append(record.Events, convertEventToRecord(event))
+	}
+	for _, event := range snapshot.Trash {
+		record.Trash = append(record.Trash, convertEventToRecord(event))
+	}
+	for _, calendar := range snapshot.Calendars {
+		record.Calendars = append(record.Calendars, convertCalendarToRecord(calendar))
+	}
+	for _, entry := range snapshot.Audit {
+		record.Audit = append(record.Audit, convertAuditEntryToRecord(entry))
+	}
+	return record
+}
+
+func convertRecordToSnapshot(record SnapshotRecord) (memory.Snapshot, error) {
+	snapshot := memory.Snapshot{
+		EventSeq:    record.EventSeq,
+		CalendarSeq: record.CalendarSeq,
+	}
+	for _, eventRecord := range record.Events {
+		event, err := convertRecordToEvent(eventRecord)
+		if err != nil {
+			return memory.Snapshot{}, fmt.Errorf("event %d preparing error: %w", eventRecord.Id, err)
+		}
+		snapshot.Events = append(snapshot.Events, event)
+	}
+	for _, eventRecord := range record.Trash {
+		event, err := convertRecordToEvent(eventRecord)
+		if err != nil {
+			return memory.Snapshot{}, fmt.Errorf("trashed event %d preparing error: %w", eventRecord.Id, err)
+		}
+		snapshot.Trash = append(snapshot.Trash, event)
+	}
+	for _, calendarRecord := range record.Calendars {
+		snapshot.Calendars = append(snapshot.Calendars, convertRecordToCalendar(calendarRecord))
+	}
+	for _, auditRecord := range record.Audit {
+		snapshot.Audit = append(snapshot.Audit, convertRecordToAuditEntry(auditRecord))
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,300 @@
+package file
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/memory"
+	"go.uber.org/zap"
+)
+
+const (
+	snapshotFileName = "snapshot.json"
+	logFileName      = "changes.log"
+	lockFileName     = "lock"
+)
+
+// Error about files locked by another process, error message names that process
+// File storage is for one process only, services, scheduler and purger can't share it
+var ErrLocked = errors.New("storage files are used by another process")
+
+type Config struct {
+	Path          string // directory of storage files, it is created if missing
+	SnapshotEvery int    // snapshot is taken after this number of logged operations, 1000 by default, 0 means only on open and close
+	Sync          bool   // fsync log after every operation, true by default, without it the last operations could be lost on crash of OS
+}
+
+func NewConfig(m map[string]string) (*Config, error) {
+	path, ok := m["path"]
+	if !ok || path == "" {
+		return nil, fmt.Errorf("`path` key is missing")
+	}
+
+	snapshotEvery := 1000
+	if val, ok := m["snapshot_every"]; ok && val != "" {
+		var err error
+		snapshotEvery, err = strconv.Atoi(val)
+		if err != nil || snapshotEvery < 0 {
+			return nil, fmt.Errorf("snapshot_every key must be not negative integer: %s", val)
+		}
+	}
+
+	sync := true
+	if val, ok := m["sync"]; ok && val != "" {
+		var err error
+		sync, err = strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("sync key error %w", err)
+		}
+	}
+
+	return &Config{
+		Path:          path,
+		SnapshotEvery: snapshotEvery,
+		Sync:          sync,
+	}, nil
+}
+
+// Storage that keeps data in memory and persists it into directory:
+// every operation is appended to log before it is applied, log is periodically compacted into snapshot
+// On open storage is restored from snapshot and log, torn write at the end of log (crash during write) is dropped
+// Files are locked, so only one process could use them, process that holds lock is written into lock file
+type Storage struct {
+	*memory.Storage
+	dir      string
+	log      *changeLog
+	lockFile *os.File
+	logger   *zap.SugaredLogger // for logging errors of background snapshots, could be nil
+	done     chan struct{}
+	wg       sync.WaitGroup
+	closed   bool
+	mx       sync.Mutex // for Close
+}
+
+func NewStorage(cfg Config, logger *zap.SugaredLogger) (*Storage, error) {
+	err := os.MkdirAll(cfg.Path, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("can't create directory of storage %w", err)
+	}
+
+	lock, err := os.OpenFile(filepath.Join(cfg.Path, lockFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("can't open lock file %w", err)
+	}
+	if err := lockFile(lock); err != nil {
+		_ = lock.Close()
+		return nil, fmt.Errorf("%w (%s): %s", ErrLocked, readLockHolder(cfg.Path), err)
+	}
+	if !canLock && logger != nil {
+		logger.Warnf("file locking is not supported on this platform, files of storage in %s are not protected from other processes", cfg.Path)
+	}
+	if err := writeLockHolder(lock); err != nil {
+		_ = lock.Close()
+		return nil, fmt.Errorf("can't write lock file %w", err)
+	}
+
+	s, err := open(cfg, lock, logger)
+	if err != nil {
+		_ = lock.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Process that holds lock: pid and command line
+func lockHolder() string {
+	return fmt.Sprintf("pid %d: %s", os.Getpid(), strings.Join(os.Args, " "))
+}
+
+// Replace content of locked lock file by holder of lock
+func writeLockHolder(lock *os.File) error {
+	if err := lock.Truncate(0); err != nil {
+		return err
+	}
+	_, err := lock.WriteAt([]byte(lockHolder()), 0)
+	return err
+}
+
+// Holder of lock of directory as it is written into lock file
+func readLockHolder(dir string) string {
+	data, err := ioutil.ReadFile(filepath.Join(dir, lockFileName))
+	if err != nil || len(data) == 0 {
+		return "unknown process"
+	}
+	return string(data)
+}
+
+// Restore storage from files and start background snapshots
+func open(cfg Config, lock *os.File, logger *zap.SugaredLogger) (*Storage, error) {
+	snapshotRecord := SnapshotRecord{}
+	data, err := ioutil.ReadFile(filepath.Join(cfg.Path, snapshotFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("can't read snapshot %w", err)
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &snapshotRecord)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse snapshot %w", err)
+		}
+	}
+
+	snapshot, err := convertRecordToSnapshot(snapshotRecord)
+	if err != nil {
+		return nil, fmt.Errorf("can't restore snapshot %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(cfg.Path, logFileName), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("can't open log %w", err)
+	}
+
+	changes, seq, size, err := readLog(file, snapshotRecord.Seq)
+	if err == nil {
+		// drop torn write, so new batches are not appended after it
+		err = file.Truncate(size)
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("can't restore log %w", err)
+	}
+
+	log := &changeLog{
+		file:      file,
+		size:      size,
+		sync:      cfg.Sync,
+		seq:       seq,
+		threshold: cfg.SnapshotEvery,
+		snapshot:  make(chan struct{}, 1),
+	}
+
+	s := &Storage{
+		Storage:  memory.NewStorageFromSnapshot(snapshot, changes, log),
+		dir:      cfg.Path,
+		log:      log,
+		lockFile: lock,
+		logger:   logger,
+		done:     make(chan struct{}),
+	}
+
+	// replayed log is compacted, so it is not replayed again on next open
+	if len(changes) > 0 {
+		err = s.Compact()
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+
+	s.wg.Add(1)
+	go s.snapshotLoop()
+
+	return s, nil
+}
+
+// Write snapshot of all data and drop log
+// Snapshot is written to temporary file and renamed, so crash never leaves partially written snapshot
+func (s *Storage) Compact() error {
+	return s.Storage.Snapshot(func(snapshot memory.Snapshot) error {
+		data, err := json.Marshal(convertSnapshotToRecord(snapshot, s.log.seq))
+		if err != nil {
+			return err
+		}
+
+		tmpPath := filepath.Join(s.dir, snapshotFileName+".tmp")
+		err = writeFileSync(tmpPath, data)
+		if err != nil {
+			return fmt.Errorf("can't write snapshot %w", err)
+		}
+
+		err = os.Rename(tmpPath, filepath.Join(s.dir, snapshotFileName))
+		if err != nil {
+			return fmt.Errorf("can't write snapshot %w", err)
+		}
+		err = syncDir(s.dir)
+		if err != nil {
+			return fmt.Errorf("can't write snapshot %w", err)
+		}
+
+		// batches that are in snapshot are skipped on open, so crash before reset is not a problem
+		err = s.log.reset()
+		if err != nil {
+			return fmt.Errorf("can't reset log %w", err)
+		}
+		return nil
+	})
+}
+
+// Take the last snapshot and release files, storage must not be used after close
+func (s *Storage) Close() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	close(s.done)
+	s.wg.Wait()
+
+	err := s.Compact()
+
+	// journal is still referenced by memory storage, writes after close fail on closed file
+	closeErr := s.log.file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	_ = s.lockFile.Close()
+	return err
+}
+
+// Take snapshot when log is long enough
+func (s *Storage) snapshotLoop() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.log.snapshot:
+			err := s.Compact()
+			if err != nil && s.logger != nil {
+				s.logger.Errorf("can't take snapshot of file storage, log keeps growing: %s\n", err)
+			}
+		}
+	}
+}
+
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Make rename durable
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return file.Sync()
+}
@@ -0,0 +1,421 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/storagetest"
+)
+
+func NewTestStorage(t *testing.T, dir string) *Storage {
+	storage, err := NewStorage(Config{Path: dir, SnapshotEvery: 0, Sync: true}, nil)
+	if err != nil {
+		t.Fatalf("can't open storage %s", err)
+	}
+	return storage
+}
+
+func NewTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "calendar-file-storage")
+	if err != nil {
+		t.Fatalf("can't create temp dir %s", err)
+	}
+	return dir
+}
+
+// Release files as crashed process does: without the last snapshot
+func crash(storage *Storage) {
+	close(storage.done)
+	storage.wg.Wait()
+	_ = storage.log.file.Close()
+	_ = storage.lockFile.Close()
+}
+
+// Fill storage with events of all kinds, returns id of event in trash
+func fillTestStorage(t *testing.T, storage *Storage) int {
+	ctx := context.Background()
+
+	calendarId, err := storage.ForOwner("alice").AddCalendar(ctx, entities.NewCalendar("Work"))
+	if err != nil {
+		t.Fatalf("can't add calendar %s", err)
+	}
+
+	loc, _ := entities.LoadLocation("Europe/Moscow")
+	meeting := entities.NewEvent("Meeting", entities.NewDateTimeInLocation(2019, 11, 25, 10, 0, loc), entities.NewDateTimeInLocation(2019, 11, 25, 11, 0, loc))
+	meeting = entities.WithLocation(meeting, loc)
+	meeting = entities.WithReminders(meeting, []entities.Reminder{entities.NewReminder(60), entities.NewReminder(10)})
+	meeting = entities.WithAttendees(meeting, []string{"bob@example.com", "carol@example.com"})
+	meeting = entities.WithCalendarId(meeting, calendarId)
+	meeting = entities.WithDescription(meeting, "Weekly sync")
+	meeting = entities.WithPlace(meeting, "Room 1")
+	recurrence, _ := entities.ParseRecurrence("FREQ=WEEKLY;BYDAY=MO")
+	meeting = entities.WithRecurrence(meeting, recurrence.WithExDates([]entities.DateTime{entities.NewDateTimeInLocation(2019, 12, 2, 10, 0, loc)}))
+
+	meetingId, err := storage.ForOwner("alice").AddEvent(ctx, meeting)
+	if err != nil {
+		t.Fatalf("can't add event %s", err)
+	}
+
+	holiday := entities.NewAllDayEvent("Holiday", entities.NewDate(2019, 12, 31), entities.NewDate(2020, 1, 1))
+	holidayId, _ := storage.AddEvent(ctx, holiday)
+
+	trashedId, _ := storage.AddEvent(ctx, entities.NewEvent("Trashed", entities.NewDateTime(2019, 11, 26, 10, 0), entities.NewDateTime(2019, 11, 26, 11, 0)))
+
+	_ = storage.MarkReminderAsNotified(ctx, meetingId, 60, meeting.Start(), time.Now())
+	_ = storage.RespondToInvitation(ctx, meetingId, "bob@example.com", entities.AttendeeStatusAccepted)
+	_ = storage.MarkAttendeeAsInvited(ctx, meetingId, "carol@example.com", meeting.Start(), meeting.End())
+	_ = storage.UpdateEvent(ctx, holidayId, entities.WithColor(holiday, "red"))
+	_ = storage.DeleteEvent(ctx, trashedId)
+
+	return trashedId
+}
+
+// Records of all data of storage, they are compared to check that data is restored
+func storageRecords(t *testing.T, storage *Storage) ([]EventRecord, []EventRecord, []CalendarRecord, [][]AuditRecord) {
+	ctx := context.Background()
+
+	events, err := storage.GetAllEvents(ctx)
+	if err != nil {
+		t.Fatalf("can't get events %s", err)
+	}
+	trash, _ := storage.GetTrashedEvents(ctx)
+	calendars, _ := storage.GetCalendars(ctx)
+
+	var eventRecords, trashRecords []EventRecord
+	var calendarRecords []CalendarRecord
+	var history [][]AuditRecord
+	for _, event := range events {
+		eventRecords = append(eventRecords, convertEventToRecord(event))
+	}
+	for _, event := range trash {
+		trashRecords = append(trashRecords, convertEventToRecord(event))
+	}
+	for _, cal := range calendars {
+		calendarRecords = append(calendarRecords, convertCalendarToRecord(cal))
+	}
+	for _, event := range append(events, trash...) {
+		entries, _ := storage.GetEventHistory(ctx, event.Id())
+		var records []AuditRecord
+		for _, entry := range entries {
+			records = append(records, convertAuditEntryToRecord(entry))
+		}
+		history = append(history, records)
+	}
+
+	return eventRecords, trashRecords, calendarRecords, history
+}
+
+func TestRestore(t *testing.T) {
+	for _, closeStorage := range []bool{true, false} {
+		dir := NewTestDir(t)
+
+		storage := NewTestStorage(t, dir)
+		fillTestStorage(t, storage)
+		events, trash, calendars, history := storageRecords(t, storage)
+
+		if closeStorage {
+			err := storage.Close()
+			if err != nil {
+				t.Fatalf("can't close storage %s", err)
+			}
+		} else {
+			crash(storage)
+		}
+
+		restored := NewTestStorage(t, dir)
+		restoredEvents, restoredTrash, restoredCalendars, restoredHistory := storageRecords(t, restored)
+
+		if len(events) != 2 || !reflect.DeepEqual(restoredEvents, events) {
+			t.Errorf("events must be restored (close %t)\n%+v\ninstead of\n%+v", closeStorage, events, restoredEvents)
+		}
+		if len(trash) != 1 || !reflect.DeepEqual(restoredTrash, trash) {
+			t.Errorf("trash must be restored (close %t)\n%+v\ninstead of\n%+v", closeStorage, trash, restoredTrash)
+		}
+		if len(calendars) != 1 || !reflect.DeepEqual(restoredCalendars, calendars) {
+			t.Errorf("calendars must be restored (close %t)\n%+v\ninstead of\n%+v", closeStorage, calendars, restoredCalendars)
+		}
+		if !reflect.DeepEqual(restoredHistory, history) {
+			t.Errorf("history must be restored (close %t)\n%+v\ninstead of\n%+v", closeStorage, history, restoredHistory)
+		}
+
+		// ids are not reused
+		id, _ := restored.AddEvent(context.Background(), entities.NewEvent("New", entities.NewDateTime(2019, 11, 27, 10, 0), entities.NewDateTime(2019, 11, 27, 11, 0)))
+		if id != 4 {
+			t.Errorf("id of new event must be 4 instead of %d (close %t)", id, closeStorage)
+		}
+
+		_ = restored.Close()
+		_ = os.RemoveAll(dir)
+	}
+}
+
+// Purged event is removed from snapshot, but its id is not reused
+func TestRestorePurged(t *testing.T) {
+	dir := NewTestDir(t)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+
+	storage := NewTestStorage(t, dir)
+	trashedId := fillTestStorage(t, storage)
+
+	count, err := storage.PurgeEvents(ctx, time.Now().Add(time.Minute))
+	if err != nil || count != 1 {
+		t.Fatalf("must be purged 1 event instead of %d, error %v", count, err)
+	}
+	_ = storage.Close()
+
+	restored := NewTestStorage(t, dir)
+	defer restored.Close()
+
+	trash, _ := restored.GetTrashedEvents(ctx)
+	if len(trash) != 0 {
+		t.Errorf("trash must be empty instead of %+v", trash)
+	}
+
+	if _, err := restored.GetEventHistory(ctx, trashedId); err != nil {
+		t.Errorf("history of purged event must be kept, got %s", err)
+	}
+
+	id, _ := restored.AddEvent(ctx, entities.NewEvent("New", entities.NewDateTime(2019, 11, 27, 10, 0), entities.NewDateTime(2019, 11, 27, 11, 0)))
+	if id != trashedId+1 {
+		t.Errorf("id of new event must be %d instead of %d", trashedId+1, id)
+	}
+}
+
+// Committed transaction is one batch of log, rolled back transaction is not written at all
+func TestRestoreTx(t *testing.T) {
+	dir := NewTestDir(t)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	event := entities.NewEvent("Event", entities.NewDateTime(2019, 11, 25, 10, 0), entities.NewDateTime(2019, 11, 25, 11, 0))
+
+	storage := NewTestStorage(t, dir)
+	err := storage.WithTx(ctx, func(tx entities.Storage) error {
+		// events start at different hours, so their order is stable
+		for i := 0; i < 3; i++ {
+			start := entities.NewDateTime(2019, 11, 25, 10+i, 0)
+			if _, err := tx.AddEvent(ctx, entities.NewEvent("Event", start, start.PlusMinutes(60))); err != nil {
+				return err
+			}
+		}
+		return tx.DeleteEvent(ctx, 1)
+	})
+	if err != nil {
+		t.Fatalf("transaction must be committed, got %s", err)
+	}
+	txErr := errors.New("test error")
+	err = storage.WithTx(ctx, func(tx entities.Storage) error {
+		_, _ = tx.AddEvent(ctx, event)
+		return txErr
+	})
+	if err != txErr {
+		t.Fatalf("error of transaction must be returned instead of %v", err)
+	}
+	events, trash, calendars, history := storageRecords(t, storage)
+	crash(storage)
+
+	restored := NewTestStorage(t, dir)
+	defer restored.Close()
+	restoredEvents, restoredTrash, restoredCalendars, restoredHistory := storageRecords(t, restored)
+
+	if len(events) != 2 || len(trash) != 1 || !reflect.DeepEqual(restoredEvents, events) || !reflect.DeepEqual(restoredTrash, trash) {
+		t.Errorf("2 events and 1 trashed event must be restored\n%+v %+v\ninstead of\n%+v %+v", events, trash, restoredEvents, restoredTrash)
+	}
+	if !reflect.DeepEqual(restoredCalendars, calendars) || !reflect.DeepEqual(restoredHistory, history) {
+		t.Errorf("calendars and history must be restored\n%+v %+v\ninstead of\n%+v %+v", calendars, history, restoredCalendars, restoredHistory)
+	}
+
+	id, _ := restored.AddEvent(ctx, event)
+	if id != 4 {
+		t.Errorf("id of new event must be 4 instead of %d", id)
+	}
+}
+
+// Crash during write leaves torn line at the end of log, it is dropped
+func TestRestoreTornWrite(t *testing.T) {
+	dir := NewTestDir(t)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+
+	storage := NewTestStorage(t, dir)
+	_, _ = storage.AddEvent(ctx, entities.NewEvent("First", entities.NewDateTime(2019, 11, 25, 10, 0), entities.NewDateTime(2019, 11, 25, 11, 0)))
+	crash(storage)
+
+	logPath := filepath.Join(dir, logFileName)
+	file, _ := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0644)
+	_, _ = file.WriteString(`1234abcd {"seq":2,"changes":[{"op":"put_ev`)
+	_ = file.Close()
+
+	restored := NewTestStorage(t, dir)
+
+	count, _ := restored.Count(ctx)
+	if count != 1 {
+		t.Errorf("must be 1 event instead of %d", count)
+	}
+
+	id, err := restored.AddEvent(ctx, entities.NewEvent("Second", entities.NewDateTime(2019, 11, 25, 12, 0), entities.NewDateTime(2019, 11, 25, 13, 0)))
+	if err != nil || id != 2 {
+		t.Errorf("id of new event must be 2 instead of %d, error %v", id, err)
+	}
+	crash(restored)
+
+	// new batches are not appended after torn line
+	restored = NewTestStorage(t, dir)
+	defer restored.Close()
+
+	count, _ = restored.Count(ctx)
+	if count != 2 {
+		t.Errorf("must be 2 events instead of %d", count)
+	}
+}
+
+func TestRestoreCorruptedLog(t *testing.T) {
+	dir := NewTestDir(t)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+
+	storage := NewTestStorage(t, dir)
+	for i := 0; i < 3; i++ {
+		_, _ = storage.AddEvent(ctx, entities.NewEvent("Event", entities.NewDateTime(2019, 11, 25, 10, 0), entities.NewDateTime(2019, 11, 25, 11, 0)))
+	}
+	crash(storage)
+
+	logPath := filepath.Join(dir, logFileName)
+	data, _ := ioutil.ReadFile(logPath)
+	data[20] ^= 0xff
+	_ = ioutil.WriteFile(logPath, data, 0644)
+
+	_, err := NewStorage(Config{Path: dir}, nil)
+	if !errors.Is(err, ErrCorruptedLog) {
+		t.Errorf("must be ErrCorruptedLog instead of %v", err)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	dir := NewTestDir(t)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+
+	storage := NewTestStorage(t, dir)
+	for i := 0; i < 3; i++ {
+		_, _ = storage.AddEvent(ctx, entities.NewEvent("Event", entities.NewDateTime(2019, 11, 25, 10, 0), entities.NewDateTime(2019, 11, 25, 11, 0)))
+	}
+
+	err := storage.Compact()
+	if err != nil {
+		t.Fatalf("can't compact storage %s", err)
+	}
+
+	info, _ := os.Stat(filepath.Join(dir, logFileName))
+	if info.Size() != 0 {
+		t.Errorf("log must be empty after snapshot instead of %d bytes", info.Size())
+	}
+
+	_ = storage.DeleteEvent(ctx, 1)
+	crash(storage)
+
+	restored := NewTestStorage(t, dir)
+	defer restored.Close()
+
+	count, _ := restored.Count(ctx)
+	if count != 2 {
+		t.Errorf("must be 2 events (from snapshot and log) instead of %d", count)
+	}
+}
+
+// Snapshot is taken in background after SnapshotEvery operations
+func TestSnapshotEvery(t *testing.T) {
+	dir := NewTestDir(t)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+
+	storage, err := NewStorage(Config{Path: dir, SnapshotEvery: 2}, nil)
+	if err != nil {
+		t.Fatalf("can't open storage %s", err)
+	}
+	defer storage.Close()
+
+	for i := 0; i < 2; i++ {
+		_, _ = storage.AddEvent(ctx, entities.NewEvent("Event", entities.NewDateTime(2019, 11, 25, 10, 0), entities.NewDateTime(2019, 11, 25, 11, 0)))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(filepath.Join(dir, snapshotFileName)); err == nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("snapshot must be taken after 2 operations")
+}
+
+func TestLocked(t *testing.T) {
+	dir := NewTestDir(t)
+	defer os.RemoveAll(dir)
+
+	storage := NewTestStorage(t, dir)
+
+	_, err := NewStorage(Config{Path: dir}, nil)
+	if !errors.Is(err, ErrLocked) {
+		t.Errorf("must be ErrLocked instead of %v", err)
+	}
+	if holder := fmt.Sprintf("pid %d:", os.Getpid()); err == nil || !strings.Contains(err.Error(), holder) {
+		t.Errorf("error must name process that holds lock (%s) instead of %v", holder, err)
+	}
+
+	_ = storage.Close()
+
+	storage, err = NewStorage(Config{Path: dir}, nil)
+	if err != nil {
+		t.Fatalf("storage must be opened after close, got %s", err)
+	}
+	_ = storage.Close()
+}
+
+func TestNewConfig(t *testing.T) {
+	_, err := NewConfig(map[string]string{})
+	if err == nil {
+		t.Errorf("must be error for missing path")
+	}
+
+	cfg, err := NewConfig(map[string]string{"path": "/tmp/calendar"})
+	if err != nil || cfg.SnapshotEvery != 1000 || !cfg.Sync {
+		t.Errorf("snapshot_every must be 1000 and sync must be on by default instead of %+v, error %v", cfg, err)
+	}
+
+	cfg, err = NewConfig(map[string]string{"path": "/tmp/calendar", "snapshot_every": "10", "sync": "false"})
+	if err != nil || cfg.SnapshotEvery != 10 || cfg.Sync {
+		t.Errorf("snapshot_every must be 10 and sync must be off instead of %+v, error %v", cfg, err)
+	}
+
+	_, err = NewConfig(map[string]string{"path": "/tmp/calendar", "snapshot_every": "-1"})
+	if err == nil {
+		t.Errorf("must be error for negative snapshot_every")
+	}
+}
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) (entities.Storage, func()) {
+		dir := NewTestDir(t)
+		storage := NewTestStorage(t, dir)
+		return storage, func() {
+			_ = storage.Close()
+			_ = os.RemoveAll(dir)
+		}
+	})
+}
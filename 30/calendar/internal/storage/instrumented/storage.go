@@ -0,0 +1,249 @@
+package instrumented
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/monitoring"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	SlowThreshold time.Duration // calls that last longer are logged, 0 disables logging, 100ms by default
+}
+
+func NewConfig(m map[string]string) (*Config, error) {
+	slowThreshold := 100 * time.Millisecond
+	if val, ok := m["slow_threshold"]; ok && val != "" {
+		var err error
+		slowThreshold, err = time.ParseDuration(val)
+		if err != nil || slowThreshold < 0 {
+			return nil, fmt.Errorf("slow_threshold key must be not negative duration: %s", val)
+		}
+	}
+
+	return &Config{
+		SlowThreshold: slowThreshold,
+	}, nil
+}
+
+// Storage that observes latency and errors of every call of storage by method and logs slow calls
+// Calls through owner views and transactional views are observed too, ForOwner itself is not observed
+// Searcher, Transactor and Claimer features of storage are kept
+type Storage struct {
+	entities.Storage                            // storage (view) under instrumentation
+	metrics          *monitoring.StorageMetrics // could be nil
+	logger           *zap.SugaredLogger         // could be nil
+	slowThreshold    time.Duration
+	now              func() time.Time
+}
+
+// Constructor, metrics and logger could be nil
+func NewStorage(storage entities.Storage, cfg Config, metrics *monitoring.StorageMetrics, logger *zap.SugaredLogger) *Storage {
+	return &Storage{
+		Storage:       storage,
+		metrics:       metrics,
+		logger:        logger,
+		slowThreshold: cfg.SlowThreshold,
+		now:           time.Now,
+	}
+}
+
+// Instrumented storage of the same metrics and logger
+func (s *Storage) wrap(storage entities.Storage) *Storage {
+	return &Storage{
+		Storage:       storage,
+		metrics:       s.metrics,
+		logger:        s.logger,
+		slowThreshold: s.slowThreshold,
+		now:           s.now,
+	}
+}
+
+// Observe call of method started at start, it is deferred with pointer to error that is returned by method
+func (s *Storage) observe(method string, start time.Time, err *error) {
+	elapsed := s.now().Sub(start)
+	s.metrics.Observe(method, elapsed, *err)
+	if s.logger != nil && s.slowThreshold > 0 && elapsed >= s.slowThreshold {
+		s.logger.Warnf("slow call of storage %s took %s, error %v", method, elapsed, *err)
+	}
+}
+
+func (s *Storage) ForOwner(owner string) entities.Storage {
+	return s.wrap(s.Storage.ForOwner(owner))
+}
+
+func (s *Storage) AddEvent(ctx context.Context, event entities.Event) (id int, err error) {
+	defer s.observe("AddEvent", s.now(), &err)
+	return s.Storage.AddEvent(ctx, event)
+}
+
+func (s *Storage) UpdateEvent(ctx context.Context, id int, event entities.Event) (err error) {
+	defer s.observe("UpdateEvent", s.now(), &err)
+	return s.Storage.UpdateEvent(ctx, id, event)
+}
+
+func (s *Storage) DeleteEvent(ctx context.Context, id int) (err error) {
+	defer s.observe("DeleteEvent", s.now(), &err)
+	return s.Storage.DeleteEvent(ctx, id)
+}
+
+func (s *Storage) GetTrashedEvents(ctx context.Context) (events []entities.Event, err error) {
+	defer s.observe("GetTrashedEvents", s.now(), &err)
+	return s.Storage.GetTrashedEvents(ctx)
+}
+
+func (s *Storage) RestoreEvent(ctx context.Context, id int) (err error) {
+	defer s.observe("RestoreEvent", s.now(), &err)
+	return s.Storage.RestoreEvent(ctx, id)
+}
+
+func (s *Storage) PurgeEvents(ctx context.Context, before time.Time) (n int, err error) {
+	defer s.observe("PurgeEvents", s.now(), &err)
+	return s.Storage.PurgeEvents(ctx, before)
+}
+
+func (s *Storage) GetEventHistory(ctx context.Context, id int) (entries []entities.AuditEntry, err error) {
+	defer s.observe("GetEventHistory", s.now(), &err)
+	return s.Storage.GetEventHistory(ctx, id)
+}
+
+func (s *Storage) GetEvent(ctx context.Context, id int) (event entities.Event, err error) {
+	defer s.observe("GetEvent", s.now(), &err)
+	return s.Storage.GetEvent(ctx, id)
+}
+
+func (s *Storage) GetAllEvents(ctx context.Context) (events []entities.Event, err error) {
+	defer s.observe("GetAllEvents", s.now(), &err)
+	return s.Storage.GetAllEvents(ctx)
+}
+
+func (s *Storage) GetEventsByPeriod(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime, calendarIds ...int) (events []entities.Event, err error) {
+	defer s.observe("GetEventsByPeriod", s.now(), &err)
+	return s.Storage.GetEventsByPeriod(ctx, startTime, endTime, calendarIds...)
+}
+
+func (s *Storage) GetAllEventsPage(ctx context.Context, limit int, cursor string) (page entities.EventPage, err error) {
+	defer s.observe("GetAllEventsPage", s.now(), &err)
+	return s.Storage.GetAllEventsPage(ctx, limit, cursor)
+}
+
+func (s *Storage) GetEventsByPeriodPage(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime, limit int, cursor string, calendarIds ...int) (page entities.EventPage, err error) {
+	defer s.observe("GetEventsByPeriodPage", s.now(), &err)
+	return s.Storage.GetEventsByPeriodPage(ctx, startTime, endTime, limit, cursor, calendarIds...)
+}
+
+func (s *Storage) GetOverlappingEvents(ctx context.Context, start entities.DateTime, end entities.DateTime) (events []entities.Event, err error) {
+	defer s.observe("GetOverlappingEvents", s.now(), &err)
+	return s.Storage.GetOverlappingEvents(ctx, start, end)
+}
+
+func (s *Storage) GetEventsToNotify(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime) (notifications []entities.Notification, err error) {
+	defer s.observe("GetEventsToNotify", s.now(), &err)
+	return s.Storage.GetEventsToNotify(ctx, startTime, endTime)
+}
+
+func (s *Storage) MarkEventAsNotified(ctx context.Context, id int, when time.Time) (err error) {
+	defer s.observe("MarkEventAsNotified", s.now(), &err)
+	return s.Storage.MarkEventAsNotified(ctx, id, when)
+}
+
+func (s *Storage) MarkReminderAsNotified(ctx context.Context, id int, beforeMinutes int, start entities.DateTime, when time.Time) (err error) {
+	defer s.observe("MarkReminderAsNotified", s.now(), &err)
+	return s.Storage.MarkReminderAsNotified(ctx, id, beforeMinutes, start, when)
+}
+
+func (s *Storage) RespondToInvitation(ctx context.Context, id int, email string, status string) (err error) {
+	defer s.observe("RespondToInvitation", s.now(), &err)
+	return s.Storage.RespondToInvitation(ctx, id, email, status)
+}
+
+func (s *Storage) GetInvitationsToSend(ctx context.Context, now time.Time) (invitations []entities.Invitation, err error) {
+	defer s.observe("GetInvitationsToSend", s.now(), &err)
+	return s.Storage.GetInvitationsToSend(ctx, now)
+}
+
+func (s *Storage) MarkAttendeeAsInvited(ctx context.Context, id int, email string, start entities.DateTime, end entities.DateTime) (err error) {
+	defer s.observe("MarkAttendeeAsInvited", s.now(), &err)
+	return s.Storage.MarkAttendeeAsInvited(ctx, id, email, start, end)
+}
+
+func (s *Storage) Count(ctx context.Context) (n int, err error) {
+	defer s.observe("Count", s.now(), &err)
+	return s.Storage.Count(ctx)
+}
+
+func (s *Storage) ClearAll(ctx context.Context) (err error) {
+	defer s.observe("ClearAll", s.now(), &err)
+	return s.Storage.ClearAll(ctx)
+}
+
+func (s *Storage) AddCalendar(ctx context.Context, calendar entities.Calendar) (id int, err error) {
+	defer s.observe("AddCalendar", s.now(), &err)
+	return s.Storage.AddCalendar(ctx, calendar)
+}
+
+func (s *Storage) UpdateCalendar(ctx context.Context, id int, calendar entities.Calendar) (err error) {
+	defer s.observe("UpdateCalendar", s.now(), &err)
+	return s.Storage.UpdateCalendar(ctx, id, calendar)
+}
+
+func (s *Storage) DeleteCalendar(ctx context.Context, id int) (err error) {
+	defer s.observe("DeleteCalendar", s.now(), &err)
+	return s.Storage.DeleteCalendar(ctx, id)
+}
+
+func (s *Storage) GetCalendar(ctx context.Context, id int) (calendar entities.Calendar, err error) {
+	defer s.observe("GetCalendar", s.now(), &err)
+	return s.Storage.GetCalendar(ctx, id)
+}
+
+func (s *Storage) GetCalendars(ctx context.Context) (calendars []entities.Calendar, err error) {
+	defer s.observe("GetCalendars", s.now(), &err)
+	return s.Storage.GetCalendars(ctx)
+}
+
+// If storage is not entities.Searcher return entities.ErrSearchNotSupported, it is not observed
+func (s *Storage) SearchEvents(ctx context.Context, query string, startTime *entities.DateTime, endTime *entities.DateTime, limit int) (events []entities.Event, err error) {
+	searcher, ok := s.Storage.(entities.Searcher)
+	if !ok {
+		return nil, entities.ErrSearchNotSupported
+	}
+	defer s.observe("SearchEvents", s.now(), &err)
+	return searcher.SearchEvents(ctx, query, startTime, endTime, limit)
+}
+
+// If storage is not entities.Claimer return entities.ErrClaimNotSupported, it is not observed
+func (s *Storage) ClaimEventsToNotify(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime, now time.Time, lease time.Duration) (notifications []entities.Notification, err error) {
+	claimer, ok := s.Storage.(entities.Claimer)
+	if !ok {
+		return nil, entities.ErrClaimNotSupported
+	}
+	defer s.observe("ClaimEventsToNotify", s.now(), &err)
+	return claimer.ClaimEventsToNotify(ctx, startTime, endTime, now, lease)
+}
+
+// If storage is not entities.Claimer return entities.ErrClaimNotSupported, it is not observed
+func (s *Storage) ClaimInvitationsToSend(ctx context.Context, now time.Time, lease time.Duration) (invitations []entities.Invitation, err error) {
+	claimer, ok := s.Storage.(entities.Claimer)
+	if !ok {
+		return nil, entities.ErrClaimNotSupported
+	}
+	defer s.observe("ClaimInvitationsToSend", s.now(), &err)
+	return claimer.ClaimInvitationsToSend(ctx, now, lease)
+}
+
+// Whole transaction is observed as WithTx, calls through transactional view are observed by their methods
+// If storage is not entities.Transactor return entities.ErrTxNotSupported, it is not observed
+func (s *Storage) WithTx(ctx context.Context, fn func(tx entities.Storage) error) (err error) {
+	transactor, ok := s.Storage.(entities.Transactor)
+	if !ok {
+		return entities.ErrTxNotSupported
+	}
+	defer s.observe("WithTx", s.now(), &err)
+	return transactor.WithTx(ctx, func(tx entities.Storage) error {
+		return fn(s.wrap(tx))
+	})
+}
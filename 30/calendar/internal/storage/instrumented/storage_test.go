@@ -0,0 +1,164 @@
+package instrumented
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/monitoring"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/memory"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/storagetest"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Storage that hides optional features (search, transactions) of memory storage
+type storageWithoutFeatures struct {
+	entities.Storage
+}
+
+func newTestEvent(name string) entities.Event {
+	start := entities.NewDateTime(2019, 11, 25, 10, 0)
+	return entities.NewEvent(name, start, start.PlusMinutes(60))
+}
+
+// Clock that moves by step on every reading, so every call lasts step
+func newTestClock(step time.Duration) func() time.Time {
+	now := time.Date(2019, 11, 25, 10, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+// Values of metrics by name and label of method: count of histogram, value of counter
+func gatherValues(t *testing.T, registry *prometheus.Registry) map[string]float64 {
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("can't gather metrics %s", err)
+	}
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			key := family.GetName()
+			for _, label := range metric.GetLabel() {
+				key += " " + label.GetValue()
+			}
+			if histogram := metric.GetHistogram(); histogram != nil {
+				values[key] = float64(histogram.GetSampleCount())
+			} else {
+				values[key] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return values
+}
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+	registry := prometheus.NewRegistry()
+	storage := NewStorage(memory.NewStorage(), Config{}, monitoring.NewStorageMetrics(registry, "memory", nil), nil)
+
+	id, _ := storage.AddEvent(ctx, newTestEvent("Meeting"))
+	_, _ = storage.ForOwner("alice").GetEvent(ctx, id)
+	_, _ = storage.GetEvent(ctx, id)
+	_ = storage.WithTx(ctx, func(tx entities.Storage) error {
+		_, err := tx.GetEventsToNotify(ctx, nil, nil)
+		return err
+	})
+
+	values := gatherValues(t, registry)
+	expected := map[string]float64{
+		"storage_call_duration_seconds GetEvent memory":          2,
+		"storage_call_duration_seconds AddEvent memory":          1,
+		"storage_call_duration_seconds WithTx memory":            1,
+		"storage_call_duration_seconds GetEventsToNotify memory": 1,
+		"storage_errors_count GetEvent memory":                   1,
+	}
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("%s must be %v instead of %v", key, value, values[key])
+		}
+	}
+	if _, ok := values["storage_errors_count AddEvent memory"]; ok {
+		t.Errorf("successful calls must not be counted as errors, got %v", values)
+	}
+}
+
+func TestSlowCalls(t *testing.T) {
+	ctx := context.Background()
+	core, logs := observer.New(zapcore.WarnLevel)
+
+	storage := NewStorage(memory.NewStorage(), Config{SlowThreshold: 100 * time.Millisecond}, nil, zap.New(core).Sugar())
+	storage.now = newTestClock(50 * time.Millisecond)
+	_, _ = storage.AddEvent(ctx, newTestEvent("Meeting"))
+	if logs.Len() != 0 {
+		t.Errorf("fast call must not be logged instead of %v", logs.All())
+	}
+
+	storage.now = newTestClock(100 * time.Millisecond)
+	_, _ = storage.ForOwner("alice").GetEventsByPeriod(ctx, nil, nil)
+	if logs.Len() != 1 {
+		t.Fatalf("slow call must be logged instead of %v", logs.All())
+	}
+	if message := logs.All()[0].Message; message != "slow call of storage GetEventsByPeriod took 100ms, error <nil>" {
+		t.Errorf("message must be about slow GetEventsByPeriod instead of %s", message)
+	}
+
+	storage.slowThreshold = 0
+	_, _ = storage.Count(ctx)
+	if logs.Len() != 1 {
+		t.Errorf("calls must not be logged with zero threshold instead of %v", logs.All())
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	ctx := context.Background()
+	storage := NewStorage(memory.NewStorage(), Config{}, nil, nil)
+
+	if _, err := storage.SearchEvents(ctx, "meeting", nil, nil, 0); err != nil {
+		t.Errorf("search must be passed to storage, got %s", err)
+	}
+	err := storage.WithTx(ctx, func(tx entities.Storage) error {
+		if _, ok := tx.(*Storage); !ok {
+			t.Errorf("transactional view must be instrumented instead of %T", tx)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("transaction must be passed to storage, got %s", err)
+	}
+
+	withoutFeatures := NewStorage(storageWithoutFeatures{memory.NewStorage()}, Config{}, nil, nil)
+	if err := withoutFeatures.WithTx(ctx, func(tx entities.Storage) error { return nil }); err != entities.ErrTxNotSupported {
+		t.Errorf("must be ErrTxNotSupported for storage without transactions instead of %v", err)
+	}
+	if _, err := withoutFeatures.SearchEvents(ctx, "meeting", nil, nil, 0); err != entities.ErrSearchNotSupported {
+		t.Errorf("must be ErrSearchNotSupported for storage without search instead of %v", err)
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	cfg, err := NewConfig(map[string]string{})
+	if err != nil || cfg.SlowThreshold != 100*time.Millisecond {
+		t.Errorf("slow threshold must be 100ms by default instead of %v, error %v", cfg, err)
+	}
+
+	cfg, err = NewConfig(map[string]string{"slow_threshold": "0"})
+	if err != nil || cfg.SlowThreshold != 0 {
+		t.Errorf("slow threshold must be 0 instead of %v, error %v", cfg, err)
+	}
+
+	if _, err = NewConfig(map[string]string{"slow_threshold": "-1s"}); err == nil {
+		t.Errorf("must be error for negative slow threshold")
+	}
+}
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) (entities.Storage, func()) {
+		return NewStorage(memory.NewStorage(), Config{}, monitoring.NewStorageMetrics(prometheus.NewRegistry(), "memory", nil), nil), nil
+	})
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+)
+
+// Reminder of occurrence of event that is claimed by scheduler
+type claimKey struct {
+	eventId       int
+	beforeMinutes int
+	start         int64 // unix time of start of occurrence
+}
+
+func newClaimKey(notification entities.Notification) claimKey {
+	return claimKey{
+		eventId:       notification.Id(),
+		beforeMinutes: notification.Reminder().BeforeMinutes(),
+		start:         notification.Start().Time().Unix(),
+	}
+}
+
+// Claims of reminders are not changes of events, so they are not journaled and are not transactional
+// Claims are shared by all views (including transactional ones) and are lost on restart of persisted storage,
+// so claimed reminders of file storage could be claimed again after restart
+type claims map[claimKey]time.Time // lease of claim by reminder
+
+// Attendee of event that is claimed by scheduler to send invitation
+type inviteClaimKey struct {
+	eventId int
+	email   string
+}
+
+// Claims of attendees, they are not journaled and are not transactional as claims of reminders
+type inviteClaims map[inviteClaimKey]time.Time // lease of claim by attendee
+
+// Claim due reminders of occurrences under lock of storage, so concurrent claims never get the same reminder
+// Expired claims of reminders that are notified or of occurrences that are deleted are dropped
+func (calendar *Storage) ClaimEventsToNotify(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime, now time.Time, lease time.Duration) ([]entities.Notification, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	calendar.mx.Lock()
+	defer calendar.mx.Unlock()
+
+	leasedUntil := now.Add(lease)
+	claimed := make(map[claimKey]bool)
+	var notifications []entities.Notification
+
+	for _, id := range calendar.index.toNotify(startTime, endTime) {
+		event, _ := calendar.events.get(id)
+		if !calendar.isOwned(event) {
+			continue
+		}
+		for _, notification := range event.NotificationsInPeriod(startTime, endTime) {
+			key := newClaimKey(notification)
+			if lease, ok := calendar.claims[key]; ok && now.Before(lease) {
+				continue
+			}
+			calendar.claims[key] = leasedUntil
+			claimed[key] = true
+			notifications = append(notifications, notification)
+		}
+	}
+
+	// reminders of expired claims are retried whatever their time is
+	for key, lease := range calendar.claims {
+		if claimed[key] || now.Before(lease) {
+			continue
+		}
+		notification, ok := calendar.dueNotification(key)
+		if !ok {
+			delete(calendar.claims, key)
+			continue
+		}
+		if !calendar.isOwned(notification.Event) {
+			continue
+		}
+		calendar.claims[key] = leasedUntil
+		notifications = append(notifications, notification)
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		if !notifications[i].Time().Equal(notifications[j].Time()) {
+			return notifications[i].Time().Less(notifications[j].Time())
+		}
+		return notifications[i].Id() < notifications[j].Id()
+	})
+
+	return notifications, nil
+}
+
+// Notification of not notified reminder of occurrence of active event
+// Must be called under lock
+func (calendar *Storage) dueNotification(key claimKey) (entities.Notification, bool) {
+	event, ok := calendar.events.get(key.eventId)
+	if !ok {
+		return entities.Notification{}, false
+	}
+	notifyTime := entities.ConvertFromTime(time.Unix(key.start, 0)).MinusMinutes(key.beforeMinutes)
+	for _, notification := range event.NotificationsInPeriod(&notifyTime, &notifyTime) {
+		if newClaimKey(notification) == key {
+			return notification, true
+		}
+	}
+	return entities.Notification{}, false
+}
+
+// Claim invitations to send under lock of storage, so concurrent claims never get the same attendee
+// Expired claims of attendees that are invited or of events that are deleted are dropped
+func (calendar *Storage) ClaimInvitationsToSend(ctx context.Context, now time.Time, lease time.Duration) ([]entities.Invitation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	calendar.mx.Lock()
+	defer calendar.mx.Unlock()
+
+	leasedUntil := now.Add(lease)
+	claimed := make(map[inviteClaimKey]bool)
+	var invitations []entities.Invitation
+
+	calendar.events.each(func(event entities.Event) {
+		if !calendar.isOwned(event) || event.IsOver(now) {
+			return
+		}
+		for _, invitation := range event.Invitations() {
+			key := inviteClaimKey{eventId: invitation.Id(), email: invitation.Attendee().Email()}
+			if lease, ok := calendar.inviteClaims[key]; ok && now.Before(lease) {
+				continue
+			}
+			calendar.inviteClaims[key] = leasedUntil
+			claimed[key] = true
+			invitations = append(invitations, invitation)
+		}
+	})
+
+	for key, lease := range calendar.inviteClaims {
+		if !claimed[key] && !now.Before(lease) {
+			delete(calendar.inviteClaims, key)
+		}
+	}
+
+	sort.SliceStable(invitations, func(i, j int) bool {
+		return invitations[i].Id() < invitations[j].Id()
+	})
+
+	return invitations, nil
+}
@@ -0,0 +1,419 @@
+package memory
+
+import (
+	"hash/fnv"
+	"math"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+)
+
+// All day event is floating in time zone of query, so its candidates are searched in wider range
+const allDayMargin = int64(48 * time.Hour / time.Second)
+
+// Key of time index: unix time and id of event, so keys are unique
+type indexKey struct {
+	t  int64
+	id int
+}
+
+func (key indexKey) less(that indexKey) bool {
+	if key.t != that.t {
+		return key.t < that.t
+	}
+	return key.id < that.id
+}
+
+// Node of treap: binary search tree by key and heap by priority, so tree is balanced in average
+type treapNode struct {
+	key         indexKey
+	priority    uint32
+	left, right *treapNode
+}
+
+// Ordered index of ids of events by time, O(log n) insert and remove, O(log n + k) range query
+// Index is persistent: insert and remove copy only path to changed node and never modify nodes,
+// so copy of index is just copy of struct that shares nodes with original, changes of copy don't touch original
+type timeIndex struct {
+	root *treapNode
+	size int
+}
+
+func (index *timeIndex) insert(key indexKey) {
+	index.root = insertNode(index.root, &treapNode{key: key, priority: keyPriority(key)})
+	index.size++
+}
+
+func (index *timeIndex) remove(key indexKey) {
+	var removed bool
+	index.root, removed = removeNode(index.root, key)
+	if removed {
+		index.size--
+	}
+}
+
+// Check that key is in index
+func (index *timeIndex) has(key indexKey) bool {
+	node := index.root
+	for node != nil {
+		switch {
+		case key.less(node.key):
+			node = node.left
+		case node.key.less(key):
+			node = node.right
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Call fn for keys with time in [from, to] in ascending order
+func (index *timeIndex) ascendRange(from int64, to int64, fn func(key indexKey)) {
+	ascendNode(index.root, from, to, fn)
+}
+
+// Call fn for all keys in ascending order
+func (index *timeIndex) ascend(fn func(key indexKey)) {
+	ascendNode(index.root, math.MinInt64, math.MaxInt64, fn)
+}
+
+// Call fn for keys greater than after with time not greater than to in ascending order until fn returns false
+func (index *timeIndex) ascendAfter(after indexKey, to int64, fn func(key indexKey) bool) {
+	ascendAfterNode(index.root, after, to, fn)
+}
+
+// Pseudo random but deterministic priority
+func keyPriority(key indexKey) uint32 {
+	h := fnv.New32a()
+	var buf [16]byte
+	for i := 0; i < 8; i++ {
+		buf[i] = byte(key.t >> (8 * i))
+		buf[8+i] = byte(int64(key.id) >> (8 * i))
+	}
+	_, _ = h.Write(buf[:])
+	return h.Sum32()
+}
+
+// Nodes of path to inserted node are copied, rotated nodes are always copies
+func insertNode(node *treapNode, n *treapNode) *treapNode {
+	if node == nil {
+		return n
+	}
+	copied := *node
+	if n.key.less(node.key) {
+		copied.left = insertNode(node.left, n)
+		if copied.left.priority > copied.priority {
+			return rotateRight(&copied)
+		}
+	} else {
+		copied.right = insertNode(node.right, n)
+		if copied.right.priority > copied.priority {
+			return rotateLeft(&copied)
+		}
+	}
+	return &copied
+}
+
+// Nodes of path to removed node are copied, tree without key is returned as is
+func removeNode(node *treapNode, key indexKey) (*treapNode, bool) {
+	if node == nil {
+		return nil, false
+	}
+	copied := *node
+	var removed bool
+	switch {
+	case key.less(node.key):
+		copied.left, removed = removeNode(node.left, key)
+	case node.key.less(key):
+		copied.right, removed = removeNode(node.right, key)
+	default:
+		return mergeNodes(node.left, node.right), true
+	}
+	if !removed {
+		return node, false
+	}
+	return &copied, true
+}
+
+// Merge trees, all keys of left are less than keys of right, nodes of merged spines are copied
+func mergeNodes(left *treapNode, right *treapNode) *treapNode {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	if left.priority > right.priority {
+		copied := *left
+		copied.right = mergeNodes(left.right, right)
+		return &copied
+	}
+	copied := *right
+	copied.left = mergeNodes(left, right.left)
+	return &copied
+}
+
+// Rotations modify node and its child, so both must be copies
+func rotateRight(node *treapNode) *treapNode {
+	left := node.left
+	node.left = left.right
+	left.right = node
+	return left
+}
+
+func rotateLeft(node *treapNode) *treapNode {
+	right := node.right
+	node.right = right.left
+	right.left = node
+	return right
+}
+
+func ascendNode(node *treapNode, from int64, to int64, fn func(key indexKey)) {
+	if node == nil {
+		return
+	}
+	if from <= node.key.t {
+		ascendNode(node.left, from, to, fn)
+	}
+	if from <= node.key.t && node.key.t <= to {
+		fn(node.key)
+	}
+	if node.key.t <= to {
+		ascendNode(node.right, from, to, fn)
+	}
+}
+
+// Return false if iteration is stopped
+func ascendAfterNode(node *treapNode, after indexKey, to int64, fn func(key indexKey) bool) bool {
+	if node == nil {
+		return true
+	}
+	if after.less(node.key) {
+		if !ascendAfterNode(node.left, after, to, fn) {
+			return false
+		}
+		if node.key.t > to || !fn(node.key) {
+			return false
+		}
+	}
+	return ascendAfterNode(node.right, after, to, fn)
+}
+
+// Indexes of active events (not in trash) for period, overlapping and notify queries
+// Index gives candidates, exact conditions are checked by methods of event
+type eventIndex struct {
+	timed     timeIndex  // not recurring and not all day events by start
+	allDay    timeIndex  // not recurring all day events by start
+	recurring timeIndex  // recurring events by start, they are checked by every query
+	notify    timeIndex  // not notified reminders of not recurring events by time of notification
+	words     *wordIndex // inverted index: ids of events by words of name
+	maxTimed  int64      // the longest duration (seconds) of timed events ever indexed, it is never decreased
+	maxAllDay int64      // the longest duration (seconds) of all day events ever indexed, it is never decreased
+}
+
+func newEventIndex() *eventIndex {
+	return &eventIndex{words: &wordIndex{words: make(map[string]timeIndex)}}
+}
+
+// Index for transactional view of storage, changes of it don't touch original
+// Time indexes are persistent, so it costs O(1) and further changes cost as changes of original
+// Original must not be changed while overlay is used
+func (index *eventIndex) overlay() *eventIndex {
+	overlay := *index
+	overlay.words = &wordIndex{base: index.words, words: make(map[string]timeIndex)}
+	return &overlay
+}
+
+func (index *eventIndex) add(event entities.Event) {
+	key := indexKey{t: event.Start().Time().Unix(), id: event.Id()}
+	duration := event.End().Time().Unix() - key.t
+
+	switch {
+	case event.IsRecurring():
+		index.recurring.insert(key)
+	case event.IsAllDay():
+		index.allDay.insert(key)
+		if duration > index.maxAllDay {
+			index.maxAllDay = duration
+		}
+	default:
+		index.timed.insert(key)
+		if duration > index.maxTimed {
+			index.maxTimed = duration
+		}
+	}
+
+	// reminders of recurring event are due by occurrences, so they are checked by every notify query
+	if !event.IsRecurring() {
+		for _, notification := range event.NotificationsInPeriod(nil, nil) {
+			index.notify.insert(indexKey{t: notification.Time().Time().Unix(), id: event.Id()})
+		}
+	}
+
+	for _, word := range entities.SearchWords(event.Name()) {
+		ids := index.words.get(word)
+		if key := (indexKey{id: event.Id()}); !ids.has(key) {
+			ids.insert(key)
+			index.words.set(word, ids)
+		}
+	}
+}
+
+func (index *eventIndex) remove(event entities.Event) {
+	key := indexKey{t: event.Start().Time().Unix(), id: event.Id()}
+
+	switch {
+	case event.IsRecurring():
+		index.recurring.remove(key)
+	case event.IsAllDay():
+		index.allDay.remove(key)
+	default:
+		index.timed.remove(key)
+	}
+
+	if !event.IsRecurring() {
+		for _, notification := range event.NotificationsInPeriod(nil, nil) {
+			index.notify.remove(indexKey{t: notification.Time().Time().Unix(), id: event.Id()})
+		}
+	}
+
+	for _, word := range entities.SearchWords(event.Name()) {
+		ids := index.words.get(word)
+		ids.remove(indexKey{id: event.Id()})
+		index.words.set(word, ids)
+	}
+}
+
+// Ids of events that could have occurrences started in period, nil means no boundary
+func (index *eventIndex) inPeriod(startTime *entities.DateTime, endTime *entities.DateTime) []int {
+	from, to := bounds(startTime, endTime)
+
+	var ids []int
+	collect := func(key indexKey) {
+		ids = append(ids, key.id)
+	}
+	index.timed.ascendRange(from, to, collect)
+	index.allDay.ascendRange(sub(from, index.maxAllDay+allDayMargin), add(to, allDayMargin), collect)
+	index.recurring.ascend(collect)
+	return ids
+}
+
+// Ids of events that could have occurrences overlapping [start, end)
+func (index *eventIndex) overlapping(start entities.DateTime, end entities.DateTime) []int {
+	from, to := start.Time().Unix(), end.Time().Unix()
+
+	var ids []int
+	collect := func(key indexKey) {
+		ids = append(ids, key.id)
+	}
+	index.timed.ascendRange(sub(from, index.maxTimed), to, collect)
+	index.allDay.ascendRange(sub(from, index.maxAllDay+allDayMargin), add(to, allDayMargin), collect)
+	index.recurring.ascend(collect)
+	return ids
+}
+
+// Ids of events with not notified reminders which time is in period and of recurring events, every id is returned once
+func (index *eventIndex) toNotify(startTime *entities.DateTime, endTime *entities.DateTime) []int {
+	from, to := bounds(startTime, endTime)
+
+	var ids []int
+	seen := make(map[int]bool)
+	index.notify.ascendRange(from, to, func(key indexKey) {
+		if !seen[key.id] {
+			seen[key.id] = true
+			ids = append(ids, key.id)
+		}
+	})
+	index.recurring.ascend(func(key indexKey) {
+		ids = append(ids, key.id)
+	})
+	return ids
+}
+
+// Key before keys of events that start not earlier than from and are after cursor (ids of events are positive)
+func afterKey(from int64, cursor entities.Cursor) indexKey {
+	key := indexKey{t: from}
+	if !cursor.IsZero() {
+		cursorKey := indexKey{t: cursor.Start(), id: cursor.Id()}
+		if key.less(cursorKey) {
+			key = cursorKey
+		}
+	}
+	return key
+}
+
+// Ids of events which names contain all words, words must not be empty
+func (index *eventIndex) withWords(words []string) []int {
+	// the rarest word gives the least candidates
+	rarest := index.words.get(words[0])
+	for _, word := range words[1:] {
+		if ids := index.words.get(word); ids.size < rarest.size {
+			rarest = ids
+		}
+	}
+
+	var ids []int
+	rarest.ascend(func(key indexKey) {
+		for _, word := range words {
+			if wordIds := index.words.get(word); !wordIds.has(key) {
+				return
+			}
+		}
+		ids = append(ids, key.id)
+	})
+	return ids
+}
+
+// Inverted index of words, ids of events with word are keys (with zero time) of time index
+// Index of transactional view is overlay of changed words over index of storage, see eventIndex.overlay
+type wordIndex struct {
+	base  *wordIndex           // index under overlay, nil for index of storage
+	words map[string]timeIndex // ids by word, empty ids of overlay hide ids of base
+}
+
+func (index *wordIndex) get(word string) timeIndex {
+	if ids, ok := index.words[word]; ok {
+		return ids
+	}
+	if index.base != nil {
+		return index.base.get(word)
+	}
+	return timeIndex{}
+}
+
+func (index *wordIndex) set(word string, ids timeIndex) {
+	if ids.size == 0 && index.base == nil {
+		delete(index.words, word)
+		return
+	}
+	index.words[word] = ids
+}
+
+// Unix times of boundaries of period, nil is unbounded
+func bounds(startTime *entities.DateTime, endTime *entities.DateTime) (int64, int64) {
+	from, to := int64(math.MinInt64), int64(math.MaxInt64)
+	if startTime != nil {
+		from = startTime.Time().Unix()
+	}
+	if endTime != nil {
+		to = endTime.Time().Unix()
+	}
+	return from, to
+}
+
+// Subtraction without overflow of unbounded time
+func sub(t int64, d int64) int64 {
+	if t < math.MinInt64+d {
+		return math.MinInt64
+	}
+	return t - d
+}
+
+// Addition without overflow of unbounded time
+func add(t int64, d int64) int64 {
+	if t > math.MaxInt64-d {
+		return math.MaxInt64
+	}
+	return t + d
+}
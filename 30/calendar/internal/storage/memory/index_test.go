@@ -0,0 +1,276 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+)
+
+func TestTimeIndex(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	index := timeIndex{}
+	keys := make(map[indexKey]bool)
+
+	for i := 0; i < 2000; i++ {
+		key := indexKey{t: int64(rnd.Intn(500)), id: rnd.Intn(100)}
+		if rnd.Intn(3) == 0 {
+			index.remove(key)
+			delete(keys, key)
+		} else if !keys[key] {
+			index.insert(key)
+			keys[key] = true
+		}
+	}
+
+	if index.size != len(keys) {
+		t.Fatalf("size of index must be %d instead of %d", len(keys), index.size)
+	}
+
+	for _, period := range [][2]int64{{0, 500}, {100, 200}, {250, 250}, {300, 100}} {
+		var expected []indexKey
+		for key := range keys {
+			if period[0] <= key.t && key.t <= period[1] {
+				expected = append(expected, key)
+			}
+		}
+		sort.Slice(expected, func(i, j int) bool {
+			return expected[i].less(expected[j])
+		})
+
+		var got []indexKey
+		index.ascendRange(period[0], period[1], func(key indexKey) {
+			got = append(got, key)
+		})
+
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("keys in %v must be %v instead of %v", period, expected, got)
+		}
+	}
+
+	// index is persistent, so changes of copy don't touch original
+	var original []indexKey
+	index.ascend(func(key indexKey) {
+		original = append(original, key)
+	})
+	indexCopy := index
+	for i := 0; i < 500; i++ {
+		key := indexKey{t: int64(rnd.Intn(500)), id: rnd.Intn(100)}
+		if indexCopy.has(key) {
+			indexCopy.remove(key)
+		} else {
+			indexCopy.insert(key)
+		}
+	}
+	var got []indexKey
+	index.ascend(func(key indexKey) {
+		got = append(got, key)
+	})
+	if !reflect.DeepEqual(got, original) || index.size != len(original) {
+		t.Errorf("changes of copy must not touch original index")
+	}
+}
+
+// Random events of all kinds for index, every fifth is all day, every tenth is recurring
+func newRandomEvent(rnd *rand.Rand, i int) entities.Event {
+	loc, _ := entities.LoadLocation("Europe/Moscow")
+	start := entities.NewDateTime(2019, 11, 1, 0, 0).PlusMinutes(rnd.Intn(60 * 24 * 60))
+	event := entities.NewEvent(fmt.Sprintf("Event %d", i), start, start.PlusMinutes(15+rnd.Intn(240)))
+	switch {
+	case i%10 == 0:
+		recurrence, _ := entities.ParseRecurrence("FREQ=WEEKLY;COUNT=5")
+		event = entities.WithRecurrence(event, recurrence)
+	case i%5 == 0:
+		date := entities.ConvertDateFromTime(start.Time())
+		event = entities.WithAllDay(entities.WithLocation(event, loc), date, date.AddDays(rnd.Intn(3)))
+	}
+	return entities.WithReminders(event, []entities.Reminder{entities.NewReminder(rnd.Intn(120)), entities.NewReminder(1440)})
+}
+
+// Indexed queries give the same results as full scan of events
+func TestIndexedQueries(t *testing.T) {
+	ctx := context.Background()
+	rnd := rand.New(rand.NewSource(1))
+	calendar := NewStorage()
+
+	for i := 0; i < 500; i++ {
+		id, _ := calendar.AddEvent(ctx, newRandomEvent(rnd, i))
+		switch rnd.Intn(5) {
+		case 0:
+			_ = calendar.DeleteEvent(ctx, id)
+		case 1:
+			_ = calendar.UpdateEvent(ctx, id, newRandomEvent(rnd, i))
+		case 2:
+			_ = calendar.MarkEventAsNotified(ctx, id, time.Now())
+		}
+	}
+	_ = calendar.RestoreEvent(ctx, 1)
+
+	allEvents, _ := calendar.GetAllEvents(ctx)
+
+	for i := 0; i < 20; i++ {
+		start := entities.NewDateTime(2019, 11, 1, 0, 0).PlusMinutes(rnd.Intn(60 * 24 * 60))
+		end := start.PlusMinutes(rnd.Intn(7 * 24 * 60))
+
+		var expected []entities.Event
+		var expectedOverlapping []entities.Event
+		var expectedNotifications []entities.Notification
+		for _, event := range allEvents {
+			expected = append(expected, event.OccurrencesInPeriod(&start, &end)...)
+			expectedOverlapping = append(expectedOverlapping, event.OccurrencesOverlapping(start, end)...)
+			expectedNotifications = append(expectedNotifications, event.NotificationsInPeriod(&start, &end)...)
+		}
+
+		events, _ := calendar.GetEventsByPeriod(ctx, &start, &end)
+		if !sameEvents(events, expected) {
+			t.Errorf("events in period %s - %s must be %d events instead of %d", start, end, len(expected), len(events))
+		}
+
+		overlapping, _ := calendar.GetOverlappingEvents(ctx, start, end)
+		if !sameEvents(overlapping, expectedOverlapping) {
+			t.Errorf("events overlapping %s - %s must be %d events instead of %d", start, end, len(expectedOverlapping), len(overlapping))
+		}
+
+		notifications, _ := calendar.GetEventsToNotify(ctx, &start, &end)
+		if len(notifications) != len(expectedNotifications) {
+			t.Errorf("notifications in period %s - %s must be %d instead of %d", start, end, len(expectedNotifications), len(notifications))
+		}
+		for j := 1; j < len(notifications); j++ {
+			if notifications[j].Time().Less(notifications[j-1].Time()) {
+				t.Errorf("notifications must be sorted by time")
+				break
+			}
+		}
+	}
+}
+
+// Events are the same regardless of order
+func sameEvents(events []entities.Event, expected []entities.Event) bool {
+	key := func(event entities.Event) string {
+		return fmt.Sprintf("%d %s", event.Id(), event.Start())
+	}
+	got := make(map[string]int)
+	for _, event := range events {
+		got[key(event)]++
+	}
+	for _, event := range expected {
+		got[key(event)]--
+	}
+	for _, count := range got {
+		if count != 0 {
+			return false
+		}
+	}
+	return len(events) == len(expected)
+}
+
+// Concurrent writes and indexed reads, run with -race
+func TestConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	calendar := NewStorage()
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < 200; i++ {
+				id, _ := calendar.AddEvent(ctx, newRandomEvent(rnd, i))
+				if i%3 == 0 {
+					_ = calendar.DeleteEvent(ctx, id)
+				}
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			start := entities.NewDateTime(2019, 11, 1, 0, 0)
+			end := entities.NewDateTime(2019, 12, 1, 0, 0)
+			for i := 0; i < 200; i++ {
+				_, _ = calendar.GetEventsByPeriod(ctx, &start, &end)
+				_, _ = calendar.GetEventsToNotify(ctx, &start, &end)
+				_, _ = calendar.GetAllEvents(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+
+	count, _ := calendar.Count(ctx)
+	if count != 4*200-4*67 {
+		t.Errorf("must be %d events instead of %d", 4*200-4*67, count)
+	}
+}
+
+// Storage with n events, about 100 events per day since 2019-01-01, so result of query of one day doesn't depend on n
+func newBenchmarkStorage(b *testing.B, n int) *Storage {
+	ctx := context.Background()
+	rnd := rand.New(rand.NewSource(1))
+	calendar := NewStorage()
+	for i := 0; i < n; i++ {
+		start := entities.NewDateTime(2019, 1, 1, 0, 0).PlusMinutes(rnd.Intn(n / 100 * 24 * 60))
+		event := entities.NewEvent("Event", start, start.PlusMinutes(60))
+		event = entities.WithReminders(event, []entities.Reminder{entities.NewReminder(15)})
+		if _, err := calendar.AddEvent(ctx, event); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return calendar
+}
+
+// Duration of query of one day must grow sub-linearly with number of events
+func BenchmarkGetEventsByPeriod(b *testing.B) {
+	ctx := context.Background()
+	start := entities.NewDateTime(2019, 1, 5, 0, 0)
+	end := entities.NewDateTime(2019, 1, 6, 0, 0)
+
+	for _, n := range []int{1000, 10000, 100000} {
+		calendar := newBenchmarkStorage(b, n)
+		b.Run(fmt.Sprintf("events=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = calendar.GetEventsByPeriod(ctx, &start, &end)
+			}
+		})
+	}
+}
+
+func BenchmarkGetEventsToNotify(b *testing.B) {
+	ctx := context.Background()
+	start := entities.NewDateTime(2019, 1, 5, 12, 0)
+	end := entities.NewDateTime(2019, 1, 5, 12, 30)
+
+	for _, n := range []int{1000, 10000, 100000} {
+		calendar := newBenchmarkStorage(b, n)
+		b.Run(fmt.Sprintf("events=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = calendar.GetEventsToNotify(ctx, &start, &end)
+			}
+		})
+	}
+}
+
+// Concurrent period queries and adds of events (outside of queried period)
+func BenchmarkConcurrentAccess(b *testing.B) {
+	ctx := context.Background()
+	calendar := newBenchmarkStorage(b, 100000)
+	start := entities.NewDateTime(2019, 1, 5, 0, 0)
+	end := entities.NewDateTime(2019, 1, 6, 0, 0)
+	added := entities.NewDateTime(2020, 1, 5, 10, 0)
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i%10 == 0 {
+				_, _ = calendar.AddEvent(ctx, entities.NewEvent("Event", added, added.PlusMinutes(60)))
+			} else {
+				_, _ = calendar.GetEventsByPeriod(ctx, &start, &end)
+			}
+			i++
+		}
+	})
+}
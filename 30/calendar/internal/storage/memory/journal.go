@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+)
+
+// Operations of changes of storage data
+const (
+	ChangePutEvent       = "put_event"       // event is added or replaced, it is removed from trash
+	ChangeTrashEvent     = "trash_event"     // event is moved to trash (or replaced in trash)
+	ChangeRemoveEvent    = "remove_event"    // event is deleted permanently from events and trash
+	ChangePutCalendar    = "put_calendar"    // calendar is added or replaced
+	ChangeRemoveCalendar = "remove_calendar" // calendar is deleted, its events are removed by separate changes
+	ChangeAudit          = "audit"           // audit entry is appended
+)
+
+// Change of storage data, data is modified only by changes, so changes could be journaled and replayed
+type Change struct {
+	Op       string              // one of Change* constants
+	Id       int                 // id of removed event or calendar
+	Event    entities.Event      // put or trashed event
+	Calendar entities.Calendar   // put calendar
+	Entry    entities.AuditEntry // appended audit entry
+}
+
+// Journal of changes, e.g. append-only log of file storage
+// Changes of one operation are written by one call and applied only if they are written successfully
+// Write is called under lock of storage, so changes are written in order they are applied
+type Journal interface {
+	Write(changes []Change) error
+}
+
+// Full state of storage data, with journaled changes after it, it is enough to restore storage
+type Snapshot struct {
+	Events      []entities.Event // sorted by id
+	Trash       []entities.Event // sorted by id
+	Calendars   []entities.Calendar
+	Audit       []entities.AuditEntry
+	EventSeq    int // last generated id of event
+	CalendarSeq int // last generated id of calendar
+}
+
+// Restore storage from snapshot and changes made after snapshot, further changes are written to journal (if it is not nil)
+func NewStorageFromSnapshot(snapshot Snapshot, changes []Change, journal Journal) *Storage {
+	calendar := NewStorage()
+	calendar.journal = journal
+	calendar.autoincrement = snapshot.EventSeq
+	calendar.calendarSeq = snapshot.CalendarSeq
+	calendar.audit.entries = append(calendar.audit.entries, snapshot.Audit...)
+
+	for _, event := range snapshot.Events {
+		calendar.events.put(event)
+		calendar.index.add(event)
+	}
+	for _, event := range snapshot.Trash {
+		calendar.trash.put(event)
+	}
+	for _, cal := range snapshot.Calendars {
+		calendar.calendars.put(cal)
+	}
+
+	for _, change := range changes {
+		calendar.applyChange(change)
+	}
+
+	return calendar
+}
+
+// Take snapshot of all data of storage (regardless of owner of storage view)
+// save is called under read lock, so no changes are applied (and journaled) until it returns
+func (calendar *Storage) Snapshot(save func(snapshot Snapshot) error) error {
+	calendar.mx.RLock()
+	defer calendar.mx.RUnlock()
+
+	snapshot := Snapshot{
+		Events:      calendar.events.sorted(),
+		Trash:       calendar.trash.sorted(),
+		Audit:       calendar.audit.all(),
+		EventSeq:    calendar.autoincrement,
+		CalendarSeq: calendar.calendarSeq,
+	}
+	calendar.calendars.each(func(cal entities.Calendar) {
+		snapshot.Calendars = append(snapshot.Calendars, cal)
+	})
+	sort.Slice(snapshot.Calendars, func(i, j int) bool {
+		return snapshot.Calendars[i].Id() < snapshot.Calendars[j].Id()
+	})
+
+	return save(snapshot)
+}
+
+// Write changes to journal and apply them
+// Must be called under lock
+func (data *storageData) apply(changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if data.journal != nil {
+		err := data.journal.Write(changes)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, change := range changes {
+		data.applyChange(change)
+	}
+	return nil
+}
+
+// Apply one change, ids of put events and calendars move sequences forward, so replayed storage doesn't reuse ids
+func (data *storageData) applyChange(change Change) {
+	switch change.Op {
+	case ChangePutEvent:
+		id := change.Event.Id()
+		data.trash.remove(id)
+		if old, ok := data.events.get(id); ok {
+			data.index.remove(old)
+		}
+		data.events.put(change.Event)
+		data.index.add(change.Event)
+		if id > data.autoincrement {
+			data.autoincrement = id
+		}
+	case ChangeTrashEvent:
+		id := change.Event.Id()
+		if old, ok := data.events.get(id); ok {
+			data.index.remove(old)
+			data.events.remove(id)
+		}
+		data.trash.put(change.Event)
+		if id > data.autoincrement {
+			data.autoincrement = id
+		}
+	case ChangeRemoveEvent:
+		if old, ok := data.events.get(change.Id); ok {
+			data.index.remove(old)
+			data.events.remove(change.Id)
+		}
+		data.trash.remove(change.Id)
+	case ChangePutCalendar:
+		id := change.Calendar.Id()
+		data.calendars.put(change.Calendar)
+		if id > data.calendarSeq {
+			data.calendarSeq = id
+		}
+	case ChangeRemoveCalendar:
+		data.calendars.remove(change.Id)
+	case ChangeAudit:
+		data.audit.append(change.Entry)
+	}
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+)
+
+// Journal that records changes and fails when err is set
+type testJournal struct {
+	changes []Change
+	writes  int // number of successful writes
+	err     error
+}
+
+func (j *testJournal) Write(changes []Change) error {
+	if j.err != nil {
+		return j.err
+	}
+	j.changes = append(j.changes, changes...)
+	j.writes++
+	return nil
+}
+
+func TestJournal(t *testing.T) {
+	ctx := context.Background()
+	journal := &testJournal{}
+	calendar := NewStorageFromSnapshot(Snapshot{}, nil, journal)
+
+	event := entities.NewEvent("Meeting", entities.NewDateTime(2019, 11, 25, 10, 0), entities.NewDateTime(2019, 11, 25, 11, 0))
+	id, _ := calendar.AddEvent(ctx, event)
+	_ = calendar.DeleteEvent(ctx, id)
+
+	if len(journal.changes) != 4 {
+		t.Fatalf("must be 4 journaled changes instead of %+v", journal.changes)
+	}
+
+	// storage restored from journal has the same data
+	restored := NewStorageFromSnapshot(Snapshot{}, journal.changes, nil)
+	trash, _ := restored.GetTrashedEvents(ctx)
+	if len(trash) != 1 || trash[0].Id() != id {
+		t.Errorf("event must be in trash of restored storage instead of %+v", trash)
+	}
+	history, _ := restored.GetEventHistory(ctx, id)
+	if len(history) != 2 {
+		t.Errorf("must be 2 audit entries instead of %+v", history)
+	}
+
+	// changes that are not journaled are not applied
+	journal.err = errors.New("disk is full")
+	err := calendar.RestoreEvent(ctx, id)
+	if err != journal.err {
+		t.Errorf("must be error of journal instead of %v", err)
+	}
+	trash, _ = calendar.GetTrashedEvents(ctx)
+	if len(trash) != 1 {
+		t.Errorf("event must be still in trash instead of %+v", trash)
+	}
+
+	_, err = calendar.AddEvent(ctx, event)
+	if err != journal.err {
+		t.Errorf("must be error of journal instead of %v", err)
+	}
+
+	// id of failed event is not used
+	journal.err = nil
+	newId, _ := calendar.AddEvent(ctx, event)
+	if newId != id+1 {
+		t.Errorf("id of new event must be %d instead of %d", id+1, newId)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	calendar := NewStorage()
+
+	calendarId, _ := calendar.AddCalendar(ctx, entities.NewCalendar("Work"))
+	event := entities.NewEvent("Meeting", entities.NewDateTime(2019, 11, 25, 10, 0), entities.NewDateTime(2019, 11, 25, 11, 0))
+	id, _ := calendar.AddEvent(ctx, entities.WithCalendarId(event, calendarId))
+	trashedId, _ := calendar.AddEvent(ctx, event)
+	_ = calendar.DeleteEvent(ctx, trashedId)
+
+	var snapshot Snapshot
+	_ = calendar.Snapshot(func(s Snapshot) error {
+		snapshot = s
+		return nil
+	})
+
+	if len(snapshot.Events) != 1 || snapshot.Events[0].Id() != id || len(snapshot.Trash) != 1 || len(snapshot.Calendars) != 1 {
+		t.Fatalf("snapshot must have 1 event, 1 trashed event and 1 calendar instead of %+v", snapshot)
+	}
+	if snapshot.EventSeq != 2 || snapshot.CalendarSeq != 1 || len(snapshot.Audit) != 3 {
+		t.Errorf("snapshot must have sequences 2 and 1 and 3 audit entries instead of %+v", snapshot)
+	}
+
+	restored := NewStorageFromSnapshot(snapshot, nil, nil)
+	if _, err := restored.GetEvent(ctx, id); err != nil {
+		t.Errorf("event must be restored from snapshot, got %s", err)
+	}
+	if newId, _ := restored.AddCalendar(ctx, entities.NewCalendar("Home")); newId != 2 {
+		t.Errorf("id of new calendar must be 2 instead of %d", newId)
+	}
+}
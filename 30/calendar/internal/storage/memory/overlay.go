@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+)
+
+// Map of events by id
+// Map of transactional view is overlay of changed events over map of storage, so it costs as changes of transaction,
+// map under overlay must not be changed while overlay is used
+type eventMap struct {
+	base    *eventMap              // map under overlay, nil for map of storage
+	events  map[int]entities.Event // put events
+	removed map[int]bool           // ids of events of base that are removed by overlay
+	size    int
+}
+
+func newEventMap() *eventMap {
+	return &eventMap{events: make(map[int]entities.Event), removed: make(map[int]bool)}
+}
+
+// Overlay of map, its changes don't touch map
+func (m *eventMap) overlay() *eventMap {
+	return &eventMap{base: m, events: make(map[int]entities.Event), removed: make(map[int]bool), size: m.size}
+}
+
+func (m *eventMap) get(id int) (entities.Event, bool) {
+	if event, ok := m.events[id]; ok {
+		return event, true
+	}
+	if m.base == nil || m.removed[id] {
+		return entities.Event{}, false
+	}
+	return m.base.get(id)
+}
+
+func (m *eventMap) put(event entities.Event) {
+	if _, ok := m.get(event.Id()); !ok {
+		m.size++
+	}
+	m.events[event.Id()] = event
+	delete(m.removed, event.Id())
+}
+
+func (m *eventMap) remove(id int) {
+	if _, ok := m.get(id); !ok {
+		return
+	}
+	m.size--
+	delete(m.events, id)
+	if m.base != nil {
+		m.removed[id] = true
+	}
+}
+
+func (m *eventMap) len() int {
+	return m.size
+}
+
+// Call fn for every event in no particular order
+func (m *eventMap) each(fn func(event entities.Event)) {
+	for _, event := range m.events {
+		fn(event)
+	}
+	if m.base == nil {
+		return
+	}
+	m.base.each(func(event entities.Event) {
+		if _, ok := m.events[event.Id()]; !ok && !m.removed[event.Id()] {
+			fn(event)
+		}
+	})
+}
+
+// Events sorted by id
+func (m *eventMap) sorted() []entities.Event {
+	events := make([]entities.Event, 0, m.size)
+	m.each(func(event entities.Event) {
+		events = append(events, event)
+	})
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Id() < events[j].Id()
+	})
+	return events
+}
+
+// Map of calendars by id, overlay works as overlay of eventMap
+type calendarMap struct {
+	base      *calendarMap
+	calendars map[int]entities.Calendar
+	removed   map[int]bool
+}
+
+func newCalendarMap() *calendarMap {
+	return &calendarMap{calendars: make(map[int]entities.Calendar), removed: make(map[int]bool)}
+}
+
+func (m *calendarMap) overlay() *calendarMap {
+	return &calendarMap{base: m, calendars: make(map[int]entities.Calendar), removed: make(map[int]bool)}
+}
+
+func (m *calendarMap) get(id int) (entities.Calendar, bool) {
+	if cal, ok := m.calendars[id]; ok {
+		return cal, true
+	}
+	if m.base == nil || m.removed[id] {
+		return entities.Calendar{}, false
+	}
+	return m.base.get(id)
+}
+
+func (m *calendarMap) put(cal entities.Calendar) {
+	m.calendars[cal.Id()] = cal
+	delete(m.removed, cal.Id())
+}
+
+func (m *calendarMap) remove(id int) {
+	delete(m.calendars, id)
+	if m.base != nil {
+		m.removed[id] = true
+	}
+}
+
+// Call fn for every calendar in no particular order
+func (m *calendarMap) each(fn func(cal entities.Calendar)) {
+	for _, cal := range m.calendars {
+		fn(cal)
+	}
+	if m.base == nil {
+		return
+	}
+	m.base.each(func(cal entities.Calendar) {
+		if _, ok := m.calendars[cal.Id()]; !ok && !m.removed[cal.Id()] {
+			fn(cal)
+		}
+	})
+}
+
+// Log of audit entries, log of transactional view is overlay of appended entries over log of storage
+type auditLog struct {
+	base    *auditLog
+	entries []entities.AuditEntry
+}
+
+func (log *auditLog) overlay() *auditLog {
+	return &auditLog{base: log}
+}
+
+func (log *auditLog) append(entry entities.AuditEntry) {
+	log.entries = append(log.entries, entry)
+}
+
+// Call fn for every entry, the oldest first
+func (log *auditLog) each(fn func(entry entities.AuditEntry)) {
+	if log.base != nil {
+		log.base.each(fn)
+	}
+	for _, entry := range log.entries {
+		fn(entry)
+	}
+}
+
+// Copy of all entries, the oldest first
+func (log *auditLog) all() []entities.AuditEntry {
+	var entries []entities.AuditEntry
+	log.each(func(entry entities.AuditEntry) {
+		entries = append(entries, entry)
+	})
+	return entries
+}
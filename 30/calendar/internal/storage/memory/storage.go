@@ -1,99 +1,270 @@
 package memory
 
 import (
-	"errors"
+	"context"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"math"
 	"sort"
 	"sync"
 	"time"
 )
 
-// Simplest entities struct, not support all day and repeat properties inherent for more sophisticated entities
+// Simplest entities struct, not support all day property inherent for more sophisticated entities
+// Operations on memory never block on I/O, so context is only checked before operation is started
 type Storage struct {
-	events        map[int]entities.Event // map of events indexed by id
-	mx            sync.RWMutex           // rw mutex for safe concurrent read and modification of entities
-	autoincrement int                    // autoincrement counter to generate next id on adding event in entities
+	*storageData
+	owner string // owner of events storage deals with, empty means all owners
+}
+
+// Data of storage, shared by all views of storage for different owners
+type storageData struct {
+	events        *eventMap    // map of events indexed by id
+	trash         *eventMap    // map of deleted events indexed by id
+	audit         *auditLog    // in-memory log of changes of events, only appended
+	calendars     *calendarMap // map of calendars indexed by id
+	mx            sync.RWMutex // rw mutex for safe concurrent read and modification of entities
+	autoincrement int          // autoincrement counter to generate next id on adding event in entities
+	calendarSeq   int          // autoincrement counter to generate next id on adding calendar
+	journal       Journal      // journal of changes, nil for storage that is not persisted
+	index         *eventIndex  // indexes of events for period queries, maintained by changes
+	claims        claims       // claims of due reminders by schedulers, see ClaimEventsToNotify
+	inviteClaims  inviteClaims // claims of attendees by schedulers, see ClaimInvitationsToSend
 }
 
 // Constructor
 func NewStorage() *Storage {
 	calendar := &Storage{
-		events: make(map[int]entities.Event),
-		mx:     sync.RWMutex{},
+		storageData: &storageData{
+			events:       newEventMap(),
+			trash:        newEventMap(),
+			calendars:    newCalendarMap(),
+			audit:        &auditLog{},
+			mx:           sync.RWMutex{},
+			index:        newEventIndex(),
+			claims:       make(claims),
+			inviteClaims: make(inviteClaims),
+		},
 	}
 	return calendar
 }
 
+// Storage view that deals only with events of owner, empty owner means events of all owners
+func (calendar *Storage) ForOwner(owner string) entities.Storage {
+	return &Storage{
+		storageData: calendar.storageData,
+		owner:       owner,
+	}
+}
+
 // Add event in entities, return new id for identify event in entities
-func (calendar *Storage) AddEvent(event entities.Event) (int, error) {
+// Event is added with owner of storage (if it is not empty)
+// If calendar of event is set but not found returns entities.StorageErrorCalendarNotFound
+func (calendar *Storage) AddEvent(ctx context.Context, event entities.Event) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if calendar.owner != "" {
+		event = entities.WithOwner(event, calendar.owner)
+	}
+
 	calendar.mx.Lock()
-	calendar.autoincrement++
-	id := calendar.autoincrement
-	calendar.events[id] = entities.WithId(event, id)
+	if !calendar.hasCalendar(event.CalendarId()) {
+		calendar.mx.Unlock()
+		return 0, entities.StorageErrorCalendarNotFound
+	}
+	id := calendar.autoincrement + 1
+	created := entities.WithVersion(entities.WithId(event, id), 1)
+	err := calendar.apply([]Change{
+		{Op: ChangePutEvent, Event: created},
+		calendar.auditChange(entities.AuditActionCreate, nil, &created),
+	})
 	calendar.mx.Unlock()
+	if err != nil {
+		return 0, err
+	}
 	return id, nil
 }
 
 // Update event
 // Get id and new event struct (inner id of event will be ignored)
+// Owner of event is not changed, version of event is incremented
+// Responses and states of invitation of attendees that are still attendees of event are kept
+// If version of event is not 0 and it is not version of stored event returns entities.StorageErrorVersionMismatch
+// If calendar of event is set but not found returns entities.StorageErrorCalendarNotFound
 // If not found returns error
-func (calendar *Storage) UpdateEvent(id int, event entities.Event) error {
+func (calendar *Storage) UpdateEvent(ctx context.Context, id int, event entities.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	if id <= 0 {
-		return errors.New("event not found")
+		return entities.StorageErrorEventNotFound
 	}
 
-	calendar.mx.RLock()
-	_, ok := calendar.events[id]
-	calendar.mx.RUnlock()
+	// check and update must be atomic
+	calendar.mx.Lock()
+	defer calendar.mx.Unlock()
 
-	if !ok {
-		return errors.New("event not found")
+	oldEvent, ok := calendar.events.get(id)
+	if !ok || !calendar.isOwned(oldEvent) {
+		return entities.StorageErrorEventNotFound
 	}
 
-	newEvent := entities.WithId(event, id)
+	if event.Version() != 0 && event.Version() != oldEvent.Version() {
+		return entities.StorageErrorVersionMismatch
+	}
 
-	calendar.mx.Lock()
-	calendar.events[id] = newEvent
-	calendar.mx.Unlock()
+	if !calendar.hasCalendar(event.CalendarId()) {
+		return entities.StorageErrorCalendarNotFound
+	}
+
+	newEvent := entities.WithOwner(entities.WithId(event, id), oldEvent.Owner())
+	newEvent = entities.WithVersion(newEvent, oldEvent.Version()+1)
+	newEvent = entities.WithAttendeeStatesOf(newEvent, oldEvent)
 
-	return nil
+	return calendar.apply([]Change{
+		{Op: ChangePutEvent, Event: newEvent},
+		calendar.auditChange(entities.AuditActionUpdate, &oldEvent, &newEvent),
+	})
 }
 
-// Delete event from entities by id of event in entities
+// Delete event from entities by id of event in entities, event is moved to trash
 // If not found returns error
-func (calendar *Storage) DeleteEvent(id int) error {
+func (calendar *Storage) DeleteEvent(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if id <= 0 {
-		return errors.New("event not found")
+		return entities.StorageErrorEventNotFound
+	}
+
+	calendar.mx.Lock()
+	defer calendar.mx.Unlock()
+
+	event, ok := calendar.events.get(id)
+	if !ok || !calendar.isOwned(event) {
+		return entities.StorageErrorEventNotFound
+	}
+
+	deleted := entities.WithDeletedTime(event, time.Now())
+
+	return calendar.apply([]Change{
+		{Op: ChangeTrashEvent, Event: deleted},
+		calendar.auditChange(entities.AuditActionDelete, &event, &deleted),
+	})
+}
+
+// Get events in trash, the most recently deleted first
+func (calendar *Storage) GetTrashedEvents(ctx context.Context) ([]entities.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	calendar.mx.RLock()
-	_, ok := calendar.events[id]
-	calendar.mx.RUnlock()
+	defer calendar.mx.RUnlock()
 
-	if !ok {
-		return errors.New("event not found")
+	var events []entities.Event
+	calendar.trash.each(func(event entities.Event) {
+		if calendar.isOwned(event) {
+			events = append(events, event)
+		}
+	})
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].DeletedTime().After(events[j].DeletedTime())
+	})
+
+	return events, nil
+}
+
+// Restore event from trash
+// If not found in trash returns error
+func (calendar *Storage) RestoreEvent(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
 	calendar.mx.Lock()
-	delete(calendar.events, id)
-	calendar.mx.Unlock()
+	defer calendar.mx.Unlock()
+
+	event, ok := calendar.trash.get(id)
+	if !ok || !calendar.isOwned(event) {
+		return entities.StorageErrorEventNotFound
+	}
+
+	restored := entities.WithDeletedTime(event, time.Time{})
+
+	return calendar.apply([]Change{
+		{Op: ChangePutEvent, Event: restored},
+		calendar.auditChange(entities.AuditActionRestore, &event, &restored),
+	})
+}
+
+// Get audit entries of event (including event in trash or purged one), the oldest first
+// If there are no entries returns error
+func (calendar *Storage) GetEventHistory(ctx context.Context, id int) ([]entities.AuditEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	calendar.mx.RLock()
+	defer calendar.mx.RUnlock()
+
+	var entries []entities.AuditEntry
+	calendar.audit.each(func(entry entities.AuditEntry) {
+		if entry.EventId() == id && (calendar.owner == "" || entry.Owner() == calendar.owner) {
+			entries = append(entries, entry)
+		}
+	})
+
+	if len(entries) == 0 {
+		return nil, entities.StorageErrorEventNotFound
+	}
+
+	return entries, nil
+}
+
+// Delete permanently events moved to trash before time, return number of purged events
+func (calendar *Storage) PurgeEvents(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	calendar.mx.Lock()
+	defer calendar.mx.Unlock()
 
-	return nil
+	var changes []Change
+	calendar.trash.each(func(event entities.Event) {
+		if calendar.isOwned(event) && event.DeletedTime().Before(before) {
+			changes = append(changes, Change{Op: ChangeRemoveEvent, Id: event.Id()})
+		}
+	})
+
+	err := calendar.apply(changes)
+	if err != nil {
+		return 0, err
+	}
+	return len(changes), nil
 }
 
 // Get event by id of event in entities
 // 2d param says found or not
-func (calendar *Storage) GetEvent(id int) (entities.Event, error) {
+func (calendar *Storage) GetEvent(ctx context.Context, id int) (entities.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return entities.Event{}, err
+	}
+
 	if id <= 0 {
 		return entities.Event{}, entities.StorageErrorEventNotFound
 	}
 
 	calendar.mx.RLock()
-	event, ok := calendar.events[id]
+	event, ok := calendar.events.get(id)
 	calendar.mx.RUnlock()
 
-	if !ok {
+	if !ok || !calendar.isOwned(event) {
 		return entities.Event{}, entities.StorageErrorEventNotFound
 	}
 
@@ -101,19 +272,24 @@ func (calendar *Storage) GetEvent(id int) (entities.Event, error) {
 }
 
 // Get all events of entities sorted by Less method of events
-func (calendar *Storage) GetAllEvents() ([]entities.Event, error) {
-	calendar.mx.RLock()
-	eventsMap := calendar.events
-	calendar.mx.RUnlock()
+func (calendar *Storage) GetAllEvents(ctx context.Context) ([]entities.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	if len(eventsMap) <= 0 {
+	calendar.mx.RLock()
+	if calendar.events.len() <= 0 {
+		calendar.mx.RUnlock()
 		return nil, nil
 	}
 
-	events := make([]entities.Event, 0, len(eventsMap))
-	for _, event := range eventsMap {
-		events = append(events, event)
-	}
+	events := make([]entities.Event, 0, calendar.events.len())
+	calendar.events.each(func(event entities.Event) {
+		if calendar.isOwned(event) {
+			events = append(events, event)
+		}
+	})
+	calendar.mx.RUnlock()
 
 	sort.Slice(events, func(i, j int) bool {
 		return events[i].Less(events[j])
@@ -123,30 +299,25 @@ func (calendar *Storage) GetAllEvents() ([]entities.Event, error) {
 }
 
 // Get all events that started in period (boundary of period are included) sorted by Less method of events
+// Recurring events are expanded into occurrences that started in period
+// Candidates are found by index of starts, so only events near period are checked
 // You also can pass nil for start or end times
 // nil has special means - no boundary for range period
-func (calendar *Storage) GetEventsByPeriod(startTime *entities.DateTime, endTime *entities.DateTime) ([]entities.Event, error) {
-	calendar.mx.RLock()
-	eventsMap := calendar.events
-	calendar.mx.RUnlock()
-
-	if len(eventsMap) <= 0 {
-		return nil, nil
+// If calendarIds are passed only events of these calendars are got
+func (calendar *Storage) GetEventsByPeriod(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime, calendarIds ...int) ([]entities.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
+	calendar.mx.RLock()
 	var events []entities.Event
-	for _, event := range eventsMap {
-		inPeriod := true
-		if startTime != nil && !startTime.LessOrEqual(event.Start()) {
-			inPeriod = false
-		}
-		if endTime != nil && !event.Start().LessOrEqual(*endTime) {
-			inPeriod = false
-		}
-		if inPeriod {
-			events = append(events, event)
+	for _, id := range calendar.index.inPeriod(startTime, endTime) {
+		event, _ := calendar.events.get(id)
+		if calendar.isOwned(event) && inCalendars(event, calendarIds) {
+			events = append(events, event.OccurrencesInPeriod(startTime, endTime)...)
 		}
 	}
+	calendar.mx.RUnlock()
 
 	sort.Slice(events, func(i, j int) bool {
 		return events[i].Less(events[j])
@@ -155,72 +326,478 @@ func (calendar *Storage) GetEventsByPeriod(startTime *entities.DateTime, endTime
 	return events, nil
 }
 
-//
-func (calendar *Storage) GetEventsToNotify(startTime *entities.DateTime, endTime *entities.DateTime) ([]entities.Event, error) {
-	allEvents, err := calendar.GetAllEvents()
+// Get page of all events ordered by start and id
+// Not recurring events are got by index of starts from cursor, so only events of page are checked
+func (calendar *Storage) GetAllEventsPage(ctx context.Context, limit int, cursor string) (entities.EventPage, error) {
+	if err := ctx.Err(); err != nil {
+		return entities.EventPage{}, err
+	}
 
+	after, err := entities.ParseCursor(cursor)
 	if err != nil {
-		return nil, err
+		return entities.EventPage{}, err
 	}
 
-	if len(allEvents) == 0 {
-		return nil, nil
+	calendar.mx.RLock()
+	var events []entities.Event
+	key := afterKey(math.MinInt64, after)
+	calendar.index.timed.ascendAfter(key, math.MaxInt64, calendar.pageCollector(&events, limit, nil))
+	calendar.index.allDay.ascendAfter(key, math.MaxInt64, calendar.pageCollector(&events, limit, nil))
+	calendar.index.recurring.ascend(func(key indexKey) {
+		event, _ := calendar.events.get(key.id)
+		if calendar.isOwned(event) {
+			events = append(events, event)
+		}
+	})
+	calendar.mx.RUnlock()
+
+	return entities.NewEventPage(events, after, limit), nil
+}
+
+// Get page of events that started in period ordered by start and id
+// Timed events are got by index of starts from cursor, all day and recurring events are checked as by GetEventsByPeriod,
+// occurrences of them are expanded from cursor up to limit (see Event.OccurrencesInPage) and merged with timed events
+func (calendar *Storage) GetEventsByPeriodPage(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime, limit int, cursor string, calendarIds ...int) (entities.EventPage, error) {
+	if err := ctx.Err(); err != nil {
+		return entities.EventPage{}, err
+	}
+
+	after, err := entities.ParseCursor(cursor)
+	if err != nil {
+		return entities.EventPage{}, err
 	}
 
+	from, to := bounds(startTime, endTime)
+
+	calendar.mx.RLock()
 	var events []entities.Event
-	for _, event := range allEvents {
+	calendar.index.timed.ascendAfter(afterKey(from, after), to, calendar.pageCollector(&events, limit, calendarIds))
 
-		if !event.IsNotifyingEnabled() || event.IsNotified() {
-			continue
+	var ids []int
+	calendar.index.allDay.ascendRange(sub(from, calendar.index.maxAllDay+allDayMargin), add(to, allDayMargin), func(key indexKey) {
+		if after.IsZero() || key.t >= after.Start() {
+			ids = append(ids, key.id)
+		}
+	})
+	calendar.index.recurring.ascend(func(key indexKey) {
+		ids = append(ids, key.id)
+	})
+	for _, id := range ids {
+		event, _ := calendar.events.get(id)
+		if calendar.isOwned(event) && inCalendars(event, calendarIds) {
+			events = append(events, event.OccurrencesInPage(startTime, endTime, after, limit)...)
 		}
+	}
+	calendar.mx.RUnlock()
 
-		beforeMinutes := event.BeforeMinutes()
-		start := event.Start()
+	return entities.NewEventPage(events, after, limit), nil
+}
+
+// Collector of indexed events of page, it stops iteration when one event more than limit is collected
+// Must be called under lock
+func (calendar *Storage) pageCollector(events *[]entities.Event, limit int, calendarIds []int) func(key indexKey) bool {
+	count := 0
+	return func(key indexKey) bool {
+		event, _ := calendar.events.get(key.id)
+		if calendar.isOwned(event) && inCalendars(event, calendarIds) {
+			*events = append(*events, event)
+			count++
+		}
+		return limit <= 0 || count <= limit
+	}
+}
+
+// Search events by words of name, candidates are found by inverted index of words
+func (calendar *Storage) SearchEvents(ctx context.Context, query string, startTime *entities.DateTime, endTime *entities.DateTime, limit int) ([]entities.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-		notifyT := start.Time().Add(-time.Duration(beforeMinutes) * time.Minute)
-		notifyTime := entities.ConvertFromTime(notifyT)
+	words := entities.SearchWords(query)
+	if len(words) == 0 {
+		return nil, entities.ErrEmptySearchQuery
+	}
 
-		inPeriod := true
-		if startTime != nil && !startTime.LessOrEqual(notifyTime) {
-			inPeriod = false
+	calendar.mx.RLock()
+	var events []entities.Event
+	for _, id := range calendar.index.withWords(words) {
+		event, _ := calendar.events.get(id)
+		if calendar.isOwned(event) && len(event.OccurrencesInPeriod(startTime, endTime)) > 0 {
+			events = append(events, event)
 		}
-		if endTime != nil && !notifyTime.LessOrEqual(*endTime) {
-			inPeriod = false
+	}
+	calendar.mx.RUnlock()
+
+	return entities.RankSearchResults(events, words, limit), nil
+}
+
+// Get occurrences of events that overlap interval [start, end) sorted by Less method of events
+func (calendar *Storage) GetOverlappingEvents(ctx context.Context, start entities.DateTime, end entities.DateTime) ([]entities.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	calendar.mx.RLock()
+	defer calendar.mx.RUnlock()
+
+	var events []entities.Event
+	for _, id := range calendar.index.overlapping(start, end) {
+		event, _ := calendar.events.get(id)
+		if calendar.isOwned(event) {
+			events = append(events, event.OccurrencesOverlapping(start, end)...)
 		}
+	}
 
-		if inPeriod {
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Less(events[j])
+	})
+
+	return events, nil
+}
+
+// Get notifications of not notified reminders which time is in period sorted by time
+// Events are found by index of times of notifications
+func (calendar *Storage) GetEventsToNotify(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime) ([]entities.Notification, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	calendar.mx.RLock()
+	var events []entities.Event
+	for _, id := range calendar.index.toNotify(startTime, endTime) {
+		event, _ := calendar.events.get(id)
+		if calendar.isOwned(event) {
 			events = append(events, event)
 		}
 	}
+	calendar.mx.RUnlock()
+
+	if len(events) == 0 {
+		return nil, nil
+	}
 
 	sort.Slice(events, func(i, j int) bool {
 		return events[i].Less(events[j])
 	})
 
-	return events, nil
+	var notifications []entities.Notification
+	for _, event := range events {
+		notifications = append(notifications, event.NotificationsInPeriod(startTime, endTime)...)
+	}
+
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return notifications[i].Time().Less(notifications[j].Time())
+	})
+
+	return notifications, nil
 }
 
-// Mark event as notified and set time when was notified
-func (calendar *Storage) MarkEventAsNotified(id int, when time.Time) error {
-	event, err := calendar.GetEvent(id)
+// Mark all reminders of event as notified, version of event is not changed
+// If not found returns error
+func (calendar *Storage) MarkEventAsNotified(ctx context.Context, id int, when time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return calendar.changeEvent(id, entities.AuditActionNotified, func(event entities.Event) (entities.Event, bool) {
+		return event.Notified(when), true
+	}, nil)
+}
+
+// Mark reminder of occurrence of event as notified, version of event is not changed
+// If event or its reminder not found returns error
+func (calendar *Storage) MarkReminderAsNotified(ctx context.Context, id int, beforeMinutes int, start entities.DateTime, when time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return calendar.changeEvent(id, entities.AuditActionNotified, func(event entities.Event) (entities.Event, bool) {
+		return event.ReminderNotified(beforeMinutes, start, when)
+	}, entities.StorageErrorReminderNotFound)
+}
+
+// Response of attendee to invitation, version of event is not changed
+// If status is unknown returns entities.ErrInvalidAttendeeStatus
+// If event not found returns error, if event has no such attendee returns entities.StorageErrorAttendeeNotFound
+func (calendar *Storage) RespondToInvitation(ctx context.Context, id int, email string, status string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := entities.ValidateAttendeeStatus(status)
 	if err != nil {
 		return err
 	}
-	newEvent := event.Notified(when)
-	return calendar.UpdateEvent(id, newEvent)
+	return calendar.changeEvent(id, entities.AuditActionRespond, func(event entities.Event) (entities.Event, bool) {
+		return event.AttendeeResponded(email, status)
+	}, entities.StorageErrorAttendeeNotFound)
+}
+
+// Get invitations that must be enqueued sorted by id of event, attendees of event are in their order
+// Invitations of events that are over at now are not enqueued
+func (calendar *Storage) GetInvitationsToSend(ctx context.Context, now time.Time) ([]entities.Invitation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	calendar.mx.RLock()
+	defer calendar.mx.RUnlock()
+
+	var invitations []entities.Invitation
+	calendar.events.each(func(event entities.Event) {
+		if calendar.isOwned(event) && !event.IsOver(now) {
+			invitations = append(invitations, event.Invitations()...)
+		}
+	})
+
+	sort.SliceStable(invitations, func(i, j int) bool {
+		return invitations[i].Id() < invitations[j].Id()
+	})
+
+	return invitations, nil
+}
+
+// Mark attendee of event as invited to event with start and end, version of event is not changed and it is not recorded into audit
+// If event not found returns error, if event has no such attendee returns entities.StorageErrorAttendeeNotFound
+func (calendar *Storage) MarkAttendeeAsInvited(ctx context.Context, id int, email string, start entities.DateTime, end entities.DateTime) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return calendar.changeEvent(id, "", func(event entities.Event) (entities.Event, bool) {
+		// claim of attendee ends with invitation, so update of event is not delayed by lease
+		delete(calendar.inviteClaims, inviteClaimKey{eventId: id, email: email})
+		return event.AttendeeInvited(email, start, end)
+	}, entities.StorageErrorAttendeeNotFound)
+}
+
+// Inner helper that replace event by its changed copy atomically, version of event is not changed
+// change returns event after change, false means that part of event (e.g. reminder) not found, then notFoundErr is returned
+// Change is recorded into audit with action, empty action means that change is not recorded
+func (calendar *Storage) changeEvent(id int, action string, change func(event entities.Event) (entities.Event, bool), notFoundErr error) error {
+	calendar.mx.Lock()
+	defer calendar.mx.Unlock()
+
+	event, ok := calendar.events.get(id)
+	if !ok || !calendar.isOwned(event) {
+		return entities.StorageErrorEventNotFound
+	}
+
+	newEvent, ok := change(event)
+	if !ok {
+		return notFoundErr
+	}
+
+	changes := []Change{{Op: ChangePutEvent, Event: newEvent}}
+	if action != "" {
+		changes = append(changes, calendar.auditChange(action, &event, &newEvent))
+	}
+
+	return calendar.apply(changes)
 }
 
 // Total number of events now in entities
-func (calendar *Storage) Count() (int, error) {
+func (calendar *Storage) Count(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
 	calendar.mx.RLock()
-	count := len(calendar.events)
-	calendar.mx.RUnlock()
+	defer calendar.mx.RUnlock()
+
+	if calendar.owner == "" {
+		return calendar.events.len(), nil
+	}
+
+	count := 0
+	calendar.events.each(func(event entities.Event) {
+		if calendar.isOwned(event) {
+			count++
+		}
+	})
 	return count, nil
 }
 
-func (calendar *Storage) ClearAll() error {
+// Delete all events (of owner), including events in trash, and all calendars (of owner)
+func (calendar *Storage) ClearAll(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	calendar.mx.Lock()
-	calendar.events = make(map[int]entities.Event)
-	calendar.mx.Unlock()
-	return nil
+	defer calendar.mx.Unlock()
+
+	var changes []Change
+	calendar.events.each(func(event entities.Event) {
+		if calendar.isOwned(event) {
+			changes = append(changes, Change{Op: ChangeRemoveEvent, Id: event.Id()})
+		}
+	})
+	calendar.trash.each(func(event entities.Event) {
+		if calendar.isOwned(event) {
+			changes = append(changes, Change{Op: ChangeRemoveEvent, Id: event.Id()})
+		}
+	})
+	calendar.calendars.each(func(cal entities.Calendar) {
+		if calendar.isOwnedCalendar(cal) {
+			changes = append(changes, Change{Op: ChangeRemoveCalendar, Id: cal.Id()})
+		}
+	})
+	return calendar.apply(changes)
+}
+
+// Add calendar with owner of storage (if it is not empty), return id of new calendar
+func (calendar *Storage) AddCalendar(ctx context.Context, cal entities.Calendar) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if calendar.owner != "" {
+		cal = entities.CalendarWithOwner(cal, calendar.owner)
+	}
+
+	calendar.mx.Lock()
+	defer calendar.mx.Unlock()
+
+	id := calendar.calendarSeq + 1
+	err := calendar.apply([]Change{{Op: ChangePutCalendar, Calendar: entities.CalendarWithId(cal, id)}})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Update (rename) calendar, owner of calendar is not changed
+// If not found returns entities.StorageErrorCalendarNotFound
+func (calendar *Storage) UpdateCalendar(ctx context.Context, id int, cal entities.Calendar) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	calendar.mx.Lock()
+	defer calendar.mx.Unlock()
+
+	oldCal, ok := calendar.calendars.get(id)
+	if !ok || !calendar.isOwnedCalendar(oldCal) {
+		return entities.StorageErrorCalendarNotFound
+	}
+
+	return calendar.apply([]Change{
+		{Op: ChangePutCalendar, Calendar: entities.CalendarWithOwner(entities.CalendarWithId(cal, id), oldCal.Owner())},
+	})
+}
+
+// Delete calendar with all its events (including events in trash) permanently
+// If not found returns entities.StorageErrorCalendarNotFound
+func (calendar *Storage) DeleteCalendar(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	calendar.mx.Lock()
+	defer calendar.mx.Unlock()
+
+	cal, ok := calendar.calendars.get(id)
+	if !ok || !calendar.isOwnedCalendar(cal) {
+		return entities.StorageErrorCalendarNotFound
+	}
+
+	changes := []Change{{Op: ChangeRemoveCalendar, Id: id}}
+	calendar.events.each(func(event entities.Event) {
+		if event.CalendarId() == id {
+			changes = append(changes, Change{Op: ChangeRemoveEvent, Id: event.Id()})
+		}
+	})
+	calendar.trash.each(func(event entities.Event) {
+		if event.CalendarId() == id {
+			changes = append(changes, Change{Op: ChangeRemoveEvent, Id: event.Id()})
+		}
+	})
+
+	return calendar.apply(changes)
+}
+
+// Get calendar by id
+// If not found returns entities.StorageErrorCalendarNotFound
+func (calendar *Storage) GetCalendar(ctx context.Context, id int) (entities.Calendar, error) {
+	if err := ctx.Err(); err != nil {
+		return entities.Calendar{}, err
+	}
+
+	calendar.mx.RLock()
+	defer calendar.mx.RUnlock()
+
+	cal, ok := calendar.calendars.get(id)
+	if !ok || !calendar.isOwnedCalendar(cal) {
+		return entities.Calendar{}, entities.StorageErrorCalendarNotFound
+	}
+
+	return cal, nil
+}
+
+// Get all calendars (of owner) sorted by id
+func (calendar *Storage) GetCalendars(ctx context.Context) ([]entities.Calendar, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	calendar.mx.RLock()
+	defer calendar.mx.RUnlock()
+
+	var calendars []entities.Calendar
+	calendar.calendars.each(func(cal entities.Calendar) {
+		if calendar.isOwnedCalendar(cal) {
+			calendars = append(calendars, cal)
+		}
+	})
+
+	sort.Slice(calendars, func(i, j int) bool {
+		return calendars[i].Id() < calendars[j].Id()
+	})
+
+	return calendars, nil
+}
+
+// Change that appends audit entry with diff of event before and after change, actor of change is owner of storage
+func (calendar *Storage) auditChange(action string, before *entities.Event, after *entities.Event) Change {
+	event := after
+	if event == nil {
+		event = before
+	}
+	entry := entities.NewAuditEntry(event.Id(), event.Owner(), calendar.owner, action, time.Now(), entities.DiffEvents(before, after))
+	return Change{Op: ChangeAudit, Entry: entry}
+}
+
+// Does storage deal with event
+func (calendar *Storage) isOwned(event entities.Event) bool {
+	return calendar.owner == "" || event.Owner() == calendar.owner
+}
+
+// Does storage deal with calendar
+func (calendar *Storage) isOwnedCalendar(cal entities.Calendar) bool {
+	return calendar.owner == "" || cal.Owner() == calendar.owner
+}
+
+// Is there calendar with id that storage deals with, 0 means event without calendar so it is always ok
+// Must be called under lock
+func (calendar *Storage) hasCalendar(id int) bool {
+	if id == 0 {
+		return true
+	}
+	cal, ok := calendar.calendars.get(id)
+	return ok && calendar.isOwnedCalendar(cal)
+}
+
+// Does event belong to one of calendars, empty list of calendars means any calendar
+func inCalendars(event entities.Event, calendarIds []int) bool {
+	if len(calendarIds) == 0 {
+		return true
+	}
+	for _, id := range calendarIds {
+		if event.CalendarId() == id {
+			return true
+		}
+	}
+	return false
 }
@@ -1,7 +1,11 @@
 package memory
 
 import (
+	"context"
+	"fmt"
 	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/storagetest"
+	"math/rand"
 	"reflect"
 	"testing"
 	"time"
@@ -20,7 +24,7 @@ func TestAddEvent(t *testing.T) {
 		entities.NewDateTime(2019, 10, 15, 22, 0),
 	)
 
-	id, _ := calendar.AddEvent(event1)
+	id, _ := calendar.AddEvent(context.Background(), event1)
 
 	if id <= 0 {
 		t.Errorf("id %d of new added event must be > 0", id)
@@ -35,7 +39,7 @@ func TestAddEvent(t *testing.T) {
 		entities.NewDateTime(2019, 10, 16, 1, 0),
 	)
 
-	_, _ = calendar.AddEvent(event2)
+	_, _ = calendar.AddEvent(context.Background(), event2)
 
 	if getCalendarCount(calendar) != 2 {
 		t.Errorf("entities must has 2 event instead of %d\n", getCalendarCount(calendar))
@@ -51,9 +55,9 @@ func TestGetEvent(t *testing.T) {
 		entities.NewDateTime(2019, 10, 15, 22, 0),
 	)
 
-	id, _ := calendar.AddEvent(event1)
+	id, _ := calendar.AddEvent(context.Background(), event1)
 
-	event, err := calendar.GetEvent(id)
+	event, err := calendar.GetEvent(context.Background(), id)
 	if err == entities.StorageErrorEventNotFound {
 		t.Error("Get Event must be ok")
 	}
@@ -62,12 +66,12 @@ func TestGetEvent(t *testing.T) {
 		t.Error("get Event return another event")
 	}
 
-	_, err = calendar.GetEvent(10000)
+	_, err = calendar.GetEvent(context.Background(), 10000)
 	if err != entities.StorageErrorEventNotFound {
 		t.Error("get Event must not be ok")
 	}
 
-	_, err = calendar.GetEvent(0)
+	_, err = calendar.GetEvent(context.Background(), 0)
 	if err != entities.StorageErrorEventNotFound {
 		t.Error("get Event must not be ok")
 	}
@@ -82,31 +86,31 @@ func TestUpdateEvent(t *testing.T) {
 		entities.NewDateTime(2019, 10, 15, 22, 0),
 	)
 
-	id, _ := calendar.AddEvent(event1)
+	id, _ := calendar.AddEvent(context.Background(), event1)
 
 	event2 := entities.NewEvent("Watch movie",
 		entities.NewDateTime(2019, 10, 15, 22, 0),
 		entities.NewDateTime(2019, 10, 16, 1, 0),
 	)
 
-	err := calendar.UpdateEvent(id, event2)
+	err := calendar.UpdateEvent(context.Background(), id, event2)
 
 	if err != nil {
 		t.Errorf("update err %s must not be happened\n", err)
 	}
 
-	event, _ := calendar.GetEvent(id)
+	event, _ := calendar.GetEvent(context.Background(), id)
 
 	if event.Name() != "Watch movie" {
 		t.Error("get return another event, event actually not updated")
 	}
 
-	err = calendar.UpdateEvent(0, entities.Event{})
+	err = calendar.UpdateEvent(context.Background(), 0, entities.Event{})
 	if err == nil {
 		t.Error("update by id = 0 must return error")
 	}
 
-	err = calendar.UpdateEvent(1000, entities.Event{})
+	err = calendar.UpdateEvent(context.Background(), 1000, entities.Event{})
 	if err == nil {
 		t.Error("update by id of not existed event must return error")
 	}
@@ -121,26 +125,26 @@ func TestDeleteEvent(t *testing.T) {
 		entities.NewDateTime(2019, 10, 15, 22, 0),
 	)
 
-	id1, _ := calendar.AddEvent(event1)
+	id1, _ := calendar.AddEvent(context.Background(), event1)
 
 	event2 := entities.NewEvent("Watch movie",
 		entities.NewDateTime(2019, 10, 15, 22, 0),
 		entities.NewDateTime(2019, 10, 16, 1, 0),
 	)
 
-	id2, _ := calendar.AddEvent(event2)
+	id2, _ := calendar.AddEvent(context.Background(), event2)
 
-	err := calendar.DeleteEvent(0)
+	err := calendar.DeleteEvent(context.Background(), 0)
 	if err == nil {
 		t.Error("delete by id = 0 must return error")
 	}
 
-	err = calendar.DeleteEvent(1000)
+	err = calendar.DeleteEvent(context.Background(), 1000)
 	if err == nil {
 		t.Error("delete by id of not existed event must return error")
 	}
 
-	err = calendar.DeleteEvent(id1)
+	err = calendar.DeleteEvent(context.Background(), id1)
 	if err != nil {
 		t.Errorf("delete by id = %d must not return error", id1)
 	}
@@ -149,7 +153,7 @@ func TestDeleteEvent(t *testing.T) {
 		t.Error("delete actually not happened")
 	}
 
-	_ = calendar.DeleteEvent(id2)
+	_ = calendar.DeleteEvent(context.Background(), id2)
 
 	if getCalendarCount(calendar) != 0 {
 		t.Error("delete actually not happened, after 2 delete calender must be empty")
@@ -159,37 +163,37 @@ func TestDeleteEvent(t *testing.T) {
 func TestGetEvents(t *testing.T) {
 	calendar := NewStorage()
 
-	_, _ = calendar.AddEvent(entities.NewEvent("Monday",
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Monday",
 		entities.NewDateTime(2019, 11, 18, 8, 0),
 		entities.NewDateTime(2019, 11, 18, 10, 0),
 	))
 
-	_, _ = calendar.AddEvent(entities.NewEvent("Tuesday",
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Tuesday",
 		entities.NewDateTime(2019, 11, 19, 8, 0),
 		entities.NewDateTime(2019, 11, 19, 10, 0),
 	))
 
-	_, _ = calendar.AddEvent(entities.NewEvent("Wednesday",
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Wednesday",
 		entities.NewDateTime(2019, 11, 20, 8, 0),
 		entities.NewDateTime(2019, 11, 20, 10, 0),
 	))
 
-	_, _ = calendar.AddEvent(entities.NewEvent("Thursday",
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Thursday",
 		entities.NewDateTime(2019, 11, 21, 8, 0),
 		entities.NewDateTime(2019, 11, 21, 10, 0),
 	))
 
-	_, _ = calendar.AddEvent(entities.NewEvent("Friday",
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Friday",
 		entities.NewDateTime(2019, 11, 22, 8, 0),
 		entities.NewDateTime(2019, 11, 22, 10, 0),
 	))
 
-	_, _ = calendar.AddEvent(entities.NewEvent("Saturday",
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Saturday",
 		entities.NewDateTime(2019, 11, 23, 8, 0),
 		entities.NewDateTime(2019, 11, 23, 10, 0),
 	))
 
-	_, _ = calendar.AddEvent(entities.NewEvent("Sunday",
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Sunday",
 		entities.NewDateTime(2019, 11, 24, 8, 0),
 		entities.NewDateTime(2019, 11, 24, 10, 0),
 	))
@@ -199,12 +203,12 @@ func TestGetEvents(t *testing.T) {
 		return
 	}
 
-	allEvents, _ := calendar.GetAllEvents()
+	allEvents, _ := calendar.GetAllEvents(context.Background())
 	if len(allEvents) != 7 {
 		t.Error("7 events must be in entities and GetAllEvents must return all of them")
 	}
 
-	allEvents2, _ := calendar.GetEventsByPeriod(nil, nil)
+	allEvents2, _ := calendar.GetEventsByPeriod(context.Background(), nil, nil)
 	if len(allEvents2) != 7 {
 		t.Error("7 events must be in entities and GetEventsByTimestampsPeriod(nil, nil) must return all of them")
 	}
@@ -214,7 +218,7 @@ func TestGetEvents(t *testing.T) {
 	}
 
 	eventTime := entities.NewDateTime(2019, 11, 18, 8, 0)
-	eventList, _ := calendar.GetEventsByPeriod(nil, &eventTime)
+	eventList, _ := calendar.GetEventsByPeriod(context.Background(), nil, &eventTime)
 
 	if len(eventList) != 1 {
 		t.Errorf("Must be returned one event")
@@ -225,7 +229,7 @@ func TestGetEvents(t *testing.T) {
 	}
 
 	eventTime = entities.NewDateTime(2019, 11, 24, 8, 0)
-	eventList, _ = calendar.GetEventsByPeriod(&eventTime, nil)
+	eventList, _ = calendar.GetEventsByPeriod(context.Background(), &eventTime, nil)
 
 	if len(eventList) != 1 {
 		t.Errorf("Must be returned one event")
@@ -237,7 +241,7 @@ func TestGetEvents(t *testing.T) {
 
 	startEventTime := entities.NewDateTime(2019, 11, 20, 8, 0)
 	endEventTime := entities.NewDateTime(2019, 11, 22, 8, 0)
-	eventList, _ = calendar.GetEventsByPeriod(&startEventTime, &endEventTime)
+	eventList, _ = calendar.GetEventsByPeriod(context.Background(), &startEventTime, &endEventTime)
 
 	if len(eventList) != 3 {
 		t.Errorf("Must be returned 3 events")
@@ -255,7 +259,7 @@ func TestGetEvents(t *testing.T) {
 
 	startEventTime = entities.NewDateTime(2019, 11, 20, 8, 1)
 	endEventTime = entities.NewDateTime(2019, 11, 20, 9, 59)
-	eventList, _ = calendar.GetEventsByPeriod(&startEventTime, &endEventTime)
+	eventList, _ = calendar.GetEventsByPeriod(context.Background(), &startEventTime, &endEventTime)
 
 	if len(eventList) != 0 {
 		t.Errorf("Must be returned 0 events")
@@ -267,7 +271,7 @@ func TestGetEventsForNotification1(t *testing.T) {
 	calendar := NewStorage()
 
 	_, err := calendar.AddEvent(
-		entities.NewDetailedEvent(
+		context.Background(), entities.NewDetailedEvent(
 			"TestEvent",
 			entities.NewDateTime(2019, 11, 18, 8, 0),
 			entities.NewDateTime(2019, 11, 18, 10, 0),
@@ -284,7 +288,7 @@ func TestGetEventsForNotification1(t *testing.T) {
 	}
 
 	_, err = calendar.AddEvent(
-		entities.NewDetailedEvent(
+		context.Background(), entities.NewDetailedEvent(
 			"TestEvent2",
 			entities.NewDateTime(2019, 11, 18, 8, 0),
 			entities.NewDateTime(2019, 11, 18, 10, 0),
@@ -303,7 +307,7 @@ func TestGetEventsForNotification1(t *testing.T) {
 	start := entities.NewDateTime(2019, 11, 18, 7, 0)
 	end := entities.NewDateTime(2019, 11, 18, 8, 0)
 
-	entites, err := calendar.GetEventsToNotify(&start, &end)
+	entites, err := calendar.GetEventsToNotify(context.Background(), &start, &end)
 
 	if err != nil {
 		t.Errorf("Error while getting events: %s", err)
@@ -321,7 +325,7 @@ func TestGetEventsForNotification2(t *testing.T) {
 	calendar := NewStorage()
 
 	event1Id, err := calendar.AddEvent(
-		entities.NewDetailedEvent(
+		context.Background(), entities.NewDetailedEvent(
 			"TestEvent",
 			entities.NewDateTime(2019, 11, 18, 8, 0),
 			entities.NewDateTime(2019, 11, 18, 10, 0),
@@ -338,7 +342,7 @@ func TestGetEventsForNotification2(t *testing.T) {
 	}
 
 	_, err = calendar.AddEvent(
-		entities.NewDetailedEvent(
+		context.Background(), entities.NewDetailedEvent(
 			"TestEvent2",
 			entities.NewDateTime(2019, 11, 18, 8, 0),
 			entities.NewDateTime(2019, 11, 18, 10, 0),
@@ -357,7 +361,7 @@ func TestGetEventsForNotification2(t *testing.T) {
 	start := entities.NewDateTime(2019, 11, 18, 7, 0)
 	end := entities.NewDateTime(2019, 11, 18, 7, 49)
 
-	events, err := calendar.GetEventsToNotify(&start, &end)
+	events, err := calendar.GetEventsToNotify(context.Background(), &start, &end)
 
 	if err != nil {
 		t.Errorf("Error while getting events: %s", err)
@@ -380,7 +384,7 @@ func TestGetEventsForNotification3(t *testing.T) {
 	calendar := NewStorage()
 
 	_, err := calendar.AddEvent(
-		entities.NewDetailedEvent(
+		context.Background(), entities.NewDetailedEvent(
 			"TestEvent",
 			entities.NewDateTime(2019, 11, 18, 8, 0),
 			entities.NewDateTime(2019, 11, 18, 10, 0),
@@ -397,7 +401,7 @@ func TestGetEventsForNotification3(t *testing.T) {
 	}
 
 	_, err = calendar.AddEvent(
-		entities.NewDetailedEvent(
+		context.Background(), entities.NewDetailedEvent(
 			"TestEvent2",
 			entities.NewDateTime(2019, 11, 18, 8, 0),
 			entities.NewDateTime(2019, 11, 18, 10, 0),
@@ -416,7 +420,7 @@ func TestGetEventsForNotification3(t *testing.T) {
 	start := entities.NewDateTime(2019, 11, 18, 8, 1)
 	end := entities.NewDateTime(2019, 11, 18, 8, 59)
 
-	events, err := calendar.GetEventsToNotify(&start, &end)
+	events, err := calendar.GetEventsToNotify(context.Background(), &start, &end)
 
 	if err != nil {
 		t.Errorf("Error while getting events: %s", err)
@@ -456,7 +460,7 @@ func TestGetEventsForNotification4(t *testing.T) {
 	}
 
 	for index, event := range originalEvents {
-		id, err := calendar.AddEvent(event)
+		id, err := calendar.AddEvent(context.Background(), event)
 		if err != nil {
 			t.Errorf("Error while insert %s: %s", event.Name(), err)
 			return
@@ -467,7 +471,7 @@ func TestGetEventsForNotification4(t *testing.T) {
 	start := originalEvents[0].Start().MinusMinutes(originalEvents[0].BeforeMinutes())
 	end := originalEvents[1].Start().MinusMinutes(originalEvents[1].BeforeMinutes())
 
-	events, err := calendar.GetEventsToNotify(&start, &end)
+	events, err := calendar.GetEventsToNotify(context.Background(), &start, &end)
 
 	if err != nil {
 		t.Errorf("Error while getting events: %s", err)
@@ -492,7 +496,7 @@ func TestMarkEventAsNotified(t *testing.T) {
 	calendar := NewStorage()
 
 	id, err := calendar.AddEvent(
-		entities.NewDetailedEvent(
+		context.Background(), entities.NewDetailedEvent(
 			"A",
 			entities.NewDateTime(2019, 11, 18, 8, 0),
 			entities.NewDateTime(2019, 11, 18, 10, 0),
@@ -508,7 +512,7 @@ func TestMarkEventAsNotified(t *testing.T) {
 		return
 	}
 
-	event, err := calendar.GetEvent(id)
+	event, err := calendar.GetEvent(context.Background(), id)
 
 	if err != nil {
 		t.Errorf("Error while get by id %d: %s", id, err)
@@ -522,9 +526,9 @@ func TestMarkEventAsNotified(t *testing.T) {
 
 	dt := time.Date(2000, time.Month(10), 13, 14, 32, 18, 0, time.UTC)
 
-	_ = calendar.MarkEventAsNotified(id, dt)
+	_ = calendar.MarkEventAsNotified(context.Background(), id, dt)
 
-	event, err = calendar.GetEvent(id)
+	event, err = calendar.GetEvent(context.Background(), id)
 
 	if err != nil {
 		t.Errorf("Error while get by id %d: %s", id, err)
@@ -543,7 +547,941 @@ func TestMarkEventAsNotified(t *testing.T) {
 	}
 }
 
+func TestGetRecurringEvents(t *testing.T) {
+	calendar := NewStorage()
+
+	recurrence, _ := entities.ParseRecurrence("FREQ=WEEKLY;BYDAY=MO,WE;COUNT=6")
+	recurrence = recurrence.WithExDates([]entities.DateTime{
+		entities.NewDateTime(2019, 11, 20, 10, 0),
+	})
+
+	_, _ = calendar.AddEvent(context.Background(), entities.WithRecurrence(entities.NewEvent("Stand-up",
+		entities.NewDateTime(2019, 11, 18, 10, 0),
+		entities.NewDateTime(2019, 11, 18, 10, 15),
+	), recurrence))
+
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Retro",
+		entities.NewDateTime(2019, 11, 29, 17, 0),
+		entities.NewDateTime(2019, 11, 29, 18, 0),
+	))
+
+	allEvents, _ := calendar.GetAllEvents(context.Background())
+	if len(allEvents) != 2 {
+		t.Errorf("GetAllEvents must return 2 events (not expanded) instead of %d", len(allEvents))
+	}
+
+	start := entities.NewDateTime(2019, 11, 19, 0, 0)
+	end := entities.NewDateTime(2019, 11, 30, 23, 59)
+
+	eventList, err := calendar.GetEventsByPeriod(context.Background(), &start, &end)
+	if err != nil {
+		t.Errorf("Error while getting events: %s", err)
+		return
+	}
+
+	var names []string
+	for _, event := range eventList {
+		names = append(names, fmt.Sprintf("%s %s", event.Name(), event.Start()))
+	}
+
+	expectedNames := []string{
+		"Stand-up 25 Nov 2019 10:00",
+		"Stand-up 27 Nov 2019 10:00",
+		"Retro 29 Nov 2019 17:00",
+	}
+	if !reflect.DeepEqual(expectedNames, names) {
+		t.Errorf("Expected events %v, instead of %v", expectedNames, names)
+	}
+
+	event, _ := calendar.GetEvent(context.Background(), eventList[0].Id())
+	if !event.IsRecurring() || event.Recurrence().String() != recurrence.String() || len(event.Recurrence().ExDates()) != 1 {
+		t.Errorf("Recurrence of event must be stored")
+	}
+}
+
+func TestGetAllDayEvents(t *testing.T) {
+	calendar := NewStorage()
+
+	_, _ = calendar.AddEvent(context.Background(), entities.NewAllDayEvent("Holidays",
+		entities.NewDate(2019, 12, 31),
+		entities.NewDate(2020, 1, 2),
+	))
+
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Party",
+		entities.NewDateTime(2019, 12, 31, 22, 0),
+		entities.NewDateTime(2020, 1, 1, 2, 0),
+	))
+
+	start := entities.NewDateTime(2020, 1, 1, 0, 0)
+	end := entities.NewDateTime(2020, 1, 1, 23, 59)
+
+	eventList, err := calendar.GetEventsByPeriod(context.Background(), &start, &end)
+	if err != nil {
+		t.Errorf("Error while getting events: %s", err)
+		return
+	}
+
+	if len(eventList) != 1 {
+		t.Errorf("Must be returned 1 event instead of %d", len(eventList))
+		return
+	}
+
+	event := eventList[0]
+	if !event.IsAllDay() || event.Name() != "Holidays" {
+		t.Errorf("Must be returned all day event `Holidays` instead of %s", event)
+	}
+
+	if event.StartDate() != entities.NewDate(2019, 12, 31) || event.EndDate() != entities.NewDate(2020, 1, 2) {
+		t.Errorf("Dates of all day event must be stored, got %s", event)
+	}
+
+	start = entities.NewDateTime(2020, 1, 3, 0, 0)
+	end = entities.NewDateTime(2020, 1, 3, 23, 59)
+
+	eventList, _ = calendar.GetEventsByPeriod(context.Background(), &start, &end)
+	if len(eventList) != 0 {
+		t.Errorf("Must be returned 0 events instead of %d", len(eventList))
+	}
+}
+
+func TestForOwner(t *testing.T) {
+	calendar := NewStorage()
+
+	alice := calendar.ForOwner("alice")
+	bob := calendar.ForOwner("bob")
+
+	event := entities.NewEvent("Team sync",
+		entities.NewDateTime(2019, 11, 25, 10, 0),
+		entities.NewDateTime(2019, 11, 25, 11, 0),
+	)
+
+	id, err := alice.AddEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	_, _ = bob.AddEvent(context.Background(), event)
+
+	aliceEvent, err := alice.GetEvent(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if aliceEvent.Owner() != "alice" {
+		t.Errorf("owner of event must be `alice` instead of `%s`", aliceEvent.Owner())
+	}
+
+	_, err = bob.GetEvent(context.Background(), id)
+	if err != entities.StorageErrorEventNotFound {
+		t.Errorf("bob must not get event of alice, got error %v", err)
+	}
+
+	err = bob.UpdateEvent(context.Background(), id, entities.WithDescription(event, "Hacked"))
+	if err == nil {
+		t.Errorf("bob must not update event of alice")
+	}
+
+	err = bob.DeleteEvent(context.Background(), id)
+	if err == nil {
+		t.Errorf("bob must not delete event of alice")
+	}
+
+	start := entities.NewDateTime(2019, 11, 25, 0, 0)
+	end := entities.NewDateTime(2019, 11, 25, 23, 59)
+
+	eventList, _ := alice.GetEventsByPeriod(context.Background(), &start, &end)
+	if len(eventList) != 1 || eventList[0].Id() != id || eventList[0].Name() != "Team sync" {
+		t.Errorf("alice must get only her own event, got %v", eventList)
+	}
+
+	cnt, _ := bob.Count(context.Background())
+	if cnt != 1 {
+		t.Errorf("bob must has 1 event instead of %d", cnt)
+	}
+
+	if getCalendarCount(calendar) != 2 {
+		t.Errorf("storage without owner must has 2 events instead of %d", getCalendarCount(calendar))
+	}
+}
+
+func TestGetOverlappingEvents(t *testing.T) {
+	calendar := NewStorage()
+
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Morning",
+		entities.NewDateTime(2019, 11, 25, 9, 0),
+		entities.NewDateTime(2019, 11, 25, 10, 0),
+	))
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Lunch",
+		entities.NewDateTime(2019, 11, 25, 12, 0),
+		entities.NewDateTime(2019, 11, 25, 13, 0),
+	))
+
+	eventList, err := calendar.GetOverlappingEvents(context.Background(), entities.NewDateTime(2019, 11, 25, 10, 0), entities.NewDateTime(2019, 11, 25, 12, 30))
+	if err != nil {
+		t.Fatalf("Error while getting events: %s", err)
+	}
+	if len(eventList) != 1 || eventList[0].Name() != "Lunch" {
+		t.Errorf("Must be returned only `Lunch` event instead of %v", eventList)
+	}
+}
+
+func TestCheckDateBusy(t *testing.T) {
+	calendar := NewStorage()
+	alice := calendar.ForOwner("alice")
+	bob := calendar.ForOwner("bob")
+
+	meeting := entities.NewEvent("Meeting",
+		entities.NewDateTime(2019, 11, 25, 10, 0),
+		entities.NewDateTime(2019, 11, 25, 11, 0),
+	)
+	id, _ := alice.AddEvent(context.Background(), entities.WithPlace(meeting, "Room 42"))
+
+	overlapping := entities.NewEvent("Sync",
+		entities.NewDateTime(2019, 11, 25, 10, 30),
+		entities.NewDateTime(2019, 11, 25, 11, 30),
+	)
+
+	err := entities.CheckDateBusy(context.Background(), alice, overlapping, 0)
+	busyErr, ok := err.(*entities.ErrDateBusy)
+	if !ok {
+		t.Fatalf("date must be busy for alice, got error %v", err)
+	}
+	if len(busyErr.Conflicts()) != 1 || busyErr.Conflicts()[0].Id() != id {
+		t.Errorf("conflict must be event %d instead of %v", id, busyErr.Conflicts())
+	}
+
+	if err := entities.CheckDateBusy(context.Background(), bob, overlapping, 0); err != nil {
+		t.Errorf("date must not be busy for bob, got error %s", err)
+	}
+
+	if err := entities.CheckDateBusy(context.Background(), bob, entities.WithPlace(overlapping, "Room 42"), 0); err == nil {
+		t.Errorf("room must be busy for bob too")
+	}
+
+	if err := entities.CheckDateBusy(context.Background(), bob, entities.WithPlace(overlapping, "Room 7"), 0); err != nil {
+		t.Errorf("other room must not be busy for bob, got error %s", err)
+	}
+
+	if err := entities.CheckDateBusy(context.Background(), alice, overlapping, id); err != nil {
+		t.Errorf("updated event must not conflict with itself, got error %s", err)
+	}
+
+	r, _ := entities.ParseRecurrence("FREQ=WEEKLY;COUNT=3")
+	weekly := entities.WithRecurrence(entities.NewEvent("Weekly",
+		entities.NewDateTime(2019, 11, 11, 10, 45),
+		entities.NewDateTime(2019, 11, 11, 11, 15),
+	), r)
+	if err := entities.CheckDateBusy(context.Background(), alice, weekly, 0); err == nil {
+		t.Errorf("third occurrence of recurring event must conflict with meeting")
+	}
+}
+
 func getCalendarCount(storage *Storage) int {
-	cnt, _ := storage.Count()
+	cnt, _ := storage.Count(context.Background())
 	return cnt
 }
+
+func TestReminders(t *testing.T) {
+	calendar := NewStorage()
+
+	event := entities.NewEvent("Meeting",
+		entities.NewDateTime(2019, 11, 25, 10, 0),
+		entities.NewDateTime(2019, 11, 25, 11, 0),
+	)
+	event = entities.WithReminders(event, []entities.Reminder{entities.NewReminder(10), entities.NewReminder(30)})
+
+	id, err := calendar.AddEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	start := entities.NewDateTime(2019, 11, 25, 9, 0)
+	end := entities.NewDateTime(2019, 11, 25, 10, 0)
+
+	notifications, err := calendar.GetEventsToNotify(context.Background(), &start, &end)
+	if err != nil {
+		t.Fatalf("Error while getting events: %s", err)
+	}
+
+	var minutes []int
+	for _, notification := range notifications {
+		minutes = append(minutes, notification.Reminder().BeforeMinutes())
+	}
+	if !reflect.DeepEqual(minutes, []int{30, 10}) {
+		t.Fatalf("must be notification per reminder [30 10] instead of %v", minutes)
+	}
+
+	dt := time.Date(2019, 11, 25, 9, 30, 0, 0, time.UTC)
+	err = calendar.MarkReminderAsNotified(context.Background(), id, 30, event.Start(), dt)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	notifications, _ = calendar.GetEventsToNotify(context.Background(), &start, &end)
+	if len(notifications) != 1 || notifications[0].Reminder().BeforeMinutes() != 10 {
+		t.Errorf("must be only notification of reminder 10 instead of %v", notifications)
+	}
+
+	dbEvent, _ := calendar.GetEvent(context.Background(), id)
+	if dbEvent.IsNotified() {
+		t.Errorf("event must not be marked as notified until all reminders are notified")
+	}
+	if reminders := dbEvent.Reminders(); !reminders[0].IsNotified() || !reminders[0].NotifiedTime().Equal(dt) {
+		t.Errorf("reminder 30 must be notified at %s, got %+v", dt, reminders[0])
+	}
+
+	_ = calendar.MarkReminderAsNotified(context.Background(), id, 10, event.Start(), dt)
+	dbEvent, _ = calendar.GetEvent(context.Background(), id)
+	if !dbEvent.IsNotified() {
+		t.Errorf("event must be marked as notified when all reminders are notified")
+	}
+
+	if err := calendar.MarkReminderAsNotified(context.Background(), id, 5, event.Start(), dt); err == nil {
+		t.Errorf("must be error for unknown reminder")
+	}
+}
+
+func TestGetBusyIntervals(t *testing.T) {
+	calendar := NewStorage()
+
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Night",
+		entities.NewDateTime(2019, 11, 24, 23, 0),
+		entities.NewDateTime(2019, 11, 25, 1, 0),
+	))
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Morning",
+		entities.NewDateTime(2019, 11, 25, 9, 0),
+		entities.NewDateTime(2019, 11, 25, 10, 0),
+	))
+	_, _ = calendar.AddEvent(context.Background(), entities.NewEvent("Tomorrow",
+		entities.NewDateTime(2019, 11, 26, 9, 0),
+		entities.NewDateTime(2019, 11, 26, 10, 0),
+	))
+
+	busy, err := entities.GetBusyIntervals(context.Background(), calendar, entities.NewDateTime(2019, 11, 25, 0, 0), entities.NewDateTime(2019, 11, 26, 0, 0))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	expected := []entities.Interval{
+		entities.NewInterval(entities.NewDateTime(2019, 11, 25, 0, 0), entities.NewDateTime(2019, 11, 25, 1, 0)),
+		entities.NewInterval(entities.NewDateTime(2019, 11, 25, 9, 0), entities.NewDateTime(2019, 11, 25, 10, 0)),
+	}
+	if !reflect.DeepEqual(busy, expected) {
+		t.Errorf("busy intervals must be %v instead of %v", expected, busy)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	calendar := NewStorage()
+
+	event := entities.NewDetailedEvent("Meeting",
+		entities.NewDateTime(2019, 11, 25, 10, 0),
+		entities.NewDateTime(2019, 11, 25, 11, 0),
+		true,
+		10,
+		false,
+		time.Time{},
+	)
+
+	id, err := calendar.AddEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	err = calendar.DeleteEvent(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if _, err := calendar.GetEvent(context.Background(), id); err == nil {
+		t.Errorf("event in trash must not be found")
+	}
+
+	start := entities.NewDateTime(2019, 11, 25, 0, 0)
+	end := entities.NewDateTime(2019, 11, 26, 0, 0)
+
+	events, _ := calendar.GetEventsByPeriod(context.Background(), &start, &end)
+	if len(events) != 0 {
+		t.Errorf("event in trash must not be got by period instead of %v", events)
+	}
+
+	notifications, _ := calendar.GetEventsToNotify(context.Background(), &start, &end)
+	if len(notifications) != 0 {
+		t.Errorf("event in trash must not be notified instead of %v", notifications)
+	}
+
+	if cnt, _ := calendar.Count(context.Background()); cnt != 0 {
+		t.Errorf("event in trash must not be counted, count must be 0 instead of %d", cnt)
+	}
+
+	if err := calendar.DeleteEvent(context.Background(), id); err == nil {
+		t.Errorf("event in trash must not be deleted again")
+	}
+
+	trashed, err := calendar.GetTrashedEvents(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if len(trashed) != 1 || trashed[0].Id() != id || !trashed[0].IsDeleted() {
+		t.Fatalf("trash must have one deleted event with id = %d instead of %v", id, trashed)
+	}
+
+	err = calendar.RestoreEvent(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	restored, err := calendar.GetEvent(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if restored.IsDeleted() || restored.Name() != "Meeting" {
+		t.Errorf("event must be restored from trash instead of %v", restored)
+	}
+
+	if err := calendar.RestoreEvent(context.Background(), id); err == nil {
+		t.Errorf("event that is not in trash must not be restored")
+	}
+
+	_ = calendar.DeleteEvent(context.Background(), id)
+
+	// trashed event is not purged before it was deleted
+	cnt, err := calendar.PurgeEvents(context.Background(), time.Now().Add(-time.Hour))
+	if err != nil || cnt != 0 {
+		t.Errorf("must be purged 0 events instead of %d, error %v", cnt, err)
+	}
+
+	cnt, err = calendar.PurgeEvents(context.Background(), time.Now().Add(time.Hour))
+	if err != nil || cnt != 1 {
+		t.Errorf("must be purged 1 event instead of %d, error %v", cnt, err)
+	}
+
+	trashed, _ = calendar.GetTrashedEvents(context.Background())
+	if len(trashed) != 0 {
+		t.Errorf("trash must be empty after purge instead of %v", trashed)
+	}
+}
+
+func TestUpdateEventVersion(t *testing.T) {
+	calendar := NewStorage()
+
+	event := entities.NewEvent("Meeting",
+		entities.NewDateTime(2019, 11, 25, 10, 0),
+		entities.NewDateTime(2019, 11, 25, 11, 0),
+	)
+
+	id, err := calendar.AddEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	dbEvent, _ := calendar.GetEvent(context.Background(), id)
+	if dbEvent.Version() != 1 {
+		t.Fatalf("version of new event must be 1 instead of %d", dbEvent.Version())
+	}
+
+	// the first client updates event it got
+	err = calendar.UpdateEvent(context.Background(), id, entities.WithVersion(entities.NewEvent("Meeting 1", dbEvent.Start(), dbEvent.End()), 1))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	// the second client updates event it got before, so its update must be rejected
+	err = calendar.UpdateEvent(context.Background(), id, entities.WithVersion(entities.NewEvent("Meeting 2", dbEvent.Start(), dbEvent.End()), 1))
+	if err != entities.StorageErrorVersionMismatch {
+		t.Errorf("expected error `%s` instead of `%v`", entities.StorageErrorVersionMismatch, err)
+	}
+
+	dbEvent, _ = calendar.GetEvent(context.Background(), id)
+	if dbEvent.Name() != "Meeting 1" || dbEvent.Version() != 2 {
+		t.Errorf("must be event `Meeting 1` of version 2 instead of `%s` of version %d", dbEvent.Name(), dbEvent.Version())
+	}
+
+	// update without expected version is not checked
+	err = calendar.UpdateEvent(context.Background(), id, entities.NewEvent("Meeting 3", dbEvent.Start(), dbEvent.End()))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	dbEvent, _ = calendar.GetEvent(context.Background(), id)
+	if dbEvent.Name() != "Meeting 3" || dbEvent.Version() != 3 {
+		t.Errorf("must be event `Meeting 3` of version 3 instead of `%s` of version %d", dbEvent.Name(), dbEvent.Version())
+	}
+
+	err = calendar.UpdateEvent(context.Background(), id+100, entities.WithVersion(dbEvent, 3))
+	if err == nil || err == entities.StorageErrorVersionMismatch {
+		t.Errorf("update of not existing event must fail with not found error instead of `%v`", err)
+	}
+}
+
+func TestEventHistory(t *testing.T) {
+	calendar := NewStorage()
+	alice := calendar.ForOwner("alice")
+
+	event := entities.NewEvent("Meeting",
+		entities.NewDateTime(2019, 11, 25, 10, 0),
+		entities.NewDateTime(2019, 11, 25, 11, 0),
+	)
+	event = entities.WithReminders(event, []entities.Reminder{entities.NewReminder(10)})
+
+	id, err := alice.AddEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	moved := entities.WithReminders(
+		entities.NewEvent("Meeting", entities.NewDateTime(2019, 11, 25, 12, 0), entities.NewDateTime(2019, 11, 25, 13, 0)),
+		[]entities.Reminder{entities.NewReminder(10)},
+	)
+	err = alice.UpdateEvent(context.Background(), id, moved)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	// scheduler deals with events of all owners
+	err = calendar.MarkReminderAsNotified(context.Background(), id, 10, entities.NewDateTime(2019, 11, 25, 12, 0), time.Date(2019, 11, 25, 11, 50, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	err = alice.DeleteEvent(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	entries, err := alice.GetEventHistory(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	var actions []string
+	for _, entry := range entries {
+		actions = append(actions, entry.Action()+" by "+entry.Actor())
+	}
+	expectedActions := []string{"create by alice", "update by alice", "notified by ", "delete by alice"}
+	if !reflect.DeepEqual(actions, expectedActions) {
+		t.Fatalf("history must be %v instead of %v", expectedActions, actions)
+	}
+
+	expectedChanges := []entities.FieldChange{
+		entities.NewFieldChange(entities.FieldStart, "2019-11-25 10:00", "2019-11-25 12:00"),
+		entities.NewFieldChange(entities.FieldEnd, "2019-11-25 11:00", "2019-11-25 13:00"),
+	}
+	if !reflect.DeepEqual(entries[1].Changes(), expectedChanges) {
+		t.Errorf("changes of update must be %+v instead of %+v", expectedChanges, entries[1].Changes())
+	}
+
+	if entries[3].Owner() != "alice" || len(entries[3].Changes()) != 1 || entries[3].Changes()[0].Field() != "deletedTime" {
+		t.Errorf("delete must change only deleted time of event of alice instead of %+v", entries[3])
+	}
+
+	if _, err := calendar.ForOwner("bob").GetEventHistory(context.Background(), id); err == nil {
+		t.Errorf("history of event of other owner must not be found")
+	}
+
+	// history is kept for purged event
+	_, _ = alice.PurgeEvents(context.Background(), time.Now().Add(time.Hour))
+
+	entries, err = alice.GetEventHistory(context.Background(), id)
+	if err != nil || len(entries) != 4 {
+		t.Errorf("history of purged event must be kept, got %d entries, error %v", len(entries), err)
+	}
+}
+
+// Test calendars CRUD, filter events by calendars and cascade deleting of calendar
+func TestCalendars(t *testing.T) {
+	calendar := NewStorage()
+	alice := calendar.ForOwner("alice")
+	bob := calendar.ForOwner("bob")
+
+	workId, err := alice.AddCalendar(context.Background(), entities.NewCalendar("work"))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	personalId, _ := alice.AddCalendar(context.Background(), entities.NewCalendar("personal"))
+	teamId, _ := bob.AddCalendar(context.Background(), entities.NewCalendar("team-X"))
+
+	err = alice.UpdateCalendar(context.Background(), personalId, entities.NewCalendar("home"))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	calendars, _ := alice.GetCalendars(context.Background())
+	expectedCalendars := []entities.Calendar{
+		entities.CalendarWithOwner(entities.CalendarWithId(entities.NewCalendar("work"), workId), "alice"),
+		entities.CalendarWithOwner(entities.CalendarWithId(entities.NewCalendar("home"), personalId), "alice"),
+	}
+	if !reflect.DeepEqual(calendars, expectedCalendars) {
+		t.Fatalf("calendars of alice must be %+v instead of %+v", expectedCalendars, calendars)
+	}
+
+	if _, err := alice.GetCalendar(context.Background(), teamId); err != entities.StorageErrorCalendarNotFound {
+		t.Errorf("calendar of other owner must not be found instead of error %v", err)
+	}
+	if err := alice.UpdateCalendar(context.Background(), teamId, entities.NewCalendar("mine")); err != entities.StorageErrorCalendarNotFound {
+		t.Errorf("update of calendar of other owner must not be found instead of error %v", err)
+	}
+
+	start := entities.NewDateTime(2019, 11, 25, 10, 0)
+	end := entities.NewDateTime(2019, 11, 25, 11, 0)
+
+	// event can't be added in calendar of other owner
+	_, err = alice.AddEvent(context.Background(), entities.WithCalendarId(entities.NewEvent("Standup", start, end), teamId))
+	if err != entities.StorageErrorCalendarNotFound {
+		t.Errorf("adding event in calendar of other owner must be error instead of %v", err)
+	}
+
+	workEventId, _ := alice.AddEvent(context.Background(), entities.WithCalendarId(entities.NewEvent("Meeting", start, end), workId))
+	homeEventId, _ := alice.AddEvent(context.Background(), entities.WithCalendarId(entities.NewEvent("Dinner", entities.NewDateTime(2019, 11, 25, 18, 0), entities.NewDateTime(2019, 11, 25, 19, 0)), personalId))
+	freeEventId, _ := alice.AddEvent(context.Background(), entities.NewEvent("Walk", entities.NewDateTime(2019, 11, 25, 12, 0), entities.NewDateTime(2019, 11, 25, 13, 0)))
+
+	names := func(events []entities.Event) []string {
+		var names []string
+		for _, event := range events {
+			names = append(names, event.Name())
+		}
+		return names
+	}
+
+	events, _ := alice.GetEventsByPeriod(context.Background(), nil, nil)
+	if !reflect.DeepEqual(names(events), []string{"Meeting", "Walk", "Dinner"}) {
+		t.Errorf("without filter all events must be got instead of %v", names(events))
+	}
+
+	events, _ = alice.GetEventsByPeriod(context.Background(), nil, nil, workId)
+	if !reflect.DeepEqual(names(events), []string{"Meeting"}) {
+		t.Errorf("only events of work calendar must be got instead of %v", names(events))
+	}
+
+	events, _ = alice.GetEventsByPeriod(context.Background(), &start, nil, workId, personalId)
+	if !reflect.DeepEqual(names(events), []string{"Meeting", "Dinner"}) {
+		t.Errorf("only events of work and home calendars must be got instead of %v", names(events))
+	}
+
+	// move event to other calendar
+	walk, _ := alice.GetEvent(context.Background(), freeEventId)
+	err = alice.UpdateEvent(context.Background(), freeEventId, entities.WithCalendarId(walk, personalId))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	walk, _ = alice.GetEvent(context.Background(), freeEventId)
+	if walk.CalendarId() != personalId {
+		t.Errorf("event must be moved in calendar %d instead of %d", personalId, walk.CalendarId())
+	}
+	err = alice.UpdateEvent(context.Background(), freeEventId, entities.WithCalendarId(walk, teamId))
+	if err != entities.StorageErrorCalendarNotFound {
+		t.Errorf("moving event in calendar of other owner must be error instead of %v", err)
+	}
+
+	// delete calendar with events, including event in trash
+	_ = alice.DeleteEvent(context.Background(), homeEventId)
+
+	if err := bob.DeleteCalendar(context.Background(), personalId); err != entities.StorageErrorCalendarNotFound {
+		t.Errorf("deleting calendar of other owner must not be found instead of error %v", err)
+	}
+
+	err = alice.DeleteCalendar(context.Background(), personalId)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if _, err := alice.GetCalendar(context.Background(), personalId); err != entities.StorageErrorCalendarNotFound {
+		t.Errorf("deleted calendar must not be found instead of error %v", err)
+	}
+
+	events, _ = alice.GetAllEvents(context.Background())
+	if len(events) != 1 || events[0].Id() != workEventId {
+		t.Errorf("only event of work calendar must be left instead of %v", names(events))
+	}
+
+	trash, _ := alice.GetTrashedEvents(context.Background())
+	if len(trash) != 0 {
+		t.Errorf("trashed event of deleted calendar must be deleted instead of %v", names(trash))
+	}
+}
+
+// Test responses of attendees and invitations to send
+func TestAttendees(t *testing.T) {
+	calendar := NewStorage()
+
+	event := entities.NewEvent("Meeting",
+		entities.NewDateTime(2019, 11, 25, 10, 0),
+		entities.NewDateTime(2019, 11, 25, 11, 0),
+	)
+	event = entities.WithAttendees(event, []string{"alice@example.com", "bob@example.com"})
+
+	id, err := calendar.AddEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	now := time.Date(2019, 11, 25, 9, 0, 0, 0, time.UTC)
+	invitations, err := calendar.GetInvitationsToSend(context.Background(), now)
+	if err != nil || len(invitations) != 2 {
+		t.Fatalf("must be 2 invitations instead of %+v, error %v", invitations, err)
+	}
+
+	for _, invitation := range invitations {
+		err = calendar.MarkAttendeeAsInvited(context.Background(), invitation.Id(), invitation.Attendee().Email(), invitation.Start(), invitation.End())
+		if err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+	}
+
+	invitations, _ = calendar.GetInvitationsToSend(context.Background(), now)
+	if len(invitations) != 0 {
+		t.Fatalf("must be no invitations after marking instead of %+v", invitations)
+	}
+
+	err = calendar.RespondToInvitation(context.Background(), id, "alice@example.com", entities.AttendeeStatusAccepted)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	err = calendar.RespondToInvitation(context.Background(), id, "alice@example.com", "maybe")
+	if err != entities.ErrInvalidAttendeeStatus {
+		t.Errorf("must be ErrInvalidAttendeeStatus instead of %v", err)
+	}
+
+	err = calendar.RespondToInvitation(context.Background(), id, "carol@example.com", entities.AttendeeStatusAccepted)
+	if err != entities.StorageErrorAttendeeNotFound {
+		t.Errorf("must be StorageErrorAttendeeNotFound instead of %v", err)
+	}
+
+	dbEvent, _ := calendar.GetEvent(context.Background(), id)
+	if dbEvent.Version() != 1 {
+		t.Errorf("response must not change version of event, got %d", dbEvent.Version())
+	}
+
+	// organizer changes time and attendees, response of alice is kept
+	moved := entities.NewEvent("Meeting",
+		entities.NewDateTime(2019, 11, 25, 12, 0),
+		entities.NewDateTime(2019, 11, 25, 13, 0),
+	)
+	moved = entities.WithAttendees(moved, []string{"alice@example.com", "carol@example.com"})
+	err = calendar.UpdateEvent(context.Background(), id, moved)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	dbEvent, _ = calendar.GetEvent(context.Background(), id)
+	var statuses []string
+	for _, attendee := range dbEvent.AttendeeList() {
+		statuses = append(statuses, attendee.Email()+" "+attendee.Status())
+	}
+	expectedStatuses := []string{"alice@example.com accepted", "carol@example.com needs-action"}
+	if !reflect.DeepEqual(statuses, expectedStatuses) {
+		t.Errorf("attendees must be %v instead of %v", expectedStatuses, statuses)
+	}
+
+	invitations, _ = calendar.GetInvitationsToSend(context.Background(), now)
+	if len(invitations) != 2 || !invitations[0].IsUpdate() || invitations[1].IsUpdate() {
+		t.Errorf("must be update for alice and invitation of carol instead of %+v", invitations)
+	}
+
+	for _, invitation := range invitations {
+		_ = calendar.MarkAttendeeAsInvited(context.Background(), invitation.Id(), invitation.Attendee().Email(), invitation.Start(), invitation.End())
+	}
+
+	// organizer changes only end of event, alice and carol get updates
+	moved = entities.WithAttendees(entities.NewEvent("Meeting", moved.Start(), moved.End().PlusMinutes(30)), []string{"alice@example.com", "carol@example.com"})
+	_ = calendar.UpdateEvent(context.Background(), id, moved)
+	invitations, _ = calendar.GetInvitationsToSend(context.Background(), now)
+	if len(invitations) != 2 || !invitations[0].IsUpdate() || !invitations[1].IsUpdate() {
+		t.Errorf("must be updates for alice and carol about changed end instead of %+v", invitations)
+	}
+
+	// invitations of event that is over are not sent
+	invitations, _ = calendar.GetInvitationsToSend(context.Background(), time.Date(2019, 11, 25, 13, 31, 0, 0, time.UTC))
+	if len(invitations) != 0 {
+		t.Errorf("must be no invitations of event that is over instead of %+v", invitations)
+	}
+
+	entries, _ := calendar.GetEventHistory(context.Background(), id)
+	if len(entries) != 4 || entries[1].Action() != entities.AuditActionRespond {
+		t.Errorf("response must be recorded into history instead of %+v", entries)
+	}
+}
+
+// Test that operations are not started with cancelled context
+func TestCancelledContext(t *testing.T) {
+	calendar := NewStorage()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	event := entities.NewEvent("Meeting",
+		entities.NewDateTime(2019, 11, 25, 10, 0),
+		entities.NewDateTime(2019, 11, 25, 11, 0),
+	)
+
+	_, err := calendar.AddEvent(ctx, event)
+	if err != context.Canceled {
+		t.Errorf("must be context.Canceled instead of %v", err)
+	}
+
+	count, _ := calendar.Count(context.Background())
+	if count != 0 {
+		t.Errorf("event must not be added with cancelled context, got %d events", count)
+	}
+
+	_, err = calendar.GetAllEvents(ctx)
+	if err != context.Canceled {
+		t.Errorf("must be context.Canceled instead of %v", err)
+	}
+}
+
+// Pages of all events and of events in period give the same events as not paged queries, ordered by start and id
+func TestEventsPages(t *testing.T) {
+	ctx := context.Background()
+	rnd := rand.New(rand.NewSource(1))
+	calendar := NewStorage()
+
+	for i := 0; i < 300; i++ {
+		_, _ = calendar.AddEvent(ctx, newRandomEvent(rnd, i))
+	}
+
+	start := entities.NewDateTime(2019, 11, 10, 0, 0)
+	end := entities.NewDateTime(2019, 12, 10, 0, 0)
+
+	allEvents, _ := calendar.GetAllEvents(ctx)
+	periodEvents, _ := calendar.GetEventsByPeriod(ctx, &start, &end)
+
+	queries := map[string]struct {
+		expected []entities.Event
+		getPage  func(limit int, cursor string) (entities.EventPage, error)
+	}{
+		"all": {allEvents, func(limit int, cursor string) (entities.EventPage, error) {
+			return calendar.GetAllEventsPage(ctx, limit, cursor)
+		}},
+		"period": {periodEvents, func(limit int, cursor string) (entities.EventPage, error) {
+			return calendar.GetEventsByPeriodPage(ctx, &start, &end, limit, cursor)
+		}},
+	}
+
+	for name, query := range queries {
+		entities.SortEventsByStartAndId(query.expected)
+
+		for _, limit := range []int{1, 7, 50, len(query.expected), 0} {
+			var events []entities.Event
+			cursor := ""
+			for pages := 0; ; pages++ {
+				if pages > len(query.expected) {
+					t.Fatalf("%s: pages with limit %d must end", name, limit)
+				}
+				page, err := query.getPage(limit, cursor)
+				if err != nil {
+					t.Fatalf("%s: must not be error instead of %s", name, err)
+				}
+				if page.NextCursor != "" && len(page.Events) != limit {
+					t.Errorf("%s: not last page must have %d events instead of %d", name, limit, len(page.Events))
+				}
+				events = append(events, page.Events...)
+				if page.NextCursor == "" {
+					break
+				}
+				cursor = page.NextCursor
+			}
+
+			if !reflect.DeepEqual(events, query.expected) {
+				t.Errorf("%s: pages with limit %d must have %d events instead of %d", name, limit, len(query.expected), len(events))
+			}
+		}
+	}
+
+	_, err := calendar.GetAllEventsPage(ctx, 10, "invalid")
+	if err != entities.ErrInvalidCursor {
+		t.Errorf("must be ErrInvalidCursor instead of %v", err)
+	}
+}
+
+func TestSearchEvents(t *testing.T) {
+	ctx := context.Background()
+	calendar := NewStorage()
+
+	add := func(name string, day int) int {
+		start := entities.NewDateTime(2019, 11, day, 10, 0)
+		id, _ := calendar.AddEvent(ctx, entities.NewEvent(name, start, start.PlusMinutes(60)))
+		return id
+	}
+
+	retro41 := add("Retro of sprint 41", 1)
+	retro := add("RETRO", 8)
+	retro42 := add("Retro of sprint 42", 15)
+	planning := add("Sprint planning", 15)
+	deleted := add("Retro of sprint 40", 1)
+	_ = calendar.DeleteEvent(ctx, deleted)
+
+	search := func(calendar entities.Storage, query string, start *entities.DateTime, end *entities.DateTime, limit int) []int {
+		events, err := calendar.(entities.Searcher).SearchEvents(ctx, query, start, end, limit)
+		if err != nil {
+			t.Fatalf("must not be error instead of %s", err)
+		}
+		ids := []int{}
+		for _, event := range events {
+			ids = append(ids, event.Id())
+		}
+		return ids
+	}
+
+	tests := []struct {
+		query    string
+		start    *entities.DateTime
+		end      *entities.DateTime
+		limit    int
+		expected []int
+	}{
+		{"retro", nil, nil, 0, []int{retro, retro42, retro41}},
+		{"Sprint RETRO", nil, nil, 0, []int{retro42, retro41}},
+		{"sprint", nil, nil, 0, []int{planning, retro42, retro41}},
+		{"retro", nil, nil, 2, []int{retro, retro42}},
+		{"retrospective", nil, nil, 0, []int{}},
+		{"retro planning", nil, nil, 0, []int{}},
+	}
+
+	start := entities.NewDateTime(2019, 11, 5, 0, 0)
+	end := entities.NewDateTime(2019, 11, 10, 0, 0)
+	tests = append(tests, struct {
+		query    string
+		start    *entities.DateTime
+		end      *entities.DateTime
+		limit    int
+		expected []int
+	}{"retro", &start, &end, 0, []int{retro}})
+
+	for _, test := range tests {
+		ids := search(calendar, test.query, test.start, test.end, test.limit)
+		if !reflect.DeepEqual(ids, test.expected) {
+			t.Errorf("search of %q must find %v instead of %v", test.query, test.expected, ids)
+		}
+	}
+
+	// renamed event is found by new name only
+	_ = calendar.UpdateEvent(ctx, planning, entities.NewEvent("Sprint retro",
+		entities.NewDateTime(2019, 11, 15, 12, 0),
+		entities.NewDateTime(2019, 11, 15, 13, 0),
+	))
+	if ids := search(calendar, "planning", nil, nil, 0); len(ids) != 0 {
+		t.Errorf("renamed event must not be found by old name, found %v", ids)
+	}
+	if ids := search(calendar, "sprint retro", nil, nil, 1); !reflect.DeepEqual(ids, []int{planning}) {
+		t.Errorf("renamed event must be found by new name, found %v", ids)
+	}
+
+	_, _ = calendar.ForOwner("alice").AddEvent(ctx, entities.NewEvent("Retro of alice",
+		entities.NewDateTime(2019, 11, 2, 10, 0),
+		entities.NewDateTime(2019, 11, 2, 11, 0),
+	))
+	if ids := search(calendar.ForOwner("alice"), "retro", nil, nil, 0); len(ids) != 1 {
+		t.Errorf("view of owner must find only events of owner, found %v", ids)
+	}
+
+	_, err := calendar.SearchEvents(ctx, " ,. ", nil, nil, 0)
+	if err != entities.ErrEmptySearchQuery {
+		t.Errorf("must be ErrEmptySearchQuery instead of %v", err)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) (entities.Storage, func()) {
+		return NewStorage(), nil
+	})
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+)
+
+// Run fn with transactional view of storage
+// View works on overlay of data: it sees data of storage with its own changes, changes of view don't touch storage,
+// so beginning of transaction costs O(1) and changes of view cost as changes of storage
+// Changes of view are recorded and applied to storage at once when fn succeeds,
+// so they are written to journal of storage by one write and other views see all of them or none
+// Storage is locked until fn returns, so transactions are serialized and other calls of storage wait for commit,
+// fn must use only tx (not storage itself) or it is deadlocked
+func (calendar *Storage) WithTx(ctx context.Context, fn func(tx entities.Storage) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	calendar.mx.Lock()
+	defer calendar.mx.Unlock()
+
+	recorder := &changeRecorder{}
+	tx := &Storage{
+		storageData: calendar.storageData.overlay(recorder),
+		owner:       calendar.owner,
+	}
+
+	err := fn(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return calendar.apply(recorder.changes)
+}
+
+// Journal of transactional view, it records changes to apply them to storage on commit
+type changeRecorder struct {
+	changes []Change
+}
+
+func (recorder *changeRecorder) Write(changes []Change) error {
+	recorder.changes = append(recorder.changes, changes...)
+	return nil
+}
+
+// Overlay of data with own lock and journal, data must not be changed while overlay is used
+// Must be called under lock
+func (data *storageData) overlay(journal Journal) *storageData {
+	return &storageData{
+		events:        data.events.overlay(),
+		trash:         data.trash.overlay(),
+		audit:         data.audit.overlay(),
+		calendars:     data.calendars.overlay(),
+		autoincrement: data.autoincrement,
+		calendarSeq:   data.calendarSeq,
+		journal:       journal,
+		index:         data.index.overlay(),
+		claims:        data.claims, // claims are not transactional
+		inviteClaims:  data.inviteClaims,
+	}
+}
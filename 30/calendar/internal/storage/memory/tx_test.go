@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+)
+
+func TestWithTx(t *testing.T) {
+	ctx := context.Background()
+	calendar := NewStorage()
+	journal := &testJournal{}
+	calendar.journal = journal
+
+	event := entities.NewEvent("Meeting", entities.NewDateTime(2019, 11, 25, 10, 0), entities.NewDateTime(2019, 11, 25, 11, 0))
+	deletedId, _ := calendar.AddEvent(ctx, event)
+	start, end := entities.NewDateTime(2019, 11, 25, 0, 0), entities.NewDateTime(2019, 11, 26, 0, 0)
+	journal.writes = 0
+
+	var createdId int
+	err := calendar.WithTx(ctx, func(tx entities.Storage) error {
+		var err error
+		createdId, err = tx.AddEvent(ctx, entities.NewEvent("Retro", event.Start(), event.End()))
+		if err != nil {
+			return err
+		}
+		if err := tx.DeleteEvent(ctx, deletedId); err != nil {
+			return err
+		}
+
+		events, _ := tx.GetEventsByPeriod(ctx, &start, &end)
+		if len(events) != 1 || events[0].Id() != createdId {
+			t.Errorf("transaction must see only its own event instead of %+v", events)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("transaction must be committed, got %s", err)
+	}
+
+	if _, err := calendar.GetEvent(ctx, createdId); err != nil {
+		t.Errorf("created event must be visible after commit, got %s", err)
+	}
+	if _, err := calendar.GetEvent(ctx, deletedId); err != entities.StorageErrorEventNotFound {
+		t.Errorf("deleted event must not be visible after commit, got %v", err)
+	}
+	events, _ := calendar.GetEventsByPeriod(ctx, &start, &end)
+	if len(events) != 1 || events[0].Id() != createdId {
+		t.Errorf("index must have only created event instead of %+v", events)
+	}
+	if journal.writes != 1 {
+		t.Errorf("changes of transaction must be written to journal by 1 write instead of %d", journal.writes)
+	}
+	if history, _ := calendar.GetEventHistory(ctx, createdId); len(history) != 1 {
+		t.Errorf("history of created event must have 1 entry instead of %+v", history)
+	}
+}
+
+func TestWithTxRollback(t *testing.T) {
+	ctx := context.Background()
+	calendar := NewStorage()
+
+	event := entities.NewEvent("Meeting", entities.NewDateTime(2019, 11, 25, 10, 0), entities.NewDateTime(2019, 11, 25, 11, 0))
+	id, _ := calendar.AddEvent(ctx, event)
+
+	txErr := errors.New("test error")
+	err := calendar.WithTx(ctx, func(tx entities.Storage) error {
+		if _, err := tx.AddEvent(ctx, event); err != nil {
+			return err
+		}
+		if err := tx.UpdateEvent(ctx, id, entities.WithDescription(event, "Retro")); err != nil {
+			return err
+		}
+		if _, err := tx.AddCalendar(ctx, entities.NewCalendar("Work")); err != nil {
+			return err
+		}
+		return txErr
+	})
+	if err != txErr {
+		t.Fatalf("error of transaction must be returned instead of %v", err)
+	}
+
+	events, _ := calendar.GetAllEvents(ctx)
+	if len(events) != 1 || events[0].Description() != "" {
+		t.Errorf("storage must have only not updated event instead of %+v", events)
+	}
+	if calendars, _ := calendar.GetCalendars(ctx); len(calendars) != 0 {
+		t.Errorf("storage must not have calendars instead of %+v", calendars)
+	}
+	if history, _ := calendar.GetEventHistory(ctx, id); len(history) != 1 {
+		t.Errorf("history of event must have 1 entry instead of %+v", history)
+	}
+
+	newId, _ := calendar.AddEvent(ctx, event)
+	if newId != id+1 {
+		t.Errorf("id of new event must be %d instead of %d", id+1, newId)
+	}
+
+	ctxCancelled, cancel := context.WithCancel(ctx)
+	err = calendar.WithTx(ctxCancelled, func(tx entities.Storage) error {
+		_, err := tx.AddEvent(ctx, event)
+		cancel()
+		return err
+	})
+	if err != context.Canceled {
+		t.Errorf("transaction must fail with cancelled context instead of %v", err)
+	}
+	if count, _ := calendar.Count(ctx); count != 2 {
+		t.Errorf("storage must have 2 events instead of %d", count)
+	}
+}
+
+// Transaction sees storage through overlay with its own changes, storage doesn't see them until commit
+func TestWithTxOverlay(t *testing.T) {
+	ctx := context.Background()
+	calendar := NewStorage()
+
+	calendarId, _ := calendar.AddCalendar(ctx, entities.NewCalendar("Work"))
+	start := entities.NewDateTime(2019, 11, 25, 10, 0)
+	planningId, _ := calendar.AddEvent(ctx, entities.NewEvent("Sprint planning", start, start.PlusMinutes(60)))
+	reviewId, _ := calendar.AddEvent(ctx, entities.NewEvent("Sprint review", start.PlusMinutes(120), start.PlusMinutes(180)))
+
+	txErr := errors.New("test error")
+	err := calendar.WithTx(ctx, func(tx entities.Storage) error {
+		searcher := tx.(entities.Searcher)
+		if err := tx.DeleteEvent(ctx, planningId); err != nil {
+			return err
+		}
+		if err := tx.UpdateEvent(ctx, reviewId, entities.NewEvent("Demo", start, start.PlusMinutes(60))); err != nil {
+			return err
+		}
+		if err := tx.DeleteCalendar(ctx, calendarId); err != nil {
+			return err
+		}
+
+		if count, _ := tx.Count(ctx); count != 1 {
+			t.Errorf("transaction must see 1 event instead of %d", count)
+		}
+		if found, _ := searcher.SearchEvents(ctx, "sprint", nil, nil, 0); len(found) != 0 {
+			t.Errorf("transaction must not find events by old names instead of %+v", found)
+		}
+		if found, _ := searcher.SearchEvents(ctx, "demo", nil, nil, 0); len(found) != 1 || found[0].Id() != reviewId {
+			t.Errorf("transaction must find updated event by new name instead of %+v", found)
+		}
+		if trashed, _ := tx.GetTrashedEvents(ctx); len(trashed) != 1 || trashed[0].Id() != planningId {
+			t.Errorf("transaction must see deleted event in trash instead of %+v", trashed)
+		}
+		if calendars, _ := tx.GetCalendars(ctx); len(calendars) != 0 {
+			t.Errorf("transaction must not see deleted calendar instead of %+v", calendars)
+		}
+		return txErr
+	})
+	if err != txErr {
+		t.Fatalf("error of transaction must be returned instead of %v", err)
+	}
+
+	if count, _ := calendar.Count(ctx); count != 2 {
+		t.Errorf("storage must have 2 events instead of %d", count)
+	}
+	if found, _ := calendar.SearchEvents(ctx, "sprint", nil, nil, 0); len(found) != 2 {
+		t.Errorf("storage must find 2 events by name instead of %+v", found)
+	}
+	if trashed, _ := calendar.GetTrashedEvents(ctx); len(trashed) != 0 {
+		t.Errorf("storage must have empty trash instead of %+v", trashed)
+	}
+	if calendars, _ := calendar.GetCalendars(ctx); len(calendars) != 1 {
+		t.Errorf("storage must have 1 calendar instead of %+v", calendars)
+	}
+}
+
+// Duration of small transaction must not depend on number of events in storage
+func BenchmarkWithTx(b *testing.B) {
+	ctx := context.Background()
+	start := entities.NewDateTime(2020, 1, 5, 10, 0)
+
+	for _, n := range []int{1000, 10000, 100000} {
+		calendar := newBenchmarkStorage(b, n)
+		b.Run(fmt.Sprintf("events=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				err := calendar.WithTx(ctx, func(tx entities.Storage) error {
+					id, err := tx.AddEvent(ctx, entities.NewEvent("Event", start, start.PlusMinutes(60)))
+					if err != nil {
+						return err
+					}
+					return tx.DeleteEvent(ctx, id)
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
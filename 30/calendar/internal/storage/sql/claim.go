@@ -0,0 +1,344 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+)
+
+// Reminder or attendee claimed by scheduler
+// Expired and ClaimedStart are state of claim of reminder before it is claimed again
+type ClaimRow struct {
+	EventId       int    `db:"event_id"`
+	BeforeMinutes int    `db:"before_minutes"`
+	Email         string `db:"email"`
+	Expired       bool   `db:"expired"`       // reminder is claimed again because claim is expired
+	ClaimedStart  string `db:"claimed_start"` // start of the latest claimed occurrence of recurring event, empty if it is unknown
+}
+
+// Due reminders are claimed by one statement: rows are locked with SKIP LOCKED, so concurrent claims skip rows of each other
+// and rows claimed by committed concurrent claim are rechecked and skipped too
+// Lease of claim is kept in claimed_until column of reminders, it is reset when reminders of event are replaced by update
+// Reminder of recurring event is claimed with all its occurrences due in period, start of the latest of them is kept
+// in claimed_start column in the same transaction, reminder is released at once if no occurrence is due
+// Expired claim of recurring reminder is retried by its claimed occurrence (whatever period is) and occurrences due in period,
+// it is released only if claimed occurrence is notified or is not occurrence of event anymore
+func (s *Storage) ClaimEventsToNotify(ctx context.Context, startTime *entities.DateTime, endTime *entities.DateTime, now time.Time, lease time.Duration) ([]entities.Notification, error) {
+	params := map[string]interface{}{
+		"now":          now.In(time.UTC).Format(timestampTzLayout),
+		"leased_until": now.Add(lease).In(time.UTC).Format(timestampTzLayout),
+	}
+
+	// reminders of expired claims of not recurring events are retried whatever their time is
+	where := []string{
+		fmt.Sprintf(
+			"((r.claimed_until IS NULL AND %s) OR (r.claimed_until <= :now AND (events.rrule IS NOT NULL OR r.notified_time IS NULL)))",
+			dueRemindersWhere(startTime, endTime, params),
+		),
+	}
+	where = s.activeWhere(where, params)
+
+	query := fmt.Sprintf(
+		`UPDATE reminders SET claimed_until = :leased_until
+				FROM (
+					SELECT r.event_id, r.before_minutes, r.claimed_until, r.claimed_start
+					FROM reminders r
+					JOIN events ON events.id = r.event_id
+					WHERE %s
+					FOR UPDATE OF r SKIP LOCKED
+				) claimed
+				WHERE reminders.event_id = claimed.event_id AND reminders.before_minutes = claimed.before_minutes
+				RETURNING reminders.event_id, reminders.before_minutes, claimed.claimed_until IS NOT NULL AS expired,
+					COALESCE(to_char(claimed.claimed_start AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24::MI::SS'), '') AS claimed_start`,
+		strings.Join(where, " AND "),
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+
+	defer cancel()
+
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// rollback after commit does nothing
+	defer func() { _ = tx.Rollback() }()
+
+	claims, err := s.claim(ctx, tx, query, params)
+	if err != nil || len(claims) == 0 {
+		return nil, err
+	}
+
+	// events are got after claim, so notifications are built by their current state
+	ids := make(map[int]bool)
+	eventParams := make(map[string]interface{})
+	var names []string
+	for _, claim := range claims {
+		if ids[claim.EventId] {
+			continue
+		}
+		ids[claim.EventId] = true
+		name := fmt.Sprintf("id_%d", len(names))
+		eventParams[name] = claim.EventId
+		names = append(names, ":"+name)
+	}
+
+	events, err := s.queryEvents(ctx, tx, buildSelectEventQuery(fmt.Sprintf("id IN (%s)", strings.Join(names, ", "))), eventParams)
+	if err != nil {
+		return nil, err
+	}
+
+	eventsById := make(map[int]entities.Event, len(events))
+	for _, event := range events {
+		eventsById[event.Id()] = event
+	}
+
+	var notifications []entities.Notification
+	var released []ClaimRow
+	claimedStarts := make(map[ClaimRow]entities.DateTime)
+	for _, claim := range claims {
+		event, ok := eventsById[claim.EventId]
+		if !ok {
+			continue
+		}
+		if event.IsRecurring() {
+			due, err := claimedOccurrences(event, claim, startTime, endTime)
+			if err != nil {
+				return nil, err
+			}
+			if len(due) == 0 {
+				released = append(released, claim)
+				continue
+			}
+			notifications = append(notifications, due...)
+			claimedStarts[claim] = due[len(due)-1].Start()
+			continue
+		}
+		for _, reminder := range event.Reminders() {
+			if reminder.BeforeMinutes() == claim.BeforeMinutes && !reminder.IsNotified() {
+				notifications = append(notifications, entities.NewNotification(event, reminder))
+			}
+		}
+	}
+
+	// reminder without due occurrences must not wait for expiration of its lease
+	if err := s.release(ctx, tx, released, params["leased_until"]); err != nil {
+		return nil, err
+	}
+
+	if err := s.keepClaimedStarts(ctx, tx, claimedStarts); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		if !notifications[i].Time().Equal(notifications[j].Time()) {
+			return notifications[i].Time().Less(notifications[j].Time())
+		}
+		return notifications[i].Id() < notifications[j].Id()
+	})
+
+	return notifications, nil
+}
+
+// Helper that build notifications of claimed reminder of recurring event sorted by start of occurrence:
+// occurrences due in period and, if claim is expired, not notified occurrence claimed by previous claim
+func claimedOccurrences(event entities.Event, claim ClaimRow, startTime *entities.DateTime, endTime *entities.DateTime) ([]entities.Notification, error) {
+	var due []entities.Notification
+	for _, notification := range event.NotificationsInPeriod(startTime, endTime) {
+		if notification.Reminder().BeforeMinutes() == claim.BeforeMinutes {
+			due = append(due, notification)
+		}
+	}
+
+	if !claim.Expired || claim.ClaimedStart == "" {
+		return due, nil
+	}
+
+	claimedStart, err := convertSqlDateTimeToEventTime(claim.ClaimedStart)
+	if err != nil {
+		return nil, err
+	}
+	for _, notification := range due {
+		if notification.Start().Equal(*claimedStart) {
+			return due, nil
+		}
+	}
+
+	// notification of claimed occurrence is built again only if it is still not notified occurrence of event
+	notifyTime := claimedStart.MinusMinutes(claim.BeforeMinutes)
+	for _, notification := range event.NotificationsInPeriod(&notifyTime, &notifyTime) {
+		if notification.Reminder().BeforeMinutes() == claim.BeforeMinutes && notification.Start().Equal(*claimedStart) {
+			due = append(due, notification)
+			sort.SliceStable(due, func(i, j int) bool {
+				return due[i].Start().Less(due[j].Start())
+			})
+			break
+		}
+	}
+
+	return due, nil
+}
+
+// Inner helper that keep start of the latest claimed occurrence of claimed reminders of recurring events
+func (s *Storage) keepClaimedStarts(ctx context.Context, e sqlx.ExtContext, claimedStarts map[ClaimRow]entities.DateTime) error {
+	if len(claimedStarts) == 0 {
+		return nil
+	}
+
+	params := make(map[string]interface{})
+	var rows []string
+	for claim, start := range claimedStarts {
+		i := len(rows)
+		params[fmt.Sprintf("event_id_%d", i)] = claim.EventId
+		params[fmt.Sprintf("before_minutes_%d", i)] = claim.BeforeMinutes
+		params[fmt.Sprintf("start_%d", i)] = convertEventTimeToSqlDateTime(start)
+		rows = append(rows, fmt.Sprintf(
+			"(CAST(:event_id_%d AS INT), CAST(:before_minutes_%d AS INT), CAST(:start_%d AS TIMESTAMPTZ))", i, i, i,
+		))
+	}
+
+	query := fmt.Sprintf(
+		`UPDATE reminders SET claimed_start = claimed.start
+				FROM (VALUES %s) AS claimed(event_id, before_minutes, start)
+				WHERE reminders.event_id = claimed.event_id AND reminders.before_minutes = claimed.before_minutes`,
+		strings.Join(rows, ", "),
+	)
+
+	_, err := sqlx.NamedExecContext(ctx, e, query, params)
+	return err
+}
+
+// Attendees are claimed by one statement with SKIP LOCKED as reminders (see ClaimEventsToNotify)
+// Lease of claim is kept in invite_claimed_until column of attendees, it is reset when attendee is marked as invited
+// and when attendees of event are replaced by update
+func (s *Storage) ClaimInvitationsToSend(ctx context.Context, now time.Time, lease time.Duration) ([]entities.Invitation, error) {
+	params := map[string]interface{}{
+		"now":          now.In(time.UTC).Format(timestampTzLayout),
+		"leased_until": now.Add(lease).In(time.UTC).Format(timestampTzLayout),
+	}
+
+	where := []string{
+		"(a.invited_start IS NULL OR a.invited_start <> events.start_time OR a.invited_end <> events.end_time)",
+		"(a.invite_claimed_until IS NULL OR a.invite_claimed_until <= :now)",
+		"(events.rrule IS NOT NULL OR events.end_time >= :now)",
+	}
+	where = s.activeWhere(where, params)
+
+	query := fmt.Sprintf(
+		`UPDATE attendees SET invite_claimed_until = :leased_until
+				FROM (
+					SELECT a.event_id, a.email
+					FROM attendees a
+					JOIN events ON events.id = a.event_id
+					WHERE %s
+					FOR UPDATE OF a SKIP LOCKED
+				) claimed
+				WHERE attendees.event_id = claimed.event_id AND attendees.email = claimed.email
+				RETURNING attendees.event_id, attendees.email`,
+		strings.Join(where, " AND "),
+	)
+
+	claims, err := s.claim(ctx, s.queryer(), query, params)
+	if err != nil || len(claims) == 0 {
+		return nil, err
+	}
+
+	// events are got after claim, so invitations are built by their current state
+	claimed := make(map[int]map[string]bool)
+	eventParams := make(map[string]interface{})
+	var names []string
+	for _, claim := range claims {
+		if _, ok := claimed[claim.EventId]; !ok {
+			claimed[claim.EventId] = make(map[string]bool)
+			name := fmt.Sprintf("id_%d", len(names))
+			eventParams[name] = claim.EventId
+			names = append(names, ":"+name)
+		}
+		claimed[claim.EventId][claim.Email] = true
+	}
+
+	query = buildSelectEventQuery(fmt.Sprintf("id IN (%s)", strings.Join(names, ", "))) + " ORDER BY id"
+	events, err := s.getEvents(ctx, query, eventParams)
+	if err != nil {
+		return nil, err
+	}
+
+	var invitations []entities.Invitation
+	for _, event := range events {
+		for _, invitation := range event.Invitations() {
+			if claimed[event.Id()][invitation.Attendee().Email()] {
+				invitations = append(invitations, invitation)
+			}
+		}
+	}
+
+	return invitations, nil
+}
+
+// Inner helper that release claims of reminders with lease leasedUntil, claims changed since then are kept
+func (s *Storage) release(ctx context.Context, e sqlx.ExtContext, claims []ClaimRow, leasedUntil interface{}) error {
+	if len(claims) == 0 {
+		return nil
+	}
+
+	params := map[string]interface{}{"leased_until": leasedUntil}
+	var rows []string
+	for i, claim := range claims {
+		params[fmt.Sprintf("event_id_%d", i)] = claim.EventId
+		params[fmt.Sprintf("before_minutes_%d", i)] = claim.BeforeMinutes
+		rows = append(rows, fmt.Sprintf("(:event_id_%d, :before_minutes_%d)", i, i))
+	}
+
+	query := fmt.Sprintf(
+		`UPDATE reminders SET claimed_until = NULL
+				WHERE claimed_until = :leased_until AND (event_id, before_minutes) IN (%s)`,
+		strings.Join(rows, ", "),
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+
+	defer cancel()
+
+	_, err := sqlx.NamedExecContext(ctx, e, query, params)
+	return err
+}
+
+// Inner helper that run claim statement and scan claimed reminders
+func (s *Storage) claim(ctx context.Context, e sqlx.ExtContext, query string, params map[string]interface{}) ([]ClaimRow, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+
+	defer cancel()
+
+	rows, err := sqlx.NamedQueryContext(ctx, e, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil && s.logger != nil {
+			s.logger.Errorf("error on rows.Close: %s\n", err)
+		}
+	}()
+
+	var claims []ClaimRow
+	for rows.Next() {
+		claim := ClaimRow{}
+		err := rows.StructScan(&claim)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, claim)
+	}
+
+	return claims, rows.Err()
+}
@@ -0,0 +1,334 @@
+package sql
+
+//go:generate go run ../../../tools/embedsql -dir ../../../sql -out migrations_gen.go -pkg sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Key of advisory lock that serializes migrations of concurrent replicas
+const migrationLockKey = "calendar.schema_version"
+
+var (
+	ErrSchemaTooOld       = errors.New("schema of db is too old")
+	ErrUnknownVersion     = errors.New("unknown schema version")
+	ErrIrreversibleChange = errors.New("migration can't be undone")
+)
+
+var migrationFileRegexp = regexp.MustCompile(`^([VU])(\d+)__(\w+)\.sql$`)
+
+// Versioned migration of schema, Down is empty if migration can't be undone
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// State of migration in db
+type MigrationStatus struct {
+	Migration
+	Applied     bool
+	AppliedTime time.Time // zero if migration is not applied
+}
+
+// Embedded migrations (see sql directory) sorted by version
+func Migrations() []Migration {
+	byVersion := make(map[int]*Migration)
+	for fileName, content := range migrationFiles {
+		match := migrationFileRegexp.FindStringSubmatch(fileName)
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[2])
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[3]}
+			byVersion[version] = migration
+		}
+		if match[1] == "V" {
+			migration.Up = content
+		} else {
+			migration.Down = content
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations
+}
+
+// Version of the latest embedded migration, storage requires schema of this version
+func LatestVersion() int {
+	migrations := Migrations()
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// Runner of embedded migrations
+// Applied versions are kept in schema_version table, every migration is applied in its own transaction
+// under advisory lock, so concurrent replicas don't race: replica that waited for lock sees already applied migration
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// Constructor
+func NewMigrator(cfg Config) (*Migrator, error) {
+	db, err := connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// migration could rewrite whole table, so there is no timeout of query, context of caller limits duration
+	return &Migrator{
+		db:         db,
+		migrations: Migrations(),
+	}, nil
+}
+
+// Close connection to db
+func (m *Migrator) Close() error {
+	return m.db.Close()
+}
+
+// Current version of schema, 0 for empty db
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	return schemaVersion(ctx, m.db)
+}
+
+// State of every embedded migration
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied := make(map[int]time.Time)
+
+	exists, err := tableExists(ctx, m.db, "schema_version")
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		var rows []struct {
+			Version     int    `db:"version"`
+			AppliedTime string `db:"applied_time"`
+		}
+		err = m.db.SelectContext(ctx, &rows,
+			`SELECT version, to_char(applied_time AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS') AS applied_time FROM schema_version`)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			appliedTime, err := time.Parse(datetimeLayout, row.AppliedTime)
+			if err != nil {
+				return nil, err
+			}
+			applied[row.Version] = appliedTime
+		}
+	} else {
+		// schema is not managed by migrator yet, it is baselined by the first migration
+		version, err := schemaVersion(ctx, m.db)
+		if err != nil {
+			return nil, err
+		}
+		for i := 1; i <= version; i++ {
+			applied[i] = time.Time{}
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedTime, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{
+			Migration:   migration,
+			Applied:     ok,
+			AppliedTime: appliedTime,
+		})
+	}
+	return statuses, nil
+}
+
+// Apply all not applied migrations, returns number of applied migrations
+// Schema newer than the latest embedded migration (e.g. migrated by newer replica) is kept as is
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if version >= LatestVersion() {
+		return 0, nil
+	}
+	return m.To(ctx, LatestVersion())
+}
+
+// Undo the last applied migration, returns number of undone migrations (0 for empty db)
+func (m *Migrator) Down(ctx context.Context) (int, error) {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if version == 0 {
+		return 0, nil
+	}
+	return m.To(ctx, version-1)
+}
+
+// Apply or undo migrations until schema has version, returns number of applied (or undone) migrations
+func (m *Migrator) To(ctx context.Context, version int) (int, error) {
+	if version < 0 || version > LatestVersion() {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	count := 0
+	for {
+		done, err := m.step(ctx, version)
+		if err != nil {
+			return count, err
+		}
+		if done {
+			return count, nil
+		}
+		count++
+	}
+}
+
+// Apply (or undo) one migration toward version in transaction under advisory lock
+// Returns true if schema already has version
+func (m *Migrator) step(ctx context.Context, version int) (bool, error) {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, migrationLockKey)
+	if err != nil {
+		return false, err
+	}
+
+	err = ensureSchemaVersionTable(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+
+	current, err := schemaVersion(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	if current == version {
+		// baseline of schema_version table is kept
+		return true, tx.Commit()
+	}
+
+	if current < version {
+		migration, ok := m.migration(current + 1)
+		if !ok {
+			return false, fmt.Errorf("%w: %d", ErrUnknownVersion, current+1)
+		}
+		_, err = tx.ExecContext(ctx, migration.Up)
+		if err != nil {
+			return false, fmt.Errorf("failed to apply migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO schema_version(version, name) VALUES($1, $2)`, migration.Version, migration.Name)
+		if err != nil {
+			return false, err
+		}
+	} else {
+		migration, ok := m.migration(current)
+		if !ok {
+			return false, fmt.Errorf("%w: %d", ErrUnknownVersion, current)
+		}
+		if migration.Down == "" {
+			return false, fmt.Errorf("%w: %d %s", ErrIrreversibleChange, migration.Version, migration.Name)
+		}
+		_, err = tx.ExecContext(ctx, migration.Down)
+		if err != nil {
+			return false, fmt.Errorf("failed to undo migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_version WHERE version = $1`, migration.Version)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return false, tx.Commit()
+}
+
+func (m *Migrator) migration(version int) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// Create schema_version table if it doesn't exist
+// Schema that was migrated by flyway before is baselined with versions from flyway_schema_history table
+func ensureSchemaVersionTable(ctx context.Context, tx *sqlx.Tx) error {
+	exists, err := tableExists(ctx, tx, "schema_version")
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `CREATE TABLE schema_version (
+		version INT PRIMARY KEY,
+		name VARCHAR(256) NOT NULL,
+		applied_time TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return err
+	}
+
+	flyway, err := tableExists(ctx, tx, "flyway_schema_history")
+	if err != nil || !flyway {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO schema_version(version, name, applied_time)
+		SELECT version::int, replace(description, ' ', '_'), installed_on
+		FROM flyway_schema_history
+		WHERE success AND version IS NOT NULL`)
+	return err
+}
+
+// Current version of schema, version of flyway is used if schema is not managed by migrator yet, 0 for empty db
+func schemaVersion(ctx context.Context, q sqlx.QueryerContext) (int, error) {
+	query := `SELECT COALESCE(MAX(version), 0) FROM schema_version`
+
+	exists, err := tableExists(ctx, q, "schema_version")
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		exists, err = tableExists(ctx, q, "flyway_schema_history")
+		if err != nil || !exists {
+			return 0, err
+		}
+		query = `SELECT COALESCE(MAX(version::int), 0) FROM flyway_schema_history WHERE success AND version IS NOT NULL`
+	}
+
+	var version int
+	err = sqlx.GetContext(ctx, q, &version, query)
+	return version, err
+}
+
+func tableExists(ctx context.Context, q sqlx.QueryerContext, table string) (bool, error) {
+	var exists bool
+	err := sqlx.GetContext(ctx, q, &exists, `SELECT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1)`, table)
+	return exists, err
+}
@@ -0,0 +1,174 @@
+package sql
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// Embedded migrations must be regenerated after change of sql directory
+func TestEmbeddedMigrations(t *testing.T) {
+	infos, err := ioutil.ReadDir("../../../sql")
+	if err != nil {
+		t.Fatalf("can't read sql directory %s", err)
+	}
+
+	count := 0
+	for _, info := range infos {
+		if !migrationFileRegexp.MatchString(info.Name()) {
+			continue
+		}
+		count++
+
+		content, err := ioutil.ReadFile(filepath.Join("../../../sql", info.Name()))
+		if err != nil {
+			t.Fatalf("can't read migration %s", err)
+		}
+
+		if embedded, ok := migrationFiles[info.Name()]; !ok || embedded != string(content) {
+			t.Errorf("migration %s must be embedded as is, run go generate", info.Name())
+		}
+	}
+
+	if count != len(migrationFiles) {
+		t.Errorf("must be %d embedded migrations instead of %d, run go generate", count, len(migrationFiles))
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	migrations := Migrations()
+	if len(migrations) == 0 {
+		t.Fatal("must be embedded migrations")
+	}
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Errorf("version of migration %s must be %d instead of %d", migration.Name, i+1, migration.Version)
+		}
+		if migration.Up == "" {
+			t.Errorf("migration %d must have V script", migration.Version)
+		}
+		if migration.Down == "" {
+			t.Errorf("migration %d must have U script", migration.Version)
+		}
+	}
+
+	if latest := LatestVersion(); latest != len(migrations) {
+		t.Errorf("latest version must be %d instead of %d", len(migrations), latest)
+	}
+}
+
+// Test migrations on actual DB, db is migrated to the latest version after test
+func TestMigrator(t *testing.T) {
+
+	if config.skip {
+		t.SkipNow()
+	}
+
+	ctx := context.Background()
+
+	migrator, err := NewMigrator(*config.dbConfig)
+	if err != nil {
+		t.Fatalf("can't init migrator %s", err)
+	}
+	defer func() {
+		_ = migrator.Close()
+	}()
+
+	_, err = migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("can't migrate db up %s", err)
+	}
+
+	latest := LatestVersion()
+	if version, _ := migrator.Version(ctx); version != latest {
+		t.Fatalf("version must be %d instead of %d", latest, version)
+	}
+
+	count, err := migrator.Down(ctx)
+	if err != nil || count != 1 {
+		t.Fatalf("must be undone 1 migration instead of %d, error %v", count, err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("can't get status %s", err)
+	}
+	for _, status := range statuses {
+		if status.Applied != (status.Version < latest) {
+			t.Errorf("applied of migration %d must be %t", status.Version, status.Version < latest)
+		}
+	}
+
+	// concurrent replicas apply migration only once
+	counts := make([]int, 3)
+	errs := make([]error, 3)
+	wg := sync.WaitGroup{}
+	for i := range counts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			counts[i], errs[i] = migrator.Up(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	for i := range counts {
+		if errs[i] != nil {
+			t.Errorf("concurrent migration must not fail, got %s", errs[i])
+		}
+		total += counts[i]
+	}
+	if total != 1 {
+		t.Errorf("migration must be applied 1 time instead of %d", total)
+	}
+
+	if version, _ := migrator.Version(ctx); version != latest {
+		t.Errorf("version must be %d instead of %d", latest, version)
+	}
+
+	_, err = migrator.To(ctx, latest+1)
+	if err == nil {
+		t.Errorf("unknown version must be error")
+	}
+}
+
+func TestNewStorageCheckSchema(t *testing.T) {
+
+	if config.skip {
+		t.SkipNow()
+	}
+
+	cfg := *config.dbConfig
+	cfg.CheckSchema = true
+
+	storage, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("storage must start on migrated db, got %s", err)
+	}
+	_ = storage.db.Close()
+}
+
+func TestNewConfigCheckSchema(t *testing.T) {
+	m := map[string]string{"host": "localhost", "port": "5432", "dbname": "calendar", "user": "otus", "password": "1234"}
+
+	cfg, err := NewConfig(m)
+	if err != nil || cfg.CheckSchema {
+		t.Fatalf("schema must not be checked by default, error %v", err)
+	}
+
+	m["check_schema"] = "true"
+	cfg, err = NewConfig(m)
+	if err != nil || !cfg.CheckSchema {
+		t.Errorf("schema must be checked, error %v", err)
+	}
+
+	m["check_schema"] = "sometimes"
+	_, err = NewConfig(m)
+	if err == nil {
+		t.Errorf("must be error for invalid check_schema")
+	}
+}
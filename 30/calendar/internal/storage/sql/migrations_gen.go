@@ -0,0 +1,202 @@
+// Code generated by tools/embedsql from ../../../sql; DO NOT EDIT.
+
+package sql
+
+// Content of migration files by file name
+var migrationFiles = map[string]string{
+	"U10__Audit.sql": `DROP TABLE audit;
+`,
+	"U11__Calendars.sql": `DROP INDEX calendar_start_idx;
+ALTER TABLE events DROP COLUMN calendar_id;
+DROP TABLE calendars;
+`,
+	"U12__Attendees.sql": `ALTER TABLE events ADD COLUMN attendees TEXT NOT NULL DEFAULT '';
+
+-- responses of attendees are lost
+UPDATE events e SET attendees = a.emails
+    FROM (
+        SELECT event_id, string_agg(email, ',' ORDER BY position) AS emails
+        FROM attendees
+        GROUP BY event_id
+    ) a
+    WHERE a.event_id = e.id;
+DROP TABLE attendees;
+`,
+	"U13__Search.sql": `DROP INDEX events_name_search_idx;
+`,
+	"U14__Claims.sql": `DROP INDEX reminders_claimed_until_idx;
+ALTER TABLE reminders DROP COLUMN claimed_until;
+`,
+	"U15__OccurrenceReminders.sql": `ALTER TABLE reminders DROP COLUMN notified_start;
+`,
+	"U16__InvitedEnd.sql": `ALTER TABLE attendees DROP COLUMN invited_end;
+`,
+	"U17__InviteClaims.sql": `ALTER TABLE attendees DROP COLUMN invite_claimed_until;
+`,
+	"U18__ClaimedStart.sql": `ALTER TABLE reminders DROP COLUMN claimed_start;
+`,
+	"U1__Initial.sql": `DROP TABLE events;
+`,
+	"U2__Recurrence.sql": `DROP INDEX rrule_start_idx;
+ALTER TABLE events DROP COLUMN exdates;
+ALTER TABLE events DROP COLUMN rrule;
+`,
+	"U3__AllDay.sql": `DROP INDEX all_day_idx;
+ALTER TABLE events DROP COLUMN end_date;
+ALTER TABLE events DROP COLUMN start_date;
+`,
+	"U4__Timezone.sql": `ALTER TABLE events DROP COLUMN timezone;
+ALTER TABLE events ALTER COLUMN end_time TYPE TIMESTAMP USING end_time AT TIME ZONE 'UTC';
+ALTER TABLE events ALTER COLUMN start_time TYPE TIMESTAMP USING start_time AT TIME ZONE 'UTC';
+`,
+	"U5__Details.sql": `ALTER TABLE events DROP COLUMN color;
+ALTER TABLE events DROP COLUMN attendees;
+ALTER TABLE events DROP COLUMN organizer;
+ALTER TABLE events DROP COLUMN location;
+ALTER TABLE events DROP COLUMN description;
+`,
+	"U6__Owner.sql": `DROP INDEX owner_start_idx;
+ALTER TABLE events DROP COLUMN owner;
+`,
+	"U7__Reminders.sql": `ALTER TABLE events ADD COLUMN before_minutes INT NULL DEFAULT NULL;
+ALTER TABLE events ADD COLUMN notified_time TIMESTAMP NULL DEFAULT NULL;
+
+-- only one reminder per event is kept, the earliest one
+UPDATE events e SET before_minutes = r.before_minutes, notified_time = r.notified_time
+    FROM (
+        SELECT DISTINCT ON (event_id) event_id, before_minutes, notified_time
+        FROM reminders
+        ORDER BY event_id, before_minutes DESC
+    ) r
+    WHERE r.event_id = e.id;
+DROP TABLE reminders;
+`,
+	"U8__Trash.sql": `-- events in trash can't be kept without deleted_time, they are purged
+DELETE FROM events WHERE deleted_time IS NOT NULL;
+DROP INDEX deleted_time_idx;
+ALTER TABLE events DROP COLUMN deleted_time;
+`,
+	"U9__Versions.sql": `ALTER TABLE events DROP COLUMN version;
+`,
+	"V10__Audit.sql": `CREATE TABLE audit (
+    id BIGSERIAL PRIMARY KEY,
+    event_id INT NOT NULL,
+    owner VARCHAR(64) NOT NULL DEFAULT '',
+    actor VARCHAR(64) NOT NULL DEFAULT '',
+    action VARCHAR(16) NOT NULL,
+    changed_time TIMESTAMP NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]'
+);
+CREATE INDEX audit_event_idx ON audit USING btree (event_id, id);
+
+-- audit is append-only, history is kept even for purged events
+CREATE RULE audit_no_update AS ON UPDATE TO audit DO INSTEAD NOTHING;
+CREATE RULE audit_no_delete AS ON DELETE TO audit DO INSTEAD NOTHING;
+`,
+	"V11__Calendars.sql": `CREATE TABLE calendars (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(256) NOT NULL,
+    owner VARCHAR(64) NOT NULL DEFAULT ''
+);
+CREATE INDEX calendars_owner_idx ON calendars USING btree (owner);
+
+-- deleting of calendar deletes its events (and their reminders by cascade)
+ALTER TABLE events ADD COLUMN calendar_id INT NULL DEFAULT NULL REFERENCES calendars(id) ON DELETE CASCADE;
+CREATE INDEX calendar_start_idx ON events USING btree (calendar_id, start_time) WHERE calendar_id IS NOT NULL;
+`,
+	"V12__Attendees.sql": `CREATE TABLE IF NOT EXISTS attendees (
+    event_id INT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    email VARCHAR(256) NOT NULL,
+    position INT NOT NULL, -- order of attendees in event
+    status VARCHAR(16) NOT NULL DEFAULT 'needs-action',
+    invited_start TIMESTAMPTZ NULL DEFAULT NULL, -- start of event in the last enqueued invitation (or update)
+    PRIMARY KEY (event_id, email)
+);
+
+-- attendees of existing events are considered as already invited, so invitations are not sent to them
+INSERT INTO attendees(event_id, email, position, invited_start)
+    SELECT DISTINCT ON (e.id, a.email) e.id, a.email, a.position, e.start_time
+    FROM events e, unnest(string_to_array(e.attendees, ',')) WITH ORDINALITY AS a(email, position)
+    WHERE e.attendees <> ''
+    ORDER BY e.id, a.email, a.position;
+ALTER TABLE events DROP COLUMN attendees;
+`,
+	"V13__Search.sql": `-- full-text search of events by words of name, 'simple' configuration only lower cases words (no stemming)
+CREATE INDEX events_name_search_idx ON events USING gin (to_tsvector('simple', name));
+`,
+	"V14__Claims.sql": `-- lease of claim of reminder by scheduler, NULL if reminder is not claimed
+ALTER TABLE reminders ADD COLUMN claimed_until TIMESTAMPTZ NULL DEFAULT NULL;
+-- claims are checked for expiration only for not notified reminders
+CREATE INDEX reminders_claimed_until_idx ON reminders (claimed_until) WHERE notified_time IS NULL AND claimed_until IS NOT NULL;
+`,
+	"V15__OccurrenceReminders.sql": `-- start of the latest occurrence which notification of reminder is enqueued, so reminders of recurring events
+-- are notified for every occurrence, NULL if reminder is not notified
+ALTER TABLE reminders ADD COLUMN notified_start TIMESTAMPTZ NULL DEFAULT NULL;
+
+-- notified reminders are considered as notified for the first occurrence of event
+UPDATE reminders SET notified_start = events.start_time
+    FROM events
+    WHERE events.id = reminders.event_id AND reminders.notified_time IS NOT NULL;
+`,
+	"V16__InvitedEnd.sql": `-- end of event in the last enqueued invitation (or update), so update is sent when end of event is changed too
+ALTER TABLE attendees ADD COLUMN invited_end TIMESTAMPTZ NULL DEFAULT NULL;
+
+-- attendees invited to current start of event are considered as invited to its current end
+UPDATE attendees SET invited_end = events.end_time
+    FROM events
+    WHERE events.id = attendees.event_id AND attendees.invited_start = events.start_time;
+`,
+	"V17__InviteClaims.sql": `-- lease of claim of attendee by scheduler to send invitation, NULL if attendee is not claimed
+ALTER TABLE attendees ADD COLUMN invite_claimed_until TIMESTAMPTZ NULL DEFAULT NULL;
+`,
+	"V18__ClaimedStart.sql": `-- start of the latest occurrence of recurring event claimed with reminder, so occurrence of expired claim is retried
+-- whatever period of next claim is, NULL if it is unknown
+ALTER TABLE reminders ADD COLUMN claimed_start TIMESTAMPTZ NULL DEFAULT NULL;
+`,
+	"V1__Initial.sql": `CREATE TABLE IF NOT EXISTS events (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(256),
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP NOT NULL,
+	before_minutes INT NULL DEFAULT NULL,
+	notified_time  TIMESTAMP NULL DEFAULT NULL
+);
+CREATE INDEX start_idx ON events USING btree (start_time, end_time);`,
+	"V2__Recurrence.sql": `ALTER TABLE events ADD COLUMN rrule VARCHAR(256) NULL DEFAULT NULL;
+ALTER TABLE events ADD COLUMN exdates TEXT NULL DEFAULT NULL;
+CREATE INDEX rrule_start_idx ON events USING btree (start_time) WHERE rrule IS NOT NULL;
+`,
+	"V3__AllDay.sql": `ALTER TABLE events ADD COLUMN start_date DATE NULL DEFAULT NULL;
+ALTER TABLE events ADD COLUMN end_date DATE NULL DEFAULT NULL;
+CREATE INDEX all_day_idx ON events USING btree (start_date, end_date) WHERE start_date IS NOT NULL;
+`,
+	"V4__Timezone.sql": `ALTER TABLE events ALTER COLUMN start_time TYPE TIMESTAMPTZ USING start_time AT TIME ZONE 'UTC';
+ALTER TABLE events ALTER COLUMN end_time TYPE TIMESTAMPTZ USING end_time AT TIME ZONE 'UTC';
+ALTER TABLE events ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+`,
+	"V5__Details.sql": `ALTER TABLE events ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN location VARCHAR(256) NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN organizer VARCHAR(256) NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN attendees TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN color VARCHAR(32) NOT NULL DEFAULT '';
+`,
+	"V6__Owner.sql": `ALTER TABLE events ADD COLUMN owner VARCHAR(64) NOT NULL DEFAULT '';
+CREATE INDEX owner_start_idx ON events USING btree (owner, start_time);
+`,
+	"V7__Reminders.sql": `CREATE TABLE IF NOT EXISTS reminders (
+    event_id INT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    before_minutes INT NOT NULL,
+    notified_time TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (event_id, before_minutes)
+);
+INSERT INTO reminders(event_id, before_minutes, notified_time)
+    SELECT id, before_minutes, notified_time FROM events WHERE before_minutes IS NOT NULL;
+ALTER TABLE events DROP COLUMN before_minutes;
+ALTER TABLE events DROP COLUMN notified_time;
+`,
+	"V8__Trash.sql": `ALTER TABLE events ADD COLUMN deleted_time TIMESTAMP NULL DEFAULT NULL;
+CREATE INDEX deleted_time_idx ON events USING btree (deleted_time) WHERE deleted_time IS NOT NULL;
+`,
+	"V9__Versions.sql": `ALTER TABLE events ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+`,
+}
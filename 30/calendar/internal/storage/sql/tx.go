@@ -0,0 +1,112 @@
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+)
+
+// Transaction of transactional view, it is shared by views of transactional view for other owners
+type txState struct {
+	tx         *sqlx.Tx
+	savepoints int // counter of savepoints, for unique names
+}
+
+// Transaction of one operation of storage
+// In transactional view it is savepoint of transaction of view, so failed operation is rolled back
+// but doesn't abort transaction of view, and commit only releases savepoint
+type storageTx struct {
+	*sqlx.Tx
+	savepoint string // empty for own transaction of operation
+	done      bool   // savepoint is released or rolled back
+}
+
+func (tx *storageTx) Commit() error {
+	if tx.savepoint == "" {
+		return tx.Tx.Commit()
+	}
+	if tx.done {
+		return dbsql.ErrTxDone
+	}
+	tx.done = true
+	_, err := tx.Tx.Exec("RELEASE SAVEPOINT " + tx.savepoint)
+	return err
+}
+
+// Rollback after commit does nothing (and returns dbsql.ErrTxDone) as for own transaction
+func (tx *storageTx) Rollback() error {
+	if tx.savepoint == "" {
+		return tx.Tx.Rollback()
+	}
+	if tx.done {
+		return dbsql.ErrTxDone
+	}
+	tx.done = true
+	_, err := tx.Tx.Exec("ROLLBACK TO SAVEPOINT " + tx.savepoint)
+	return err
+}
+
+// Begin transaction of operation, in transactional view it is savepoint of transaction of view
+func (s *Storage) begin(ctx context.Context) (*storageTx, error) {
+	if s.tx == nil {
+		tx, err := s.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &storageTx{Tx: tx}, nil
+	}
+
+	s.tx.savepoints++
+	savepoint := fmt.Sprintf("op_%d", s.tx.savepoints)
+	_, err := s.tx.tx.ExecContext(ctx, "SAVEPOINT "+savepoint)
+	if err != nil {
+		return nil, err
+	}
+	return &storageTx{Tx: s.tx.tx, savepoint: savepoint}, nil
+}
+
+// Handle of queries that are run out of transaction of operation:
+// transaction of transactional view, so it sees changes of view, or pool of connections
+func (s *Storage) queryer() sqlx.ExtContext {
+	if s.tx != nil {
+		return s.tx.tx
+	}
+	return s.db
+}
+
+// Run fn with transactional view of storage backed by SQL transaction, it is committed if fn returns nil
+// Every operation of view is run in savepoint, so failed operation doesn't abort transaction
+// WithTx of transactional view is nested transaction (savepoint)
+func (s *Storage) WithTx(ctx context.Context, fn func(tx entities.Storage) error) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// rollback after commit does nothing
+	defer func() { _ = tx.Rollback() }()
+
+	state := s.tx
+	if state == nil {
+		state = &txState{tx: tx.Tx}
+	}
+
+	err = fn(&Storage{
+		db:      s.db,
+		timeout: s.timeout,
+		logger:  s.logger,
+		owner:   s.owner,
+		tx:      state,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
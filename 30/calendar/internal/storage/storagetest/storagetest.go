@@ -0,0 +1,673 @@
+// Package storagetest is conformance suite of entities.Storage, every storage implementation must pass it
+// Suite checks contract of interface that is not obvious from signatures:
+// inclusive boundaries of periods, not found errors, windows of notifications and concurrent writes
+// Optional features are checked only for storages that have them
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+)
+
+// Factory of empty storage for one test, returned func releases storage (could be nil)
+type Factory func(t *testing.T) (entities.Storage, func())
+
+// Run conformance suite against storages made by factory, every subtest gets new empty storage
+func Run(t *testing.T, newStorage Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, storage entities.Storage)
+	}{
+		{"Boundaries", testBoundaries},
+		{"Pages", testPages},
+		{"NotFound", testNotFound},
+		{"NotifyWindows", testNotifyWindows},
+		{"RecurringReminders", testRecurringReminders},
+		{"Concurrency", testConcurrency},
+		{"Claims", testClaims},
+		{"InviteClaims", testInviteClaims},
+		{"CancelledContext", testCancelledContext},
+	}
+
+	for _, tt := range tests {
+		test := tt.test
+		t.Run(tt.name, func(t *testing.T) {
+			storage, release := newStorage(t)
+			if release != nil {
+				defer release()
+			}
+			test(t, storage)
+		})
+	}
+}
+
+func newEvent(name string, hour, minute int) entities.Event {
+	start := entities.NewDateTime(2019, 11, 25, hour, minute)
+	return entities.NewEvent(name, start, start.PlusMinutes(30))
+}
+
+func addEvent(t *testing.T, storage entities.Storage, event entities.Event) int {
+	id, err := storage.AddEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("event %s must be added, got %s", event.Name(), err)
+	}
+	return id
+}
+
+func names(events []entities.Event) map[string]bool {
+	result := make(map[string]bool)
+	for _, event := range events {
+		result[event.Name()] = true
+	}
+	return result
+}
+
+// Start and end of period are inclusive for GetEventsByPeriod, interval of GetOverlappingEvents is [start, end)
+func testBoundaries(t *testing.T, storage entities.Storage) {
+	ctx := context.Background()
+
+	addEvent(t, storage, newEvent("Before", 9, 59))
+	addEvent(t, storage, newEvent("AtStart", 10, 0))
+	addEvent(t, storage, newEvent("AtEnd", 12, 0))
+	addEvent(t, storage, newEvent("After", 12, 1))
+
+	start, end := entities.NewDateTime(2019, 11, 25, 10, 0), entities.NewDateTime(2019, 11, 25, 12, 0)
+
+	events, err := storage.GetEventsByPeriod(ctx, &start, &end)
+	if err != nil {
+		t.Fatalf("events must be got by period, got %s", err)
+	}
+	got := names(events)
+	if len(events) != 2 || !got["AtStart"] || !got["AtEnd"] {
+		t.Errorf("events at start and at end of period must be got instead of %v", events)
+	}
+
+	if events, _ := storage.GetEventsByPeriod(ctx, nil, &end); len(events) != 3 || names(events)["After"] {
+		t.Errorf("period without start must have 3 events instead of %v", events)
+	}
+	if events, _ := storage.GetEventsByPeriod(ctx, &start, nil); len(events) != 3 || names(events)["Before"] {
+		t.Errorf("period without end must have 3 events instead of %v", events)
+	}
+	if events, _ := storage.GetEventsByPeriod(ctx, nil, nil); len(events) != 4 {
+		t.Errorf("unbounded period must have 4 events instead of %v", events)
+	}
+
+	// Before ends at 10:29 and After starts at 12:01, so they don't overlap [10:29, 12:01)
+	overlapStart, overlapEnd := entities.NewDateTime(2019, 11, 25, 10, 29), entities.NewDateTime(2019, 11, 25, 12, 1)
+	events, err = storage.GetOverlappingEvents(ctx, overlapStart, overlapEnd)
+	if err != nil {
+		t.Fatalf("overlapping events must be got, got %s", err)
+	}
+	got = names(events)
+	if len(events) != 2 || !got["AtStart"] || !got["AtEnd"] {
+		t.Errorf("events that end at start or start at end of interval must not overlap it instead of %v", events)
+	}
+}
+
+// Pages of period are the same events as period in order of start and id
+func testPages(t *testing.T, storage entities.Storage) {
+	ctx := context.Background()
+
+	for _, event := range []entities.Event{newEvent("Third", 11, 0), newEvent("First", 10, 0), newEvent("Second", 10, 0)} {
+		addEvent(t, storage, event)
+	}
+	start, end := entities.NewDateTime(2019, 11, 25, 10, 0), entities.NewDateTime(2019, 11, 25, 11, 0)
+
+	var paged []entities.Event
+	cursor := ""
+	for i := 0; i < 5; i++ {
+		page, err := storage.GetEventsByPeriodPage(ctx, &start, &end, 1, cursor)
+		if err != nil {
+			t.Fatalf("page must be got, got %s", err)
+		}
+		paged = append(paged, page.Events...)
+		cursor = page.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	if len(paged) != 3 || paged[0].Name() != "First" || paged[1].Name() != "Second" || paged[2].Name() != "Third" {
+		t.Errorf("pages must have 3 events in order of start and id instead of %v", paged)
+	}
+
+	if _, err := storage.GetEventsByPeriodPage(ctx, &start, &end, 1, "not a cursor"); !errors.Is(err, entities.ErrInvalidCursor) {
+		t.Errorf("must be ErrInvalidCursor for unparsable cursor instead of %v", err)
+	}
+
+	// occurrences of infinite recurring event are merged in order with timed events, page by page from cursor
+	daily, _ := entities.ParseRecurrence("FREQ=DAILY")
+	addEvent(t, storage, entities.WithRecurrence(newEvent("Daily", 10, 30), daily))
+
+	paged = nil
+	cursor = ""
+	for i := 0; i < 3; i++ {
+		page, err := storage.GetEventsByPeriodPage(ctx, &start, nil, 2, cursor)
+		if err != nil {
+			t.Fatalf("page must be got, got %s", err)
+		}
+		if len(page.Events) != 2 || page.NextCursor == "" {
+			t.Fatalf("page %d of infinite event must be full and have next cursor instead of %v", i, page)
+		}
+		paged = append(paged, page.Events...)
+		cursor = page.NextCursor
+	}
+
+	var names []string
+	for _, event := range paged {
+		names = append(names, event.Name())
+	}
+	expected := []string{"First", "Second", "Daily", "Third", "Daily", "Daily"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("pages must have events %v instead of %v", expected, names)
+	}
+	if !paged[5].Start().Equal(start.PlusMinutes(2*24*60 + 30)) {
+		t.Errorf("the last occurrence must start at %s instead of %s", start.PlusMinutes(2*24*60+30), paged[5].Start())
+	}
+}
+
+// Methods return error of cancelled context instead of empty result
+func testCancelledContext(t *testing.T, storage entities.Storage) {
+	id := addEvent(t, storage, newEvent("Meeting", 10, 0))
+	start, end := entities.NewDateTime(2019, 11, 25, 0, 0), entities.NewDateTime(2019, 11, 26, 0, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	methods := map[string]func() error{
+		"Count": func() error {
+			_, err := storage.Count(ctx)
+			return err
+		},
+		"GetEvent": func() error {
+			_, err := storage.GetEvent(ctx, id)
+			return err
+		},
+		"GetAllEvents": func() error {
+			_, err := storage.GetAllEvents(ctx)
+			return err
+		},
+		"GetEventsByPeriod": func() error {
+			_, err := storage.GetEventsByPeriod(ctx, &start, &end)
+			return err
+		},
+		"GetEventsToNotify": func() error {
+			_, err := storage.GetEventsToNotify(ctx, &start, &end)
+			return err
+		},
+		"GetCalendars": func() error {
+			_, err := storage.GetCalendars(ctx)
+			return err
+		},
+		"AddEvent": func() error {
+			_, err := storage.AddEvent(ctx, newEvent("Retro", 11, 0))
+			return err
+		},
+	}
+
+	for method, call := range methods {
+		if err := call(); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s with cancelled context must be context.Canceled instead of %v", method, err)
+		}
+	}
+
+	if count, _ := storage.Count(context.Background()); count != 1 {
+		t.Errorf("event must not be added with cancelled context, count must be 1 instead of %d", count)
+	}
+}
+
+// Unknown events, events in trash and events of other owners are not found by the same error
+func testNotFound(t *testing.T, storage entities.Storage) {
+	ctx := context.Background()
+	when := time.Date(2019, 11, 25, 9, 0, 0, 0, time.UTC)
+	start := entities.NewDateTime(2019, 11, 25, 10, 0)
+
+	event := entities.WithAttendees(entities.WithReminders(newEvent("Meeting", 10, 0), []entities.Reminder{entities.NewReminder(15)}), []string{"bob@example.com"})
+	id := addEvent(t, storage.ForOwner("alice"), event)
+	deletedId := addEvent(t, storage, newEvent("Deleted", 11, 0))
+	if err := storage.DeleteEvent(ctx, deletedId); err != nil {
+		t.Fatalf("event must be deleted, got %s", err)
+	}
+	unknownId := deletedId + 1000
+
+	methods := map[string]func(s entities.Storage, id int) error{
+		"GetEvent": func(s entities.Storage, id int) error {
+			_, err := s.GetEvent(ctx, id)
+			return err
+		},
+		"UpdateEvent": func(s entities.Storage, id int) error {
+			return s.UpdateEvent(ctx, id, newEvent("Updated", 10, 0))
+		},
+		"DeleteEvent": func(s entities.Storage, id int) error {
+			return s.DeleteEvent(ctx, id)
+		},
+		"MarkEventAsNotified": func(s entities.Storage, id int) error {
+			return s.MarkEventAsNotified(ctx, id, when)
+		},
+		"MarkReminderAsNotified": func(s entities.Storage, id int) error {
+			return s.MarkReminderAsNotified(ctx, id, 15, start, when)
+		},
+		"RespondToInvitation": func(s entities.Storage, id int) error {
+			return s.RespondToInvitation(ctx, id, "bob@example.com", entities.AttendeeStatusAccepted)
+		},
+		"MarkAttendeeAsInvited": func(s entities.Storage, id int) error {
+			return s.MarkAttendeeAsInvited(ctx, id, "bob@example.com", start, start.PlusMinutes(30))
+		},
+	}
+
+	cases := []struct {
+		name    string
+		storage entities.Storage
+		id      int
+	}{
+		{"unknown", storage, unknownId},
+		{"deleted", storage, deletedId},
+		{"other owner", storage.ForOwner("bob"), id},
+	}
+
+	for _, c := range cases {
+		for method, call := range methods {
+			if err := call(c.storage, c.id); !errors.Is(err, entities.StorageErrorEventNotFound) {
+				t.Errorf("%s of %s event must be StorageErrorEventNotFound instead of %v", method, c.name, err)
+			}
+		}
+	}
+
+	if err := storage.RestoreEvent(ctx, unknownId); !errors.Is(err, entities.StorageErrorEventNotFound) {
+		t.Errorf("RestoreEvent of unknown event must be StorageErrorEventNotFound instead of %v", err)
+	}
+	if err := storage.RestoreEvent(ctx, id); !errors.Is(err, entities.StorageErrorEventNotFound) {
+		t.Errorf("RestoreEvent of event not in trash must be StorageErrorEventNotFound instead of %v", err)
+	}
+	if _, err := storage.GetEventHistory(ctx, unknownId); !errors.Is(err, entities.StorageErrorEventNotFound) {
+		t.Errorf("GetEventHistory of unknown event must be StorageErrorEventNotFound instead of %v", err)
+	}
+
+	if err := storage.MarkReminderAsNotified(ctx, id, 30, start, when); !errors.Is(err, entities.StorageErrorReminderNotFound) {
+		t.Errorf("unknown reminder must be StorageErrorReminderNotFound instead of %v", err)
+	}
+	if err := storage.RespondToInvitation(ctx, id, "carol@example.com", entities.AttendeeStatusAccepted); !errors.Is(err, entities.StorageErrorAttendeeNotFound) {
+		t.Errorf("unknown attendee must be StorageErrorAttendeeNotFound instead of %v", err)
+	}
+	if _, err := storage.AddEvent(ctx, entities.WithCalendarId(newEvent("Planning", 10, 0), unknownId)); !errors.Is(err, entities.StorageErrorCalendarNotFound) {
+		t.Errorf("event of unknown calendar must be StorageErrorCalendarNotFound instead of %v", err)
+	}
+	if _, err := storage.GetCalendar(ctx, unknownId); !errors.Is(err, entities.StorageErrorCalendarNotFound) {
+		t.Errorf("unknown calendar must be StorageErrorCalendarNotFound instead of %v", err)
+	}
+
+	// event is found by its owner and by view of all owners after all failed calls
+	if _, err := storage.ForOwner("alice").GetEvent(ctx, id); err != nil {
+		t.Errorf("event must be got by its owner, got %s", err)
+	}
+	if _, err := storage.GetEvent(ctx, id); err != nil {
+		t.Errorf("event must be got by view of all owners, got %s", err)
+	}
+}
+
+// Window of notifications is inclusive, notified reminders and deleted events are not notified
+func testNotifyWindows(t *testing.T, storage entities.Storage) {
+	ctx := context.Background()
+	when := time.Date(2019, 11, 25, 9, 0, 0, 0, time.UTC)
+
+	// reminders are due at 9:45 and 9:30
+	reminders := []entities.Reminder{entities.NewReminder(15), entities.NewReminder(30)}
+	id := addEvent(t, storage, entities.WithReminders(newEvent("Meeting", 10, 0), reminders))
+	deletedId := addEvent(t, storage, entities.WithReminders(newEvent("Deleted", 10, 0), reminders))
+	if err := storage.DeleteEvent(ctx, deletedId); err != nil {
+		t.Fatalf("event must be deleted, got %s", err)
+	}
+
+	notify := func(startHour, startMinute, endHour, endMinute int) []entities.Notification {
+		start := entities.NewDateTime(2019, 11, 25, startHour, startMinute)
+		end := entities.NewDateTime(2019, 11, 25, endHour, endMinute)
+		notifications, err := storage.GetEventsToNotify(ctx, &start, &end)
+		if err != nil {
+			t.Fatalf("notifications must be got, got %s", err)
+		}
+		return notifications
+	}
+
+	if notifications := notify(9, 30, 9, 45); len(notifications) != 2 || notifications[0].Reminder().BeforeMinutes() != 30 {
+		t.Errorf("reminders at start and at end of window must be due in order of time instead of %v", notifications)
+	}
+	if notifications := notify(9, 31, 9, 44); len(notifications) != 0 {
+		t.Errorf("reminders out of window must not be due instead of %v", notifications)
+	}
+	if notifications, _ := storage.GetEventsToNotify(ctx, nil, nil); len(notifications) != 2 {
+		t.Errorf("unbounded window must have 2 notifications instead of %v", notifications)
+	}
+
+	if err := storage.MarkReminderAsNotified(ctx, id, 30, entities.NewDateTime(2019, 11, 25, 10, 0), when); err != nil {
+		t.Fatalf("reminder must be marked as notified, got %s", err)
+	}
+	if notifications := notify(9, 0, 10, 0); len(notifications) != 1 || notifications[0].Reminder().BeforeMinutes() != 15 {
+		t.Errorf("only not notified reminder must be due instead of %v", notifications)
+	}
+
+	if err := storage.MarkEventAsNotified(ctx, id, when); err != nil {
+		t.Fatalf("event must be marked as notified, got %s", err)
+	}
+	if notifications := notify(9, 0, 10, 0); len(notifications) != 0 {
+		t.Errorf("notified event must not be due instead of %v", notifications)
+	}
+}
+
+// Reminders of recurring event are due for every occurrence, notified occurrence doesn't stop next ones
+func testRecurringReminders(t *testing.T, storage entities.Storage) {
+	ctx := context.Background()
+	when := time.Date(2019, 11, 25, 9, 45, 0, 0, time.UTC)
+
+	// reminder is due at 9:45 every day
+	recurrence, _ := entities.ParseRecurrence("FREQ=DAILY")
+	event := entities.WithRecurrence(entities.WithReminders(newEvent("Standup", 10, 0), []entities.Reminder{entities.NewReminder(15)}), recurrence)
+	id := addEvent(t, storage, event)
+
+	notify := func(day int) []entities.Notification {
+		start := entities.NewDateTime(2019, 11, day, 9, 0)
+		end := entities.NewDateTime(2019, 11, day, 10, 0)
+		notifications, err := storage.GetEventsToNotify(ctx, &start, &end)
+		if err != nil {
+			t.Fatalf("notifications must be got, got %s", err)
+		}
+		return notifications
+	}
+
+	notifications := notify(25)
+	if len(notifications) != 1 || !notifications[0].Start().Equal(entities.NewDateTime(2019, 11, 25, 10, 0)) {
+		t.Fatalf("reminder of the first occurrence must be due instead of %v", notifications)
+	}
+	if err := storage.MarkReminderAsNotified(ctx, id, 15, notifications[0].Start(), when); err != nil {
+		t.Fatalf("reminder must be marked as notified, got %s", err)
+	}
+	if notifications := notify(25); len(notifications) != 0 {
+		t.Errorf("notified occurrence must not be due instead of %v", notifications)
+	}
+
+	notifications = notify(26)
+	if len(notifications) != 1 || !notifications[0].Time().Equal(entities.NewDateTime(2019, 11, 26, 9, 45)) {
+		t.Fatalf("reminder of occurrence of the second day must be due instead of %v", notifications)
+	}
+	if !notifications[0].Start().Equal(entities.NewDateTime(2019, 11, 26, 10, 0)) || notifications[0].Id() != id {
+		t.Errorf("notification must be about occurrence of the second day instead of %v", notifications[0])
+	}
+
+	// window of two days has due occurrence of each day
+	start, end := entities.NewDateTime(2019, 11, 26, 0, 0), entities.NewDateTime(2019, 11, 27, 23, 59)
+	if notifications, _ := storage.GetEventsToNotify(ctx, &start, &end); len(notifications) != 2 {
+		t.Errorf("reminders of two occurrences must be due instead of %v", notifications)
+	}
+
+	claimer, ok := storage.(entities.Claimer)
+	if !ok {
+		return
+	}
+	now := time.Date(2019, 11, 26, 9, 45, 0, 0, time.UTC)
+	start, end = entities.NewDateTime(2019, 11, 26, 9, 0), entities.NewDateTime(2019, 11, 26, 10, 0)
+	notifications, err := claimer.ClaimEventsToNotify(ctx, &start, &end, now, time.Minute)
+	if err != nil || len(notifications) != 1 || !notifications[0].Start().Equal(entities.NewDateTime(2019, 11, 26, 10, 0)) {
+		t.Fatalf("reminder of occurrence of the second day must be claimed instead of %v, error %v", notifications, err)
+	}
+	if err := storage.MarkReminderAsNotified(ctx, id, 15, notifications[0].Start(), now); err != nil {
+		t.Fatalf("reminder must be marked as notified, got %s", err)
+	}
+
+	// claim of the third day is not blocked by claim of the second day
+	start, end = entities.NewDateTime(2019, 11, 27, 9, 0), entities.NewDateTime(2019, 11, 27, 10, 0)
+	notifications, err = claimer.ClaimEventsToNotify(ctx, &start, &end, now.Add(time.Second), time.Minute)
+	if err != nil || len(notifications) != 1 || !notifications[0].Start().Equal(entities.NewDateTime(2019, 11, 27, 10, 0)) {
+		t.Errorf("reminder of occurrence of the third day must be claimed instead of %v, error %v", notifications, err)
+	}
+}
+
+// Concurrent adds get unique ids, only one of concurrent updates of the same version wins
+func testConcurrency(t *testing.T, storage entities.Storage) {
+	ctx := context.Background()
+	const workers, perWorker = 8, 10
+
+	var mx sync.Mutex
+	ids := make(map[int]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				id, err := storage.AddEvent(ctx, newEvent("Meeting", 10, 0))
+				mx.Lock()
+				if err != nil {
+					t.Errorf("event must be added, got %s", err)
+				} else if ids[id] {
+					t.Errorf("id %d must be unique", id)
+				}
+				ids[id] = true
+				mx.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if count, _ := storage.Count(ctx); count != workers*perWorker {
+		t.Errorf("must be %d events instead of %d", workers*perWorker, count)
+	}
+
+	id := addEvent(t, storage, newEvent("Retro", 11, 0))
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- storage.UpdateEvent(ctx, id, entities.WithVersion(newEvent("Retro", 12, 0), 1))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	updated := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			updated++
+		case !errors.Is(err, entities.StorageErrorVersionMismatch):
+			t.Errorf("must be StorageErrorVersionMismatch for lost update instead of %v", err)
+		}
+	}
+	if updated != 1 {
+		t.Errorf("only one update of version 1 must win instead of %d", updated)
+	}
+
+	if event, err := storage.GetEvent(ctx, id); err != nil || event.Version() != 2 {
+		t.Errorf("event must have version 2 instead of %v, error %v", event, err)
+	}
+}
+
+// Due reminders are claimed once until lease expires, concurrent claims get different reminders
+func testClaims(t *testing.T, storage entities.Storage) {
+	claimer, ok := storage.(entities.Claimer)
+	if !ok {
+		t.Skip("storage is not entities.Claimer")
+	}
+
+	ctx := context.Background()
+	now := time.Date(2019, 11, 25, 9, 0, 0, 0, time.UTC)
+
+	// reminders are due at 9:45 and 9:30
+	reminders := []entities.Reminder{entities.NewReminder(15), entities.NewReminder(30)}
+	id := addEvent(t, storage, entities.WithReminders(newEvent("Meeting", 10, 0), reminders))
+	addEvent(t, storage, entities.WithReminders(newEvent("Retro", 12, 0), reminders))
+
+	start, end := entities.NewDateTime(2019, 11, 25, 9, 0), entities.NewDateTime(2019, 11, 25, 10, 0)
+	claim := func(now time.Time) []entities.Notification {
+		notifications, err := claimer.ClaimEventsToNotify(ctx, &start, &end, now, time.Minute)
+		if err != nil {
+			t.Fatalf("reminders must be claimed, got %s", err)
+		}
+		return notifications
+	}
+
+	notifications := claim(now)
+	if len(notifications) != 2 || notifications[0].Reminder().BeforeMinutes() != 30 || notifications[1].Reminder().BeforeMinutes() != 15 {
+		t.Fatalf("due reminders must be claimed in order of time instead of %v", notifications)
+	}
+	if notifications := claim(now.Add(59 * time.Second)); len(notifications) != 0 {
+		t.Errorf("claimed reminders must not be claimed again until lease expires instead of %v", notifications)
+	}
+
+	if err := storage.MarkReminderAsNotified(ctx, id, 30, notifications[0].Start(), now); err != nil {
+		t.Fatalf("reminder must be marked as notified, got %s", err)
+	}
+
+	// claim of not notified reminder is expired, it is claimed again even out of period
+	start, end = entities.NewDateTime(2019, 11, 25, 11, 0), entities.NewDateTime(2019, 11, 25, 12, 0)
+	notifications = claim(now.Add(time.Minute))
+	if len(notifications) != 3 || notifications[0].Reminder().BeforeMinutes() != 15 || notifications[0].Id() != id {
+		t.Errorf("expired claim and due reminders of period must be claimed instead of %v", notifications)
+	}
+
+	// concurrent claims
+	for i := 0; i < 5; i++ {
+		addEvent(t, storage, entities.WithReminders(newEvent("Planning", 14, 0), reminders))
+	}
+	start, end = entities.NewDateTime(2019, 11, 25, 13, 0), entities.NewDateTime(2019, 11, 25, 14, 0)
+
+	var mx sync.Mutex
+	claimed := make(map[string]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			notifications, err := claimer.ClaimEventsToNotify(ctx, &start, &end, now, time.Minute)
+			mx.Lock()
+			defer mx.Unlock()
+			if err != nil {
+				t.Errorf("reminders must be claimed, got %s", err)
+			}
+			for _, notification := range notifications {
+				key := fmt.Sprintf("%d/%d", notification.Id(), notification.Reminder().BeforeMinutes())
+				if claimed[key] {
+					t.Errorf("reminder %s must be claimed once", key)
+				}
+				claimed[key] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(claimed) != 10 {
+		t.Errorf("all 10 due reminders must be claimed instead of %v", claimed)
+	}
+
+	// expired claim of occurrence of recurring event is retried even if occurrence is not due in period of next claim
+	daily, _ := entities.ParseRecurrence("FREQ=DAILY")
+	standupId := addEvent(t, storage, entities.WithRecurrence(entities.WithReminders(newEvent("Standup", 16, 0), []entities.Reminder{entities.NewReminder(15)}), daily))
+	standups := func(notifications []entities.Notification) []entities.Notification {
+		var result []entities.Notification
+		for _, notification := range notifications {
+			if notification.Id() == standupId {
+				result = append(result, notification)
+			}
+		}
+		return result
+	}
+	occurrenceStart := entities.NewDateTime(2019, 11, 25, 16, 0)
+
+	start, end = entities.NewDateTime(2019, 11, 25, 15, 30), entities.NewDateTime(2019, 11, 25, 16, 0)
+	notifications = standups(claim(now))
+	if len(notifications) != 1 || !notifications[0].Start().Equal(occurrenceStart) {
+		t.Fatalf("reminder of occurrence at %s must be claimed instead of %v", occurrenceStart, notifications)
+	}
+
+	start, end = entities.NewDateTime(2019, 11, 25, 17, 0), entities.NewDateTime(2019, 11, 25, 18, 0)
+	notifications = standups(claim(now.Add(time.Minute)))
+	if len(notifications) != 1 || !notifications[0].Start().Equal(occurrenceStart) {
+		t.Fatalf("expired claim of occurrence at %s must be claimed again out of period instead of %v", occurrenceStart, notifications)
+	}
+	if notifications := standups(claim(now.Add(time.Minute))); len(notifications) != 0 {
+		t.Errorf("occurrence claimed again must not be claimed until lease expires instead of %v", notifications)
+	}
+
+	if err := storage.MarkReminderAsNotified(ctx, standupId, 15, occurrenceStart, now); err != nil {
+		t.Fatalf("reminder of occurrence must be marked as notified, got %s", err)
+	}
+	if notifications := standups(claim(now.Add(2 * time.Minute))); len(notifications) != 0 {
+		t.Errorf("notified occurrence must not be claimed again instead of %v", notifications)
+	}
+}
+
+func testInviteClaims(t *testing.T, storage entities.Storage) {
+	claimer, ok := storage.(entities.Claimer)
+	if !ok {
+		t.Skip("storage is not entities.Claimer")
+	}
+
+	ctx := context.Background()
+	now := time.Date(2019, 11, 25, 9, 0, 0, 0, time.UTC)
+
+	id := addEvent(t, storage, entities.WithAttendees(newEvent("Meeting", 10, 0), []string{"alice@example.com", "bob@example.com"}))
+	claim := func(now time.Time) []entities.Invitation {
+		invitations, err := claimer.ClaimInvitationsToSend(ctx, now, time.Minute)
+		if err != nil {
+			t.Fatalf("invitations must be claimed, got %s", err)
+		}
+		return invitations
+	}
+
+	invitations := claim(now)
+	if len(invitations) != 2 || invitations[0].Attendee().Email() != "alice@example.com" || invitations[1].Attendee().Email() != "bob@example.com" {
+		t.Fatalf("invitations of alice and bob must be claimed instead of %v", invitations)
+	}
+	if invitations := claim(now.Add(59 * time.Second)); len(invitations) != 0 {
+		t.Errorf("claimed invitations must not be claimed again until lease expires instead of %v", invitations)
+	}
+
+	if err := storage.MarkAttendeeAsInvited(ctx, id, "alice@example.com", invitations[0].Start(), invitations[0].End()); err != nil {
+		t.Fatalf("attendee must be marked as invited, got %s", err)
+	}
+
+	// claim of not invited attendee is expired, it is claimed again
+	invitations = claim(now.Add(time.Minute))
+	if len(invitations) != 1 || invitations[0].Attendee().Email() != "bob@example.com" {
+		t.Errorf("only invitation of bob must be claimed again instead of %v", invitations)
+	}
+
+	// concurrent claims
+	for i := 0; i < 5; i++ {
+		addEvent(t, storage, entities.WithAttendees(newEvent("Planning", 14, 0), []string{"carol@example.com", "dave@example.com"}))
+	}
+
+	var mx sync.Mutex
+	claimed := make(map[string]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			invitations, err := claimer.ClaimInvitationsToSend(ctx, now, time.Minute)
+			mx.Lock()
+			defer mx.Unlock()
+			if err != nil {
+				t.Errorf("invitations must be claimed, got %s", err)
+			}
+			for _, invitation := range invitations {
+				key := fmt.Sprintf("%d/%s", invitation.Id(), invitation.Attendee().Email())
+				if claimed[key] {
+					t.Errorf("invitation %s must be claimed once", key)
+				}
+				claimed[key] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(claimed) != 10 {
+		t.Errorf("all 10 invitations must be claimed instead of %v", claimed)
+	}
+}
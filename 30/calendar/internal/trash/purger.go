@@ -0,0 +1,107 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"go.uber.org/zap"
+)
+
+var ErrorStorageNotInitialized = errors.New("storage not initialized")
+
+// Trash purger
+// Delete permanently with some freq events that were moved to trash more than retention ago
+type Purger struct {
+	interval  time.Duration    // frequency of purge
+	retention time.Duration    // how long deleted events are kept in trash
+	storage   entities.Storage // calendar storage
+	logger    *zap.SugaredLogger
+	nowTimeFn func() time.Time // for possibility to redeclare in tests
+	cancelFn  context.CancelFunc
+}
+
+// Constructor
+func NewPurger(interval time.Duration, retention time.Duration, storage entities.Storage, logger *zap.SugaredLogger) *Purger {
+	return &Purger{
+		interval:  interval,
+		retention: retention,
+		storage:   storage,
+		logger:    logger,
+	}
+}
+
+// Run purger
+func (p *Purger) Run() error {
+
+	if p.storage == nil {
+		return ErrorStorageNotInitialized
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	p.cancelFn = cancelFn
+
+	p.run(ctx)
+
+	return nil
+}
+
+// Stop purger
+func (p *Purger) Stop() {
+	if p.cancelFn != nil {
+		p.cancelFn()
+	}
+}
+
+// Inner helper that run purge process. Take into account context.Done()
+func (p *Purger) run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	p.purge(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		case <-ticker.C:
+			p.purge(ctx)
+		}
+	}
+}
+
+// delete permanently events that were moved to trash before now minus retention
+// Purge is cancelled on stop of purger
+func (p *Purger) purge(ctx context.Context) {
+	before := p.now().Add(-p.retention)
+
+	cnt, err := p.storage.PurgeEvents(ctx, before)
+	if err != nil {
+		p.logErrorf("Purger.purge, storage.PurgeEvents return error %w", err)
+		return
+	}
+
+	p.logInfof("%d event(s) purged from trash (deleted before %s)", cnt, before)
+}
+
+// now helper, call nowTimeFn, that could be redefined in test
+func (p *Purger) now() time.Time {
+	if p.nowTimeFn == nil {
+		return time.Now()
+	}
+	return p.nowTimeFn()
+}
+
+// log formatted error
+func (p *Purger) logErrorf(format string, err error) {
+	if p.logger != nil {
+		p.logger.Error(fmt.Errorf(format, err))
+	}
+}
+
+// print formatted info level message into log
+func (p *Purger) logInfof(format string, args ...interface{}) {
+	if p.logger != nil {
+		p.logger.Infof(format, args...)
+	}
+}
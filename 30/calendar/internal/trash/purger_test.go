@@ -0,0 +1,45 @@
+package trash
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/domain/entities"
+	"github.com/mitrickx/otus-golang-2019/30/calendar/internal/storage/memory"
+)
+
+func TestPurge(t *testing.T) {
+	storage := memory.NewStorage()
+	purger := NewPurger(time.Hour, 24*time.Hour, storage, nil)
+
+	id, _ := storage.AddEvent(context.Background(), entities.NewEvent(
+		"TestEvent",
+		entities.NewDateTime(2019, 11, 18, 8, 0),
+		entities.NewDateTime(2019, 11, 18, 10, 0),
+	))
+	_ = storage.DeleteEvent(context.Background(), id)
+
+	// retention is not expired yet
+	purger.nowTimeFn = func() time.Time {
+		return time.Now().Add(23 * time.Hour)
+	}
+
+	purger.purge(context.Background())
+
+	events, _ := storage.GetTrashedEvents(context.Background())
+	if len(events) != 1 {
+		t.Fatalf("event must be kept in trash until retention expired instead of %+v", events)
+	}
+
+	purger.nowTimeFn = func() time.Time {
+		return time.Now().Add(25 * time.Hour)
+	}
+
+	purger.purge(context.Background())
+
+	events, _ = storage.GetTrashedEvents(context.Background())
+	if len(events) != 0 {
+		t.Errorf("event must be purged from trash when retention expired instead of %+v", events)
+	}
+}
@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-    http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -15,26 +15,8 @@ limitations under the License.
 */
 package main
 
-import (
-	"log"
-	"os"
-	"time"
-)
-
 import "github.com/mitrickx/otus-golang-2019/30/calendar/cmd"
 
-// Load TZ, must be before any use of time, so why it is in init in main file
-func init() {
-	if tz := os.Getenv("TZ"); tz != "" {
-		l, err := time.LoadLocation(tz)
-		if err != nil {
-			log.Printf("error loading timezone location: %s", err)
-		} else {
-			time.UTC = l;
-		}
-	}
-}
-
 func main() {
 	cmd.Execute()
 }
@@ -0,0 +1,3 @@
+DROP INDEX calendar_start_idx;
+ALTER TABLE events DROP COLUMN calendar_id;
+DROP TABLE calendars;
@@ -0,0 +1,11 @@
+ALTER TABLE events ADD COLUMN attendees TEXT NOT NULL DEFAULT '';
+
+-- responses of attendees are lost
+UPDATE events e SET attendees = a.emails
+    FROM (
+        SELECT event_id, string_agg(email, ',' ORDER BY position) AS emails
+        FROM attendees
+        GROUP BY event_id
+    ) a
+    WHERE a.event_id = e.id;
+DROP TABLE attendees;
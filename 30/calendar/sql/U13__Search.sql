@@ -0,0 +1 @@
+DROP INDEX events_name_search_idx;
@@ -0,0 +1,2 @@
+DROP INDEX reminders_claimed_until_idx;
+ALTER TABLE reminders DROP COLUMN claimed_until;
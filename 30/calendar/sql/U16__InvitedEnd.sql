@@ -0,0 +1 @@
+ALTER TABLE attendees DROP COLUMN invited_end;
@@ -0,0 +1,3 @@
+DROP INDEX rrule_start_idx;
+ALTER TABLE events DROP COLUMN exdates;
+ALTER TABLE events DROP COLUMN rrule;
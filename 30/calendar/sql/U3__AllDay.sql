@@ -0,0 +1,3 @@
+DROP INDEX all_day_idx;
+ALTER TABLE events DROP COLUMN end_date;
+ALTER TABLE events DROP COLUMN start_date;
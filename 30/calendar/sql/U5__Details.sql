@@ -0,0 +1,5 @@
+ALTER TABLE events DROP COLUMN color;
+ALTER TABLE events DROP COLUMN attendees;
+ALTER TABLE events DROP COLUMN organizer;
+ALTER TABLE events DROP COLUMN location;
+ALTER TABLE events DROP COLUMN description;
@@ -0,0 +1,2 @@
+DROP INDEX owner_start_idx;
+ALTER TABLE events DROP COLUMN owner;
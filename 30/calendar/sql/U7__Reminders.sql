@@ -0,0 +1,12 @@
+ALTER TABLE events ADD COLUMN before_minutes INT NULL DEFAULT NULL;
+ALTER TABLE events ADD COLUMN notified_time TIMESTAMP NULL DEFAULT NULL;
+
+-- only one reminder per event is kept, the earliest one
+UPDATE events e SET before_minutes = r.before_minutes, notified_time = r.notified_time
+    FROM (
+        SELECT DISTINCT ON (event_id) event_id, before_minutes, notified_time
+        FROM reminders
+        ORDER BY event_id, before_minutes DESC
+    ) r
+    WHERE r.event_id = e.id;
+DROP TABLE reminders;
@@ -0,0 +1,4 @@
+-- events in trash can't be kept without deleted_time, they are purged
+DELETE FROM events WHERE deleted_time IS NOT NULL;
+DROP INDEX deleted_time_idx;
+ALTER TABLE events DROP COLUMN deleted_time;
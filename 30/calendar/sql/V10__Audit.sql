@@ -0,0 +1,14 @@
+CREATE TABLE audit (
+    id BIGSERIAL PRIMARY KEY,
+    event_id INT NOT NULL,
+    owner VARCHAR(64) NOT NULL DEFAULT '',
+    actor VARCHAR(64) NOT NULL DEFAULT '',
+    action VARCHAR(16) NOT NULL,
+    changed_time TIMESTAMP NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]'
+);
+CREATE INDEX audit_event_idx ON audit USING btree (event_id, id);
+
+-- audit is append-only, history is kept even for purged events
+CREATE RULE audit_no_update AS ON UPDATE TO audit DO INSTEAD NOTHING;
+CREATE RULE audit_no_delete AS ON DELETE TO audit DO INSTEAD NOTHING;
@@ -0,0 +1,10 @@
+CREATE TABLE calendars (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(256) NOT NULL,
+    owner VARCHAR(64) NOT NULL DEFAULT ''
+);
+CREATE INDEX calendars_owner_idx ON calendars USING btree (owner);
+
+-- deleting of calendar deletes its events (and their reminders by cascade)
+ALTER TABLE events ADD COLUMN calendar_id INT NULL DEFAULT NULL REFERENCES calendars(id) ON DELETE CASCADE;
+CREATE INDEX calendar_start_idx ON events USING btree (calendar_id, start_time) WHERE calendar_id IS NOT NULL;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS attendees (
+    event_id INT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    email VARCHAR(256) NOT NULL,
+    position INT NOT NULL, -- order of attendees in event
+    status VARCHAR(16) NOT NULL DEFAULT 'needs-action',
+    invited_start TIMESTAMPTZ NULL DEFAULT NULL, -- start of event in the last enqueued invitation (or update)
+    PRIMARY KEY (event_id, email)
+);
+
+-- attendees of existing events are considered as already invited, so invitations are not sent to them
+INSERT INTO attendees(event_id, email, position, invited_start)
+    SELECT DISTINCT ON (e.id, a.email) e.id, a.email, a.position, e.start_time
+    FROM events e, unnest(string_to_array(e.attendees, ',')) WITH ORDINALITY AS a(email, position)
+    WHERE e.attendees <> ''
+    ORDER BY e.id, a.email, a.position;
+ALTER TABLE events DROP COLUMN attendees;
@@ -0,0 +1,2 @@
+-- full-text search of events by words of name, 'simple' configuration only lower cases words (no stemming)
+CREATE INDEX events_name_search_idx ON events USING gin (to_tsvector('simple', name));
@@ -0,0 +1,4 @@
+-- lease of claim of reminder by scheduler, NULL if reminder is not claimed
+ALTER TABLE reminders ADD COLUMN claimed_until TIMESTAMPTZ NULL DEFAULT NULL;
+-- claims are checked for expiration only for not notified reminders
+CREATE INDEX reminders_claimed_until_idx ON reminders (claimed_until) WHERE notified_time IS NULL AND claimed_until IS NOT NULL;
@@ -0,0 +1,8 @@
+-- start of the latest occurrence which notification of reminder is enqueued, so reminders of recurring events
+-- are notified for every occurrence, NULL if reminder is not notified
+ALTER TABLE reminders ADD COLUMN notified_start TIMESTAMPTZ NULL DEFAULT NULL;
+
+-- notified reminders are considered as notified for the first occurrence of event
+UPDATE reminders SET notified_start = events.start_time
+    FROM events
+    WHERE events.id = reminders.event_id AND reminders.notified_time IS NOT NULL;
@@ -0,0 +1,7 @@
+-- end of event in the last enqueued invitation (or update), so update is sent when end of event is changed too
+ALTER TABLE attendees ADD COLUMN invited_end TIMESTAMPTZ NULL DEFAULT NULL;
+
+-- attendees invited to current start of event are considered as invited to its current end
+UPDATE attendees SET invited_end = events.end_time
+    FROM events
+    WHERE events.id = attendees.event_id AND attendees.invited_start = events.start_time;
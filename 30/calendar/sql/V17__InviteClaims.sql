@@ -0,0 +1,2 @@
+-- lease of claim of attendee by scheduler to send invitation, NULL if attendee is not claimed
+ALTER TABLE attendees ADD COLUMN invite_claimed_until TIMESTAMPTZ NULL DEFAULT NULL;
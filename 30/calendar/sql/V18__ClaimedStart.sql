@@ -0,0 +1,3 @@
+-- start of the latest occurrence of recurring event claimed with reminder, so occurrence of expired claim is retried
+-- whatever period of next claim is, NULL if it is unknown
+ALTER TABLE reminders ADD COLUMN claimed_start TIMESTAMPTZ NULL DEFAULT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE events ADD COLUMN rrule VARCHAR(256) NULL DEFAULT NULL;
+ALTER TABLE events ADD COLUMN exdates TEXT NULL DEFAULT NULL;
+CREATE INDEX rrule_start_idx ON events USING btree (start_time) WHERE rrule IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE events ADD COLUMN start_date DATE NULL DEFAULT NULL;
+ALTER TABLE events ADD COLUMN end_date DATE NULL DEFAULT NULL;
+CREATE INDEX all_day_idx ON events USING btree (start_date, end_date) WHERE start_date IS NOT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE events ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN location VARCHAR(256) NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN organizer VARCHAR(256) NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN attendees TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN color VARCHAR(32) NOT NULL DEFAULT '';
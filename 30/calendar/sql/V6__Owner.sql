@@ -0,0 +1,2 @@
+ALTER TABLE events ADD COLUMN owner VARCHAR(64) NOT NULL DEFAULT '';
+CREATE INDEX owner_start_idx ON events USING btree (owner, start_time);
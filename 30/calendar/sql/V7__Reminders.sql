@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS reminders (
+    event_id INT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    before_minutes INT NOT NULL,
+    notified_time TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (event_id, before_minutes)
+);
+INSERT INTO reminders(event_id, before_minutes, notified_time)
+    SELECT id, before_minutes, notified_time FROM events WHERE before_minutes IS NOT NULL;
+ALTER TABLE events DROP COLUMN before_minutes;
+ALTER TABLE events DROP COLUMN notified_time;
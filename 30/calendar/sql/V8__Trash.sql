@@ -0,0 +1,2 @@
+ALTER TABLE events ADD COLUMN deleted_time TIMESTAMP NULL DEFAULT NULL;
+CREATE INDEX deleted_time_idx ON events USING btree (deleted_time) WHERE deleted_time IS NOT NULL;
@@ -6,7 +6,7 @@ Feature: Create event
     Given Clean DB
     When I send "POST" request to "http://http:8888/create_event" with "application/x-www-form-urlencoded" params:
     """
-    name=Add test&start=2019-12-21 14:00&end=2019-12-21 15:00&beforeMinutes=10
+    name=Add test&start=2099-12-21 14:00&end=2099-12-21 15:00&beforeMinutes=10
     """
     Then The response code should be 200
     And The response contentType should be "application/json"
@@ -14,7 +14,7 @@ Feature: Create event
     And Extracted number is event id
     And The record should match:
       | name      | start_time        | end_time          | before_minutes  | notified_time |
-      | Add test  | 2019-12-21 14:00  | 2019-12-21 15:00  | 10              | nil           |
+      | Add test  | 2099-12-21 14:00  | 2099-12-21 15:00  | 10              | nil           |
 
   Scenario: Create event, 400 invalid start date
     Given Clean DB
@@ -35,3 +35,23 @@ Feature: Create event
     Then The response code should be 400
     And The response contentType should be "application/json"
     And The response json should has field "error" with value match "^invalid format of datetime"
+
+  Scenario: Create event, 422 end before start
+    Given Clean DB
+    When I send "POST" request to "http://http:8888/create_event" with "application/x-www-form-urlencoded" params:
+    """
+    name=Add test&start=2099-12-21 14:00&end=2099-12-21 13:00&beforeMinutes=10
+    """
+    Then The response code should be 422
+    And The response contentType should be "application/json"
+    And The response json should has field "error" with value match "^end must not be before start$"
+
+  Scenario: Create event, 422 start in the past
+    Given Clean DB
+    When I send "POST" request to "http://http:8888/create_event" with "application/x-www-form-urlencoded" params:
+    """
+    name=Add test&start=2019-12-21 14:00&end=2019-12-21 15:00&beforeMinutes=10
+    """
+    Then The response code should be 422
+    And The response contentType should be "application/json"
+    And The response json should has field "error" with value match "^start of new event must not be in the past$"
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -135,12 +136,12 @@ func (t *featureTest) theRecordShouldMatch(eventsData *gherkin.DataTable) error
 
 	cfg := GetTestConfig()
 
-	dbEvent, err := cfg.DbStorage.GetEvent(event.Id())
+	dbEvent, err := cfg.DbStorage.GetEvent(context.Background(), event.Id())
 	if err != nil {
 		return fmt.Errorf("error when get event by id %d from db `%s`", event.Id(), err)
 	}
 
-	if dbEvent != event {
+	if !reflect.DeepEqual(dbEvent, event) {
 		return fmt.Errorf("expected event\n%#v\ninstread of event\n%#v", event, dbEvent)
 	}
 	return nil
@@ -155,7 +156,7 @@ func (t *featureTest) existingRecords(eventsData *gherkin.DataTable) error {
 
 	config := GetTestConfig()
 	for _, event := range events {
-		eventId, err := config.DbStorage.InsertEvent(event)
+		eventId, err := config.DbStorage.InsertEvent(context.Background(), event)
 		if err != nil {
 			return fmt.Errorf("preparation db, add event into is failed %s", err)
 		}
@@ -178,11 +179,11 @@ func (t *featureTest) theRecordsShouldMatch(eventsData *gherkin.DataTable) error
 	for index, event := range expectedEvents {
 		dbEventId := t.eventIds[index]
 		expectedEvent := entities.WithId(event, dbEventId)
-		dbEvent, err := config.DbStorage.GetEvent(dbEventId)
+		dbEvent, err := config.DbStorage.GetEvent(context.Background(), dbEventId)
 		if err != nil {
 			return fmt.Errorf("error when get event by id %d from db `%s`", dbEventId, err)
 		}
-		if expectedEvent != dbEvent {
+		if !reflect.DeepEqual(expectedEvent, dbEvent) {
 			err := fmt.Sprintf("expected event\n%#v\ninstread of event\n%#v", expectedEvent, dbEvent)
 			errList = append(errList, err)
 		}
@@ -199,7 +200,7 @@ func (t *featureTest) theRecordsShouldMatch(eventsData *gherkin.DataTable) error
 
 func (t *featureTest) cleanDB() error {
 	config := GetTestConfig()
-	err := config.DbStorage.ClearAll()
+	err := config.DbStorage.ClearAll(context.Background())
 	if err != nil {
 		return fmt.Errorf("cleaning error return error %s", err)
 	}
@@ -208,7 +209,7 @@ func (t *featureTest) cleanDB() error {
 
 func (t *featureTest) theDBShouldBeClean() error {
 	config := GetTestConfig()
-	count, err := config.DbStorage.Count()
+	count, err := config.DbStorage.Count(context.Background())
 	if err != nil {
 		return fmt.Errorf("counting all items cause error `%s`", err)
 	}
@@ -255,7 +256,7 @@ func (t *featureTest) theResponseJsonIsEventListResponseFilledWithEventsOfIds(id
 
 	expectedEventListResponse := httpService.EventListResponse{}
 	for _, eventId := range eventIds {
-		event, err := config.DbStorage.GetEvent(eventId)
+		event, err := config.DbStorage.GetEvent(context.Background(), eventId)
 		if err != nil {
 			return fmt.Errorf("error when get event by id %d from db `%s`", eventId, err)
 		}
@@ -377,7 +378,7 @@ func (t *featureTest) fieldOfRecordsMustBeNotNil(field, ids string) error {
 	config := GetTestConfig()
 
 	for _, eventId := range eventIds {
-		event, err := config.DbStorage.GetEvent(eventId)
+		event, err := config.DbStorage.GetEvent(context.Background(), eventId)
 		if err != nil {
 			return fmt.Errorf("error when get event by id %d from db `%s`", eventId, err)
 		}
@@ -0,0 +1,73 @@
+// Generator that embeds versioned sql migrations into go source file
+// Migrations are Flyway-style files: V<version>__<name>.sql applies migration, U<version>__<name>.sql undoes it
+//
+// Usage: go run ./tools/embedsql -dir ./sql -out ./internal/storage/sql/migrations_gen.go -pkg sql
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var fileNameRegexp = regexp.MustCompile(`^[VU]\d+__\w+\.sql$`)
+
+func main() {
+	dir := flag.String("dir", "sql", "directory with migration files")
+	out := flag.String("out", "migrations_gen.go", "output go file")
+	pkg := flag.String("pkg", "sql", "package of output go file")
+	flag.Parse()
+
+	infos, err := ioutil.ReadDir(*dir)
+	if err != nil {
+		log.Fatalf("can't read directory of migrations %s", err)
+	}
+
+	var names []string
+	for _, info := range infos {
+		if !info.IsDir() && fileNameRegexp.MatchString(info.Name()) {
+			names = append(names, info.Name())
+		}
+	}
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by tools/embedsql from %s; DO NOT EDIT.\n\n", filepath.ToSlash(*dir))
+	fmt.Fprintf(buf, "package %s\n\n", *pkg)
+	fmt.Fprintf(buf, "// Content of migration files by file name\n")
+	fmt.Fprintf(buf, "var migrationFiles = map[string]string{\n")
+	for _, name := range names {
+		content, err := ioutil.ReadFile(filepath.Join(*dir, name))
+		if err != nil {
+			log.Fatalf("can't read migration %s", err)
+		}
+		fmt.Fprintf(buf, "%s: %s,\n", strconv.Quote(name), quote(string(content)))
+	}
+	fmt.Fprintf(buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("can't format generated source %s", err)
+	}
+
+	err = ioutil.WriteFile(*out, src, 0644)
+	if err != nil {
+		log.Fatalf("can't write generated source %s", err)
+	}
+}
+
+// Raw string literal keeps sql readable, interpreted literal is used only if it is impossible
+func quote(s string) string {
+	if strings.ContainsAny(s, "`\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}